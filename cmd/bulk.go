@@ -0,0 +1,141 @@
+package main
+
+import (
+	"backend-app/internal/account"
+	"backend-app/internal/bulk"
+	"backend-app/internal/config"
+	"backend-app/internal/storage"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runImport выполняет подкоманду import. В отличие от API размер файла не ограничен,
+// а отчёт об ошибках печатается построчно.
+func runImport(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: main import [flags] <file.csv|file.ndjson|->")
+		fs.PrintDefaults()
+	}
+	format := fs.String("format", "", "csv or ndjson, by default taken from the file extension")
+	dryRun := fs.Bool("dry-run", false, "validate the file without saving users")
+	batchSize := fs.Int("batch-size", cfg.Bulk.BatchSize, "users per transaction")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("import file is required")
+	}
+
+	path := fs.Arg(0)
+	if *format == "" {
+		*format = formatFromPath(path)
+	}
+	in := io.Reader(os.Stdin)
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	reader, err := bulk.NewReader(in, *format)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	report, err := bulk.Import(context.Background(), withTimeouts(store, cfg), reader, bulk.ImportOptions{
+		BatchSize: *batchSize,
+		DryRun:    *dryRun,
+		Accounts:  account.Policy{Reserved: cfg.Account.ReservedUsernames},
+	})
+	for _, e := range report.Errors {
+		fmt.Fprintf(os.Stderr, "line %d %s: %s\n", e.Line, e.Username, e.Message)
+	}
+	if report.ErrorsTruncated {
+		fmt.Fprintf(os.Stderr, "only the first %d errors are shown\n", bulk.MaxReportErrors)
+	}
+	verb := "created"
+	if *dryRun {
+		verb = "would be created"
+	}
+	fmt.Printf("%d rows, %d users %s, %d failed\n", report.Total, report.Created, verb, report.Failed)
+	if err != nil {
+		return fmt.Errorf("import stopped: %w", err)
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("%d rows were not imported", report.Failed)
+	}
+	return nil
+}
+
+// runExport выполняет подкоманду export.
+func runExport(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "", "csv or ndjson, by default taken from -o or csv")
+	fields := fs.String("fields", "", "comma-separated fields: "+strings.Join(bulk.ExportFields, ", "))
+	output := fs.String("o", "-", "output file, - for stdout")
+	role := fs.String("role", "", "export only users with this role")
+	country := fs.String("country", "", "export only users from this country")
+	status := fs.String("status", "", "export only users with this status")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format == "" {
+		if *format = formatFromPath(*output); *format == "" {
+			*format = bulk.FormatCSV
+		}
+	}
+	selected, err := bulk.ParseFields(*fields)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	out := io.Writer(os.Stdout)
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	n, err := bulk.Export(context.Background(), out, withTimeouts(store, cfg), bulk.ExportOptions{
+		Format: *format,
+		Fields: selected,
+		Filter: storage.UserFilter{Role: *role, Country: *country, Status: *status},
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d users exported\n", n)
+	return nil
+}
+
+func formatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return bulk.FormatCSV
+	case ".ndjson", ".jsonl":
+		return bulk.FormatNDJSON
+	default:
+		return ""
+	}
+}
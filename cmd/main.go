@@ -6,14 +6,30 @@
 package main
 
 import (
+	"backend-app/internal/cache"
 	"backend-app/internal/config"
+	"backend-app/internal/country"
 	router "backend-app/internal/delivery/http"
+	"backend-app/internal/events"
+	"backend-app/internal/fieldcrypt"
+	"backend-app/internal/jobs/dataexport"
+	"backend-app/internal/jobs/delivery"
+	"backend-app/internal/jobs/purge"
+	"backend-app/internal/jobs/relay"
 	"backend-app/internal/server"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/memory"
 	"backend-app/internal/storage/postgres"
+	"backend-app/internal/storage/sqlite"
+	"backend-app/internal/webhooks"
 	"backend-app/pkg/logger"
 	"backend-app/pkg/sl"
+	"context"
+	"expvar"
+	"fmt"
 	"log"
 	"log/slog"
+	"os"
 )
 
 func main() {
@@ -22,19 +38,167 @@ func main() {
 	if err != nil {
 		log.Fatalf("Error reading config: %v", err)
 	}
+	if len(os.Args) > 1 {
+		commands := map[string]func(*config.Config, []string) error{
+			"migrate":          runMigrate,
+			"import":           runImport,
+			"export":           runExport,
+			"rotate-keys":      runRotateKeys,
+			"grant-superadmin": runGrantSuperAdmin,
+		}
+		if run, ok := commands[os.Args[1]]; ok {
+			if err := run(cfg, os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
 	log := logger.New(cfg.Env)
-	storage, err := postgres.New(cfg)
+	// gorm логирует медленные запросы через slog.Default()
+	slog.SetDefault(log)
+	// ошибка в списке стран иначе всплыла бы только на регистрации
+	if err := (country.Policy{Allow: cfg.Countries.Allow, Deny: cfg.Countries.Deny}).Validate(); err != nil {
+		log.Error("Invalid country lists", sl.Error(err))
+		os.Exit(1)
+	}
+	if err := cfg.Cookie.Validate(); err != nil {
+		log.Error("Invalid cookie config", sl.Error(err))
+		os.Exit(1)
+	}
+	store, err := openStorage(cfg)
 
 	if err != nil {
-		log.Error("Error connect to postgreSQL", sl.Error(err))
+		log.Error("Error connect to storage", slog.String("driver", cfg.Database.Driver), sl.Error(err))
+		os.Exit(1)
 	}
+	cached, err := withCache(store, cfg)
+	if err != nil {
+		log.Error("Error configure user cache", slog.String("backend", cfg.Cache.Backend), sl.Error(err))
+		os.Exit(1)
+	}
+	repo := withTimeouts(cached, cfg)
+
+	publisher, err := newPublisher(cfg, log)
+	if err != nil {
+		log.Error("Error configure event publisher", slog.String("publisher", cfg.Outbox.Publisher), sl.Error(err))
+		os.Exit(1)
+	}
+	// кроме основного получателя события всегда уходят в очереди webhooks
+	publisher = events.Fanout{publisher, webhooks.Dispatcher{Store: repo}}
 
 	log.Info("Starting server", "env", cfg.Env, "host", cfg.HTTPServer.Host)
 	log.Info("Server timeout", "timeout", cfg.HTTPServer.Timeout)
 	log.Info("Server idle timeout", "idle_timeout", cfg.HTTPServer.IdleTimeout)
-	r := router.InitRoutes(log, &storage, cfg)
+	go purge.Run(context.Background(), log, repo, purge.Options{
+		Retention:      cfg.SoftDelete.Retention,
+		LoginRetention: cfg.Stats.LoginRetention,
+		Interval:       cfg.SoftDelete.PurgeInterval,
+	})
+	go relay.Run(context.Background(), log, repo, publisher, relay.Options{
+		Interval:       cfg.Outbox.Interval,
+		BatchSize:      cfg.Outbox.BatchSize,
+		Lease:          cfg.Outbox.Lease,
+		MaxBackoff:     cfg.Outbox.MaxBackoff,
+		Retention:      cfg.Outbox.Retention,
+		PublishTimeout: cfg.Outbox.PublishTimeout,
+	})
+	go delivery.Run(context.Background(), log, repo, delivery.Options{
+		Interval:    cfg.Webhooks.Interval,
+		BatchSize:   cfg.Webhooks.BatchSize,
+		Lease:       cfg.Webhooks.Lease,
+		MaxAttempts: cfg.Webhooks.MaxAttempts,
+		MaxBackoff:  cfg.Webhooks.MaxBackoff,
+		Timeout:     cfg.Webhooks.RequestTimeout,
+	})
+
+	go dataexport.Run(context.Background(), log, repo, dataexport.Options{
+		Interval:  cfg.Privacy.ExportInterval,
+		BatchSize: cfg.Privacy.ExportBatchSize,
+		Retention: cfg.Privacy.ExportRetention,
+	})
+
+	r := router.InitRoutes(log, repo, cfg)
 
 	if err := server.ListenAndServe(r, cfg); err != nil {
 		log.Error("Error starting server: %v", slog.String("err", err.Error()))
 	}
 }
+
+// withTimeouts ограничивает время операций хранилища для HTTP-обработчиков и фоновых задач.
+func withTimeouts(repo storage.Store, cfg *config.Config) storage.Store {
+	return storage.WithTimeouts(repo, storage.Timeouts{
+		Default:      cfg.Database.QueryTimeout,
+		PerOperation: cfg.Database.Timeouts,
+	})
+}
+
+// withCache кеширует пользователей, которых читают по id. Счётчики кеша публикуются
+// в expvar как user_cache.
+func withCache(repo storage.Store, cfg *config.Config) (storage.Store, error) {
+	var c storage.Cache
+	switch cfg.Cache.Backend {
+	case "none", "":
+		return repo, nil
+	case "memory":
+		c = cache.NewLRU(cfg.Cache.Size)
+	case "redis":
+		c = cache.NewRedis(cache.RedisOptions{
+			Addr:     cfg.Cache.RedisAddr,
+			Password: cfg.Cache.RedisPassword,
+			DB:       cfg.Cache.RedisDB,
+		})
+	default:
+		return nil, fmt.Errorf("unknown cache backend: %s", cfg.Cache.Backend)
+	}
+	metrics := &storage.CacheMetrics{}
+	expvar.Publish("user_cache", metrics)
+	return storage.WithCache(repo, c, storage.CacheOptions{
+		TTL:     cfg.Cache.TTL,
+		Prefix:  cfg.Cache.KeyPrefix,
+		Metrics: metrics,
+	}), nil
+}
+
+func openStorage(cfg *config.Config) (storage.Store, error) {
+	keyring, err := fieldcrypt.Load(cfg.Encryption)
+	if err != nil {
+		return nil, fmt.Errorf("load encryption keys: %w", err)
+	}
+
+	switch cfg.Database.Driver {
+	case "postgres", "":
+		s, err := postgres.New(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return s, s.UseKeyring(keyring)
+	case "sqlite":
+		s, err := sqlite.New(cfg.Database.Path)
+		if err != nil {
+			return nil, err
+		}
+		return s, s.UseKeyring(keyring)
+	case "memory":
+		// в памяти данные не переживают процесс, шифровать нечего
+		return memory.New(), nil
+	default:
+		return nil, fmt.Errorf("unknown database driver: %s", cfg.Database.Driver)
+	}
+}
+
+func newPublisher(cfg *config.Config, log *slog.Logger) (events.Publisher, error) {
+	switch cfg.Outbox.Publisher {
+	case "log", "":
+		return events.LogPublisher{Log: log}, nil
+	case "webhook":
+		if cfg.Outbox.WebhookURL == "" {
+			return nil, fmt.Errorf("outbox.webhook_url is required for the webhook publisher")
+		}
+		return events.WebhookPublisher{URL: cfg.Outbox.WebhookURL}, nil
+	case "nats":
+		return &events.NATSPublisher{URL: cfg.Outbox.NATSURL, Subject: cfg.Outbox.NATSSubject}, nil
+	default:
+		return nil, fmt.Errorf("unknown event publisher: %s", cfg.Outbox.Publisher)
+	}
+}
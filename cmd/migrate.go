@@ -0,0 +1,95 @@
+package main
+
+import (
+	"backend-app/internal/config"
+	"backend-app/internal/storage/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const migrateUsage = `usage: main migrate <command>
+
+commands:
+  up            apply all pending migrations
+  down [n]      roll back the last n migrations (default 1)
+  status        list migrations and when they were applied
+  to <version>  migrate up or down to the given version (0 rolls back everything)`
+
+// runMigrate выполняет подкоманду migrate. Миграции есть только у postgres,
+// sqlite и memory создают схему сами.
+func runMigrate(cfg *config.Config, args []string) error {
+	if cfg.Database.Driver != "postgres" && cfg.Database.Driver != "" {
+		return fmt.Errorf("migrations are only supported for postgres, driver is %s", cfg.Database.Driver)
+	}
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	s, err := postgres.Open(cfg)
+	if err != nil {
+		return err
+	}
+	migrator, err := s.Migrator()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var done []postgres.Migration
+	switch args[0] {
+	case "up":
+		done, err = migrator.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps: %s", args[1])
+			}
+		}
+		done, err = migrator.Down(ctx, steps)
+	case "to":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, perr := strconv.ParseInt(args[1], 10, 64)
+		if perr != nil {
+			return fmt.Errorf("invalid version: %s", args[1])
+		}
+		done, err = migrator.To(ctx, version)
+	case "status":
+		return printMigrationStatus(ctx, migrator)
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	for _, mig := range done {
+		fmt.Printf("%04d_%s\n", mig.Version, mig.Name)
+	}
+	if err == nil && len(done) == 0 {
+		fmt.Println("nothing to do")
+	}
+	return err
+}
+
+func printMigrationStatus(ctx context.Context, migrator *postgres.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, st := range statuses {
+		applied := "pending"
+		if st.AppliedAt != nil {
+			applied = st.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", st.Version, st.Name, applied)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"backend-app/internal/config"
+	"context"
+	"flag"
+	"fmt"
+)
+
+type reencrypter interface {
+	ReencryptUsers(ctx context.Context, batchSize int, progress func(scanned, updated int64)) (int64, error)
+}
+
+// runRotateKeys выполняет подкоманду rotate-keys. Её запускают после смены
+// encryption.current_key и после первого включения шифрования; старый ключ можно
+// убрать из конфигурации, когда команда завершилась без ошибок.
+func runRotateKeys(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("rotate-keys", flag.ContinueOnError)
+	batchSize := fs.Int("batch-size", cfg.Encryption.RotateBatchSize, "users per transaction")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *batchSize < 1 {
+		return fmt.Errorf("batch size must be positive")
+	}
+
+	store, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	r, ok := store.(reencrypter)
+	if !ok {
+		return fmt.Errorf("driver %s does not encrypt fields", cfg.Database.Driver)
+	}
+
+	updated, err := r.ReencryptUsers(context.Background(), *batchSize, func(scanned, updated int64) {
+		fmt.Printf("%d users scanned, %d re-encrypted\n", scanned, updated)
+	})
+	if err != nil {
+		return fmt.Errorf("rotation stopped after %d users: %w", updated, err)
+	}
+	fmt.Printf("done, %d users re-encrypted\n", updated)
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"backend-app/internal/config"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"flag"
+	"fmt"
+)
+
+// runGrantSuperAdmin выполняет подкоманду grant-superadmin. Через API роль superadmin
+// выдаёт только другой superadmin, поэтому первого назначают этой командой.
+func runGrantSuperAdmin(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("grant-superadmin", flag.ContinueOnError)
+	id := fs.Uint("id", 0, "user id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *id == 0 {
+		return fmt.Errorf("user id is required")
+	}
+
+	store, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	user, err := store.GetUserByID(ctx, *id)
+	if err != nil {
+		return err
+	}
+	role := models.RoleSuperAdmin
+	// PatchUser меняет роль вместе с версией токенов, старые токены отзываются
+	if _, err := store.PatchUser(ctx, user.ID, user.Version, storage.UserPatch{Role: &role}); err != nil {
+		return err
+	}
+	fmt.Printf("user %d is now %s\n", user.ID, role)
+	return nil
+}
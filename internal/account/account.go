@@ -0,0 +1,73 @@
+// Package account описывает правила смены имени пользователя и email: какие имена
+// допустимы, как часто их можно менять и как подтверждается новый адрес.
+package account
+
+import (
+	"backend-app/internal/storage/models"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	// ErrInvalidUsername - в имени пробелы, управляющие символы или '@': по '@' вход
+	// отличает email от имени.
+	ErrInvalidUsername  = errors.New("username must not contain spaces, control characters or '@'")
+	ErrReservedUsername = errors.New("username is reserved")
+)
+
+// Policy - правила смены имени и email.
+type Policy struct {
+	// Reserved - имена, которые нельзя занять, сравниваются без учёта регистра
+	Reserved []string
+	// UsernameCooldown и EmailCooldown - сколько ждать между сменами, 0 - без ограничений
+	UsernameCooldown time.Duration
+	EmailCooldown    time.Duration
+	// ConfirmationTTL - сколько действует токен подтверждения нового email
+	ConfirmationTTL time.Duration
+}
+
+// CheckUsername проверяет, что имя допустимо и не зарезервировано.
+func (p Policy) CheckUsername(username string) error {
+	if username == "" || strings.ContainsRune(username, '@') || strings.IndexFunc(username, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	}) >= 0 {
+		return ErrInvalidUsername
+	}
+	key := models.NormalizeUsername(username)
+	for _, reserved := range p.Reserved {
+		if models.NormalizeUsername(reserved) == key {
+			return ErrReservedUsername
+		}
+	}
+	return nil
+}
+
+// NextChange возвращает момент, с которого значение, изменённое в last, можно менять
+// снова. Нулевое время - менять можно сразу.
+func NextChange(last *time.Time, cooldown time.Duration) time.Time {
+	if last == nil || cooldown <= 0 {
+		return time.Time{}
+	}
+	return last.Add(cooldown)
+}
+
+// NewToken создаёт токен подтверждения email. Токен уходит пользователю, в хранилище
+// лежит только hash.
+func NewToken() (token, hash string) {
+	b := make([]byte, 32)
+	// crypto/rand.Read не возвращает ошибок
+	_, _ = rand.Read(b)
+	token = hex.EncodeToString(b)
+	return token, HashToken(token)
+}
+
+// HashToken возвращает hash токена, по которому его ищут в хранилище.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,50 @@
+package account_test
+
+import (
+	"backend-app/internal/account"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckUsername(t *testing.T) {
+	p := account.Policy{Reserved: []string{"admin", "Support"}}
+	tests := []struct {
+		name     string
+		username string
+		want     error
+	}{
+		{name: "valid", username: "Alice_1"},
+		{name: "unicode", username: "Grüße"},
+		{name: "empty", username: "", want: account.ErrInvalidUsername},
+		{name: "space", username: "alice smith", want: account.ErrInvalidUsername},
+		{name: "control", username: "alice\x00", want: account.ErrInvalidUsername},
+		{name: "email", username: "alice@example.com", want: account.ErrInvalidUsername},
+		{name: "reserved", username: "admin", want: account.ErrReservedUsername},
+		{name: "reserved_case", username: "ADMIN", want: account.ErrReservedUsername},
+		{name: "reserved_list_case", username: "support", want: account.ErrReservedUsername},
+		{name: "reserved_width", username: "ａｄｍｉｎ", want: account.ErrReservedUsername},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, p.CheckUsername(tt.username), tt.want)
+		})
+	}
+}
+
+func TestNextChange(t *testing.T) {
+	last := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.True(t, account.NextChange(nil, time.Hour).IsZero(), "never changed")
+	assert.True(t, account.NextChange(&last, 0).IsZero(), "no cooldown")
+	assert.Equal(t, last.Add(time.Hour), account.NextChange(&last, time.Hour))
+}
+
+func TestNewToken(t *testing.T) {
+	token, hash := account.NewToken()
+	other, _ := account.NewToken()
+	assert.Len(t, token, 64)
+	assert.NotEqual(t, token, other)
+	assert.Equal(t, hash, account.HashToken(token))
+	assert.NotEqual(t, token, hash)
+}
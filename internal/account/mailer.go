@@ -0,0 +1,79 @@
+package account
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ErrMailerDisabled - почтовый сервис не настроен, отправить токен подтверждения некуда.
+var ErrMailerDisabled = errors.New("mailer is not configured")
+
+// Mailer отправляет пользователю токен подтверждения нового email. Токен передаётся
+// только сюда: в события, логи и webhooks он не попадает.
+type Mailer interface {
+	SendEmailConfirmation(ctx context.Context, to string, token string, expiresAt time.Time) error
+}
+
+// NewMailer возвращает WebhookMailer на url или DisabledMailer, если url пуст.
+func NewMailer(url string) Mailer {
+	if url == "" {
+		return DisabledMailer{}
+	}
+	return WebhookMailer{URL: url}
+}
+
+// DisabledMailer используется, пока почтовый сервис не настроен: смена email недоступна.
+type DisabledMailer struct{}
+
+func (DisabledMailer) SendEmailConfirmation(context.Context, string, string, time.Time) error {
+	return ErrMailerDisabled
+}
+
+// EmailConfirmation - тело запроса WebhookMailer.
+type EmailConfirmation struct {
+	To        string    `json:"to"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// WebhookMailer отправляет EmailConfirmation POST-запросом почтовому сервису на URL.
+// Любой ответ кроме 2xx считается ошибкой.
+type WebhookMailer struct {
+	URL    string
+	Client *http.Client
+}
+
+func (m WebhookMailer) SendEmailConfirmation(ctx context.Context, to string, token string, expiresAt time.Time) error {
+	body, err := json.Marshal(EmailConfirmation{To: to, Token: token, ExpiresAt: expiresAt})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := m.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// дочитываем тело, чтобы соединение вернулось в пул
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("mailer responded with %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package account_test
+
+import (
+	"backend-app/internal/account"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookMailer(t *testing.T) {
+	expires := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "accepted", status: http.StatusAccepted},
+		{name: "server error", status: http.StatusBadGateway, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+
+				var got account.EmailConfirmation
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+				assert.Equal(t, "new@example.com", got.To)
+				assert.Equal(t, "token", got.Token)
+				assert.True(t, expires.Equal(got.ExpiresAt))
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			err := account.NewMailer(srv.URL).SendEmailConfirmation(t.Context(), "new@example.com", "token", expires)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDisabledMailer(t *testing.T) {
+	err := account.NewMailer("").SendEmailConfirmation(t.Context(), "new@example.com", "token", time.Now())
+	assert.ErrorIs(t, err, account.ErrMailerDisabled)
+}
@@ -0,0 +1,230 @@
+package bulk_test
+
+import (
+	"backend-app/internal/account"
+	"backend-app/internal/bulk"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/memory"
+	"backend-app/internal/storage/models"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// hash - bcrypt от "secret" с минимальной стоимостью, чтобы тесты не ждали хеширования
+var hash = func() string {
+	b, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	return string(b)
+}()
+
+func importString(t *testing.T, store bulk.Importer, format, data string, opts bulk.ImportOptions) bulk.Report {
+	t.Helper()
+	r, err := bulk.NewReader(strings.NewReader(data), format)
+	require.NoError(t, err)
+	report, err := bulk.Import(t.Context(), store, r, opts)
+	require.NoError(t, err)
+	return report
+}
+
+func messages(report bulk.Report) map[int]string {
+	m := make(map[int]string)
+	for _, e := range report.Errors {
+		m[e.Line] = e.Message
+	}
+	return m
+}
+
+func TestImportCSV(t *testing.T) {
+	store := memory.New()
+	require.NoError(t, store.CreateUser(t.Context(), &models.User{Username: "taken", Email: "taken@example.com", Password: "x", Role: "user"}))
+
+	csv := "\ufeffusername,email,passwordHash,role,verified\n" +
+		"alice,alice@example.com," + hash + ",admin,true\n" +
+		"bob,not-an-email," + hash + ",,\n" +
+		"taken,other@example.com," + hash + ",,\n" +
+		"carol,carol@example.com,plain-text,,\n" +
+		"dave,dave@example.com," + hash + ",,maybe\n" +
+		"ALICE,alice2@example.com," + hash + ",,\n" +
+		"erin,erin@example.com\n" +
+		"frank,frank@example.com," + hash + ",,false\n"
+
+	for _, dryRun := range []bool{true, false} {
+		report := importString(t, store, bulk.FormatCSV, csv, bulk.ImportOptions{BatchSize: 2, DryRun: dryRun})
+		assert.Equal(t, dryRun, report.DryRun)
+		assert.Equal(t, 8, report.Total)
+		assert.Equal(t, 2, report.Created)
+		assert.Equal(t, 6, report.Failed)
+		assert.Equal(t, map[int]string{
+			3: "invalid email (email)",
+			4: "user already exists",
+			5: "passwordHash is not a bcrypt hash",
+			6: `invalid verified value "maybe"`,
+			7: "duplicate username, first seen on line 2",
+			8: "expected 5 columns, got 2",
+		}, messages(report))
+
+		_, err := store.GetUserByUsername(t.Context(), "alice")
+		if dryRun {
+			assert.ErrorIs(t, err, storage.ErrUserNotFound, "dry run saves nothing")
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+
+	alice, err := store.GetUserByUsername(t.Context(), "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", alice.Role)
+	assert.True(t, alice.Verified)
+	assert.Equal(t, models.StatusActive, alice.Status)
+	assert.NoError(t, alice.CheckPassword("secret"), "pre-hashed password is stored as is")
+	frank, err := store.GetUserByUsername(t.Context(), "frank")
+	require.NoError(t, err)
+	assert.Equal(t, "user", frank.Role)
+}
+
+func TestImportNDJSON(t *testing.T) {
+	store := memory.New()
+	ndjson := `{"username":"alice","email":"alice@example.com","password":"password123","country":"Norway"}
+
+{"username":"bob","email":"bob@example.com","password":"password123","passwordHash":"` + hash + `"}
+{"username":"carol","email":"carol@example.com"}
+{"username":"dave","email":"dave@example.com","password":"password123","admin":true}
+{"username":
+{"username":"erin","email":"erin@example.com","password":"password123","country":"Atlantis"}
+`
+	report := importString(t, store, bulk.FormatNDJSON, ndjson, bulk.ImportOptions{})
+	assert.Equal(t, 6, report.Total)
+	assert.Equal(t, 1, report.Created)
+	errs := messages(report)
+	require.Len(t, errs, 5)
+	assert.Contains(t, errs[3], "password (excluded_with)")
+	assert.Contains(t, errs[4], "password (required_without)")
+	assert.Contains(t, errs[5], "invalid json")
+	assert.Contains(t, errs[6], "invalid json")
+	assert.Contains(t, errs[7], "unknown country")
+
+	alice, err := store.GetUserByUsername(t.Context(), "alice")
+	require.NoError(t, err)
+	assert.NoError(t, alice.CheckPassword("password123"), "plain password is hashed")
+	assert.Equal(t, "NO", alice.Country, "countries are stored as ISO 3166-1 alpha-2")
+}
+
+func TestImportUsernames(t *testing.T) {
+	store := memory.New()
+	ndjson := `{"username":"alice","email":"alice@example.com","password":"password123"}
+{"username":"root","email":"root@example.com","password":"password123"}
+{"username":"bob@example.com","email":"bob@example.com","password":"password123"}
+{"username":"carol","email":"Alice@Example.com","password":"password123"}
+{"username":"ａｌｉｃｅ","email":"alice2@example.com","password":"password123"}
+`
+	report := importString(t, store, bulk.FormatNDJSON, ndjson, bulk.ImportOptions{Accounts: account.Policy{Reserved: []string{"root"}}})
+	assert.Equal(t, 5, report.Total)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, map[int]string{
+		2: account.ErrReservedUsername.Error(),
+		3: account.ErrInvalidUsername.Error(),
+		4: "duplicate email, first seen on line 1",
+		5: "duplicate username, first seen on line 1",
+	}, messages(report))
+}
+
+func TestNewReaderErrors(t *testing.T) {
+	_, err := bulk.NewReader(strings.NewReader(""), "xml")
+	assert.ErrorIs(t, err, bulk.ErrUnknownFormat)
+	_, err = bulk.NewReader(strings.NewReader(""), bulk.FormatCSV)
+	assert.Error(t, err)
+	_, err = bulk.NewReader(strings.NewReader("username,password_hash\n"), bulk.FormatCSV)
+	assert.EqualError(t, err, `unknown csv column "password_hash"`)
+}
+
+type failingImporter struct{}
+
+func (failingImporter) ImportUsers(ctx context.Context, users []*models.User, dryRun bool) ([]error, error) {
+	return nil, errors.New("db is down")
+}
+
+func TestImportStorageError(t *testing.T) {
+	r, err := bulk.NewReader(strings.NewReader("username,email,passwordHash\nalice,alice@example.com,"+hash+"\n"), bulk.FormatCSV)
+	require.NoError(t, err)
+	_, err = bulk.Import(t.Context(), failingImporter{}, r, bulk.ImportOptions{})
+	assert.ErrorIs(t, err, bulk.ErrStorage)
+	assert.ErrorContains(t, err, "db is down")
+}
+
+func seed(t *testing.T, n int) *memory.Storage {
+	store := memory.New()
+	for i := range n {
+		name := "user" + string(rune('a'+i%26)) + strings.Repeat("x", i/26)
+		role := "user"
+		if i%2 == 0 {
+			role = "admin"
+		}
+		require.NoError(t, store.CreateUser(t.Context(), &models.User{Username: name, Email: name + "@example.com", Password: hash, Role: role}))
+	}
+	return store
+}
+
+func TestExportCSV(t *testing.T) {
+	store := seed(t, 250)
+	var buf bytes.Buffer
+	n, err := bulk.Export(t.Context(), &buf, store, bulk.ExportOptions{Format: bulk.FormatCSV, Fields: []string{"username", "id", "verified"}})
+	require.NoError(t, err)
+	assert.Equal(t, 250, n, "export goes past the page size")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 251)
+	assert.Equal(t, "username,id,verified", lines[0])
+	assert.Equal(t, "usera,1,false", lines[1])
+	assert.Equal(t, "userpxxxxxxxxx,250,false", lines[250])
+	assert.NotContains(t, buf.String(), hash)
+}
+
+func TestExportNDJSON(t *testing.T) {
+	store := seed(t, 3)
+	var buf bytes.Buffer
+	n, err := bulk.Export(t.Context(), &buf, store, bulk.ExportOptions{
+		Format: bulk.FormatNDJSON,
+		Fields: []string{"id", "email", "role"},
+		Filter: storage.UserFilter{Role: "admin"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, `{"id":1,"email":"usera@example.com","role":"admin"}`+"\n"+
+		`{"id":3,"email":"userc@example.com","role":"admin"}`+"\n", buf.String())
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := bulk.Export(t.Context(), &buf, seed(t, 5), bulk.ExportOptions{Format: bulk.FormatCSV, Fields: []string{"username", "email", "role"}})
+	require.NoError(t, err)
+
+	// выгрузка без паролей, поэтому добавляем колонку с хешем
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for i := range lines {
+		if i == 0 {
+			lines[i] += ",passwordHash"
+		} else {
+			lines[i] += "," + hash
+		}
+	}
+	report := importString(t, memory.New(), bulk.FormatCSV, strings.Join(lines, "\n"), bulk.ImportOptions{})
+	assert.Equal(t, 5, report.Created)
+	assert.Empty(t, report.Errors)
+}
+
+func TestParseFields(t *testing.T) {
+	fields, err := bulk.ParseFields("")
+	require.NoError(t, err)
+	assert.Equal(t, bulk.ExportFields, fields)
+	fields, err = bulk.ParseFields("email, id")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"email", "id"}, fields)
+	_, err = bulk.ParseFields("id,password")
+	assert.EqualError(t, err, `unknown export field "password"`)
+}
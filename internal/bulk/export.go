@@ -0,0 +1,212 @@
+package bulk
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportFields - поля, которые можно выгрузить, в порядке по умолчанию.
+// Хеши паролей и токены не выгружаются никогда.
+var ExportFields = []string{
+	"id", "username", "email", "role", "country", "status", "verified", "version", "createdAt", "updatedAt",
+}
+
+type Lister interface {
+	ListUsers(ctx context.Context, q storage.UserQuery) (storage.UserPage, error)
+}
+
+type ExportOptions struct {
+	Format string
+	// Fields - выгружаемые поля из ExportFields, пусто - все
+	Fields []string
+	Filter storage.UserFilter
+}
+
+// ParseFields разбирает список полей через запятую и проверяет, что они есть в ExportFields.
+func ParseFields(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return ExportFields, nil
+	}
+	var fields []string
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if !isExportField(f) {
+			return nil, fmt.Errorf("unknown export field %q", f)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func isExportField(f string) bool {
+	for _, ef := range ExportFields {
+		if ef == f {
+			return true
+		}
+	}
+	return false
+}
+
+// Export выгружает пользователей страницами по id и пишет каждую страницу в w сразу,
+// так что ответ идёт клиенту, пока выгрузка продолжается. Если w умеет Flush, как
+// http.ResponseWriter, он вызывается после каждой страницы. Возвращает число пользователей.
+func Export(ctx context.Context, w io.Writer, lister Lister, opts ExportOptions) (int, error) {
+	fields := opts.Fields
+	if len(fields) == 0 {
+		fields = ExportFields
+	}
+	for _, f := range fields {
+		if !isExportField(f) {
+			return 0, fmt.Errorf("unknown export field %q", f)
+		}
+	}
+
+	var enc encoder
+	switch opts.Format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(fields); err != nil {
+			return 0, err
+		}
+		enc = &csvEncoder{w: cw, fields: fields}
+	case FormatNDJSON:
+		enc = &ndjsonEncoder{w: bufio.NewWriter(w), fields: fields}
+	default:
+		return 0, ErrUnknownFormat
+	}
+	flusher, _ := w.(interface{ Flush() })
+
+	q := storage.UserQuery{Filter: opts.Filter, SortField: "id", Limit: storage.MaxPageSize}
+	total := 0
+	for {
+		page, err := lister.ListUsers(ctx, q)
+		if err != nil {
+			return total, err
+		}
+		for i := range page.Users {
+			if err := enc.encode(&page.Users[i]); err != nil {
+				return total, err
+			}
+		}
+		total += len(page.Users)
+		if err := enc.flush(); err != nil {
+			return total, err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if page.NextCursor == "" {
+			return total, nil
+		}
+		if q.Cursor, err = storage.DecodeCursor(page.NextCursor); err != nil {
+			return total, err
+		}
+	}
+}
+
+type encoder interface {
+	encode(u *models.User) error
+	flush() error
+}
+
+type csvEncoder struct {
+	w      *csv.Writer
+	fields []string
+	row    []string
+}
+
+func (e *csvEncoder) encode(u *models.User) error {
+	e.row = e.row[:0]
+	for _, f := range e.fields {
+		e.row = append(e.row, csvValue(u, f))
+	}
+	return e.w.Write(e.row)
+}
+
+func (e *csvEncoder) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func csvValue(u *models.User, field string) string {
+	switch v := value(u, field).(type) {
+	case string:
+		return v
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type ndjsonEncoder struct {
+	w      *bufio.Writer
+	fields []string
+}
+
+// encode пишет поля в запрошенном порядке, поэтому объект собирается вручную, а не из map.
+func (e *ndjsonEncoder) encode(u *models.User) error {
+	e.w.WriteByte('{')
+	for i, f := range e.fields {
+		if i > 0 {
+			e.w.WriteByte(',')
+		}
+		v := value(u, f)
+		if t, ok := v.(time.Time); ok {
+			v = t.UTC()
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(e.w, "%q:", f)
+		e.w.Write(b)
+	}
+	_, err := e.w.WriteString("}\n")
+	return err
+}
+
+func (e *ndjsonEncoder) flush() error {
+	return e.w.Flush()
+}
+
+func value(u *models.User, field string) any {
+	switch field {
+	case "id":
+		return u.ID
+	case "username":
+		return u.Username
+	case "email":
+		return u.Email
+	case "role":
+		return u.Role
+	case "country":
+		return u.Country
+	case "status":
+		return u.Status
+	case "verified":
+		return u.Verified
+	case "version":
+		return u.Version
+	case "createdAt":
+		return u.CreatedAt
+	case "updatedAt":
+		return u.UpdatedAt
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,238 @@
+package bulk
+
+import (
+	"backend-app/internal/account"
+	"backend-app/internal/country"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrStorage оборачивает ошибки хранилища, остальные ошибки Import - ошибки чтения файла.
+var ErrStorage = errors.New("failed to save users")
+
+// MaxReportErrors ограничивает число ошибок в отчёте, Failed при этом считает все.
+const MaxReportErrors = 1000
+
+type Importer interface {
+	ImportUsers(ctx context.Context, users []*models.User, dryRun bool) ([]error, error)
+}
+
+type ImportOptions struct {
+	// BatchSize - сколько пользователей сохраняется одной транзакцией
+	BatchSize int
+	// DryRun проверяет файл и конфликты с базой, ничего не сохраняя
+	DryRun bool
+	// Accounts проверяет имена так же, как регистрация
+	Accounts account.Policy
+}
+
+// Report - итог импорта. С DryRun Created - сколько пользователей было бы создано.
+type Report struct {
+	DryRun  bool       `json:"dryRun"`
+	Total   int        `json:"total"`
+	Created int        `json:"created"`
+	Failed  int        `json:"failed"`
+	Errors  []RowError `json:"errors"`
+	// ErrorsTruncated - в Errors попали не все ошибки, см. MaxReportErrors
+	ErrorsTruncated bool `json:"errorsTruncated,omitempty"`
+}
+
+func (r *Report) fail(e RowError) {
+	r.Failed++
+	if len(r.Errors) < MaxReportErrors {
+		r.Errors = append(r.Errors, e)
+	} else {
+		r.ErrorsTruncated = true
+	}
+}
+
+type pending struct {
+	line int
+	user *models.User
+	// hash - в user.Password открытый пароль, который нужно захешировать
+	hash bool
+}
+
+// Import читает записи из r и сохраняет их пачками по opts.BatchSize. Строки с ошибками
+// попадают в отчёт и не мешают остальным. Если хранилище вернуло ошибку, импорт
+// останавливается: пачки до неё уже сохранены, и отчёт описывает их.
+func Import(ctx context.Context, store Importer, r Reader, opts ImportOptions) (Report, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	report := Report{DryRun: opts.DryRun, Errors: []RowError{}}
+	validate := validator.New()
+	// пользователи из разных пачек друг друга не видят, пока пачки не сохранены,
+	// поэтому дубли внутри файла ловятся здесь, а не в хранилище
+	seen := make(map[string]int)
+	var batch []pending
+
+	for {
+		rec, line, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			report.Total++
+			report.fail(*rowErr)
+			continue
+		}
+		if err != nil {
+			return report, err
+		}
+		report.Total++
+
+		if err := validate.Struct(rec); err != nil {
+			report.fail(RowError{Line: line, Username: rec.Username, Message: validationMessage(err)})
+			continue
+		}
+		if err := opts.Accounts.CheckUsername(rec.Username); err != nil {
+			report.fail(RowError{Line: line, Username: rec.Username, Message: err.Error()})
+			continue
+		}
+		if rec.Country, err = country.Normalize(rec.Country); err != nil {
+			report.fail(RowError{Line: line, Username: rec.Username, Message: err.Error()})
+			continue
+		}
+		if rec.PasswordHash != "" {
+			if _, err := bcrypt.Cost([]byte(rec.PasswordHash)); err != nil {
+				report.fail(RowError{Line: line, Username: rec.Username, Message: "passwordHash is not a bcrypt hash"})
+				continue
+			}
+		}
+		// в хранилище username и email уникальны без учёта регистра, здесь так же
+		usernameKey, emailKey := "u:"+models.NormalizeUsername(rec.Username), "e:"+models.NormalizeEmail(rec.Email)
+		if first, ok := seen[usernameKey]; ok {
+			report.fail(RowError{Line: line, Username: rec.Username, Message: fmt.Sprintf("duplicate username, first seen on line %d", first)})
+			continue
+		}
+		if first, ok := seen[emailKey]; ok {
+			report.fail(RowError{Line: line, Username: rec.Username, Message: fmt.Sprintf("duplicate email, first seen on line %d", first)})
+			continue
+		}
+		seen[usernameKey], seen[emailKey] = line, line
+
+		batch = append(batch, pending{line: line, user: toModel(rec), hash: rec.PasswordHash == ""})
+		if len(batch) == opts.BatchSize {
+			if err := saveBatch(ctx, store, batch, opts.DryRun, &report); err != nil {
+				return report, err
+			}
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		if err := saveBatch(ctx, store, batch, opts.DryRun, &report); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+func toModel(rec Record) *models.User {
+	user := &models.User{
+		Username: rec.Username,
+		Email:    rec.Email,
+		Password: rec.PasswordHash,
+		Role:     rec.Role,
+		Country:  rec.Country,
+		Status:   rec.Status,
+		Verified: rec.Verified,
+	}
+	if user.Password == "" {
+		user.Password = rec.Password
+	}
+	if user.Role == "" {
+		user.Role = "user"
+	}
+	return user
+}
+
+func saveBatch(ctx context.Context, store Importer, batch []pending, dryRun bool, report *Report) error {
+	users := make([]*models.User, 0, len(batch))
+	for _, p := range batch {
+		users = append(users, p.user)
+	}
+	if err := hashPasswords(ctx, batch); err != nil {
+		return err
+	}
+
+	errs, err := store.ImportUsers(ctx, users, dryRun)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStorage, err)
+	}
+	for i, p := range batch {
+		switch {
+		case errs[i] == nil:
+			report.Created++
+		case errors.Is(errs[i], storage.ErrUserExists):
+			report.fail(RowError{Line: p.line, Username: p.user.Username, Message: "user already exists"})
+		default:
+			report.fail(RowError{Line: p.line, Username: p.user.Username, Message: errs[i].Error()})
+		}
+	}
+	return nil
+}
+
+// hashPasswords хеширует открытые пароли пачки на всех ядрах: bcrypt медленный намеренно,
+// и без этого импорт упирается в него, а не в базу. Готовые хеши не трогаются.
+func hashPasswords(ctx context.Context, batch []pending) error {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	for _, p := range batch {
+		if !p.hash {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(user *models.User) {
+			defer func() { <-sem; wg.Done() }()
+			if err := user.HashPassword(); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}(p.user)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// validationMessage перечисляет поля, не прошедшие проверку, без внутренних имён тегов.
+func validationMessage(err error) string {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return err.Error()
+	}
+	fields := make([]string, 0, len(verrs))
+	for _, fe := range verrs {
+		fields = append(fields, jsonName(fe.Field())+" ("+fe.Tag()+")")
+	}
+	return "invalid " + strings.Join(fields, ", ")
+}
+
+func jsonName(field string) string {
+	if field == "" {
+		return field
+	}
+	return strings.ToLower(field[:1]) + field[1:]
+}
@@ -0,0 +1,182 @@
+// Package bulk импортирует пользователей из CSV и NDJSON и выгружает их в те же форматы.
+// Файлы читаются и пишутся потоком, поэтому их размер не ограничен памятью.
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+var ErrUnknownFormat = errors.New("unknown format, expected csv or ndjson")
+
+// Record - пользователь из файла импорта. Пароль передаётся открытым текстом в Password
+// или уже захешированным bcrypt в PasswordHash, например при переезде из другой системы.
+type Record struct {
+	Username     string `json:"username" validate:"required"`
+	Email        string `json:"email" validate:"required,email"`
+	Password     string `json:"password" validate:"required_without=PasswordHash,excluded_with=PasswordHash,max=72"`
+	PasswordHash string `json:"passwordHash"`
+	// Role по умолчанию user
+	Role    string `json:"role" validate:"omitempty,oneof=user creator combined admin"`
+	Country string `json:"country"`
+	// Status по умолчанию active
+	Status   string `json:"status" validate:"omitempty,oneof=active suspended"`
+	Verified bool   `json:"verified"`
+}
+
+// RowError - ошибка одной строки файла, остальные строки она не затрагивает.
+type RowError struct {
+	Line     int    `json:"line"`
+	Username string `json:"username,omitempty"`
+	Message  string `json:"error"`
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// Reader читает записи импорта по одной.
+type Reader interface {
+	// Read возвращает следующую запись и номер строки, с которой она началась, и io.EOF,
+	// когда записи кончились. Ошибка *RowError относится только к этой строке, после
+	// неё чтение можно продолжать, любая другая ошибка означает, что файл не дочитать.
+	Read() (Record, int, error)
+}
+
+// NewReader читает r в формате csv или ndjson. В CSV первая строка - заголовок
+// с именами полей Record в том же написании, что и в JSON, порядок колонок любой.
+func NewReader(r io.Reader, format string) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatNDJSON:
+		s := bufio.NewScanner(r)
+		s.Buffer(make([]byte, 64<<10), 1<<20)
+		return &ndjsonReader{scanner: s}, nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+type csvReader struct {
+	r       *csv.Reader
+	columns []string
+}
+
+var csvColumns = map[string]bool{
+	"username": true, "email": true, "password": true, "passwordHash": true,
+	"role": true, "country": true, "status": true, "verified": true,
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("csv file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	// Excel сохраняет UTF-8 с BOM
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	seen := make(map[string]bool)
+	for i, col := range header {
+		col = strings.TrimSpace(col)
+		if !csvColumns[col] {
+			return nil, fmt.Errorf("unknown csv column %q", col)
+		}
+		if seen[col] {
+			return nil, fmt.Errorf("duplicate csv column %q", col)
+		}
+		seen[col] = true
+		header[i] = col
+	}
+	return &csvReader{r: cr, columns: header}, nil
+}
+
+func (c *csvReader) Read() (Record, int, error) {
+	fields, err := c.r.Read()
+	if err == io.EOF {
+		return Record{}, 0, io.EOF
+	}
+	var line int
+	if fields != nil {
+		line, _ = c.r.FieldPos(0)
+	}
+	if errors.Is(err, csv.ErrFieldCount) {
+		return Record{}, line, &RowError{Line: line, Message: fmt.Sprintf("expected %d columns, got %d", len(c.columns), len(fields))}
+	}
+	if err != nil {
+		return Record{}, 0, err
+	}
+
+	var rec Record
+	for i, col := range c.columns {
+		v := fields[i]
+		switch col {
+		case "username":
+			rec.Username = v
+		case "email":
+			rec.Email = v
+		case "password":
+			rec.Password = v
+		case "passwordHash":
+			rec.PasswordHash = v
+		case "role":
+			rec.Role = v
+		case "country":
+			rec.Country = v
+		case "status":
+			rec.Status = v
+		case "verified":
+			if v == "" {
+				continue
+			}
+			if rec.Verified, err = strconv.ParseBool(v); err != nil {
+				return rec, line, &RowError{Line: line, Username: rec.Username, Message: fmt.Sprintf("invalid verified value %q", v)}
+			}
+		}
+	}
+	return rec, line, nil
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (n *ndjsonReader) Read() (Record, int, error) {
+	for n.scanner.Scan() {
+		n.line++
+		b := bytes.TrimSpace(n.scanner.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+
+		var rec Record
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&rec); err != nil {
+			return rec, n.line, &RowError{Line: n.line, Username: rec.Username, Message: "invalid json: " + err.Error()}
+		}
+		return rec, n.line, nil
+	}
+	if err := n.scanner.Err(); err != nil {
+		return Record{}, 0, err
+	}
+	return Record{}, 0, io.EOF
+}
@@ -0,0 +1,64 @@
+package cache_test
+
+import (
+	"backend-app/internal/cache"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU(t *testing.T) {
+	ctx := t.Context()
+	c := cache.NewLRU(2)
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, c.Set(ctx, "b", []byte("2"), time.Minute))
+	// a использовали позже b, вытесняется b
+	_, ok, _ := c.Get(ctx, "a")
+	assert.True(t, ok)
+	require.NoError(t, c.Set(ctx, "c", []byte("3"), time.Minute))
+	assert.Equal(t, 2, c.Len())
+
+	tests := []struct {
+		key   string
+		value string
+		ok    bool
+	}{
+		{"a", "1", true},
+		{"b", "", false},
+		{"c", "3", true},
+	}
+	for _, tt := range tests {
+		v, ok, err := c.Get(ctx, tt.key)
+		require.NoError(t, err)
+		assert.Equal(t, tt.ok, ok, tt.key)
+		if tt.ok {
+			assert.Equal(t, tt.value, string(v), tt.key)
+		}
+	}
+
+	require.NoError(t, c.Set(ctx, "a", []byte("new"), time.Minute))
+	v, _, _ := c.Get(ctx, "a")
+	assert.Equal(t, "new", string(v))
+
+	require.NoError(t, c.Delete(ctx, "a", "missing"))
+	_, ok, _ = c.Get(ctx, "a")
+	assert.False(t, ok)
+}
+
+func TestLRUExpiry(t *testing.T) {
+	ctx := t.Context()
+	c := cache.NewLRU(10)
+
+	require.NoError(t, c.Set(ctx, "short", []byte("1"), 10*time.Millisecond))
+	require.NoError(t, c.Set(ctx, "long", []byte("2"), time.Minute))
+	time.Sleep(20 * time.Millisecond)
+
+	_, ok, _ := c.Get(ctx, "short")
+	assert.False(t, ok)
+	_, ok, _ = c.Get(ctx, "long")
+	assert.True(t, ok)
+	assert.Equal(t, 1, c.Len(), "expired entry is dropped on read")
+}
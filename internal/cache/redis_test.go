@@ -0,0 +1,180 @@
+package cache_test
+
+import (
+	"backend-app/internal/cache"
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis - замена Redis для тестов: понимает AUTH, SELECT, GET, SET с PX и DEL.
+type fakeRedis struct {
+	password string
+
+	mu       sync.Mutex
+	values   map[string]string
+	expires  map[string]time.Time
+	selected []string
+}
+
+func startFakeRedis(t *testing.T, password string) (*fakeRedis, string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	s := &fakeRedis{password: password, values: map[string]string{}, expires: map[string]time.Time{}}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s, ln.Addr().String()
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authed := s.password == ""
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		var reply string
+		switch cmd := strings.ToUpper(args[0]); {
+		case cmd == "AUTH":
+			if args[1] != s.password {
+				reply = "-WRONGPASS invalid password\r\n"
+				break
+			}
+			authed = true
+			reply = "+OK\r\n"
+		case !authed:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case cmd == "SELECT":
+			s.mu.Lock()
+			s.selected = append(s.selected, args[1])
+			s.mu.Unlock()
+			reply = "+OK\r\n"
+		case cmd == "GET":
+			reply = s.get(args[1])
+		case cmd == "SET":
+			ms, _ := strconv.Atoi(args[4])
+			s.mu.Lock()
+			s.values[args[1]] = args[2]
+			s.expires[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+			s.mu.Unlock()
+			reply = "+OK\r\n"
+		case cmd == "DEL":
+			n := 0
+			s.mu.Lock()
+			for _, key := range args[1:] {
+				if _, ok := s.values[key]; ok {
+					delete(s.values, key)
+					n++
+				}
+			}
+			s.mu.Unlock()
+			reply = fmt.Sprintf(":%d\r\n", n)
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeRedis) get(key string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.values[key]
+	if !ok || time.Now().After(s.expires[key]) {
+		return "$-1\r\n"
+	}
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	var n int
+	if _, err := fmt.Fscanf(r, "*%d\r\n", &n); err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		var size int
+		if _, err := fmt.Fscanf(r, "$%d\r\n", &size); err != nil {
+			return nil, err
+		}
+		b := make([]byte, size+2)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		args[i] = string(b[:size])
+	}
+	return args, nil
+}
+
+func TestRedis(t *testing.T) {
+	ctx := t.Context()
+	server, addr := startFakeRedis(t, "secret")
+	c := cache.NewRedis(cache.RedisOptions{Addr: addr, Password: "secret", DB: 2})
+	defer c.Close()
+
+	_, ok, err := c.Get(ctx, "user:1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	value := []byte("binary\x00\r\nvalue")
+	require.NoError(t, c.Set(ctx, "user:1", value, time.Minute))
+	got, ok, err := c.Get(ctx, "user:1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, value, got)
+
+	require.NoError(t, c.Set(ctx, "user:2", []byte("x"), 10*time.Millisecond))
+	time.Sleep(20 * time.Millisecond)
+	_, ok, err = c.Get(ctx, "user:2")
+	require.NoError(t, err)
+	assert.False(t, ok, "expired by PX")
+
+	require.NoError(t, c.Delete(ctx, "user:1", "user:3"))
+	_, ok, err = c.Get(ctx, "user:1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Equal(t, []string{"2"}, server.selected, "one pooled connection selects the database once")
+}
+
+func TestRedisErrors(t *testing.T) {
+	ctx := t.Context()
+	_, addr := startFakeRedis(t, "secret")
+
+	c := cache.NewRedis(cache.RedisOptions{Addr: addr, Password: "wrong"})
+	_, _, err := c.Get(ctx, "user:1")
+	assert.ErrorContains(t, err, "WRONGPASS")
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closed := ln.Addr().String()
+	ln.Close()
+	c = cache.NewRedis(cache.RedisOptions{Addr: closed, DialTimeout: 100 * time.Millisecond})
+	_, _, err = c.Get(ctx, "user:1")
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/golang-jwt/jwt/v5"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
@@ -15,6 +16,17 @@ type Config struct {
 	SwaggerPath string `yaml:"swagger_path" env-default:"./docs/swagger.json"`
 	HTTPServer `yaml:"http_server"`
 	Database   `yaml:"database"`
+	Cookie     `yaml:"cookie"`
+	SoftDelete `yaml:"soft_delete"`
+	Outbox     `yaml:"outbox"`
+	Webhooks   `yaml:"webhooks"`
+	Bulk       `yaml:"bulk"`
+	Privacy    `yaml:"privacy"`
+	Encryption `yaml:"encryption"`
+	Cache      `yaml:"cache"`
+	Countries  `yaml:"countries"`
+	Stats      `yaml:"stats"`
+	Account    `yaml:"account"`
 }
 
 type HTTPServer struct {
@@ -23,11 +35,185 @@ type HTTPServer struct {
 	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"30"`
 }
 type Database struct {
+	// Driver: postgres, sqlite или memory
+	Driver   string `yaml:"driver" env-default:"postgres"`
+	Path     string `yaml:"path" env-default:"./auth.db"`
 	Host     string `yaml:"host" env-default:"localhost"`
 	Port     int    `yaml:"port" env-default:"5432"`
 	DBName   string `yaml:"dbname" env-default:"auth"`
 	User     string `yaml:"user" env-default:"postgres"`
 	Password string `yaml:"password" env-default:"postgres"`
+	// SSLMode - sslmode libpq: disable, require, verify-ca или verify-full
+	SSLMode     string `yaml:"sslmode" env-default:"disable"`
+	SSLRootCert string `yaml:"sslrootcert"`
+	SSLCert     string `yaml:"sslcert"`
+	SSLKey      string `yaml:"sslkey"`
+	TimeZone    string `yaml:"timezone" env-default:"UTC"`
+	Pool        Pool   `yaml:"pool"`
+	// Replicas - реплики только для чтения, пользователь, пароль, база и SSL берутся у primary
+	Replicas []Replica `yaml:"replicas"`
+	// QueryTimeout ограничивает каждую операцию с хранилищем, Timeouts задаёт
+	// свои значения для отдельных методов UserRepository, например PurgeDeletedUsers
+	QueryTimeout time.Duration            `yaml:"query_timeout" env-default:"3s"`
+	Timeouts     map[string]time.Duration `yaml:"timeouts"`
+}
+
+// Pool настраивает пул соединений, одинаково для primary и реплик.
+type Pool struct {
+	MaxOpenConns    int           `yaml:"max_open_conns" env-default:"25"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env-default:"5"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env-default:"30m"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env-default:"5m"`
+}
+
+type Replica struct {
+	Host string `yaml:"host"`
+	// Port 0 - тот же порт, что у primary
+	Port int `yaml:"port"`
+}
+
+// SoftDelete задаёт, сколько удалённые пользователи хранятся до окончательного удаления.
+type SoftDelete struct {
+	Retention     time.Duration `yaml:"retention" env-default:"720h"`
+	PurgeInterval time.Duration `yaml:"purge_interval" env-default:"1h"`
+}
+
+// Outbox настраивает публикацию доменных событий о пользователях из outbox.
+type Outbox struct {
+	// Publisher: log, webhook или nats
+	Publisher  string        `yaml:"publisher" env-default:"log"`
+	Interval   time.Duration `yaml:"interval" env-default:"1s"`
+	BatchSize  int           `yaml:"batch_size" env-default:"100"`
+	Lease      time.Duration `yaml:"lease" env-default:"1m"`
+	MaxBackoff time.Duration `yaml:"max_backoff" env-default:"10m"`
+	Retention  time.Duration `yaml:"retention" env-default:"168h"`
+	// PublishTimeout ограничивает публикацию одного события
+	PublishTimeout time.Duration `yaml:"publish_timeout" env-default:"10s"`
+	WebhookURL     string        `yaml:"webhook_url"`
+	NATSURL        string        `yaml:"nats_url" env-default:"nats://localhost:4222"`
+	NATSSubject    string        `yaml:"nats_subject" env-default:"users"`
+}
+
+// Webhooks настраивает отправку событий на webhooks, зарегистрированные через API.
+type Webhooks struct {
+	Interval    time.Duration `yaml:"interval" env-default:"5s"`
+	BatchSize   int           `yaml:"batch_size" env-default:"50"`
+	Lease       time.Duration `yaml:"lease" env-default:"2m"`
+	MaxAttempts int           `yaml:"max_attempts" env-default:"10"`
+	MaxBackoff  time.Duration `yaml:"max_backoff" env-default:"1h"`
+	// RequestTimeout ограничивает один запрос к webhook
+	RequestTimeout time.Duration `yaml:"request_timeout" env-default:"10s"`
+}
+
+// Bulk настраивает импорт и экспорт пользователей через API.
+type Bulk struct {
+	BatchSize int `yaml:"batch_size" env-default:"500"`
+	// MaxImportSize - наибольший размер файла импорта в байтах, файлы больше грузите командой import
+	MaxImportSize int64 `yaml:"max_import_size" env-default:"33554432"`
+	// RequestTimeout заменяет таймауты http_server для запросов импорта и экспорта
+	RequestTimeout time.Duration `yaml:"request_timeout" env-default:"10m"`
+}
+
+// Privacy настраивает выгрузку данных пользователей по их запросу.
+type Privacy struct {
+	ExportInterval  time.Duration `yaml:"export_interval" env-default:"10s"`
+	ExportBatchSize int           `yaml:"export_batch_size" env-default:"10"`
+	// ExportRetention - сколько готовый архив доступен для скачивания
+	ExportRetention time.Duration `yaml:"export_retention" env-default:"168h"`
+}
+
+// Encryption задаёт ключи шифрования email и country в таблице users. Без ключей
+// данные хранятся открыто. KeyFile - JSON вида
+// {"current": "k1", "keys": {"k1": "<base64>"}, "indexKey": "<base64>"}, он заменяет
+// Keys, CurrentKey и IndexKey. Все ключи - 32 случайных байта в base64.
+// С шифрованием поиск пользователей в postgres находит email и country только по
+// точному значению: нечётко ищется лишь username.
+type Encryption struct {
+	KeyFile string `yaml:"key_file"`
+	// Keys - ключи шифрования ключей по идентификаторам, старые нужны, пока
+	// rotate-keys не перешифрует записанные ими строки
+	Keys       map[string]string `yaml:"keys"`
+	CurrentKey string            `yaml:"current_key"`
+	// IndexKey - ключ слепых индексов, его смена требует пересчитать все индексы
+	IndexKey        string `yaml:"index_key"`
+	RotateBatchSize int    `yaml:"rotate_batch_size" env-default:"500"`
+}
+
+// Cache настраивает кеш пользователей, которых читают по id: middleware проверки
+// токенов и refresh. Кеш в памяти у каждого экземпляра свой, изменения, сделанные
+// другим экземпляром, видны после TTL. Redis общий для всех экземпляров, в нём
+// пользователи лежат целиком, с хешем пароля и refresh token, закройте его от чужих.
+type Cache struct {
+	// Backend: none, memory или redis
+	Backend string        `yaml:"backend" env-default:"none"`
+	TTL     time.Duration `yaml:"ttl" env-default:"1m"`
+	// Size - наибольшее число пользователей в кеше в памяти
+	Size          int    `yaml:"size" env-default:"10000"`
+	RedisAddr     string `yaml:"redis_addr" env-default:"localhost:6379"`
+	RedisPassword string `yaml:"redis_password"`
+	RedisDB       int    `yaml:"redis_db"`
+	// KeyPrefix отделяет ключи сервиса, если Redis общий с другими
+	KeyPrefix string `yaml:"key_prefix" env-default:"auth:"`
+}
+
+// Countries ограничивает страны, из которых можно зарегистрироваться: непустой Allow
+// пускает только перечисленные, Deny запрещает свои в любом случае. Страны задаются
+// кодами ISO 3166-1 или английскими названиями.
+type Countries struct {
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+}
+
+// Stats настраивает статистику для администраторов. Отчёты кешируются в памяти каждого
+// экземпляра на CacheTTL, поэтому свежие регистрации и входы видны не сразу. Входы
+// старше LoginRetention удаляет задача очистки, статистика за более ранний период
+// показывает их нулями.
+type Stats struct {
+	CacheTTL time.Duration `yaml:"cache_ttl" env-default:"5m"`
+	// CacheSize - наибольшее число отчётов в кеше
+	CacheSize      int           `yaml:"cache_size" env-default:"100"`
+	LoginRetention time.Duration `yaml:"login_retention" env-default:"9504h"`
+}
+
+// Account настраивает смену имени и email пользователем. Имена из ReservedUsernames
+// нельзя занять ни при регистрации, ни при смене имени.
+type Account struct {
+	ReservedUsernames []string      `yaml:"reserved_usernames" env-default:"admin,administrator,root,support,help,security,system,api,null,me"`
+	UsernameCooldown  time.Duration `yaml:"username_cooldown" env-default:"720h"`
+	EmailCooldown     time.Duration `yaml:"email_cooldown" env-default:"24h"`
+	// EmailConfirmationTTL - сколько действует ссылка подтверждения нового email
+	EmailConfirmationTTL time.Duration `yaml:"email_confirmation_ttl" env-default:"24h"`
+	// MailerURL - почтовый сервис, которому уходит токен подтверждения нового email.
+	// Пока он не задан, смена email недоступна
+	MailerURL string `yaml:"mailer_url"`
+}
+
+// Cookie описывает режим для браузера: токены кладутся в cookie, а не в тело ответа.
+type Cookie struct {
+	Enabled        bool   `yaml:"enabled" env-default:"false"`
+	AccessInCookie bool   `yaml:"access_in_cookie" env-default:"true"`
+	Domain         string `yaml:"domain"`
+	Secure         bool   `yaml:"secure" env-default:"true"`
+	SameSite       string `yaml:"same_site" env-default:"strict"`
+	// AllowedOrigins - источники, которым CORS разрешает запросы с cookie.
+	// Обязателен в режиме cookie: браузер не отправит cookie на "*"
+	AllowedOrigins []string `yaml:"allowed_origins"`
+}
+
+// Validate проверяет, что в режиме cookie перечислены конкретные источники.
+func (c Cookie) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if len(c.AllowedOrigins) == 0 {
+		return fmt.Errorf("cookie.allowed_origins is required when cookie.enabled is set")
+	}
+	for _, origin := range c.AllowedOrigins {
+		if strings.Contains(origin, "*") {
+			return fmt.Errorf("cookie.allowed_origins: wildcard origin %q is not allowed with credentials", origin)
+		}
+	}
+	return nil
 }
 
 func ReadConfig() (*Config, error) {
@@ -58,5 +244,12 @@ type TokenPair struct {
 type Claims struct {
 	UserID uint `json:"user_id"`
 	Role string `json:"role"`
+	// TokenVersion должен совпадать с users.token_version, иначе токен отозван
+	TokenVersion uint `json:"ver"`
+	// OrgID - активная организация, Role тогда - роль пользователя в ней. 0 - токен
+	// выпущен без организации, и Role - глобальная роль пользователя
+	OrgID uint `json:"org_id,omitempty"`
+	// Perms - действующие права пользователя с учётом групп, только в access token
+	Perms []string `json:"perms,omitempty"`
 	jwt.RegisteredClaims
 }
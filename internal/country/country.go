@@ -0,0 +1,155 @@
+// Package country проверяет страны по ISO 3166-1, приводит их к кодам alpha-2 и
+// называет на языке клиента. Коды и английские названия лежат в countries.csv,
+// названия на других языках берутся из CLDR.
+package country
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+var (
+	ErrUnknown = errors.New("unknown country, expected an ISO 3166-1 code or English name")
+	// ErrNotAllowed - из страны нельзя регистрироваться, см. Policy.
+	ErrNotAllowed = errors.New("registration from this country is not allowed")
+)
+
+// Country - страна из ISO 3166-1.
+type Country struct {
+	Alpha2  string
+	Alpha3  string
+	Numeric string
+	// Name - короткое английское название
+	Name string
+}
+
+//go:embed countries.csv
+var data string
+
+var (
+	countries []Country
+	// index ищет страну по alpha-2, alpha-3, числовому коду и названию в верхнем регистре
+	index map[string]*Country
+)
+
+func init() {
+	rows, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("country: invalid countries.csv: %v", err))
+	}
+	// первая строка - заголовок
+	countries = make([]Country, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		countries = append(countries, Country{Alpha2: row[0], Alpha3: row[1], Numeric: row[2], Name: row[3]})
+	}
+	index = make(map[string]*Country, 4*len(countries))
+	for i := range countries {
+		c := &countries[i]
+		for _, key := range []string{c.Alpha2, c.Alpha3, c.Numeric, c.Name} {
+			index[strings.ToUpper(key)] = c
+		}
+	}
+}
+
+// All возвращает все страны по возрастанию alpha-2.
+func All() []Country {
+	return append([]Country(nil), countries...)
+}
+
+// Lookup ищет страну по коду alpha-2, alpha-3, числовому коду или английскому
+// названию без учёта регистра.
+func Lookup(s string) (Country, bool) {
+	c, ok := index[strings.ToUpper(strings.TrimSpace(s))]
+	if !ok {
+		return Country{}, false
+	}
+	return *c, true
+}
+
+// Normalize приводит страну к коду alpha-2. Пустая строка остаётся пустой: страна
+// у пользователя необязательна.
+func Normalize(s string) (string, error) {
+	if strings.TrimSpace(s) == "" {
+		return "", nil
+	}
+	c, ok := Lookup(s)
+	if !ok {
+		return "", ErrUnknown
+	}
+	return c.Alpha2, nil
+}
+
+// Name возвращает название страны с кодом alpha-2 на языке lang, а если для языка
+// нет названия - по-английски. Для неизвестного кода возвращает пустую строку.
+func Name(code string, lang language.Tag) string {
+	c, ok := index[strings.ToUpper(code)]
+	if !ok || c.Alpha2 != strings.ToUpper(code) {
+		return ""
+	}
+	if namer := display.Regions(lang); namer != nil {
+		if region, err := language.ParseRegion(c.Alpha2); err == nil {
+			if name := namer.Name(region); name != "" {
+				return name
+			}
+		}
+	}
+	return c.Name
+}
+
+// английский первым: он достаётся клиентам, чьи языки не поддерживаются
+var matcher = language.NewMatcher(append([]language.Tag{language.English}, display.Supported.Tags()...))
+
+// Language выбирает язык названий по заголовку Accept-Language.
+func Language(acceptLanguage string) language.Tag {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return language.English
+	}
+	_, i, _ := matcher.Match(tags...)
+	if i == 0 {
+		return language.English
+	}
+	return display.Supported.Tags()[i-1]
+}
+
+// Policy ограничивает страны, из которых можно зарегистрироваться. Непустой Allow
+// пускает только перечисленные страны, Deny запрещает свои страны в любом случае.
+// В списках - любые значения, которые понимает Lookup.
+type Policy struct {
+	Allow []string
+	Deny  []string
+}
+
+// Validate проверяет, что в списках только известные страны.
+func (p Policy) Validate() error {
+	for _, s := range append(append([]string(nil), p.Allow...), p.Deny...) {
+		if _, ok := Lookup(s); !ok {
+			return fmt.Errorf("%w: %q", ErrUnknown, s)
+		}
+	}
+	return nil
+}
+
+// Check возвращает ErrNotAllowed, если из страны с кодом alpha-2 code регистрироваться
+// нельзя. Без страны можно зарегистрироваться, только если Allow пуст.
+func (p Policy) Check(code string) error {
+	if contains(p.Deny, code) || (len(p.Allow) > 0 && !contains(p.Allow, code)) {
+		return ErrNotAllowed
+	}
+	return nil
+}
+
+func contains(list []string, code string) bool {
+	for _, s := range list {
+		if c, ok := Lookup(s); ok && c.Alpha2 == code {
+			return true
+		}
+	}
+	return false
+}
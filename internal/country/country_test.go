@@ -0,0 +1,77 @@
+package country_test
+
+import (
+	"backend-app/internal/country"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestAll(t *testing.T) {
+	all := country.All()
+	assert.Len(t, all, 249, "officially assigned ISO 3166-1 codes")
+	for _, c := range all {
+		assert.Len(t, c.Alpha2, 2)
+		assert.Len(t, c.Alpha3, 3)
+		assert.Len(t, c.Numeric, 3)
+		assert.NotEmpty(t, c.Name)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "NL", want: "NL"},
+		{in: "nl", want: "NL"},
+		{in: " NLD ", want: "NL"},
+		{in: "528", want: "NL"},
+		{in: "netherlands", want: "NL"},
+		{in: "Côte d’Ivoire", want: "CI"},
+		{in: "", want: ""},
+	}
+	for _, tt := range tests {
+		got, err := country.Normalize(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	for _, in := range []string{"Testland", "UK", "SU", "XK", "N"} {
+		_, err := country.Normalize(in)
+		assert.ErrorIs(t, err, country.ErrUnknown, in)
+	}
+}
+
+func TestName(t *testing.T) {
+	assert.Equal(t, "Netherlands", country.Name("NL", language.English))
+	assert.Equal(t, "Niederlande", country.Name("NL", language.German))
+	assert.Equal(t, "Нидерланды", country.Name("NL", language.Russian))
+	assert.Equal(t, "Niederlande", country.Name("NL", country.Language("de-CH,de;q=0.9,en;q=0.5")))
+	assert.Equal(t, "Netherlands", country.Name("NL", country.Language("tlh")), "unsupported languages fall back to English")
+	assert.Equal(t, "Netherlands", country.Name("NL", country.Language("")))
+	assert.Empty(t, country.Name("Norway", language.English), "only alpha-2 codes are named")
+	assert.Empty(t, country.Name("", language.English))
+}
+
+func TestPolicy(t *testing.T) {
+	open := country.Policy{}
+	assert.NoError(t, open.Check("NL"))
+	assert.NoError(t, open.Check(""), "country is optional without an allow list")
+
+	deny := country.Policy{Deny: []string{"KP", "IRN"}}
+	assert.NoError(t, deny.Validate())
+	assert.ErrorIs(t, deny.Check("KP"), country.ErrNotAllowed)
+	assert.ErrorIs(t, deny.Check("IR"), country.ErrNotAllowed, "lists accept any code Lookup understands")
+	assert.NoError(t, deny.Check("NL"))
+
+	allow := country.Policy{Allow: []string{"NL", "BE"}, Deny: []string{"BE"}}
+	assert.NoError(t, allow.Check("NL"))
+	assert.ErrorIs(t, allow.Check("BE"), country.ErrNotAllowed, "deny wins")
+	assert.ErrorIs(t, allow.Check("DE"), country.ErrNotAllowed)
+	assert.ErrorIs(t, allow.Check(""), country.ErrNotAllowed)
+
+	assert.ErrorIs(t, country.Policy{Allow: []string{"Atlantis"}}.Validate(), country.ErrUnknown)
+}
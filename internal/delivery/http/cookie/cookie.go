@@ -0,0 +1,96 @@
+package cookie
+
+import (
+	"backend-app/internal/config"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	AccessToken  = "access_token"
+	RefreshToken = "refresh_token"
+	CSRFToken    = "csrf_token"
+	CSRFHeader   = "X-CSRF-Token"
+
+	// refresh token нужен только эндпоинту обновления, в остальные запросы браузер его не шлёт
+	RefreshPath = "/v1/refresh"
+)
+
+// TokenResponse отдаётся вместо config.TokenPair, когда включён режим cookie.
+type TokenResponse struct {
+	AccessToken string `json:"access_token,omitempty"`
+	CSRFToken   string `json:"csrf_token"`
+}
+
+// SetTokens кладёт токены в cookie и выставляет новый CSRF токен (double-submit).
+func SetTokens(w http.ResponseWriter, cfg config.Cookie, tokens config.TokenPair) (TokenResponse, error) {
+	csrf, err := newCSRFToken()
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	http.SetCookie(w, newCookie(cfg, RefreshToken, tokens.RefreshToken, RefreshPath, config.RefreshTokenExpiry, true))
+	http.SetCookie(w, newCookie(cfg, CSRFToken, csrf, "/", config.RefreshTokenExpiry, false))
+
+	resp := TokenResponse{CSRFToken: csrf}
+	if cfg.AccessInCookie {
+		http.SetCookie(w, newCookie(cfg, AccessToken, tokens.AccessToken, "/", config.AccessTokenExpiry, true))
+	} else {
+		resp.AccessToken = tokens.AccessToken
+	}
+	return resp, nil
+}
+
+// TokenFromCookie достаёт access token из cookie, подходит для jwtauth.Verify.
+func TokenFromCookie(r *http.Request) string {
+	c, err := r.Cookie(AccessToken)
+	if err != nil {
+		return ""
+	}
+	return c.Value
+}
+
+// RefreshTokenFromCookie достаёт refresh token из cookie.
+func RefreshTokenFromCookie(r *http.Request) string {
+	c, err := r.Cookie(RefreshToken)
+	if err != nil {
+		return ""
+	}
+	return c.Value
+}
+
+func newCookie(cfg config.Cookie, name, value, path string, ttl time.Duration, httpOnly bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   cfg.Domain,
+		MaxAge:   int(ttl.Seconds()),
+		Expires:  time.Now().Add(ttl),
+		HttpOnly: httpOnly,
+		Secure:   cfg.Secure,
+		SameSite: sameSite(cfg.SameSite),
+	}
+}
+
+func sameSite(mode string) http.SameSite {
+	switch strings.ToLower(mode) {
+	case "lax":
+		return http.SameSiteLaxMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteStrictMode
+	}
+}
+
+func newCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,102 @@
+package dto_test
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/delivery/http/v1/getDeletedUsers"
+	"backend-app/internal/delivery/http/v1/searchUsers"
+	"backend-app/internal/storage/models"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sensitive - json имена, которые не должны попадать в ответы API.
+var sensitive = map[string]bool{
+	"password":     true,
+	"refreshtoken": true,
+	"tokenexpiry":  true,
+	"tokenversion": true,
+}
+
+// responseTypes - все типы, которые хендлеры отдают с данными пользователей.
+// Новый тип ответа с пользователем нужно добавить сюда.
+var responseTypes = []interface{}{
+	dto.User{},
+	dto.Me{},
+	dto.Webhook{},
+	dto.WebhookDelivery{},
+	dto.ErasureRequest{},
+	getDeletedUsers.DeletedUser{},
+	searchUsers.Result{},
+}
+
+func TestResponseTypesHideSensitiveFields(t *testing.T) {
+	for _, v := range responseTypes {
+		typ := reflect.TypeOf(v)
+		t.Run(typ.String(), func(t *testing.T) {
+			checkType(t, typ, typ.Name(), map[reflect.Type]bool{})
+		})
+	}
+}
+
+func checkType(t *testing.T, typ reflect.Type, path string, seen map[reflect.Type]bool) {
+	for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Map {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || seen[typ] {
+		return
+	}
+	seen[typ] = true
+
+	if typ == reflect.TypeOf(models.User{}) {
+		t.Errorf("%s exposes models.User, map it to dto.User", path)
+		return
+	}
+	if !strings.HasPrefix(typ.PkgPath(), "backend-app/") {
+		return
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" && f.Anonymous {
+			checkType(t, f.Type, path, seen)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		key := strings.ReplaceAll(strings.ToLower(name), "_", "")
+		if sensitive[key] {
+			t.Errorf("%s.%s exposes sensitive field %q", path, f.Name, name)
+		}
+		checkType(t, f.Type, path+"."+f.Name, seen)
+	}
+}
+
+func TestNewUserDropsSecrets(t *testing.T) {
+	user := models.User{
+		ID:           1,
+		Username:     "alice",
+		Password:     "$2a$10$secret-hash",
+		Email:        "alice@example.com",
+		RefreshToken: "secret-refresh-token",
+		TokenVersion: 7,
+		Version:      3,
+	}
+
+	b, err := json.Marshal(dto.NewUsers([]models.User{user}))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "secret")
+	assert.Contains(t, string(b), `"username":"alice"`)
+	assert.Contains(t, string(b), `"version":3`)
+}
@@ -0,0 +1,97 @@
+package dto
+
+import (
+	"backend-app/internal/permissions"
+	"backend-app/internal/storage/models"
+	"time"
+)
+
+type Group struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// ParentID - группа, права которой наследует эта
+	ParentID    *uint     `json:"parentId"`
+	Roles       []string  `json:"roles"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func NewGroup(g *models.Group) Group {
+	return Group{
+		ID:          g.ID,
+		Name:        g.Name,
+		Description: g.Description,
+		ParentID:    g.ParentID,
+		Roles:       g.RoleList(),
+		Permissions: g.PermissionList(),
+		CreatedAt:   g.CreatedAt,
+		UpdatedAt:   g.UpdatedAt,
+	}
+}
+
+func NewGroups(groups []models.Group) []Group {
+	res := make([]Group, 0, len(groups))
+	for i := range groups {
+		res = append(res, NewGroup(&groups[i]))
+	}
+	return res
+}
+
+type GroupMember struct {
+	GroupID   uint      `json:"groupId"`
+	UserID    uint      `json:"userId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func NewGroupMembers(members []models.GroupMember) []GroupMember {
+	res := make([]GroupMember, 0, len(members))
+	for _, m := range members {
+		res = append(res, GroupMember{GroupID: m.GroupID, UserID: m.UserID, CreatedAt: m.CreatedAt})
+	}
+	return res
+}
+
+type GroupRef struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// Grant объясняет, откуда у пользователя право.
+type Grant struct {
+	Permission string `json:"permission"`
+	// Role - роль, которая дала право, нет у прав, выданных группе напрямую
+	Role string `json:"role,omitempty"`
+	// Groups - путь от группы пользователя до группы, которая выдала право. Пустой у
+	// прав роли самого пользователя
+	Groups []GroupRef `json:"groups"`
+}
+
+func NewGrants(grants []permissions.Grant) []Grant {
+	res := make([]Grant, 0, len(grants))
+	for _, g := range grants {
+		grant := Grant{Permission: g.Permission, Role: g.Role, Groups: make([]GroupRef, 0, len(g.Path))}
+		for _, group := range g.Path {
+			grant.Groups = append(grant.Groups, GroupRef{ID: group.ID, Name: group.Name})
+		}
+		res = append(res, grant)
+	}
+	return res
+}
+
+// UserPermissions - действующие права пользователя и их источники.
+type UserPermissions struct {
+	UserID      uint     `json:"userId"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+	Grants      []Grant  `json:"grants"`
+}
+
+// PermissionExplanation отвечает, есть ли у пользователя право и почему.
+type PermissionExplanation struct {
+	UserID     uint    `json:"userId"`
+	Permission string  `json:"permission"`
+	Granted    bool    `json:"granted"`
+	Grants     []Grant `json:"grants"`
+}
@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"backend-app/internal/storage/models"
+	"time"
+)
+
+type Organization struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func NewOrganization(o *models.Organization) Organization {
+	return Organization{
+		ID:        o.ID,
+		Name:      o.Name,
+		CreatedAt: o.CreatedAt,
+		UpdatedAt: o.UpdatedAt,
+	}
+}
+
+func NewOrganizations(orgs []models.Organization) []Organization {
+	res := make([]Organization, 0, len(orgs))
+	for i := range orgs {
+		res = append(res, NewOrganization(&orgs[i]))
+	}
+	return res
+}
+
+// Membership - участие пользователя в организации и его роль в ней.
+type Membership struct {
+	OrgID     uint      `json:"orgId"`
+	UserID    uint      `json:"userId"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	// Organization есть только в списке организаций пользователя
+	Organization *Organization `json:"organization,omitempty"`
+}
+
+func NewMembership(m *models.Membership) Membership {
+	res := Membership{
+		OrgID:     m.OrgID,
+		UserID:    m.UserID,
+		Role:      m.Role,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+	if m.Organization != nil {
+		org := NewOrganization(m.Organization)
+		res.Organization = &org
+	}
+	return res
+}
+
+func NewMemberships(members []models.Membership) []Membership {
+	res := make([]Membership, 0, len(members))
+	for i := range members {
+		res = append(res, NewMembership(&members[i]))
+	}
+	return res
+}
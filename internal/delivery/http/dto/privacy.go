@@ -0,0 +1,104 @@
+package dto
+
+import (
+	"backend-app/internal/storage/models"
+	"time"
+)
+
+// DataExport - выгрузка данных пользователя, архив скачивается отдельно.
+type DataExport struct {
+	ID          uint       `json:"id"`
+	Format      string     `json:"format"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	// ExpiresAt - после этого архив удаляется
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+func NewDataExport(e *models.DataExport) DataExport {
+	return DataExport{
+		ID:          e.ID,
+		Format:      e.Format,
+		Status:      e.Status,
+		Error:       e.Error,
+		CreatedAt:   e.CreatedAt,
+		CompletedAt: e.CompletedAt,
+		ExpiresAt:   e.ExpiresAt,
+	}
+}
+
+func NewDataExports(exports []models.DataExport) []DataExport {
+	res := make([]DataExport, 0, len(exports))
+	for i := range exports {
+		res = append(res, NewDataExport(&exports[i]))
+	}
+	return res
+}
+
+// ErasureRequest - запрос пользователя на удаление персональных данных.
+type ErasureRequest struct {
+	ID     uint   `json:"id"`
+	UserID uint   `json:"userId"`
+	Reason string `json:"reason,omitempty"`
+	Status string `json:"status"`
+	// ReviewedBy - администратор, который одобрил или отклонил запрос
+	ReviewedBy  *uint      `json:"reviewedBy,omitempty"`
+	ReviewNote  string     `json:"reviewNote,omitempty"`
+	ReviewedAt  *time.Time `json:"reviewedAt,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+func NewErasureRequest(r *models.ErasureRequest) ErasureRequest {
+	return ErasureRequest{
+		ID:          r.ID,
+		UserID:      r.UserID,
+		Reason:      r.Reason,
+		Status:      r.Status,
+		ReviewedBy:  r.ReviewedBy,
+		ReviewNote:  r.ReviewNote,
+		ReviewedAt:  r.ReviewedAt,
+		CompletedAt: r.CompletedAt,
+		CreatedAt:   r.CreatedAt,
+	}
+}
+
+func NewErasureRequests(reqs []models.ErasureRequest) []ErasureRequest {
+	res := make([]ErasureRequest, 0, len(reqs))
+	for i := range reqs {
+		res = append(res, NewErasureRequest(&reqs[i]))
+	}
+	return res
+}
+
+// ErasureReceipt подтверждает, что данные пользователя обезличены: кто и когда
+// одобрил запрос, какие поля стёрты и сколько записей журнала очищено.
+type ErasureReceipt struct {
+	RequestID          uint      `json:"requestId"`
+	UserID             uint      `json:"userId"`
+	RequestedAt        time.Time `json:"requestedAt"`
+	ApprovedBy         uint      `json:"approvedBy"`
+	CompletedAt        time.Time `json:"completedAt"`
+	ErasedFields       []string  `json:"erasedFields"`
+	ScrubbedEvents     int64     `json:"scrubbedEvents"`
+	ScrubbedDeliveries int64     `json:"scrubbedDeliveries"`
+}
+
+// NewErasureReceipt строит квитанцию по завершённому запросу, ok - false, если он не завершён.
+func NewErasureReceipt(r *models.ErasureRequest) (ErasureReceipt, bool) {
+	if r.Status != models.ErasureCompleted || r.CompletedAt == nil || r.ReviewedBy == nil {
+		return ErasureReceipt{}, false
+	}
+	return ErasureReceipt{
+		RequestID:          r.ID,
+		UserID:             r.UserID,
+		RequestedAt:        r.CreatedAt,
+		ApprovedBy:         *r.ReviewedBy,
+		CompletedAt:        *r.CompletedAt,
+		ErasedFields:       models.ErasedFields,
+		ScrubbedEvents:     r.ScrubbedEvents,
+		ScrubbedDeliveries: r.ScrubbedDeliveries,
+	}, true
+}
@@ -0,0 +1,84 @@
+package dto
+
+import (
+	"backend-app/internal/storage/models"
+	"time"
+)
+
+type Profile struct {
+	UserID            uint                   `json:"userId"`
+	DisplayName       string                 `json:"displayName"`
+	AvatarURL         string                 `json:"avatarUrl"`
+	Bio               string                 `json:"bio"`
+	Locale            string                 `json:"locale"`
+	TimeZone          string                 `json:"timeZone"`
+	NativeLanguages   []string               `json:"nativeLanguages"`
+	LearningLanguages []models.LanguageSkill `json:"learningLanguages"`
+	// Attributes - значения атрибутов из схемы по их ключам
+	Attributes map[string]interface{} `json:"attributes" swaggertype:"object"`
+	// UpdatedAt пустой, пока профиль ни разу не сохраняли
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+}
+
+func NewProfile(p *models.Profile) Profile {
+	return Profile{
+		UserID:            p.UserID,
+		DisplayName:       p.DisplayName,
+		AvatarURL:         p.AvatarURL,
+		Bio:               p.Bio,
+		Locale:            p.Locale,
+		TimeZone:          p.TimeZone,
+		NativeLanguages:   p.NativeLanguageList(),
+		LearningLanguages: p.LearningLanguageList(),
+		Attributes:        p.Attributes,
+		UpdatedAt:         p.UpdatedAt,
+	}
+}
+
+// Me - пользователь из токена вместе с его профилем.
+type Me struct {
+	User
+	Profile Profile `json:"profile"`
+}
+
+type AttributeDefinition struct {
+	Key          string    `json:"key"`
+	Type         string    `json:"type"`
+	Description  string    `json:"description"`
+	Required     bool      `json:"required"`
+	UserEditable bool      `json:"userEditable"`
+	Enum         []string  `json:"enum,omitempty"`
+	MinLength    *int      `json:"minLength,omitempty"`
+	MaxLength    *int      `json:"maxLength,omitempty"`
+	Minimum      *float64  `json:"minimum,omitempty"`
+	Maximum      *float64  `json:"maximum,omitempty"`
+	Pattern      string    `json:"pattern,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+func NewAttributeDefinition(d *models.AttributeDefinition) AttributeDefinition {
+	return AttributeDefinition{
+		Key:          d.Key,
+		Type:         d.Type,
+		Description:  d.Description,
+		Required:     d.Required,
+		UserEditable: d.UserEditable,
+		Enum:         d.EnumList(),
+		MinLength:    d.MinLength,
+		MaxLength:    d.MaxLength,
+		Minimum:      d.Minimum,
+		Maximum:      d.Maximum,
+		Pattern:      d.Pattern,
+		CreatedAt:    d.CreatedAt,
+		UpdatedAt:    d.UpdatedAt,
+	}
+}
+
+func NewAttributeDefinitions(defs []models.AttributeDefinition) []AttributeDefinition {
+	res := make([]AttributeDefinition, 0, len(defs))
+	for i := range defs {
+		res = append(res, NewAttributeDefinition(&defs[i]))
+	}
+	return res
+}
@@ -0,0 +1,66 @@
+// Package dto содержит типы, которые API отдаёт клиентам. models.User наружу не
+// сериализуется: в нём хеш пароля, refresh token и служебные поля хранилища.
+package dto
+
+import (
+	"backend-app/internal/country"
+	"backend-app/internal/storage/models"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// User - пользователь в ответах API.
+type User struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	Country  string `json:"country"`
+	// CountryName - название страны на языке из Accept-Language, пустое для значений
+	// не из ISO 3166-1, сохранённых до проверки стран
+	CountryName string    `json:"countryName,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	Verified    bool      `json:"verified"`
+	Version     uint      `json:"version"`
+	CreatedAt   time.Time `json:"createdAt,omitempty"`
+	UpdatedAt   time.Time `json:"updatedAt,omitempty"`
+}
+
+func NewUser(u *models.User) User {
+	return User{
+		ID:          u.ID,
+		Username:    u.Username,
+		Email:       u.Email,
+		Role:        u.Role,
+		Country:     u.Country,
+		CountryName: country.Name(u.Country, language.English),
+		Status:      u.Status,
+		Verified:    u.Verified,
+		Version:     u.Version,
+		CreatedAt:   u.CreatedAt,
+		UpdatedAt:   u.UpdatedAt,
+	}
+}
+
+func NewUsers(users []models.User) []User {
+	res := make([]User, 0, len(users))
+	for i := range users {
+		res = append(res, NewUser(&users[i]))
+	}
+	return res
+}
+
+// Localize называет страну на языке lang.
+func (u User) Localize(lang language.Tag) User {
+	u.CountryName = country.Name(u.Country, lang)
+	return u
+}
+
+// LocalizeUsers называет страны пользователей на языке lang.
+func LocalizeUsers(users []User, lang language.Tag) []User {
+	for i := range users {
+		users[i] = users[i].Localize(lang)
+	}
+	return users
+}
@@ -0,0 +1,91 @@
+package dto
+
+import (
+	"backend-app/internal/storage/models"
+	"encoding/json"
+	"time"
+)
+
+// Webhook - webhook в ответах API. Секрет отдаётся только при создании.
+type Webhook struct {
+	ID        uint      `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func NewWebhook(w *models.Webhook) Webhook {
+	return Webhook{
+		ID:        w.ID,
+		URL:       w.URL,
+		Events:    w.EventTypes(),
+		Active:    w.Active,
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+func NewWebhooks(hooks []models.Webhook) []Webhook {
+	res := make([]Webhook, 0, len(hooks))
+	for i := range hooks {
+		res = append(res, NewWebhook(&hooks[i]))
+	}
+	return res
+}
+
+// WebhookDelivery - запись журнала доставок.
+type WebhookDelivery struct {
+	ID             uint            `json:"id"`
+	WebhookID      uint            `json:"webhookId"`
+	EventID        string          `json:"eventId"`
+	EventType      string          `json:"eventType"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"responseStatus,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	// NextAttemptAt есть только у доставок, ожидающих отправки
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+	DeliveredAt   *time.Time `json:"deliveredAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+func NewWebhookDelivery(d *models.WebhookDelivery) WebhookDelivery {
+	res := WebhookDelivery{
+		ID:             d.ID,
+		WebhookID:      d.WebhookID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        json.RawMessage(d.Payload),
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+	if d.Status == models.DeliveryPending {
+		next := d.NextAttemptAt
+		res.NextAttemptAt = &next
+	}
+	return res
+}
+
+func NewWebhookDeliveries(deliveries []models.WebhookDelivery) []WebhookDelivery {
+	res := make([]WebhookDelivery, 0, len(deliveries))
+	for i := range deliveries {
+		res = append(res, NewWebhookDelivery(&deliveries[i]))
+	}
+	return res
+}
+
+// WebhookWithSecret отдаётся при создании webhook и смене секрета: больше секрет
+// через API не получить.
+type WebhookWithSecret struct {
+	Webhook
+	Secret string `json:"secret,omitempty"`
+}
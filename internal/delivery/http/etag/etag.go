@@ -0,0 +1,29 @@
+// Package etag переводит версию пользователя в ETag и обратно.
+package etag
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalid = errors.New("invalid etag")
+
+// Format возвращает сильный ETag для версии.
+func Format(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// Parse достаёт версию из заголовка If-Match. Слабые ETag и "*" не подходят:
+// If-Match сравнивает только сильные ETag, а "*" не защищает от потерянных обновлений.
+func Parse(header string) (uint, error) {
+	header = strings.TrimSpace(header)
+	if len(header) < 3 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, ErrInvalid
+	}
+	version, err := strconv.ParseUint(header[1:len(header)-1], 10, 64)
+	if err != nil || version == 0 {
+		return 0, ErrInvalid
+	}
+	return uint(version), nil
+}
@@ -0,0 +1,38 @@
+package etag_test
+
+import (
+	"backend-app/internal/delivery/http/etag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		header  string
+		version uint
+		wantErr bool
+	}{
+		{header: `"3"`, version: 3},
+		{header: ` "42" `, version: 42},
+		{header: etag.Format(7), version: 7},
+		{header: `W/"3"`, wantErr: true},
+		{header: `*`, wantErr: true},
+		{header: `3`, wantErr: true},
+		{header: `"0"`, wantErr: true},
+		{header: `"abc"`, wantErr: true},
+		{header: ``, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			version, err := etag.Parse(tt.header)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, etag.ErrInvalid)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.version, version)
+		})
+	}
+}
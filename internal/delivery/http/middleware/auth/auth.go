@@ -2,10 +2,15 @@ package authMiddleware
 
 import (
 	"backend-app/internal/config"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
 	"net/http"
+	"strconv"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/jwtauth/v5"
-	"github.com/go-chi/render"
 )
 
 var (
@@ -23,8 +28,7 @@ func Authenticator(next http.Handler) http.Handler {
 		token, _, err := jwtauth.FromContext(r.Context())
 
 		if err != nil || token == nil {
-			render.Status(r, http.StatusUnauthorized)
-			render.JSON(w, r, map[string]string{"error": "Unauthorized"})
+			response.Fail(w, r, http.StatusUnauthorized, response.CodeUnauthorized, "Unauthorized")
 			return
 		}
 
@@ -32,23 +36,126 @@ func Authenticator(next http.Handler) http.Handler {
 	})
 }
 
+// AdminOnly пропускает администраторов: сервиса, организации и superadmin.
 func AdminOnly(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		_, claims, err := jwtauth.FromContext(r.Context())
+	return requireRole(next, func(role string, orgID uint) bool {
+		return role == models.RoleAdmin || role == models.RoleSuperAdmin
+	})
+}
 
-		if err != nil {
-			render.Status(r, http.StatusNotFound)
-			render.JSON(w, r, map[string]string{"error": "Not found"})
-			return
-		}
+// ServiceAdminOnly пропускает администраторов, чьи права не ограничены организацией:
+// superadmin и admin с токеном без организации. Так закрыты настройки всего сервиса,
+// например webhooks, которые получают события обо всех пользователях.
+func ServiceAdminOnly(next http.Handler) http.Handler {
+	return requireRole(next, func(role string, orgID uint) bool {
+		return role == models.RoleSuperAdmin || (role == models.RoleAdmin && orgID == 0)
+	})
+}
 
-		role, ok := claims["role"].(string)
-		if !ok || role != "admin" {
-			render.Status(r, http.StatusNotFound)
-			render.JSON(w, r, map[string]string{"error": "Not found"})
+// SuperAdminOnly пропускает только superadmin, он управляет организациями.
+func SuperAdminOnly(next http.Handler) http.Handler {
+	return requireRole(next, func(role string, orgID uint) bool {
+		return role == models.RoleSuperAdmin
+	})
+}
+
+// OrgAdminOnly пропускает superadmin и администраторов организации, id которой в
+// параметре маршрута param. Ставится через r.With, когда параметр уже разобран.
+func OrgAdminOnly(param string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, err := strconv.ParseUint(chi.URLParam(r, param), 10, 32)
+			if err != nil {
+				id = 0
+			}
+			requireRole(next, func(role string, orgID uint) bool {
+				return role == models.RoleSuperAdmin || (role == models.RoleAdmin && orgID != 0 && orgID == uint(id))
+			}).ServeHTTP(w, r)
+		})
+	}
+}
+
+// requireRole отвечает 404 всем, кому allowed отказал: не показываем, что маршрут есть.
+func requireRole(next http.Handler, allowed func(role string, orgID uint) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, err := jwtauth.FromContext(r.Context()); err != nil || !allowed(Role(r.Context()), OrgID(r.Context())) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeNotFound, "Not found")
 			return
 		}
 
 		next.ServeHTTP(w, r)
 	})
 }
+
+// Role возвращает роль из проверенного токена: в организации из токена, если она есть.
+func Role(ctx context.Context) string {
+	_, claims, err := jwtauth.FromContext(ctx)
+	if err != nil {
+		return ""
+	}
+	role, _ := claims["role"].(string)
+	return role
+}
+
+// OrgID возвращает активную организацию из проверенного токена или 0.
+func OrgID(ctx context.Context) uint {
+	_, claims, err := jwtauth.FromContext(ctx)
+	if err != nil {
+		return 0
+	}
+	id, _ := claims["org_id"].(float64)
+	return uint(id)
+}
+
+// Tenant возвращает организацию, которой ограничен запрос, или 0, если ограничения
+// нет: у токена без организации и у superadmin.
+func Tenant(ctx context.Context) uint {
+	if Role(ctx) == models.RoleSuperAdmin {
+		return 0
+	}
+	return OrgID(ctx)
+}
+
+// UserID возвращает id пользователя из проверенного токена или 0, если токена нет.
+func UserID(ctx context.Context) uint {
+	_, claims, err := jwtauth.FromContext(ctx)
+	if err != nil {
+		return 0
+	}
+	// числа из JWT приходят как float64
+	id, _ := claims["user_id"].(float64)
+	return uint(id)
+}
+
+type userGetter interface {
+	GetUserByID(ctx context.Context, id uint) (*models.User, error)
+}
+
+// TokenVersion отклоняет токены, выпущенные до смены пароля, роли или блокировки пользователя.
+// Должен стоять после Authenticator.
+func TokenVersion(users userGetter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, claims, err := jwtauth.FromContext(r.Context())
+			if err != nil {
+				response.Fail(w, r, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+				return
+			}
+
+			version, _ := claims["ver"].(float64)
+
+			// реплика может ещё не знать об отзыве токена
+			user, err := users.GetUserByID(storage.WithPrimary(r.Context()), UserID(r.Context()))
+			if problem, ok := response.ContextError(err); ok {
+				response.WriteProblem(w, r, problem)
+				return
+			}
+			if err != nil || user.TokenVersion != uint(version) || user.Status == models.StatusSuspended {
+				response.Fail(w, r, http.StatusUnauthorized, response.CodeTokenRevoked, "token revoked")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package authMiddleware_test
+
+import (
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockUsers struct {
+	user *models.User
+	err  error
+}
+
+func (m *mockUsers) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.user, nil
+}
+
+func TestTokenVersion(t *testing.T) {
+	tests := []struct {
+		name           string
+		tokenVersion   uint
+		mockUser       *models.User
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "current version",
+			tokenVersion:   2,
+			mockUser:       &models.User{ID: 1, TokenVersion: 2, Status: models.StatusActive},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "outdated version",
+			tokenVersion:   1,
+			mockUser:       &models.User{ID: 1, TokenVersion: 2, Status: models.StatusActive},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "suspended user",
+			tokenVersion:   2,
+			mockUser:       &models.User{ID: 1, TokenVersion: 2, Status: models.StatusSuspended},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "deleted user",
+			tokenVersion:   2,
+			mockError:      storage.ErrUserNotFound,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "storage failure",
+			tokenVersion:   2,
+			mockError:      errors.New("db failure"),
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, token, err := authMiddleware.AccessTokenAuth.Encode(map[string]interface{}{
+				"user_id": 1,
+				"role":    "admin",
+				"ver":     tt.tokenVersion,
+			})
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+
+			ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := jwtauth.Verifier(authMiddleware.AccessTokenAuth)(
+				authMiddleware.Authenticator(
+					authMiddleware.TokenVersion(&mockUsers{user: tt.mockUser, err: tt.mockError})(ok),
+				),
+			)
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestRoleMiddlewares(t *testing.T) {
+	tests := []struct {
+		name      string
+		role      string
+		orgID     uint
+		admin     int
+		service   int
+		super     int
+		orgAdmin1 int
+	}{
+		{
+			name:      "user",
+			role:      "user",
+			admin:     http.StatusNotFound,
+			service:   http.StatusNotFound,
+			super:     http.StatusNotFound,
+			orgAdmin1: http.StatusNotFound,
+		},
+		{
+			name:      "service admin",
+			role:      "admin",
+			admin:     http.StatusOK,
+			service:   http.StatusOK,
+			super:     http.StatusNotFound,
+			orgAdmin1: http.StatusNotFound,
+		},
+		{
+			name:      "admin of this organization",
+			role:      "admin",
+			orgID:     1,
+			admin:     http.StatusOK,
+			service:   http.StatusNotFound,
+			super:     http.StatusNotFound,
+			orgAdmin1: http.StatusOK,
+		},
+		{
+			name:      "admin of another organization",
+			role:      "admin",
+			orgID:     2,
+			admin:     http.StatusOK,
+			service:   http.StatusNotFound,
+			super:     http.StatusNotFound,
+			orgAdmin1: http.StatusNotFound,
+		},
+		{
+			name:      "member of this organization",
+			role:      "user",
+			orgID:     1,
+			admin:     http.StatusNotFound,
+			service:   http.StatusNotFound,
+			super:     http.StatusNotFound,
+			orgAdmin1: http.StatusNotFound,
+		},
+		{
+			name:      "superadmin",
+			role:      "superadmin",
+			orgID:     2,
+			admin:     http.StatusOK,
+			service:   http.StatusOK,
+			super:     http.StatusOK,
+			orgAdmin1: http.StatusOK,
+		},
+	}
+
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	router := chi.NewRouter()
+	router.Use(jwtauth.Verifier(authMiddleware.AccessTokenAuth))
+	router.Use(authMiddleware.Authenticator)
+	router.With(authMiddleware.AdminOnly).Get("/admin", ok)
+	router.With(authMiddleware.ServiceAdminOnly).Get("/service", ok)
+	router.With(authMiddleware.SuperAdminOnly).Get("/super", ok)
+	router.With(authMiddleware.OrgAdminOnly("id")).Get("/orgs/{id}", ok)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := map[string]interface{}{"user_id": 1, "role": tt.role, "ver": 0}
+			if tt.orgID != 0 {
+				claims["org_id"] = tt.orgID
+			}
+			_, token, err := authMiddleware.AccessTokenAuth.Encode(claims)
+			assert.NoError(t, err)
+
+			for path, expected := range map[string]int{
+				"/admin":   tt.admin,
+				"/service": tt.service,
+				"/super":   tt.super,
+				"/orgs/1":  tt.orgAdmin1,
+			} {
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				req.Header.Set("Authorization", "Bearer "+token)
+				rr := httptest.NewRecorder()
+				router.ServeHTTP(rr, req)
+				assert.Equal(t, expected, rr.Code, path)
+			}
+		})
+	}
+}
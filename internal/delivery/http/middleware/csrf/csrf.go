@@ -0,0 +1,44 @@
+package csrfMiddleware
+
+import (
+	"backend-app/internal/delivery/http/cookie"
+	"backend-app/pkg/api/response"
+	"crypto/subtle"
+	"net/http"
+)
+
+// DoubleSubmit проверяет, что заголовок X-CSRF-Token совпадает с cookie csrf_token.
+// Запросы без наших auth cookie не проверяются: их нельзя подделать с чужого сайта.
+func DoubleSubmit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !hasAuthCookie(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		c, err := r.Cookie(cookie.CSRFToken)
+		header := r.Header.Get(cookie.CSRFHeader)
+		if err != nil || c.Value == "" || header == "" ||
+			subtle.ConstantTimeCompare([]byte(c.Value), []byte(header)) != 1 {
+			response.Fail(w, r, http.StatusForbidden, response.CodeInvalidCSRFToken, "invalid csrf token")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func hasAuthCookie(r *http.Request) bool {
+	for _, name := range []string{cookie.AccessToken, cookie.RefreshToken} {
+		if c, err := r.Cookie(name); err == nil && c.Value != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package csrfMiddleware_test
+
+import (
+	"backend-app/internal/delivery/http/cookie"
+	csrfMiddleware "backend-app/internal/delivery/http/middleware/csrf"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoubleSubmit(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		cookies        map[string]string
+		header         string
+		expectedStatus int
+	}{
+		{
+			name:           "safe method",
+			method:         http.MethodGet,
+			cookies:        map[string]string{cookie.AccessToken: "jwt"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "no auth cookies",
+			method:         http.MethodPost,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing header",
+			method:         http.MethodPost,
+			cookies:        map[string]string{cookie.AccessToken: "jwt", cookie.CSRFToken: "token"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "missing csrf cookie",
+			method:         http.MethodDelete,
+			cookies:        map[string]string{cookie.RefreshToken: "jwt"},
+			header:         "token",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "mismatch",
+			method:         http.MethodPut,
+			cookies:        map[string]string{cookie.AccessToken: "jwt", cookie.CSRFToken: "token"},
+			header:         "other",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "match",
+			method:         http.MethodPost,
+			cookies:        map[string]string{cookie.RefreshToken: "jwt", cookie.CSRFToken: "token"},
+			header:         "token",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", nil)
+			for name, value := range tt.cookies {
+				req.AddCookie(&http.Cookie{Name: name, Value: value})
+			}
+			if tt.header != "" {
+				req.Header.Set(cookie.CSRFHeader, tt.header)
+			}
+			rr := httptest.NewRecorder()
+
+			handler := csrfMiddleware.DoubleSubmit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
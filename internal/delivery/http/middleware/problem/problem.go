@@ -0,0 +1,69 @@
+// Package problemMiddleware отвечает ошибками в формате application/problem+json там,
+// где chi отвечает сам: неизвестный маршрут, неподдерживаемый метод, паника
+// обработчика и превышение числа одновременных запросов.
+package problemMiddleware
+
+import (
+	"backend-app/pkg/api/response"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// NotFound отвечает на запросы к неизвестным маршрутам.
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	response.Fail(w, r, http.StatusNotFound, response.CodeNotFound, "Not found")
+}
+
+// MethodNotAllowed отвечает на запросы с методом, которого у маршрута нет.
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	response.Fail(w, r, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "method "+r.Method+" is not allowed")
+}
+
+// Recoverer заменяет middleware.Recoverer: паника обработчика логируется со стеком,
+// а клиент получает 500 с телом ошибки.
+func Recoverer(log *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rvr := recover()
+				if rvr == nil {
+					return
+				}
+				// http.ErrAbortHandler обрывает ответ намеренно, его не перехватываем
+				if rvr == http.ErrAbortHandler {
+					panic(rvr)
+				}
+				log.Error("handler panicked",
+					slog.Any("panic", rvr),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+					slog.String("stack", string(debug.Stack())),
+				)
+				if r.Header.Get("Connection") != "Upgrade" {
+					response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "internal server error")
+				}
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Throttle заменяет middleware.Throttle: одновременно обрабатывается не больше limit
+// запросов, остальные сразу получают 429.
+func Throttle(limit int) func(http.Handler) http.Handler {
+	tokens := make(chan struct{}, limit)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case tokens <- struct{}{}:
+				defer func() { <-tokens }()
+				next.ServeHTTP(w, r)
+			default:
+				response.Fail(w, r, http.StatusTooManyRequests, response.CodeRateLimited, "server is at capacity, try again later")
+			}
+		})
+	}
+}
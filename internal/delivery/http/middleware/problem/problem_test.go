@@ -0,0 +1,102 @@
+package problemMiddleware_test
+
+import (
+	problemMiddleware "backend-app/internal/delivery/http/middleware/problem"
+	"backend-app/pkg/api/response"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblems(t *testing.T) {
+	release := make(chan struct{})
+	busy := make(chan struct{})
+
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.Use(problemMiddleware.Recoverer(slog.Default()))
+	r.Use(problemMiddleware.Throttle(1))
+	r.NotFound(problemMiddleware.NotFound)
+	r.MethodNotAllowed(problemMiddleware.MethodNotAllowed)
+	r.Get("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	r.Get("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(busy)
+		<-release
+	})
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "not_found",
+			method:         http.MethodGet,
+			path:           "/missing",
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   response.CodeNotFound,
+		},
+		{
+			name:           "method_not_allowed",
+			method:         http.MethodPost,
+			path:           "/panic",
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedCode:   response.CodeMethodNotAllowed,
+		},
+		{
+			name:           "panic",
+			method:         http.MethodGet,
+			path:           "/panic",
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   response.CodeInternal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assertProblem(t, rr, tt.expectedStatus, tt.expectedCode, tt.path)
+		})
+	}
+
+	t.Run("throttled", func(t *testing.T) {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+		}()
+		<-busy
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/missing", nil))
+		close(release)
+		<-done
+
+		assertProblem(t, rr, http.StatusTooManyRequests, response.CodeRateLimited, "/missing")
+	})
+}
+
+func assertProblem(t *testing.T, rr *httptest.ResponseRecorder, status int, code, path string) {
+	t.Helper()
+	assert.Equal(t, status, rr.Code)
+	assert.Equal(t, response.ProblemContentType, rr.Header().Get("Content-Type"))
+	var p response.Problem
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &p))
+	assert.Equal(t, status, p.Status)
+	assert.Equal(t, code, p.Code)
+	assert.Equal(t, http.StatusText(status), p.Title)
+	assert.Equal(t, path, p.Instance)
+	assert.NotEmpty(t, p.RequestID)
+}
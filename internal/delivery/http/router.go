@@ -2,8 +2,9 @@ package router
 
 import (
 	"backend-app/internal/config"
+	problemMiddleware "backend-app/internal/delivery/http/middleware/problem"
 	v1Router "backend-app/internal/delivery/http/v1"
-	"backend-app/internal/storage/postgres"
+	"backend-app/internal/storage"
 	"log/slog"
 	"net/http"
 
@@ -16,21 +17,30 @@ import (
 	"github.com/go-chi/cors"
 )
 
-func InitRoutes(log *slog.Logger, storage *postgres.Storage, cfg *config.Config) *chi.Mux {
+func InitRoutes(log *slog.Logger, storage storage.Store, cfg *config.Config) *chi.Mux {
 	r := chi.NewRouter()
 
+	// с токеном в заголовке подходит любой источник, а cookie браузер
+	// отправит только на явно перечисленные
+	allowedOrigins := []string{"*"}
+	if cfg.Cookie.Enabled {
+		allowedOrigins = cfg.Cookie.AllowedOrigins
+	}
 	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"*"}, // Allow all origins
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: true,
+		AllowedOrigins:   allowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "If-Match"},
+		ExposedHeaders:   []string{"Link", "ETag"},
+		AllowCredentials: cfg.Cookie.Enabled,
 		MaxAge:           300,
 	}))
 
 	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
-	r.Use(middleware.Throttle(100))
+	r.Use(problemMiddleware.Recoverer(log))
+	r.Use(problemMiddleware.Throttle(100))
+	// ставятся до Mount, чтобы их унаследовал и /v1
+	r.NotFound(problemMiddleware.NotFound)
+	r.MethodNotAllowed(problemMiddleware.MethodNotAllowed)
 
 	r.Get("/swagger/*", httpSwagger.Handler(
 		httpSwagger.URL("http://localhost:8080/swagger/doc.json"), // The url pointing to API definition
@@ -39,6 +49,6 @@ func InitRoutes(log *slog.Logger, storage *postgres.Storage, cfg *config.Config)
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("Hello, World!"))
 	})
-	r.Mount("/v1", v1Router.New(log, storage))
+	r.Mount("/v1", v1Router.New(log, storage, cfg))
 	return r
 }
@@ -0,0 +1,93 @@
+// Package tenant ограничивает администраторов организации её участниками: остальные
+// пользователи для них не существуют, а администраторов сервиса они не могут менять.
+package tenant
+
+import (
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"errors"
+	"slices"
+)
+
+// ErrForbidden - администратор из токена не может так изменить пользователя.
+var ErrForbidden = errors.New("not allowed to change this user")
+
+type Members interface {
+	GetMembership(ctx context.Context, orgID uint, userID uint) (*models.Membership, error)
+}
+
+type Users interface {
+	Members
+	GetUserByID(ctx context.Context, id uint) (*models.User, error)
+	ListUserMemberships(ctx context.Context, userID uint) ([]models.Membership, error)
+}
+
+// Check возвращает storage.ErrUserNotFound, если запрос ограничен организацией, а
+// пользователь id в ней не состоит.
+func Check(ctx context.Context, members Members, id uint) error {
+	org := authMiddleware.Tenant(ctx)
+	if org == 0 {
+		return nil
+	}
+	_, err := members.GetMembership(ctx, org, id)
+	if errors.Is(err, storage.ErrMembershipNotFound) {
+		return storage.ErrUserNotFound
+	}
+	return err
+}
+
+// CheckWrite проверяет, что администратор из токена может изменить пользователя id
+// и, если role не пустая, оставить или назначить ему глобальную роль role. Роль
+// superadmin выдаёт, отбирает и меняет таким пользователям только superadmin.
+// Администратор организации не меняет глобальные роли, не трогает администраторов
+// сервиса и пользователей, которые состоят ещё и в других организациях: изменение
+// коснулось бы и их.
+func CheckWrite(ctx context.Context, users Users, id uint, role string) error {
+	if authMiddleware.Role(ctx) == models.RoleSuperAdmin {
+		return nil
+	}
+	org := authMiddleware.Tenant(ctx)
+	if org != 0 {
+		memberships, err := users.ListUserMemberships(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !slices.ContainsFunc(memberships, func(m models.Membership) bool { return m.OrgID == org }) {
+			return storage.ErrUserNotFound
+		}
+		if len(memberships) > 1 {
+			return ErrForbidden
+		}
+	}
+
+	user, err := users.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if user.Role == models.RoleSuperAdmin || role == models.RoleSuperAdmin {
+		return ErrForbidden
+	}
+	if org != 0 && (user.Role == models.RoleAdmin || (role != "" && role != user.Role)) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// TokenRole возвращает роль для токенов пользователя в организации orgID: его роль
+// участника или storage.ErrMembershipNotFound. superadmin остаётся superadmin в любой
+// своей организации, без организации у всех глобальная роль.
+func TokenRole(ctx context.Context, members Members, user *models.User, orgID uint) (string, error) {
+	if orgID == 0 {
+		return user.Role, nil
+	}
+	m, err := members.GetMembership(ctx, orgID, user.ID)
+	if err != nil {
+		return "", err
+	}
+	if user.Role == models.RoleSuperAdmin {
+		return user.Role, nil
+	}
+	return m.Role, nil
+}
@@ -0,0 +1,144 @@
+package tenant_test
+
+import (
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/delivery/http/tenant"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"testing"
+
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockUsers struct {
+	users       map[uint]*models.User
+	memberships []models.Membership
+}
+
+func (m *mockUsers) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	user, ok := m.users[id]
+	if !ok {
+		return nil, storage.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (m *mockUsers) GetMembership(ctx context.Context, orgID uint, userID uint) (*models.Membership, error) {
+	for _, ms := range m.memberships {
+		if ms.OrgID == orgID && ms.UserID == userID {
+			return &ms, nil
+		}
+	}
+	return nil, storage.ErrMembershipNotFound
+}
+
+func (m *mockUsers) ListUserMemberships(ctx context.Context, userID uint) ([]models.Membership, error) {
+	var res []models.Membership
+	for _, ms := range m.memberships {
+		if ms.UserID == userID {
+			res = append(res, ms)
+		}
+	}
+	return res, nil
+}
+
+// tokenContext возвращает контекст с проверенным токеном, как после jwtauth.Verifier.
+func tokenContext(t *testing.T, role string, orgID uint) context.Context {
+	claims := map[string]interface{}{"user_id": 100, "role": role, "ver": 0}
+	if orgID != 0 {
+		claims["org_id"] = orgID
+	}
+	_, encoded, err := authMiddleware.AccessTokenAuth.Encode(claims)
+	require.NoError(t, err)
+	token, err := authMiddleware.AccessTokenAuth.Decode(encoded)
+	require.NoError(t, err)
+	return jwtauth.NewContext(t.Context(), token, nil)
+}
+
+// 1 - участник организации 1, 2 - администратор организации 1, 3 - участник
+// организаций 1 и 2, 4 - вне организаций, 5 - superadmin в организации 1.
+func newMockUsers() *mockUsers {
+	return &mockUsers{
+		users: map[uint]*models.User{
+			1: {ID: 1, Role: "user"},
+			2: {ID: 2, Role: "admin"},
+			3: {ID: 3, Role: "user"},
+			4: {ID: 4, Role: "user"},
+			5: {ID: 5, Role: "superadmin"},
+		},
+		memberships: []models.Membership{
+			{OrgID: 1, UserID: 1, Role: "creator"},
+			{OrgID: 1, UserID: 2, Role: "admin"},
+			{OrgID: 1, UserID: 3, Role: "user"},
+			{OrgID: 2, UserID: 3, Role: "user"},
+			{OrgID: 1, UserID: 5, Role: "user"},
+		},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	users := newMockUsers()
+
+	assert.NoError(t, tenant.Check(tokenContext(t, "admin", 0), users, 4), "service admin sees everyone")
+	assert.NoError(t, tenant.Check(tokenContext(t, "superadmin", 2), users, 4), "superadmin sees everyone")
+	assert.NoError(t, tenant.Check(tokenContext(t, "admin", 1), users, 1))
+	assert.ErrorIs(t, tenant.Check(tokenContext(t, "admin", 1), users, 4), storage.ErrUserNotFound)
+	assert.ErrorIs(t, tenant.Check(tokenContext(t, "admin", 2), users, 1), storage.ErrUserNotFound)
+}
+
+func TestCheckWrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		orgID    uint
+		target   uint
+		newRole  string
+		expected error
+	}{
+		{name: "service admin", role: "admin", target: 4, newRole: "admin"},
+		{name: "service admin grants superadmin", role: "admin", target: 4, newRole: "superadmin", expected: tenant.ErrForbidden},
+		{name: "service admin changes superadmin", role: "admin", target: 5, expected: tenant.ErrForbidden},
+		{name: "superadmin grants superadmin", role: "superadmin", orgID: 2, target: 4, newRole: "superadmin"},
+		{name: "org admin keeps role", role: "admin", orgID: 1, target: 1, newRole: "user"},
+		{name: "org admin without role", role: "admin", orgID: 1, target: 1},
+		{name: "org admin changes role", role: "admin", orgID: 1, target: 1, newRole: "creator", expected: tenant.ErrForbidden},
+		{name: "org admin changes admin", role: "admin", orgID: 1, target: 2, expected: tenant.ErrForbidden},
+		{name: "org admin changes shared member", role: "admin", orgID: 1, target: 3, expected: tenant.ErrForbidden},
+		{name: "org admin changes outsider", role: "admin", orgID: 1, target: 4, expected: storage.ErrUserNotFound},
+		{name: "org admin changes superadmin", role: "admin", orgID: 1, target: 5, expected: tenant.ErrForbidden},
+		{name: "missing user", role: "admin", target: 9, expected: storage.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tenant.CheckWrite(tokenContext(t, tt.role, tt.orgID), newMockUsers(), tt.target, tt.newRole)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTokenRole(t *testing.T) {
+	users := newMockUsers()
+
+	role, err := tenant.TokenRole(t.Context(), users, users.users[1], 0)
+	require.NoError(t, err)
+	assert.Equal(t, "user", role, "global role without organization")
+
+	role, err = tenant.TokenRole(t.Context(), users, users.users[1], 1)
+	require.NoError(t, err)
+	assert.Equal(t, "creator", role, "role in the organization")
+
+	_, err = tenant.TokenRole(t.Context(), users, users.users[1], 2)
+	assert.ErrorIs(t, err, storage.ErrMembershipNotFound)
+
+	role, err = tenant.TokenRole(t.Context(), users, users.users[5], 1)
+	require.NoError(t, err)
+	assert.Equal(t, "superadmin", role)
+}
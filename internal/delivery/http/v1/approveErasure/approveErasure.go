@@ -0,0 +1,108 @@
+package approveErasure
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"backend-app/pkg/api/validate"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Eraser interface {
+	EraseUser(ctx context.Context, requestID uint, reviewedBy uint, note string) (*models.ErasureRequest, error)
+}
+
+type Request struct {
+	Note string `json:"note" validate:"max=500"`
+}
+
+// New godoc
+// @Summary Approve erasure
+// @Description Approves a pending erasure request: anonymizes the user's username, email and country, clears credentials, scrubs the user's data from the event outbox and webhook deliveries and deletes their data exports. The user keeps their ID, so audit records stay linked. Returns the completion receipt. The body is optional
+// @Tags privacy
+// @Accept json
+// @Produce json
+// @Param id path int true "Erasure request ID"
+// @Param input body approveErasure.Request false "Review note"
+// @Success 200 {object} dto.ErasureReceipt
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 409 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/erasure-requests/{id}/approve [post]
+func New(log *slog.Logger, eraser Eraser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.ApproveErasure"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			log.Info("invalid erasure request id", "param", idStr)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid erasure request id")
+			return
+		}
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil && !errors.Is(err, io.EOF) {
+			log.Error("failed to decode request body", "error", err)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidBody, "invalid request body")
+			return
+		}
+		if err := validate.New().Struct(req); err != nil {
+			log.Info("validation failed", "error", err)
+			response.WriteProblem(w, r, response.ValidationFailed(err))
+			return
+		}
+
+		reviewedBy := authMiddleware.UserID(r.Context())
+		erasure, err := eraser.EraseUser(r.Context(), uint(id), reviewedBy, req.Note)
+		if errors.Is(err, storage.ErrErasureRequestNotFound) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeErasureNotFound, "erasure request not found")
+			return
+		}
+		if errors.Is(err, storage.ErrErasureNotPending) {
+			response.Fail(w, r, http.StatusConflict, response.CodeErasureNotPending, "erasure request is not pending")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to erase user", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to erase user")
+			return
+		}
+
+		receipt, ok := dto.NewErasureReceipt(erasure)
+		if !ok {
+			log.Error("erasure request is not completed after approval", "id", erasure.ID, "status", erasure.Status)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to erase user")
+			return
+		}
+
+		log.Info("user erased", "id", erasure.ID, "user_id", erasure.UserID, "reviewed_by", reviewedBy)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, receipt)
+	}
+}
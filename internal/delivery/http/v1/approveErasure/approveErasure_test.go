@@ -0,0 +1,149 @@
+package approveErasure_test
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/delivery/http/v1/approveErasure"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockEraser struct {
+	err        error
+	reviewedBy uint
+	note       string
+}
+
+func (m *mockEraser) EraseUser(ctx context.Context, requestID uint, reviewedBy uint, note string) (*models.ErasureRequest, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.reviewedBy, m.note = reviewedBy, note
+	now := time.Now()
+	return &models.ErasureRequest{
+		ID:             requestID,
+		UserID:         3,
+		Status:         models.ErasureCompleted,
+		ReviewedBy:     &reviewedBy,
+		ReviewNote:     note,
+		ReviewedAt:     &now,
+		CompletedAt:    &now,
+		ScrubbedEvents: 4,
+	}, nil
+}
+
+func TestApproveErasureHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		body           string
+		err            error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "invalid_id",
+			url:            "/erasure-requests/abc/approve",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid erasure request id",
+		},
+		{
+			name:           "invalid_body",
+			url:            "/erasure-requests/1/approve",
+			body:           "{",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid request body",
+		},
+		{
+			name:           "not_found",
+			url:            "/erasure-requests/1/approve",
+			err:            storage.ErrErasureRequestNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "erasure request not found",
+		},
+		{
+			name:           "not_pending",
+			url:            "/erasure-requests/1/approve",
+			err:            storage.ErrErasureNotPending,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "erasure request is not pending",
+		},
+		{
+			name:           "timeout",
+			url:            "/erasure-requests/1/approve",
+			err:            context.DeadlineExceeded,
+			expectedStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name:           "storage_error",
+			url:            "/erasure-requests/1/approve",
+			err:            errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to erase user",
+		},
+		{
+			name:           "success_without_body",
+			url:            "/erasure-requests/1/approve",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "success_with_note",
+			url:            "/erasure-requests/1/approve",
+			body:           `{"note":"verified by support"}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	_, token, err := authMiddleware.AccessTokenAuth.Encode(map[string]interface{}{"user_id": 9, "role": "admin"})
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockEraser{err: tt.err}
+			router := chi.NewRouter()
+			router.Use(middleware.RequestID)
+			router.Use(jwtauth.Verifier(authMiddleware.AccessTokenAuth))
+			router.Post("/erasure-requests/{id}/approve", approveErasure.New(slog.Default(), m))
+
+			req := httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus != http.StatusOK {
+				if tt.expectedBody != "" {
+					var res response.Problem
+					_ = render.DecodeJSON(rr.Body, &res)
+					assert.Equal(t, tt.expectedBody, res.Detail)
+				}
+				return
+			}
+
+			var receipt dto.ErasureReceipt
+			require.NoError(t, render.DecodeJSON(rr.Body, &receipt))
+			assert.Equal(t, uint(1), receipt.RequestID)
+			assert.Equal(t, uint(3), receipt.UserID)
+			assert.Equal(t, uint(9), receipt.ApprovedBy, "the approving admin comes from the token")
+			assert.Equal(t, models.ErasedFields, receipt.ErasedFields)
+			assert.Equal(t, int64(4), receipt.ScrubbedEvents)
+			assert.Equal(t, uint(9), m.reviewedBy)
+		})
+	}
+}
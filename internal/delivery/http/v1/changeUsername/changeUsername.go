@@ -0,0 +1,134 @@
+package changeUsername
+
+import (
+	"backend-app/internal/account"
+	"backend-app/internal/country"
+	"backend-app/internal/delivery/http/dto"
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"backend-app/pkg/api/validate"
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Updater interface {
+	GetUserByID(ctx context.Context, id uint) (*models.User, error)
+	PatchUser(ctx context.Context, id uint, version uint, patch storage.UserPatch) (*models.User, error)
+}
+
+// Request - новое имя и текущий пароль для подтверждения.
+type Request struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// New godoc
+// @Summary Change my username
+// @Description Changes the username of the current user. Usernames are unique regardless of case, a user may change only the case of their own name. Reserved names and names with spaces, control characters or '@' are rejected. After a change the next one is possible only after the configured cooldown, until then the answer is 429 with Retry-After
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param input body changeUsername.Request true "New username and current password"
+// @Success 200 {object} dto.User
+// @Failure 400 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 403 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 409 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 429 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/me/username [put]
+func New(log *slog.Logger, updater Updater, policy account.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.ChangeUsername"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", "error", err)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidBody, "invalid request body")
+			return
+		}
+		if err := validate.New().Struct(req); err != nil {
+			log.Info("validation failed", "error", err)
+			response.WriteProblem(w, r, response.ValidationFailed(err))
+			return
+		}
+		if err := policy.CheckUsername(req.Username); err != nil {
+			log.Info("username rejected", slog.String("username", req.Username), "error", err)
+			code := response.CodeInvalidUsername
+			if errors.Is(err, account.ErrReservedUsername) {
+				code = response.CodeReservedUsername
+			}
+			response.Fail(w, r, http.StatusUnprocessableEntity, code, err.Error())
+			return
+		}
+
+		id := authMiddleware.UserID(r.Context())
+		user, err := updater.GetUserByID(r.Context(), id)
+		if err == nil && user.CheckPassword(req.Password) != nil {
+			log.Info("wrong password", "id", id)
+			response.Fail(w, r, http.StatusForbidden, response.CodeInvalidPassword, "invalid password")
+			return
+		}
+		if err == nil {
+			if next := account.NextChange(user.UsernameChangedAt, policy.UsernameCooldown); time.Now().Before(next) {
+				log.Info("username changed too recently", "id", id)
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(time.Until(next).Seconds()))))
+				response.Fail(w, r, http.StatusTooManyRequests, response.CodeChangeCooldown, "username was changed recently")
+				return
+			}
+			now := time.Now()
+			user, err = updater.PatchUser(r.Context(), id, user.Version, storage.UserPatch{
+				Username:          &req.Username,
+				UsernameChangedAt: &now,
+			})
+		}
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Info("user not found", "id", id)
+			response.Fail(w, r, http.StatusNotFound, response.CodeUserNotFound, "user not found")
+			return
+		}
+		if errors.Is(err, storage.ErrUserExists) {
+			log.Info("username already taken", "id", id)
+			response.Fail(w, r, http.StatusConflict, response.CodeUsernameTaken, "username already taken")
+			return
+		}
+		if errors.Is(err, storage.ErrVersionConflict) {
+			log.Info("user was modified concurrently", "id", id)
+			response.Fail(w, r, http.StatusConflict, response.CodeVersionConflict, "user was modified by another request")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to change username", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to change username")
+			return
+		}
+
+		log.Info("username changed", "id", id)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, dto.NewUser(user).Localize(country.Language(r.Header.Get("Accept-Language"))))
+	}
+}
@@ -0,0 +1,171 @@
+package changeUsername_test
+
+import (
+	"backend-app/internal/account"
+	"backend-app/internal/delivery/http/dto"
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/delivery/http/v1/changeUsername"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockStorage struct {
+	user    models.User
+	err     error
+	patched *storage.UserPatch
+}
+
+func (m *mockStorage) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	u := m.user
+	u.ID = id
+	return &u, nil
+}
+
+func (m *mockStorage) PatchUser(ctx context.Context, id uint, version uint, patch storage.UserPatch) (*models.User, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.patched = &patch
+	u := m.user
+	u.ID = id
+	u.Username = *patch.Username
+	return &u, nil
+}
+
+func TestChangeUsernameHandler(t *testing.T) {
+	hashed := models.User{Password: "secret123"}
+	require.NoError(t, hashed.HashPassword())
+	recently := time.Now().Add(-time.Hour)
+	longAgo := time.Now().Add(-365 * 24 * time.Hour)
+
+	tests := []struct {
+		name           string
+		body           string
+		changedAt      *time.Time
+		err            error
+		expectedStatus int
+		expectedCode   string
+		expectedBody   string
+		expectedErrors []response.FieldError
+	}{
+		{
+			name:           "invalid_body",
+			body:           "{",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid request body",
+		},
+		{
+			name:           "without_password",
+			body:           `{"username":"alice2"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   response.CodeValidationFailed,
+			expectedBody:   "validation failed",
+			expectedErrors: []response.FieldError{{Field: "password", Code: "required", Detail: "is required"}},
+		},
+		{
+			name:           "reserved",
+			body:           `{"username":"Root","password":"secret123"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   response.CodeReservedUsername,
+			expectedBody:   account.ErrReservedUsername.Error(),
+		},
+		{
+			name:           "invalid",
+			body:           `{"username":"alice smith","password":"secret123"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   response.CodeInvalidUsername,
+			expectedBody:   account.ErrInvalidUsername.Error(),
+		},
+		{
+			name:           "wrong_password",
+			body:           `{"username":"alice2","password":"wrong"}`,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "invalid password",
+		},
+		{
+			name:           "cooldown",
+			body:           `{"username":"alice2","password":"secret123"}`,
+			changedAt:      &recently,
+			expectedStatus: http.StatusTooManyRequests,
+			expectedCode:   response.CodeChangeCooldown,
+			expectedBody:   "username was changed recently",
+		},
+		{
+			name:           "taken",
+			body:           `{"username":"Bob","password":"secret123"}`,
+			err:            storage.ErrUserExists,
+			expectedStatus: http.StatusConflict,
+			expectedCode:   response.CodeUsernameTaken,
+			expectedBody:   "username already taken",
+		},
+		{
+			name:           "success",
+			body:           `{"username":"Alice","password":"secret123"}`,
+			changedAt:      &longAgo,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	_, token, err := authMiddleware.AccessTokenAuth.Encode(map[string]interface{}{"user_id": 4, "role": "user"})
+	require.NoError(t, err)
+	policy := account.Policy{Reserved: []string{"root"}, UsernameCooldown: 24 * time.Hour}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockStorage{
+				user: models.User{Username: "alice", Password: hashed.Password, UsernameChangedAt: tt.changedAt},
+				err:  tt.err,
+			}
+			router := chi.NewRouter()
+			router.Use(middleware.RequestID)
+			router.Use(jwtauth.Verifier(authMiddleware.AccessTokenAuth))
+			router.Put("/me/username", changeUsername.New(slog.Default(), m, policy))
+
+			req := httptest.NewRequest(http.MethodPut, "/me/username", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusTooManyRequests {
+				retryAfter, err := strconv.Atoi(rr.Header().Get("Retry-After"))
+				require.NoError(t, err)
+				assert.InDelta(t, 23*60*60, retryAfter, 5)
+			}
+			if tt.expectedBody != "" {
+				var res response.Problem
+				_ = render.DecodeJSON(rr.Body, &res)
+				assert.Equal(t, tt.expectedBody, res.Detail)
+				if tt.expectedCode != "" {
+					assert.Equal(t, tt.expectedCode, res.Code)
+				}
+				assert.Equal(t, tt.expectedErrors, res.Errors)
+				return
+			}
+
+			require.NotNil(t, m.patched)
+			require.NotNil(t, m.patched.UsernameChangedAt)
+			assert.WithinDuration(t, time.Now(), *m.patched.UsernameChangedAt, time.Minute)
+			var res dto.User
+			require.NoError(t, render.DecodeJSON(rr.Body, &res))
+			assert.Equal(t, uint(4), res.ID)
+			assert.Equal(t, "Alice", res.Username)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package confirmEmailChange
+
+import (
+	"backend-app/internal/account"
+	"backend-app/internal/country"
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"backend-app/pkg/api/validate"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Confirmer interface {
+	ConfirmEmailChange(ctx context.Context, tokenHash string, now time.Time) (*models.User, error)
+}
+
+// Request - токен из письма, отправленного на новый email.
+type Request struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// New godoc
+// @Summary Confirm email change
+// @Description Confirms the new email with the token sent to the new address. The token is used once, unknown and expired tokens get 404. The confirmed email is marked as verified
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body confirmEmailChange.Request true "Confirmation token"
+// @Success 200 {object} dto.User
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 409 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/email/confirm [post]
+func New(log *slog.Logger, confirmer Confirmer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.ConfirmEmailChange"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", "error", err)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidBody, "invalid request body")
+			return
+		}
+		if err := validate.New().Struct(req); err != nil {
+			log.Info("validation failed", "error", err)
+			response.WriteProblem(w, r, response.ValidationFailed(err))
+			return
+		}
+
+		user, err := confirmer.ConfirmEmailChange(r.Context(), account.HashToken(req.Token), time.Now())
+		if errors.Is(err, storage.ErrEmailChangeNotFound) || errors.Is(err, storage.ErrUserNotFound) {
+			log.Info("email change not found or expired")
+			response.Fail(w, r, http.StatusNotFound, response.CodeInvalidConfirmationToken, "invalid or expired token")
+			return
+		}
+		if errors.Is(err, storage.ErrUserExists) {
+			log.Info("email already taken")
+			response.Fail(w, r, http.StatusConflict, response.CodeEmailTaken, "email already taken")
+			return
+		}
+		if errors.Is(err, storage.ErrVersionConflict) {
+			log.Info("user was modified concurrently")
+			response.Fail(w, r, http.StatusConflict, response.CodeVersionConflict, "user was modified by another request")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to confirm email change", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to confirm email change")
+			return
+		}
+
+		log.Info("email changed", "id", user.ID)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, dto.NewUser(user).Localize(country.Language(r.Header.Get("Accept-Language"))))
+	}
+}
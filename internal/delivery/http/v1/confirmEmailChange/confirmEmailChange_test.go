@@ -0,0 +1,120 @@
+package confirmEmailChange_test
+
+import (
+	"backend-app/internal/account"
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/delivery/http/v1/confirmEmailChange"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockStorage struct {
+	change    *models.EmailChange
+	err       error
+	confirmed *time.Time
+}
+
+func (m *mockStorage) ConfirmEmailChange(ctx context.Context, tokenHash string, now time.Time) (*models.User, error) {
+	if m.change == nil || m.change.TokenHash != tokenHash || now.After(m.change.ExpiresAt) {
+		return nil, storage.ErrEmailChangeNotFound
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.confirmed = &now
+	return &models.User{ID: m.change.UserID, Username: "alice", Email: m.change.NewEmail, Verified: true, EmailChangedAt: &now}, nil
+}
+
+func TestConfirmEmailChangeHandler(t *testing.T) {
+	token, hash := account.NewToken()
+
+	tests := []struct {
+		name           string
+		body           string
+		expiresAt      time.Time
+		err            error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "without_token",
+			body:           `{}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   "validation failed",
+		},
+		{
+			name:           "unknown_token",
+			body:           `{"token":"unknown"}`,
+			expiresAt:      time.Now().Add(time.Hour),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "invalid or expired token",
+		},
+		{
+			name:           "expired",
+			body:           `{"token":"` + token + `"}`,
+			expiresAt:      time.Now().Add(-time.Minute),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "invalid or expired token",
+		},
+		{
+			name:           "taken",
+			body:           `{"token":"` + token + `"}`,
+			expiresAt:      time.Now().Add(time.Hour),
+			err:            storage.ErrUserExists,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "email already taken",
+		},
+		{
+			name:           "success",
+			body:           `{"token":"` + token + `"}`,
+			expiresAt:      time.Now().Add(time.Hour),
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockStorage{
+				change: &models.EmailChange{UserID: 4, NewEmail: "new@example.com", TokenHash: hash, ExpiresAt: tt.expiresAt},
+				err:    tt.err,
+			}
+			router := chi.NewRouter()
+			router.Use(middleware.RequestID)
+			router.Post("/email/confirm", confirmEmailChange.New(slog.Default(), m))
+
+			req := httptest.NewRequest(http.MethodPost, "/email/confirm", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				var res response.Problem
+				_ = render.DecodeJSON(rr.Body, &res)
+				assert.Equal(t, tt.expectedBody, res.Detail)
+				assert.Nil(t, m.confirmed)
+				return
+			}
+
+			require.NotNil(t, m.confirmed)
+			var res dto.User
+			require.NoError(t, render.DecodeJSON(rr.Body, &res))
+			assert.Equal(t, uint(4), res.ID)
+			assert.Equal(t, "new@example.com", res.Email)
+			assert.True(t, res.Verified)
+		})
+	}
+}
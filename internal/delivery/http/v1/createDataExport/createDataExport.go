@@ -0,0 +1,95 @@
+package createDataExport
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/privacy"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Creator interface {
+	ListDataExports(ctx context.Context, userID uint) ([]models.DataExport, error)
+	CreateDataExport(ctx context.Context, export *models.DataExport) error
+}
+
+// New godoc
+// @Summary Request data export
+// @Description Queues an archive of the current user's profile, sessions and audit entries. The archive is built in the background; poll GET /v1/me/data-exports and download it when it is ready. If an export in the same format is already pending, it is returned instead of a new one
+// @Tags privacy
+// @Produce json
+// @Param format query string false "Archive format, default json" Enums(json, zip)
+// @Success 202 {object} dto.DataExport
+// @Failure 401 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/me/data-exports [post]
+func New(log *slog.Logger, creator Creator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.CreateDataExport"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		format := r.URL.Query().Get("format")
+		switch format {
+		case "":
+			format = privacy.FormatJSON
+		case privacy.FormatJSON, privacy.FormatZIP:
+		default:
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeUnknownExportFormat, privacy.ErrUnknownFormat.Error())
+			return
+		}
+		userID := authMiddleware.UserID(r.Context())
+
+		exports, err := creator.ListDataExports(r.Context(), userID)
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to list data exports", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to create data export")
+			return
+		}
+		for i := range exports {
+			if exports[i].Status == models.ExportPending && exports[i].Format == format {
+				render.Status(r, http.StatusAccepted)
+				render.JSON(w, r, dto.NewDataExport(&exports[i]))
+				return
+			}
+		}
+
+		export := models.DataExport{
+			UserID: userID,
+			Format: format,
+			Status: models.ExportPending,
+		}
+		err = creator.CreateDataExport(r.Context(), &export)
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to create data export", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to create data export")
+			return
+		}
+
+		log.Info("data export requested", "id", export.ID, "user_id", userID, "format", format)
+		render.Status(r, http.StatusAccepted)
+		render.JSON(w, r, dto.NewDataExport(&export))
+	}
+}
@@ -0,0 +1,103 @@
+package createGroup
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/permissions"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"backend-app/pkg/api/validate"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Creator interface {
+	CreateGroup(ctx context.Context, group *models.Group) error
+}
+
+type Request struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=500"`
+	// ParentID - группа, права которой унаследует эта
+	ParentID *uint `json:"parentId" validate:"omitempty,min=1"`
+	// Roles выдаются всем участникам, администраторов группами не назначают
+	Roles       []string `json:"roles" validate:"unique,dive,oneof=user creator combined"`
+	Permissions []string `json:"permissions" validate:"unique"`
+}
+
+// New godoc
+// @Summary Create group
+// @Description Creates a group. Its members get its roles and permissions and everything granted to its parent group and the parent's ancestors. Groups cannot grant the admin role or admin permissions of this service, but may grant permissions of other services in the resource:action form. Changes apply to tokens issued afterwards
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param input body createGroup.Request true "Group"
+// @Success 201 {object} dto.Group
+// @Failure 400 {object} response.Problem
+// @Failure 409 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/groups [post]
+func New(log *slog.Logger, creator Creator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.CreateGroup"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", "error", err)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidBody, "invalid request body")
+			return
+		}
+		if err := validate.New().Struct(req); err != nil {
+			log.Info("validation failed", "error", err)
+			response.WriteProblem(w, r, response.ValidationFailed(err))
+			return
+		}
+		for _, perm := range req.Permissions {
+			if err := permissions.Check(perm); err != nil {
+				log.Info("validation failed", "error", err)
+				response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeUnknownPermission, err.Error())
+				return
+			}
+		}
+
+		group := models.Group{Name: req.Name, Description: req.Description, ParentID: req.ParentID}
+		group.SetRoles(req.Roles)
+		group.SetPermissions(req.Permissions)
+		err := creator.CreateGroup(r.Context(), &group)
+		if errors.Is(err, storage.ErrGroupExists) {
+			response.Fail(w, r, http.StatusConflict, response.CodeGroupExists, err.Error())
+			return
+		}
+		if errors.Is(err, storage.ErrGroupParentNotFound) {
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeGroupParentNotFound, err.Error())
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to create group", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to create group")
+			return
+		}
+
+		log.Info("group created", "id", group.ID)
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, dto.NewGroup(&group))
+	}
+}
@@ -0,0 +1,122 @@
+package createGroup_test
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/delivery/http/v1/createGroup"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCreator struct {
+	err error
+}
+
+func (m *mockCreator) CreateGroup(ctx context.Context, group *models.Group) error {
+	if m.err != nil {
+		return m.err
+	}
+	group.ID = 1
+	return nil
+}
+
+func TestCreateGroupHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		mockErr        error
+		expectedStatus int
+	}{
+		{
+			name:           "success",
+			body:           `{"name":"editors","parentId":2,"roles":["creator"],"permissions":["dishes:publish"]}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid_json",
+			body:           `{`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "no_name",
+			body:           `{"roles":["creator"]}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "admin_role",
+			body:           `{"name":"editors","roles":["admin"]}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "admin_permission",
+			body:           `{"name":"editors","permissions":["users:delete"]}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "malformed_permission",
+			body:           `{"name":"editors","permissions":["publish"]}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "duplicate_permission",
+			body:           `{"name":"editors","permissions":["dishes:publish","dishes:publish"]}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "exists",
+			body:           `{"name":"editors"}`,
+			mockErr:        storage.ErrGroupExists,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "parent_not_found",
+			body:           `{"name":"editors","parentId":100}`,
+			mockErr:        storage.ErrGroupParentNotFound,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "storage_error",
+			body:           `{"name":"editors"}`,
+			mockErr:        errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := chi.NewRouter()
+			router.Use(middleware.RequestID)
+			router.Post("/groups", createGroup.New(slog.Default(), &mockCreator{err: tt.mockErr}))
+
+			req := httptest.NewRequest(http.MethodPost, "/groups", bytes.NewBufferString(tt.body))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+
+			var res dto.Group
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+			assert.Equal(t, uint(1), res.ID)
+			assert.Equal(t, "editors", res.Name)
+			require.NotNil(t, res.ParentID)
+			assert.Equal(t, uint(2), *res.ParentID)
+			assert.Equal(t, []string{"creator"}, res.Roles)
+			assert.Equal(t, []string{"dishes:publish"}, res.Permissions)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package createOrganization
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"backend-app/pkg/api/validate"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Creator interface {
+	CreateOrganization(ctx context.Context, org *models.Organization) error
+}
+
+type Request struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// New godoc
+// @Summary Create organization
+// @Description Creates an organization. Only a superadmin can manage organizations
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param input body createOrganization.Request true "Organization"
+// @Success 201 {object} dto.Organization
+// @Failure 400 {object} response.Problem
+// @Failure 409 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/orgs [post]
+func New(log *slog.Logger, creator Creator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.CreateOrganization"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", "error", err)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidBody, "invalid request body")
+			return
+		}
+		req.Name = strings.TrimSpace(req.Name)
+		if err := validate.New().Struct(req); err != nil {
+			log.Info("validation failed", "error", err)
+			response.WriteProblem(w, r, response.ValidationFailed(err))
+			return
+		}
+
+		org := models.Organization{Name: req.Name}
+		err := creator.CreateOrganization(r.Context(), &org)
+		if errors.Is(err, storage.ErrOrgExists) {
+			log.Info("organization already exists", "name", org.Name)
+			response.Fail(w, r, http.StatusConflict, response.CodeOrganizationExists, err.Error())
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to create organization", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to create organization")
+			return
+		}
+
+		log.Info("organization created", "id", org.ID)
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, dto.NewOrganization(&org))
+	}
+}
@@ -0,0 +1,115 @@
+package createProfileAttribute
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/profile"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"backend-app/pkg/api/validate"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Creator interface {
+	CreateAttributeDefinition(ctx context.Context, def *models.AttributeDefinition) error
+}
+
+type Request struct {
+	// Key - имя атрибута в profile.attributes: строчные латинские буквы, цифры и _
+	Key          string `json:"key" validate:"required,max=64"`
+	Type         string `json:"type" validate:"required,oneof=string number integer boolean enum"`
+	Description  string `json:"description" validate:"max=500"`
+	Required     bool   `json:"required"`
+	UserEditable bool   `json:"userEditable"`
+	// Enum - допустимые значения атрибута типа enum
+	Enum      []string `json:"enum" validate:"max=100,unique,dive,required,max=100,excludesall=0x2C"`
+	MinLength *int     `json:"minLength" validate:"omitempty,min=0"`
+	MaxLength *int     `json:"maxLength" validate:"omitempty,min=1"`
+	Minimum   *float64 `json:"minimum"`
+	Maximum   *float64 `json:"maximum"`
+	// Pattern - регулярное выражение в синтаксисе Go RE2
+	Pattern string `json:"pattern" validate:"max=500"`
+}
+
+// New godoc
+// @Summary Create profile attribute
+// @Description Adds an attribute to the schema of profile attributes. Values of the attribute are checked against its type and constraints: minLength, maxLength and pattern for strings, minimum and maximum for numbers and integers, enum values for enums. Attributes not marked userEditable are changed only by administrators
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param input body createProfileAttribute.Request true "Attribute"
+// @Success 201 {object} dto.AttributeDefinition
+// @Failure 400 {object} response.Problem
+// @Failure 409 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/profile/attributes [post]
+func New(log *slog.Logger, creator Creator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.CreateProfileAttribute"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", "error", err)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidBody, "invalid request body")
+			return
+		}
+		if err := validate.New().Struct(req); err != nil {
+			log.Info("validation failed", "error", err)
+			response.WriteProblem(w, r, response.ValidationFailed(err))
+			return
+		}
+
+		def := &models.AttributeDefinition{
+			Key:          req.Key,
+			Type:         req.Type,
+			Description:  req.Description,
+			Required:     req.Required,
+			UserEditable: req.UserEditable,
+			MinLength:    req.MinLength,
+			MaxLength:    req.MaxLength,
+			Minimum:      req.Minimum,
+			Maximum:      req.Maximum,
+			Pattern:      req.Pattern,
+		}
+		def.SetEnum(req.Enum)
+		if err := profile.CheckDefinition(def); err != nil {
+			log.Info("validation failed", "error", err)
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidAttributeDefinition, err.Error())
+			return
+		}
+
+		err := creator.CreateAttributeDefinition(r.Context(), def)
+		if errors.Is(err, storage.ErrAttributeExists) {
+			response.Fail(w, r, http.StatusConflict, response.CodeAttributeExists, err.Error())
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to create attribute", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to create attribute")
+			return
+		}
+
+		log.Info("profile attribute created", "key", def.Key)
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, dto.NewAttributeDefinition(def))
+	}
+}
@@ -0,0 +1,115 @@
+package createProfileAttribute_test
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/delivery/http/v1/createProfileAttribute"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCreator struct {
+	err error
+}
+
+func (m *mockCreator) CreateAttributeDefinition(ctx context.Context, def *models.AttributeDefinition) error {
+	return m.err
+}
+
+func TestCreateProfileAttributeHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		mockErr        error
+		expectedStatus int
+	}{
+		{
+			name:           "success",
+			body:           `{"key":"tshirt","type":"enum","enum":["S","M","L"],"userEditable":true}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid_json",
+			body:           `{`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown_type",
+			body:           `{"key":"tshirt","type":"size"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "invalid_key",
+			body:           `{"key":"T-Shirt","type":"string"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "enum_without_values",
+			body:           `{"key":"tshirt","type":"enum"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "comma_in_enum_value",
+			body:           `{"key":"tshirt","type":"enum","enum":["S,M"]}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "length_for_number",
+			body:           `{"key":"age","type":"integer","maxLength":3}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "invalid_pattern",
+			body:           `{"key":"code","type":"string","pattern":"("}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "exists",
+			body:           `{"key":"team","type":"string"}`,
+			mockErr:        storage.ErrAttributeExists,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "storage_error",
+			body:           `{"key":"team","type":"string"}`,
+			mockErr:        errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := chi.NewRouter()
+			router.Use(middleware.RequestID)
+			router.Post("/profile/attributes", createProfileAttribute.New(slog.Default(), &mockCreator{err: tt.mockErr}))
+
+			req := httptest.NewRequest(http.MethodPost, "/profile/attributes", bytes.NewBufferString(tt.body))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+
+			var res dto.AttributeDefinition
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+			assert.Equal(t, "tshirt", res.Key)
+			assert.Equal(t, models.AttributeEnum, res.Type)
+			assert.Equal(t, []string{"S", "M", "L"}, res.Enum)
+			assert.True(t, res.UserEditable)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package createWebhook
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/storage/models"
+	"backend-app/internal/webhooks"
+	"backend-app/pkg/api/response"
+	"backend-app/pkg/api/validate"
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Creator interface {
+	CreateWebhook(ctx context.Context, hook *models.Webhook) error
+}
+
+type Request struct {
+	URL    string   `json:"url" validate:"required,http_url"`
+	Events []string `json:"events" validate:"required,min=1,unique"`
+	// Active по умолчанию true
+	Active *bool `json:"active"`
+	// Secret можно задать самому, иначе он будет сгенерирован
+	Secret string `json:"secret" validate:"omitempty,min=16,max=128"`
+}
+
+// New godoc
+// @Summary Create webhook
+// @Description Registers a webhook for user events. Requests are signed with HMAC-SHA256 of "<X-Webhook-Timestamp>.<body>" in X-Webhook-Signature. The secret is returned only in this response
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param input body createWebhook.Request true "Webhook"
+// @Success 201 {object} dto.WebhookWithSecret
+// @Failure 400 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/webhooks [post]
+func New(log *slog.Logger, creator Creator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.CreateWebhook"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", "error", err)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidBody, "invalid request body")
+			return
+		}
+		if err := validate.New().Struct(req); err != nil {
+			log.Info("validation failed", "error", err)
+			response.WriteProblem(w, r, response.ValidationFailed(err))
+			return
+		}
+		if err := webhooks.CheckEventTypes(req.Events); err != nil {
+			log.Info("validation failed", "error", err)
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeUnknownEventType, err.Error())
+			return
+		}
+
+		hook := models.Webhook{
+			URL:    req.URL,
+			Secret: req.Secret,
+			Active: req.Active == nil || *req.Active,
+		}
+		hook.SetEventTypes(req.Events)
+		if hook.Secret == "" {
+			hook.Secret = webhooks.NewSecret()
+		}
+
+		err := creator.CreateWebhook(r.Context(), &hook)
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to create webhook", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to create webhook")
+			return
+		}
+
+		log.Info("webhook created", "id", hook.ID, "events", hook.Events)
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, dto.WebhookWithSecret{Webhook: dto.NewWebhook(&hook), Secret: hook.Secret})
+	}
+}
@@ -0,0 +1,114 @@
+package createWebhook_test
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/delivery/http/v1/createWebhook"
+	"backend-app/internal/storage/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCreator struct {
+	created *models.Webhook
+	err     error
+}
+
+func (m *mockCreator) CreateWebhook(ctx context.Context, hook *models.Webhook) error {
+	if m.err != nil {
+		return m.err
+	}
+	hook.ID = 1
+	m.created = hook
+	return nil
+}
+
+func TestCreateWebhookHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		mockErr        error
+		expectedStatus int
+		wantActive     bool
+		wantSecret     string
+	}{
+		{
+			name:           "success",
+			body:           `{"url":"https://example.com/hook","events":["user.created","user.deleted"]}`,
+			expectedStatus: http.StatusCreated,
+			wantActive:     true,
+		},
+		{
+			name:           "inactive_with_secret",
+			body:           `{"url":"https://example.com/hook","events":["user.created"],"active":false,"secret":"0123456789abcdef"}`,
+			expectedStatus: http.StatusCreated,
+			wantSecret:     "0123456789abcdef",
+		},
+		{
+			name:           "invalid_json",
+			body:           `{`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid_url",
+			body:           `{"url":"not a url","events":["user.created"]}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "no_events",
+			body:           `{"url":"https://example.com/hook","events":[]}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "unknown_event",
+			body:           `{"url":"https://example.com/hook","events":["user.exploded"]}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "storage_error",
+			body:           `{"url":"https://example.com/hook","events":["user.created"]}`,
+			mockErr:        errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creator := &mockCreator{err: tt.mockErr}
+			router := chi.NewRouter()
+			router.Use(middleware.RequestID)
+			router.Post("/webhooks", createWebhook.New(slog.Default(), creator))
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBufferString(tt.body))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+
+			var res dto.WebhookWithSecret
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+			assert.Equal(t, uint(1), res.ID)
+			assert.Equal(t, tt.wantActive, res.Active)
+			assert.Equal(t, creator.created.Secret, res.Secret, "secret is returned on creation")
+			if tt.wantSecret != "" {
+				assert.Equal(t, tt.wantSecret, res.Secret)
+			} else {
+				assert.True(t, strings.HasPrefix(res.Secret, "whsec_"))
+			}
+		})
+	}
+}
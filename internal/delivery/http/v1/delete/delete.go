@@ -1,7 +1,11 @@
 package delete
 
 import (
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/delivery/http/tenant"
+	"backend-app/internal/storage"
 	"backend-app/pkg/api/response"
+	"context"
 	"errors"
 	"log/slog"
 	"net/http"
@@ -10,23 +14,27 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
-	"gorm.io/gorm"
 )
 
 type deleter interface {
-	DeleteUser(id uint) error
+	DeleteUser(ctx context.Context, id uint, deletedBy uint, reason string) error
+	tenant.Users
 }
 
 // New godoc
 // @Summary Delete user
-// @Description Deletes a user by ID
+// @Description Soft-deletes a user by ID. The user can be restored until the retention period passes. Organization admins can delete only members of their organization that belong to no other organization
 // @Tags users
 // @Produce json
 // @Param id path int true "User ID"
+// @Param reason query string false "Reason of deletion"
 // @Success 200 {object} response.Response
-// @Failure 400 {object} response.Response
-// @Failure 404 {object} response.Response
-// @Failure 500 {object} response.Response
+// @Failure 400 {object} response.Problem
+// @Failure 403 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
 // @Router /v1/user/{id} [delete]
 func New(log *slog.Logger, deleter deleter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -41,22 +49,33 @@ func New(log *slog.Logger, deleter deleter) http.HandlerFunc {
 		idUint, err := strconv.ParseUint(idStr, 10, 32)
 		if err != nil {
 			log.Error("invalid user id", "param", idStr, "error", err)
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, response.Error("invalid user id"))
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid user id")
 			return
 		}
 
-		err = deleter.DeleteUser(uint(idUint))
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		reason := r.URL.Query().Get("reason")
+		err = tenant.CheckWrite(r.Context(), deleter, uint(idUint), "")
+		if err == nil {
+			err = deleter.DeleteUser(r.Context(), uint(idUint), authMiddleware.UserID(r.Context()), reason)
+		}
+		if errors.Is(err, tenant.ErrForbidden) {
+			log.Info("forbidden for this admin", "id", idUint)
+			response.Fail(w, r, http.StatusForbidden, response.CodeForbidden, err.Error())
+			return
+		}
+		if errors.Is(err, storage.ErrUserNotFound) {
 			log.Info("user not found", "id", idUint)
-			render.Status(r, http.StatusNotFound)
-			render.JSON(w, r, response.Error("user not found"))
+			response.Fail(w, r, http.StatusNotFound, response.CodeUserNotFound, "user not found")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
 			return
 		}
 		if err != nil {
 			log.Error("failed to delete user", "error", err)
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.Error("failed to delete user"))
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to delete user")
 			return
 		}
 
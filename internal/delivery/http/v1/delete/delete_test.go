@@ -2,7 +2,10 @@ package delete_test
 
 import (
 	delete2 "backend-app/internal/delivery/http/v1/delete"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
 	"backend-app/pkg/api/response"
+	"context"
 	"errors"
 	"log/slog"
 	"net/http"
@@ -13,15 +16,27 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/gorm"
 )
 
 type mockDeleter struct {
-	DeleteFn func(id uint) error
+	DeleteFn func(id uint, deletedBy uint, reason string) error
+	Role     string
 }
 
-func (m *mockDeleter) DeleteUser(id uint) error {
-	return m.DeleteFn(id)
+func (m *mockDeleter) DeleteUser(ctx context.Context, id uint, deletedBy uint, reason string) error {
+	return m.DeleteFn(id, deletedBy, reason)
+}
+
+func (m *mockDeleter) GetMembership(ctx context.Context, orgID uint, userID uint) (*models.Membership, error) {
+	return nil, storage.ErrMembershipNotFound
+}
+
+func (m *mockDeleter) ListUserMemberships(ctx context.Context, userID uint) ([]models.Membership, error) {
+	return nil, nil
+}
+
+func (m *mockDeleter) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	return &models.User{ID: id, Role: m.Role}, nil
 }
 
 func TestDeleteUserHandler(t *testing.T) {
@@ -29,6 +44,7 @@ func TestDeleteUserHandler(t *testing.T) {
 		name           string
 		urlParam       string
 		mockDeleteErr  error
+		targetRole     string
 		expectedStatus int
 		expectedBody   string
 	}{
@@ -41,10 +57,17 @@ func TestDeleteUserHandler(t *testing.T) {
 		{
 			name:           "user_not_found",
 			urlParam:       "123",
-			mockDeleteErr:  gorm.ErrRecordNotFound,
+			mockDeleteErr:  storage.ErrUserNotFound,
 			expectedStatus: http.StatusNotFound,
 			expectedBody:   "user not found",
 		},
+		{
+			name:           "superadmin_target",
+			urlParam:       "123",
+			targetRole:     models.RoleSuperAdmin,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "not allowed to change this user",
+		},
 		{
 			name:           "internal_error",
 			urlParam:       "123",
@@ -69,22 +92,24 @@ func TestDeleteUserHandler(t *testing.T) {
 			router.Use(middleware.RequestID)
 			router.Use(render.SetContentType(render.ContentTypeJSON))
 			router.Delete("/users/{id}", delete2.New(slog.Default(), &mockDeleter{
-				DeleteFn: func(id uint) error {
+				DeleteFn: func(id uint, deletedBy uint, reason string) error {
 					return tt.mockDeleteErr
 				},
+				Role: tt.targetRole,
 			}))
 
 			router.ServeHTTP(rr, req)
 
 			assert.Equal(t, tt.expectedStatus, rr.Code)
 
-			var res response.Response
-			_ = render.DecodeJSON(rr.Body, &res)
-
 			if tt.expectedBody != "" {
-				assert.Equal(t, "Error", res.Status)
-				assert.Equal(t, tt.expectedBody, res.Error)
+				var res response.Problem
+				_ = render.DecodeJSON(rr.Body, &res)
+				assert.Equal(t, response.ProblemContentType, rr.Header().Get("Content-Type"))
+				assert.Equal(t, tt.expectedBody, res.Detail)
 			} else {
+				var res response.Response
+				_ = render.DecodeJSON(rr.Body, &res)
 				assert.Equal(t, "OK", res.Status)
 			}
 		})
@@ -0,0 +1,76 @@
+package deleteGroup
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Deleter interface {
+	DeleteGroup(ctx context.Context, id uint) error
+}
+
+// New godoc
+// @Summary Delete group
+// @Description Deletes a group and removes its members from it. A group other groups inherit from cannot be deleted
+// @Tags groups
+// @Produce json
+// @Param id path int true "Group ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 409 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/groups/{id} [delete]
+func New(log *slog.Logger, deleter Deleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.DeleteGroup"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			log.Info("invalid group id", "param", idStr)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid group id")
+			return
+		}
+
+		err = deleter.DeleteGroup(r.Context(), uint(id))
+		if errors.Is(err, storage.ErrGroupNotFound) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeGroupNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, storage.ErrGroupHasChildren) {
+			response.Fail(w, r, http.StatusConflict, response.CodeGroupHasChildren, err.Error())
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to delete group", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to delete group")
+			return
+		}
+
+		log.Info("group deleted", "id", id)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, response.OK())
+	}
+}
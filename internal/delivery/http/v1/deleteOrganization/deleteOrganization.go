@@ -0,0 +1,76 @@
+package deleteOrganization
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Deleter interface {
+	DeleteOrganization(ctx context.Context, id uint) error
+}
+
+// New godoc
+// @Summary Delete organization
+// @Description Deletes an organization without members. Members have to be removed first, so that nobody loses access by accident
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 409 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/orgs/{id} [delete]
+func New(log *slog.Logger, deleter Deleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.DeleteOrganization"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			log.Info("invalid organization id", "param", idStr)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid organization id")
+			return
+		}
+
+		err = deleter.DeleteOrganization(r.Context(), uint(id))
+		if errors.Is(err, storage.ErrOrgNotFound) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeOrganizationNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, storage.ErrOrgNotEmpty) {
+			response.Fail(w, r, http.StatusConflict, response.CodeOrganizationNotEmpty, err.Error())
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to delete organization", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to delete organization")
+			return
+		}
+
+		log.Info("organization deleted", "id", id)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, response.OK())
+	}
+}
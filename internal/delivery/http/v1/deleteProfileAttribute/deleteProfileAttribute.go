@@ -0,0 +1,62 @@
+package deleteProfileAttribute
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Deleter interface {
+	DeleteAttributeDefinition(ctx context.Context, key string) error
+}
+
+// New godoc
+// @Summary Delete profile attribute
+// @Description Removes an attribute from the schema. Its values are no longer returned and are dropped from a profile on its next update
+// @Tags profile
+// @Produce json
+// @Param key path string true "Attribute key"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/profile/attributes/{key} [delete]
+func New(log *slog.Logger, deleter Deleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.DeleteProfileAttribute"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		key := chi.URLParam(r, "key")
+		err := deleter.DeleteAttributeDefinition(r.Context(), key)
+		if errors.Is(err, storage.ErrAttributeNotFound) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeAttributeNotFound, err.Error())
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to delete attribute", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to delete attribute")
+			return
+		}
+
+		log.Info("profile attribute deleted", "key", key)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, response.OK())
+	}
+}
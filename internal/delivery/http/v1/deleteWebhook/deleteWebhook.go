@@ -0,0 +1,71 @@
+package deleteWebhook
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Deleter interface {
+	DeleteWebhook(ctx context.Context, id uint) error
+}
+
+// New godoc
+// @Summary Delete webhook
+// @Description Deletes a webhook together with its delivery log
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/webhooks/{id} [delete]
+func New(log *slog.Logger, deleter Deleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.DeleteWebhook"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			log.Info("invalid webhook id", "param", idStr)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid webhook id")
+			return
+		}
+
+		err = deleter.DeleteWebhook(r.Context(), uint(id))
+		if errors.Is(err, storage.ErrWebhookNotFound) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeWebhookNotFound, "webhook not found")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to delete webhook", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to delete webhook")
+			return
+		}
+
+		log.Info("webhook deleted", "id", id)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, response.OK())
+	}
+}
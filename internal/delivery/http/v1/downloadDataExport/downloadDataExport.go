@@ -0,0 +1,90 @@
+package downloadDataExport
+
+import (
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/privacy"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+type Getter interface {
+	GetDataExport(ctx context.Context, id uint) (*models.DataExport, error)
+}
+
+// New godoc
+// @Summary Download data export
+// @Description Downloads a ready archive of the current user's data. Exports of other users are reported as not found
+// @Tags privacy
+// @Produce json
+// @Produce application/zip
+// @Param id path int true "Data export ID"
+// @Success 200 {file} file
+// @Failure 400 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 409 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/me/data-exports/{id}/archive [get]
+func New(log *slog.Logger, getter Getter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.DownloadDataExport"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			log.Info("invalid data export id", "param", idStr)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid data export id")
+			return
+		}
+
+		export, err := getter.GetDataExport(r.Context(), uint(id))
+		// чужая выгрузка не должна отличаться от несуществующей
+		if errors.Is(err, storage.ErrDataExportNotFound) ||
+			(err == nil && export.UserID != authMiddleware.UserID(r.Context())) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeDataExportNotFound, "data export not found")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to get data export", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to get data export")
+			return
+		}
+		if export.Status != models.ExportReady {
+			response.Fail(w, r, http.StatusConflict, response.CodeDataExportNotReady, "data export is "+export.Status)
+			return
+		}
+
+		w.Header().Set("Content-Type", privacy.ContentType(export.Format))
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="data-export-%d.%s"`, export.ID, export.Format))
+		w.Header().Set("Content-Length", strconv.Itoa(len(export.Archive)))
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(export.Archive); err != nil {
+			log.Error("failed to write archive", "error", err)
+			return
+		}
+		log.Info("data export downloaded", "id", export.ID, "user_id", export.UserID)
+	}
+}
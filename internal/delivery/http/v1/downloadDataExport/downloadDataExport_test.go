@@ -0,0 +1,118 @@
+package downloadDataExport_test
+
+import (
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/delivery/http/v1/downloadDataExport"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockGetter struct {
+	export *models.DataExport
+	err    error
+}
+
+func (m *mockGetter) GetDataExport(ctx context.Context, id uint) (*models.DataExport, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	e := *m.export
+	return &e, nil
+}
+
+func TestDownloadDataExportHandler(t *testing.T) {
+	ready := &models.DataExport{ID: 5, UserID: 1, Format: "zip", Status: models.ExportReady, Archive: []byte("PK")}
+
+	tests := []struct {
+		name           string
+		url            string
+		export         *models.DataExport
+		err            error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "invalid_id",
+			url:            "/me/data-exports/abc/archive",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid data export id",
+		},
+		{
+			name:           "not_found",
+			url:            "/me/data-exports/5/archive",
+			err:            storage.ErrDataExportNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "data export not found",
+		},
+		{
+			name:           "other_user",
+			url:            "/me/data-exports/5/archive",
+			export:         &models.DataExport{ID: 5, UserID: 2, Format: "zip", Status: models.ExportReady, Archive: []byte("PK")},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "data export not found",
+		},
+		{
+			name:           "pending",
+			url:            "/me/data-exports/5/archive",
+			export:         &models.DataExport{ID: 5, UserID: 1, Format: "json", Status: models.ExportPending},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "data export is pending",
+		},
+		{
+			name:           "storage_error",
+			url:            "/me/data-exports/5/archive",
+			err:            errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to get data export",
+		},
+		{
+			name:           "success",
+			url:            "/me/data-exports/5/archive",
+			export:         ready,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	_, token, err := authMiddleware.AccessTokenAuth.Encode(map[string]interface{}{"user_id": 1, "role": "user"})
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := chi.NewRouter()
+			router.Use(middleware.RequestID)
+			router.Use(jwtauth.Verifier(authMiddleware.AccessTokenAuth))
+			router.Get("/me/data-exports/{id}/archive", downloadDataExport.New(slog.Default(), &mockGetter{export: tt.export, err: tt.err}))
+
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				var res response.Problem
+				_ = render.DecodeJSON(rr.Body, &res)
+				assert.Equal(t, tt.expectedBody, res.Detail)
+				return
+			}
+
+			assert.Equal(t, "application/zip", rr.Header().Get("Content-Type"))
+			assert.Equal(t, `attachment; filename="data-export-5.zip"`, rr.Header().Get("Content-Disposition"))
+			assert.Equal(t, "PK", rr.Body.String())
+		})
+	}
+}
@@ -1,36 +1,75 @@
 package edit
 
 import (
+	"backend-app/internal/account"
+	"backend-app/internal/country"
+	"backend-app/internal/delivery/http/etag"
+	"backend-app/internal/delivery/http/tenant"
+	"backend-app/internal/storage"
 	"backend-app/internal/storage/models"
 	"backend-app/pkg/api/response"
+	"backend-app/pkg/api/validate"
+	"context"
 	"errors"
 	"log/slog"
 	"net/http"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
-	"github.com/go-playground/validator/v10"
-	"gorm.io/gorm"
 )
 
 type Updater interface {
-	UpdateUser(user *models.User) error
+	UpdateUser(ctx context.Context, user *models.User) error
+	tenant.Users
+}
+
+// Request заменяет пользователя целиком, кроме пароля: пустой пароль остаётся прежним.
+type Request struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"omitempty,max=72"`
+	Email    string `json:"email" validate:"required,email"`
+	Role     string `json:"role" validate:"required,oneof=user creator combined admin superadmin"`
+	// Country сохраняется кодом ISO 3166-1 alpha-2
+	Country  string `json:"country"`
+	Status   string `json:"status" validate:"omitempty,oneof=active suspended"`
+	Verified bool   `json:"verified"`
+}
+
+func (req Request) toModel() models.User {
+	return models.User{
+		ID:       req.ID,
+		Username: req.Username,
+		Password: req.Password,
+		Email:    req.Email,
+		Role:     req.Role,
+		Country:  req.Country,
+		Status:   req.Status,
+		Verified: req.Verified,
+	}
 }
 
 // New godoc
 // @Summary Update user
-// @Description Updates user data
+// @Description Updates user data. The country may be given as an ISO 3166-1 alpha-2, alpha-3 or numeric code or as an English name and is stored as alpha-2. Username rules are checked only when the username changes. If-Match must carry the ETag from GET /v1/user/{id}; if the user has changed since, the update is rejected with 412. Only a superadmin can grant or revoke the superadmin role; organization admins must keep the role as is and can update only members of their organization that belong to no other organization
 // @Tags users
 // @Accept json
 // @Produce json
-// @Param input body models.User true "User data"
+// @Param If-Match header string true "ETag of the user version being updated"
+// @Param input body edit.Request true "User data"
 // @Success 200 {object} response.Response
-// @Failure 400 {object} response.Response
-// @Failure 404 {object} response.Response
-// @Failure 422 {object} response.Response
-// @Failure 500 {object} response.Response
+// @Header 200 {string} ETag "New user version"
+// @Failure 400 {object} response.Problem
+// @Failure 403 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 412 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 428 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
 // @Router /v1/user [put]
-func New(log *slog.Logger, updater Updater) http.HandlerFunc {
+func New(log *slog.Logger, updater Updater, accounts account.Policy) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.v1.UpdateUser"
 
@@ -39,37 +78,98 @@ func New(log *slog.Logger, updater Updater) http.HandlerFunc {
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
-		var req models.User
+		ifMatch := r.Header.Get("If-Match")
+		if ifMatch == "" {
+			log.Info("update without If-Match")
+			response.Fail(w, r, http.StatusPreconditionRequired, response.CodePreconditionRequired, "If-Match header is required")
+			return
+		}
+		version, err := etag.Parse(ifMatch)
+		if err != nil {
+			log.Info("invalid If-Match", slog.String("if_match", ifMatch))
+			response.Fail(w, r, http.StatusPreconditionFailed, response.CodePreconditionFailed, "precondition failed")
+			return
+		}
+
+		var req Request
 		if err := render.DecodeJSON(r.Body, &req); err != nil {
 			log.Error("failed to decode request body", "error", err)
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, response.Error("invalid request body"))
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidBody, "invalid request body")
 			return
 		}
-		log.Info("request body decoded", slog.Any("user", req))
+		log.Info("request body decoded", "id", req.ID)
 
-		if err := validator.New().Struct(req); err != nil {
+		if err := validate.New().Struct(req); err != nil {
 			log.Error("validation failed", "error", err)
-			render.Status(r, http.StatusUnprocessableEntity)
-			render.JSON(w, r, response.Error("validation failed"))
+			response.WriteProblem(w, r, response.ValidationFailed(err))
 			return
 		}
 
-		err := updater.UpdateUser(&req)
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		user := req.toModel()
+		user.Version = version
+		user.Country, err = country.Normalize(req.Country)
+		if err != nil {
+			log.Info("unknown country", slog.String("country", req.Country))
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeUnknownCountry, err.Error())
+			return
+		}
+		if user.Password != "" {
+			if err := user.HashPassword(); err != nil {
+				log.Error("failed to hash password", "error", err)
+				response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to update user")
+				return
+			}
+		}
+
+		err = tenant.CheckWrite(r.Context(), updater, user.ID, user.Role)
+		var current *models.User
+		if err == nil {
+			current, err = updater.GetUserByID(storage.WithPrimary(r.Context()), user.ID)
+		}
+		// правила username проверяются только при его смене: старые пользователи, чей
+		// username им не отвечает, могут править остальные поля
+		if err == nil && models.NormalizeUsername(req.Username) != models.NormalizeUsername(current.Username) {
+			if err := accounts.CheckUsername(req.Username); err != nil {
+				log.Info("username rejected", slog.String("username", req.Username), "error", err)
+				code := response.CodeInvalidUsername
+				if errors.Is(err, account.ErrReservedUsername) {
+					code = response.CodeReservedUsername
+				}
+				response.Fail(w, r, http.StatusUnprocessableEntity, code, err.Error())
+				return
+			}
+		}
+		if err == nil {
+			err = updater.UpdateUser(r.Context(), &user)
+		}
+		if errors.Is(err, tenant.ErrForbidden) {
+			log.Info("forbidden for this admin", "id", req.ID)
+			response.Fail(w, r, http.StatusForbidden, response.CodeForbidden, err.Error())
+			return
+		}
+		if errors.Is(err, storage.ErrVersionConflict) {
+			log.Info("user was modified concurrently", "id", req.ID, "version", version)
+			response.Fail(w, r, http.StatusPreconditionFailed, response.CodeVersionConflict, "user was modified by another request")
+			return
+		}
+		if errors.Is(err, storage.ErrUserNotFound) {
 			log.Info("user not found", "id", req.ID)
-			render.Status(r, http.StatusNotFound)
-			render.JSON(w, r, response.Error("user not found"))
+			response.Fail(w, r, http.StatusNotFound, response.CodeUserNotFound, "user not found")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
 			return
 		}
 		if err != nil {
 			log.Error("failed to update user", "error", err)
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.Error("failed to update user"))
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to update user")
 			return
 		}
 
 		log.Info("user updated successfully", "id", req.ID)
+		w.Header().Set("ETag", etag.Format(user.Version))
 		render.Status(r, http.StatusOK)
 		render.JSON(w, r, response.OK())
 	}
@@ -1,10 +1,13 @@
 package edit_test
 
 import (
+	"backend-app/internal/account"
 	"backend-app/internal/delivery/http/v1/edit"
+	"backend-app/internal/storage"
 	"backend-app/internal/storage/models"
 	"backend-app/pkg/api/response"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"log/slog"
@@ -16,28 +19,85 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/gorm"
 )
 
 type mockUpdater struct {
 	UpdateFn func(user *models.User) error
+	username string
 }
 
-func (m *mockUpdater) UpdateUser(user *models.User) error {
+func (m *mockUpdater) UpdateUser(ctx context.Context, user *models.User) error {
 	return m.UpdateFn(user)
 }
 
+func (m *mockUpdater) GetMembership(ctx context.Context, orgID uint, userID uint) (*models.Membership, error) {
+	return nil, storage.ErrMembershipNotFound
+}
+
+func (m *mockUpdater) ListUserMemberships(ctx context.Context, userID uint) ([]models.Membership, error) {
+	return nil, nil
+}
+
+func (m *mockUpdater) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	return &models.User{ID: id, Role: "user", Username: m.username}, nil
+}
+
 func TestUpdateUserHandler(t *testing.T) {
 	tests := []struct {
 		name           string
 		requestBody    interface{}
+		ifMatch        string
+		username       string
 		mockUpdateErr  error
 		expectedStatus int
 		expectedBody   string
 	}{
+		{
+			name: "missing If-Match",
+			requestBody: edit.Request{
+				ID:       1,
+				Username: "updated",
+				Password: "newpass",
+				Email:    "user@example.com",
+				Role:     "user",
+				Country:  "RU",
+			},
+			expectedStatus: http.StatusPreconditionRequired,
+			expectedBody:   "If-Match header is required",
+		},
+		{
+			name: "weak If-Match",
+			requestBody: edit.Request{
+				ID:       1,
+				Username: "updated",
+				Password: "newpass",
+				Email:    "user@example.com",
+				Role:     "user",
+				Country:  "RU",
+			},
+			ifMatch:        `W/"1"`,
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedBody:   "precondition failed",
+		},
+		{
+			name: "version conflict",
+			requestBody: edit.Request{
+				ID:       1,
+				Username: "updated",
+				Password: "newpass",
+				Email:    "user@example.com",
+				Role:     "user",
+				Country:  "RU",
+			},
+			ifMatch:        `"1"`,
+			mockUpdateErr:  storage.ErrVersionConflict,
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedBody:   "user was modified by another request",
+		},
 		{
 			name:           "invalid JSON",
 			requestBody:    "invalid json",
+			ifMatch:        `"1"`,
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   "invalid request body",
 		},
@@ -47,12 +107,37 @@ func TestUpdateUserHandler(t *testing.T) {
 				"id":    1,
 				"email": "", // required field is empty
 			},
+			ifMatch:        `"1"`,
 			expectedStatus: http.StatusUnprocessableEntity,
 			expectedBody:   "validation failed",
 		},
+		{
+			name: "email as username",
+			requestBody: edit.Request{
+				ID:       1,
+				Username: "bob@example.com",
+				Email:    "user@example.com",
+				Role:     "user",
+			},
+			ifMatch:        `"1"`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   account.ErrInvalidUsername.Error(),
+		},
+		{
+			name: "unchanged legacy username",
+			requestBody: edit.Request{
+				ID:       1,
+				Username: "Bob@example.com",
+				Email:    "user@example.com",
+				Role:     "user",
+			},
+			ifMatch:        `"1"`,
+			username:       "bob@example.com",
+			expectedStatus: http.StatusOK,
+		},
 		{
 			name: "user not found",
-			requestBody: models.User{
+			requestBody: edit.Request{
 				ID:       1,
 				Username: "updated",
 				Password: "newpass",
@@ -60,13 +145,14 @@ func TestUpdateUserHandler(t *testing.T) {
 				Role:     "user",
 				Country:  "RU",
 			},
-			mockUpdateErr:  gorm.ErrRecordNotFound,
+			ifMatch:        `"1"`,
+			mockUpdateErr:  storage.ErrUserNotFound,
 			expectedStatus: http.StatusNotFound,
 			expectedBody:   "user not found",
 		},
 		{
 			name: "internal error",
-			requestBody: models.User{
+			requestBody: edit.Request{
 				ID:       1,
 				Username: "updated",
 				Password: "newpass",
@@ -74,13 +160,14 @@ func TestUpdateUserHandler(t *testing.T) {
 				Role:     "user",
 				Country:  "RU",
 			},
+			ifMatch:        `"1"`,
 			mockUpdateErr:  errors.New("db failure"),
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   "failed to update user",
 		},
 		{
 			name: "success",
-			requestBody: models.User{
+			requestBody: edit.Request{
 				ID:       1,
 				Username: "updated",
 				Password: "newpass",
@@ -88,6 +175,7 @@ func TestUpdateUserHandler(t *testing.T) {
 				Role:     "user",
 				Country:  "RU",
 			},
+			ifMatch:        `"1"`,
 			expectedStatus: http.StatusOK,
 			expectedBody:   "", // should return OK response
 		},
@@ -108,13 +196,23 @@ func TestUpdateUserHandler(t *testing.T) {
 
 			req := httptest.NewRequest(http.MethodPut, "/users", bytes.NewBuffer(reqBody))
 			req.Header.Set("Content-Type", "application/json")
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
 			rr := httptest.NewRecorder()
 
 			handler := edit.New(slog.Default(), &mockUpdater{
 				UpdateFn: func(user *models.User) error {
-					return tt.mockUpdateErr
+					assert.Equal(t, uint(1), user.Version)
+					assert.NotEqual(t, "newpass", user.Password, "password must be hashed")
+					if tt.mockUpdateErr != nil {
+						return tt.mockUpdateErr
+					}
+					user.Version++
+					return nil
 				},
-			})
+				username: tt.username,
+			}, account.Policy{})
 
 			r := chi.NewRouter()
 			r.Use(middleware.RequestID)
@@ -126,16 +224,17 @@ func TestUpdateUserHandler(t *testing.T) {
 			assert.Equal(t, tt.expectedStatus, rr.Code)
 
 			if tt.expectedBody != "" {
-				var res response.Response
+				var res response.Problem
 				err := json.Unmarshal(rr.Body.Bytes(), &res)
 				assert.NoError(t, err)
-				assert.Equal(t, "Error", res.Status)
-				assert.Equal(t, tt.expectedBody, res.Error)
+				assert.Equal(t, response.ProblemContentType, rr.Header().Get("Content-Type"))
+				assert.Equal(t, tt.expectedBody, res.Detail)
 			} else {
 				var res response.Response
 				err := json.Unmarshal(rr.Body.Bytes(), &res)
 				assert.NoError(t, err)
 				assert.Equal(t, "OK", res.Status)
+				assert.Equal(t, `"2"`, rr.Header().Get("ETag"))
 			}
 		})
 	}
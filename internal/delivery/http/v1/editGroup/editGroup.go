@@ -0,0 +1,131 @@
+package editGroup
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/permissions"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"backend-app/pkg/api/validate"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Updater interface {
+	GetGroup(ctx context.Context, id uint) (*models.Group, error)
+	UpdateGroup(ctx context.Context, group *models.Group) error
+}
+
+// Request заменяет группу целиком.
+type Request struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=500"`
+	// ParentID - группа, права которой наследует эта, null - группа верхнего уровня
+	ParentID    *uint    `json:"parentId" validate:"omitempty,min=1"`
+	Roles       []string `json:"roles" validate:"unique,dive,oneof=user creator combined"`
+	Permissions []string `json:"permissions" validate:"unique"`
+}
+
+// New godoc
+// @Summary Update group
+// @Description Replaces name, description, parent, roles and permissions of a group. A parent that inherits from the group itself is rejected. Changes apply to tokens issued afterwards
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param input body editGroup.Request true "Group"
+// @Success 200 {object} dto.Group
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 409 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/groups/{id} [put]
+func New(log *slog.Logger, updater Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.UpdateGroup"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			log.Info("invalid group id", "param", idStr)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid group id")
+			return
+		}
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", "error", err)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidBody, "invalid request body")
+			return
+		}
+		if err := validate.New().Struct(req); err != nil {
+			log.Info("validation failed", "error", err)
+			response.WriteProblem(w, r, response.ValidationFailed(err))
+			return
+		}
+		for _, perm := range req.Permissions {
+			if err := permissions.Check(perm); err != nil {
+				log.Info("validation failed", "error", err)
+				response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeUnknownPermission, err.Error())
+				return
+			}
+		}
+
+		group, err := updater.GetGroup(r.Context(), uint(id))
+		if err == nil {
+			group.Name = req.Name
+			group.Description = req.Description
+			group.ParentID = req.ParentID
+			group.SetRoles(req.Roles)
+			group.SetPermissions(req.Permissions)
+			err = updater.UpdateGroup(r.Context(), group)
+		}
+		if errors.Is(err, storage.ErrGroupNotFound) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeGroupNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, storage.ErrGroupExists) {
+			response.Fail(w, r, http.StatusConflict, response.CodeGroupExists, err.Error())
+			return
+		}
+		if errors.Is(err, storage.ErrGroupParentNotFound) {
+			log.Info("invalid parent group", "error", err)
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeGroupParentNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, storage.ErrGroupCycle) {
+			log.Info("invalid parent group", "error", err)
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeGroupCycle, err.Error())
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to update group", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to update group")
+			return
+		}
+
+		log.Info("group updated", "id", id)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, dto.NewGroup(group))
+	}
+}
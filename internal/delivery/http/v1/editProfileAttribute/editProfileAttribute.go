@@ -0,0 +1,114 @@
+package editProfileAttribute
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/profile"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"backend-app/pkg/api/validate"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Updater interface {
+	UpdateAttributeDefinition(ctx context.Context, def *models.AttributeDefinition) error
+}
+
+// Request заменяет описание атрибута целиком, ключ не меняется.
+type Request struct {
+	Type         string   `json:"type" validate:"required,oneof=string number integer boolean enum"`
+	Description  string   `json:"description" validate:"max=500"`
+	Required     bool     `json:"required"`
+	UserEditable bool     `json:"userEditable"`
+	Enum         []string `json:"enum" validate:"max=100,unique,dive,required,max=100,excludesall=0x2C"`
+	MinLength    *int     `json:"minLength" validate:"omitempty,min=0"`
+	MaxLength    *int     `json:"maxLength" validate:"omitempty,min=1"`
+	Minimum      *float64 `json:"minimum"`
+	Maximum      *float64 `json:"maximum"`
+	Pattern      string   `json:"pattern" validate:"max=500"`
+}
+
+// New godoc
+// @Summary Update profile attribute
+// @Description Replaces type and constraints of a profile attribute. Stored values are not checked again: a value that no longer fits has to be changed with the next update of the attribute
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param key path string true "Attribute key"
+// @Param input body editProfileAttribute.Request true "Attribute"
+// @Success 200 {object} dto.AttributeDefinition
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/profile/attributes/{key} [put]
+func New(log *slog.Logger, updater Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.UpdateProfileAttribute"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", "error", err)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidBody, "invalid request body")
+			return
+		}
+		if err := validate.New().Struct(req); err != nil {
+			log.Info("validation failed", "error", err)
+			response.WriteProblem(w, r, response.ValidationFailed(err))
+			return
+		}
+
+		def := &models.AttributeDefinition{
+			Key:          chi.URLParam(r, "key"),
+			Type:         req.Type,
+			Description:  req.Description,
+			Required:     req.Required,
+			UserEditable: req.UserEditable,
+			MinLength:    req.MinLength,
+			MaxLength:    req.MaxLength,
+			Minimum:      req.Minimum,
+			Maximum:      req.Maximum,
+			Pattern:      req.Pattern,
+		}
+		def.SetEnum(req.Enum)
+		if err := profile.CheckDefinition(def); err != nil {
+			log.Info("validation failed", "error", err)
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidAttributeDefinition, err.Error())
+			return
+		}
+
+		err := updater.UpdateAttributeDefinition(r.Context(), def)
+		if errors.Is(err, storage.ErrAttributeNotFound) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeAttributeNotFound, err.Error())
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to update attribute", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to update attribute")
+			return
+		}
+
+		log.Info("profile attribute updated", "key", def.Key)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, dto.NewAttributeDefinition(def))
+	}
+}
@@ -0,0 +1,118 @@
+package editWebhook
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/internal/webhooks"
+	"backend-app/pkg/api/response"
+	"backend-app/pkg/api/validate"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Updater interface {
+	GetWebhook(ctx context.Context, id uint) (*models.Webhook, error)
+	UpdateWebhook(ctx context.Context, hook *models.Webhook) error
+}
+
+// Request заменяет настройки webhook целиком.
+type Request struct {
+	URL    string   `json:"url" validate:"required,http_url"`
+	Events []string `json:"events" validate:"required,min=1,unique"`
+	Active bool     `json:"active"`
+	// RotateSecret генерирует новый секрет, он вернётся в ответе
+	RotateSecret bool `json:"rotateSecret"`
+}
+
+// New godoc
+// @Summary Update webhook
+// @Description Replaces webhook URL, subscribed events and active flag. With rotateSecret a new secret is generated and returned once; otherwise the response has no secret
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param input body editWebhook.Request true "Webhook"
+// @Success 200 {object} dto.WebhookWithSecret
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/webhooks/{id} [put]
+func New(log *slog.Logger, updater Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.UpdateWebhook"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			log.Info("invalid webhook id", "param", idStr)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid webhook id")
+			return
+		}
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", "error", err)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidBody, "invalid request body")
+			return
+		}
+		if err := validate.New().Struct(req); err != nil {
+			log.Info("validation failed", "error", err)
+			response.WriteProblem(w, r, response.ValidationFailed(err))
+			return
+		}
+		if err := webhooks.CheckEventTypes(req.Events); err != nil {
+			log.Info("validation failed", "error", err)
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeUnknownEventType, err.Error())
+			return
+		}
+
+		hook, err := updater.GetWebhook(r.Context(), uint(id))
+		if err == nil {
+			hook.URL = req.URL
+			hook.Active = req.Active
+			hook.SetEventTypes(req.Events)
+			if req.RotateSecret {
+				hook.Secret = webhooks.NewSecret()
+			}
+			err = updater.UpdateWebhook(r.Context(), hook)
+		}
+		if errors.Is(err, storage.ErrWebhookNotFound) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeWebhookNotFound, "webhook not found")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to update webhook", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to update webhook")
+			return
+		}
+
+		log.Info("webhook updated", "id", id, "secret_rotated", req.RotateSecret)
+		resp := dto.WebhookWithSecret{Webhook: dto.NewWebhook(hook)}
+		if req.RotateSecret {
+			resp.Secret = hook.Secret
+		}
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, resp)
+	}
+}
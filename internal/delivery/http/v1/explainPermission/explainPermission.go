@@ -0,0 +1,99 @@
+package explainPermission
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/delivery/http/tenant"
+	"backend-app/internal/permissions"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Getter interface {
+	GetUserByID(ctx context.Context, id uint) (*models.User, error)
+	tenant.Members
+	permissions.Groups
+}
+
+// New godoc
+// @Summary Explain user permission
+// @Description Tells whether a user has the permission and why: every role and group chain that grants it. granted is false and grants is empty when nobody grants it
+// @Tags groups
+// @Produce json
+// @Param id path int true "User ID"
+// @Param permission path string true "Permission, e.g. content:create"
+// @Success 200 {object} dto.PermissionExplanation
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/user/{id}/permissions/{permission} [get]
+func New(log *slog.Logger, getter Getter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.ExplainPermission"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			log.Info("invalid user id", "param", idStr)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid user id")
+			return
+		}
+		perm := chi.URLParam(r, "permission")
+
+		var (
+			user   *models.User
+			role   string
+			grants []permissions.Grant
+		)
+		err = tenant.Check(r.Context(), getter, uint(id))
+		if err == nil {
+			user, err = getter.GetUserByID(r.Context(), uint(id))
+		}
+		if err == nil {
+			role, err = tenant.TokenRole(r.Context(), getter, user, authMiddleware.Tenant(r.Context()))
+		}
+		if err == nil {
+			grants, err = permissions.Effective(r.Context(), getter, user.ID, role)
+		}
+		if errors.Is(err, storage.ErrUserNotFound) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeUserNotFound, "user not found")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to get permissions", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to get permissions")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		grants = permissions.Explain(grants, perm)
+		render.JSON(w, r, dto.PermissionExplanation{
+			UserID:     user.ID,
+			Permission: perm,
+			Granted:    len(grants) > 0,
+			Grants:     dto.NewGrants(grants),
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package explainPermission_test
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/delivery/http/v1/explainPermission"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockStore struct {
+	groups []models.Group
+}
+
+func (m *mockStore) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	if id != 1 {
+		return nil, storage.ErrUserNotFound
+	}
+	return &models.User{ID: 1, Role: "user"}, nil
+}
+
+func (m *mockStore) GetMembership(ctx context.Context, orgID uint, userID uint) (*models.Membership, error) {
+	return nil, storage.ErrMembershipNotFound
+}
+
+func (m *mockStore) ListGroups(ctx context.Context) ([]models.Group, error) {
+	return m.groups, nil
+}
+
+func (m *mockStore) ListUserGroups(ctx context.Context, userID uint) ([]models.Group, error) {
+	return m.groups[1:], nil
+}
+
+func TestExplainPermissionHandler(t *testing.T) {
+	parent := uint(1)
+	store := &mockStore{groups: []models.Group{
+		{ID: 1, Name: "staff", Permissions: "dishes:read"},
+		{ID: 2, Name: "editors", ParentID: &parent, Roles: "creator"},
+	}}
+	router := chi.NewRouter()
+	router.Use(middleware.RequestID)
+	router.Get("/user/{id}/permissions/{permission}", explainPermission.New(slog.Default(), store))
+
+	explain := func(path string) (int, dto.PermissionExplanation) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		var res dto.PermissionExplanation
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+		}
+		return rr.Code, res
+	}
+
+	status, res := explain("/user/1/permissions/dishes:read")
+	require.Equal(t, http.StatusOK, status)
+	assert.True(t, res.Granted)
+	require.Len(t, res.Grants, 1)
+	assert.Empty(t, res.Grants[0].Role)
+	assert.Equal(t, []dto.GroupRef{{ID: 2, Name: "editors"}, {ID: 1, Name: "staff"}}, res.Grants[0].Groups)
+
+	status, res = explain("/user/1/permissions/profile:read")
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, res.Grants, 2)
+	assert.Equal(t, "user", res.Grants[0].Role)
+	assert.Empty(t, res.Grants[0].Groups, "the user's own role")
+	assert.Equal(t, "creator", res.Grants[1].Role)
+
+	status, res = explain("/user/1/permissions/users:delete")
+	require.Equal(t, http.StatusOK, status)
+	assert.False(t, res.Granted)
+	assert.Empty(t, res.Grants)
+
+	status, _ = explain("/user/2/permissions/dishes:read")
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _ = explain("/user/abc/permissions/dishes:read")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
@@ -0,0 +1,159 @@
+package exportUsers
+
+import (
+	"backend-app/internal/bulk"
+	"backend-app/internal/config"
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/storage"
+	"backend-app/pkg/api/response"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// New godoc
+// @Summary Export users
+// @Description Streams all users that match the filters as CSV with a header row or as NDJSON. Password hashes and tokens are never exported. Organization admins export only members of their organization. If the export fails midway the connection is closed without finishing the response
+// @Tags users
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "Output format" Enums(csv, ndjson) default(csv)
+// @Param fields query string false "Comma-separated fields: id, username, email, role, country, status, verified, version, createdAt, updatedAt; all by default"
+// @Param role query string false "Filter by role"
+// @Param country query string false "Filter by country"
+// @Param status query string false "Filter by status" Enums(active, suspended)
+// @Param verified query bool false "Filter by verification"
+// @Param created_from query string false "Created at or after, RFC3339"
+// @Param created_to query string false "Created before, RFC3339"
+// @Success 200 {string} string
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/users/export [get]
+func New(log *slog.Logger, lister bulk.Lister, cfg config.Bulk) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.ExportUsers"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		opts, err := parseOptions(r.URL.Query())
+		if err != nil {
+			log.Info("invalid query parameter", "error", err)
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, err.Error())
+			return
+		}
+		opts.Filter.OrgID = authMiddleware.Tenant(r.Context())
+
+		// выгрузка всех пользователей не укладывается в таймаут записи http_server
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(cfg.RequestTimeout))
+
+		contentType := "text/csv; charset=utf-8"
+		if opts.Format == bulk.FormatNDJSON {
+			contentType = "application/x-ndjson"
+		}
+		out := &lazyWriter{w: w, start: func() {
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("Content-Disposition", `attachment; filename="users.`+opts.Format+`"`)
+			w.WriteHeader(http.StatusOK)
+		}}
+
+		n, err := bulk.Export(r.Context(), out, lister, opts)
+		if err != nil && out.started {
+			// статус уже отправлен, оборванное соединение не даст принять часть файла за весь
+			log.Error("export interrupted", "error", err, "exported", n)
+			panic(http.ErrAbortHandler)
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to export users", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to export users")
+			return
+		}
+		if !out.started {
+			out.start()
+		}
+
+		log.Info("users exported", "count", n, "format", opts.Format)
+	}
+}
+
+// lazyWriter откладывает заголовки ответа до первой записи, чтобы ошибку первой
+// страницы ещё можно было вернуть обычным JSON.
+type lazyWriter struct {
+	w       http.ResponseWriter
+	start   func()
+	started bool
+}
+
+func (l *lazyWriter) Write(p []byte) (int, error) {
+	if !l.started {
+		l.started = true
+		l.start()
+	}
+	return l.w.Write(p)
+}
+
+func (l *lazyWriter) Flush() {
+	if l.started {
+		_ = http.NewResponseController(l.w).Flush()
+	}
+}
+
+func parseOptions(values url.Values) (bulk.ExportOptions, error) {
+	opts := bulk.ExportOptions{
+		Format: values.Get("format"),
+		Filter: storage.UserFilter{
+			Role:    values.Get("role"),
+			Country: values.Get("country"),
+			Status:  values.Get("status"),
+		},
+	}
+	if opts.Format == "" {
+		opts.Format = bulk.FormatCSV
+	}
+	if opts.Format != bulk.FormatCSV && opts.Format != bulk.FormatNDJSON {
+		return opts, bulk.ErrUnknownFormat
+	}
+
+	fields, err := bulk.ParseFields(values.Get("fields"))
+	if err != nil {
+		return opts, err
+	}
+	opts.Fields = fields
+
+	if v := values.Get("verified"); v != "" {
+		verified, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, errors.New("verified must be a boolean")
+		}
+		opts.Filter.Verified = &verified
+	}
+
+	for param, dst := range map[string]*time.Time{
+		"created_from": &opts.Filter.CreatedFrom,
+		"created_to":   &opts.Filter.CreatedTo,
+	} {
+		if v := values.Get(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return opts, fmt.Errorf("%s must be an RFC3339 timestamp", param)
+			}
+			*dst = t
+		}
+	}
+	return opts, nil
+}
@@ -0,0 +1,120 @@
+package exportUsers_test
+
+import (
+	"backend-app/internal/config"
+	"backend-app/internal/delivery/http/v1/exportUsers"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/memory"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var cfg = config.Bulk{RequestTimeout: time.Minute}
+
+type failingLister struct{}
+
+func (failingLister) ListUsers(ctx context.Context, q storage.UserQuery) (storage.UserPage, error) {
+	return storage.UserPage{}, errors.New("db error")
+}
+
+func TestExportUsersHandler(t *testing.T) {
+	store := memory.New()
+	for _, u := range []models.User{
+		{Username: "alice", Email: "alice@example.com", Password: "x", Role: "admin", Country: "Norway"},
+		{Username: "bob", Email: "bob@example.com", Password: "x", Role: "user", Country: "Chile"},
+	} {
+		require.NoError(t, store.CreateUser(t.Context(), &u))
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		failing        bool
+		expectedStatus int
+		expectedType   string
+		expectedBody   string
+		expectedError  string
+	}{
+		{
+			name:           "csv",
+			query:          "?fields=username,role",
+			expectedStatus: http.StatusOK,
+			expectedType:   "text/csv; charset=utf-8",
+			expectedBody:   "username,role\nalice,admin\nbob,user\n",
+		},
+		{
+			name:           "ndjson_filtered",
+			query:          "?format=ndjson&fields=id,country&role=user",
+			expectedStatus: http.StatusOK,
+			expectedType:   "application/x-ndjson",
+			expectedBody:   `{"id":2,"country":"Chile"}` + "\n",
+		},
+		{
+			name:           "nothing_found",
+			query:          "?fields=id&country=Peru",
+			expectedStatus: http.StatusOK,
+			expectedType:   "text/csv; charset=utf-8",
+			expectedBody:   "id\n",
+		},
+		{
+			name:           "unknown_field",
+			query:          "?fields=id,password",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  `unknown export field "password"`,
+		},
+		{
+			name:           "unknown_format",
+			query:          "?format=xml",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "unknown format, expected csv or ndjson",
+		},
+		{
+			name:           "storage_error",
+			failing:        true,
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "failed to export users",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lister interface {
+				ListUsers(ctx context.Context, q storage.UserQuery) (storage.UserPage, error)
+			} = store
+			if tt.failing {
+				lister = failingLister{}
+			}
+			router := chi.NewRouter()
+			router.Use(middleware.RequestID)
+			router.Get("/users/export", exportUsers.New(slog.Default(), lister, cfg))
+
+			req := httptest.NewRequest(http.MethodGet, "/users/export"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedError != "" {
+				var res response.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				assert.Equal(t, tt.expectedError, res.Detail)
+				return
+			}
+			assert.Equal(t, tt.expectedType, rr.Header().Get("Content-Type"))
+			assert.Contains(t, rr.Header().Get("Content-Disposition"), "attachment")
+			assert.Equal(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
@@ -1,33 +1,53 @@
 package getAllUsers
 
 import (
-	"backend-app/internal/storage/models"
+	"backend-app/internal/country"
+	"backend-app/internal/delivery/http/dto"
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/storage"
 	"backend-app/pkg/api/response"
 	"backend-app/pkg/sl"
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 )
 
-type Getter interface {
-	GetAllUsers(offset int, limit int) ([]models.User, error)
+type Lister interface {
+	ListUsers(ctx context.Context, q storage.UserQuery) (storage.UserPage, error)
 }
 
 // New godoc
 // @Summary Get all users
-// @Description Returns a list of all users with pagination
+// @Description Returns a page of users. Pages are linked with opaque cursors from nextCursor/prevCursor. Organization admins see only members of their organization
 // @Tags users
 // @Produce json
-// @Param limit query int true "Limit"
-// @Param offset query int true "Offset"
-// @Success 200 {array} models.User
-// @Failure 422 {object} response.Response
-// @Failure 500 {object} response.Response
+// @Param Accept-Language header string false "Language of country names, English by default"
+// @Param limit query int false "Page size, 1-100" default(20)
+// @Param cursor query string false "Cursor from a previous page"
+// @Param sort query string false "Sort field: id, username, email, country, created_at; prefix with - for descending. Email and country are not sortable when they are encrypted" default(id)
+// @Param role query string false "Filter by role"
+// @Param country query string false "Filter by country"
+// @Param status query string false "Filter by status" Enums(active, suspended)
+// @Param verified query bool false "Filter by verification"
+// @Param created_from query string false "Created at or after, RFC3339"
+// @Param created_to query string false "Created before, RFC3339"
+// @Param total query bool false "Include total count"
+// @Success 200 {object} response.Page{items=[]dto.User}
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
 // @Router /v1/user/all [get]
-func New(log *slog.Logger, getter Getter) http.HandlerFunc {
+func New(log *slog.Logger, lister Lister) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.v1.GetAllUsers"
 
@@ -36,30 +56,109 @@ func New(log *slog.Logger, getter Getter) http.HandlerFunc {
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
-		limit := r.URL.Query().Get("limit")
-		offset := r.URL.Query().Get("offset")
-		offsetInt, err := strconv.Atoi(offset)
+		q, err := parseQuery(r.URL.Query())
 		if err != nil {
 			log.Error("invalid query parameter", sl.Error(err))
-			render.Status(r, http.StatusUnprocessableEntity)
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, err.Error())
 			return
 		}
-		limitInt, err := strconv.Atoi(limit)
-		if err != nil {
-			log.Error("invalid query parameter", sl.Error(err))
-			render.Status(r, http.StatusUnprocessableEntity)
+		q.Filter.OrgID = authMiddleware.Tenant(r.Context())
+
+		page, err := lister.ListUsers(r.Context(), q)
+		if errors.Is(err, storage.ErrInvalidCursor) {
+			log.Error("invalid cursor", sl.Error(err))
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, "invalid cursor")
+			return
+		}
+		if errors.Is(err, storage.ErrUnsortableField) {
+			log.Info("unsortable field", sl.Error(err))
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, err.Error())
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
 			return
 		}
-		users, err := getter.GetAllUsers(offsetInt, limitInt)
 		if err != nil {
 			log.Error("failed to get users", "error", err)
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.Error("failed to get users"))
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to get users")
 			return
 		}
 
-		log.Info("users retrieved successfully", "count", len(users))
+		log.Info("users retrieved successfully", "count", len(page.Users))
 		render.Status(r, http.StatusOK)
-		render.JSON(w, r, users)
+		render.JSON(w, r, response.Page{
+			Items:      dto.LocalizeUsers(dto.NewUsers(page.Users), country.Language(r.Header.Get("Accept-Language"))),
+			NextCursor: page.NextCursor,
+			PrevCursor: page.PrevCursor,
+			Total:      page.Total,
+		})
+	}
+}
+
+func parseQuery(values url.Values) (storage.UserQuery, error) {
+	q := storage.UserQuery{
+		Limit: storage.DefaultPageSize,
+		Filter: storage.UserFilter{
+			Role:    values.Get("role"),
+			Country: values.Get("country"),
+			Status:  values.Get("status"),
+		},
+	}
+
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > storage.MaxPageSize {
+			return q, fmt.Errorf("limit must be between 1 and %d", storage.MaxPageSize)
+		}
+		q.Limit = limit
+	}
+
+	if v := values.Get("sort"); v != "" {
+		q.SortDesc = strings.HasPrefix(v, "-")
+		q.SortField = strings.TrimPrefix(v, "-")
+		if !storage.UserSortFields[q.SortField] {
+			return q, fmt.Errorf("unsupported sort field: %s", q.SortField)
+		}
 	}
+
+	if v := values.Get("cursor"); v != "" {
+		cursor, err := storage.DecodeCursor(v)
+		if err != nil {
+			return q, err
+		}
+		q.Cursor = cursor
+	}
+
+	if v := values.Get("verified"); v != "" {
+		verified, err := strconv.ParseBool(v)
+		if err != nil {
+			return q, errors.New("verified must be a boolean")
+		}
+		q.Filter.Verified = &verified
+	}
+
+	for param, dst := range map[string]*time.Time{
+		"created_from": &q.Filter.CreatedFrom,
+		"created_to":   &q.Filter.CreatedTo,
+	} {
+		if v := values.Get(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return q, fmt.Errorf("%s must be an RFC3339 timestamp", param)
+			}
+			*dst = t
+		}
+	}
+
+	if v := values.Get("total"); v != "" {
+		total, err := strconv.ParseBool(v)
+		if err != nil {
+			return q, errors.New("total must be a boolean")
+		}
+		q.WithTotal = total
+	}
+
+	return q, nil
 }
@@ -1,14 +1,19 @@
 package getAllUsers_test
 
 import (
+	"backend-app/internal/delivery/http/dto"
 	"backend-app/internal/delivery/http/v1/getAllUsers"
+	"backend-app/internal/storage"
 	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
 	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -16,21 +21,26 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-type mockGetter struct {
-	GetAllUsersFn func() ([]models.User, error)
+type mockLister struct {
+	ListUsersFn func(q storage.UserQuery) (storage.UserPage, error)
 }
 
-func (m *mockGetter) GetAllUsers() ([]models.User, error) {
-	return m.GetAllUsersFn()
+func (m *mockLister) ListUsers(ctx context.Context, q storage.UserQuery) (storage.UserPage, error) {
+	return m.ListUsersFn(q)
 }
 
 func TestGetAllUsersHandler(t *testing.T) {
+	total := int64(2)
+	cursor := storage.Cursor{SortField: "username", SortDesc: true, Value: "bob", ID: 2}.Encode()
+
 	tests := []struct {
 		name           string
-		mockReturn     []models.User
+		query          string
+		mockReturn     storage.UserPage
 		mockError      error
 		expectedStatus int
 		expectError    bool
+		checkQuery     func(t *testing.T, q storage.UserQuery)
 	}{
 		{
 			name:           "internal_error",
@@ -39,26 +49,95 @@ func TestGetAllUsersHandler(t *testing.T) {
 			expectError:    true,
 		},
 		{
-			name: "success",
-			mockReturn: []models.User{
-				{ID: 1, Email: "test1@example.com"},
-				{ID: 2, Email: "test2@example.com"},
+			name:           "invalid_limit",
+			query:          "?limit=1000",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectError:    true,
+		},
+		{
+			name:           "invalid_sort",
+			query:          "?sort=password",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectError:    true,
+		},
+		{
+			name:           "invalid_cursor",
+			query:          "?cursor=garbage",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectError:    true,
+		},
+		{
+			name:           "cursor_rejected_by_storage",
+			query:          "?cursor=" + cursor,
+			mockError:      storage.ErrInvalidCursor,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectError:    true,
+		},
+		{
+			name:           "invalid_created_from",
+			query:          "?created_from=yesterday",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectError:    true,
+		},
+		{
+			name:  "defaults",
+			query: "",
+			mockReturn: storage.UserPage{
+				Users: []models.User{{ID: 1, Email: "test1@example.com"}},
 			},
 			expectedStatus: http.StatusOK,
-			expectError:    false,
+			checkQuery: func(t *testing.T, q storage.UserQuery) {
+				assert.Equal(t, storage.DefaultPageSize, q.Limit)
+				assert.Empty(t, q.SortField)
+				assert.Nil(t, q.Cursor)
+				assert.False(t, q.WithTotal)
+			},
+		},
+		{
+			name:  "filters_and_sort",
+			query: "?limit=2&sort=-username&role=user&country=RU&status=active&verified=true&created_from=2025-01-01T00:00:00Z&total=true&cursor=" + cursor,
+			mockReturn: storage.UserPage{
+				Users: []models.User{
+					{ID: 1, Email: "test1@example.com"},
+					{ID: 2, Email: "test2@example.com"},
+				},
+				NextCursor: "next",
+				PrevCursor: "prev",
+				Total:      &total,
+			},
+			expectedStatus: http.StatusOK,
+			checkQuery: func(t *testing.T, q storage.UserQuery) {
+				assert.Equal(t, 2, q.Limit)
+				assert.Equal(t, "username", q.SortField)
+				assert.True(t, q.SortDesc)
+				assert.Equal(t, "user", q.Filter.Role)
+				assert.Equal(t, "RU", q.Filter.Country)
+				assert.Equal(t, "active", q.Filter.Status)
+				if assert.NotNil(t, q.Filter.Verified) {
+					assert.True(t, *q.Filter.Verified)
+				}
+				assert.True(t, q.Filter.CreatedFrom.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+				assert.True(t, q.WithTotal)
+				if assert.NotNil(t, q.Cursor) {
+					assert.Equal(t, uint(2), q.Cursor.ID)
+				}
+			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			req := httptest.NewRequest(http.MethodGet, "/users"+tt.query, nil)
 			rr := httptest.NewRecorder()
 
 			router := chi.NewRouter()
 			router.Use(middleware.RequestID)
 			router.Use(render.SetContentType(render.ContentTypeJSON))
-			router.Get("/users", getAllUsers.New(slog.Default(), &mockGetter{
-				GetAllUsersFn: func() ([]models.User, error) { //FIXME: попозже пофикшу здесь надо добавить оффсет и лимит
+			router.Get("/users", getAllUsers.New(slog.Default(), &mockLister{
+				ListUsersFn: func(q storage.UserQuery) (storage.UserPage, error) {
+					if tt.checkQuery != nil {
+						tt.checkQuery(t, q)
+					}
 					return tt.mockReturn, tt.mockError
 				},
 			}))
@@ -68,19 +147,26 @@ func TestGetAllUsersHandler(t *testing.T) {
 			assert.Equal(t, tt.expectedStatus, rr.Code)
 
 			if tt.expectError {
-				var res struct {
-					Status string `json:"status"`
-					Error  string `json:"error"`
-				}
+				var res response.Problem
 				err := json.Unmarshal(rr.Body.Bytes(), &res)
 				assert.NoError(t, err)
-				assert.Equal(t, "Error", res.Status)
-				assert.NotEmpty(t, res.Error)
+				assert.Equal(t, response.ProblemContentType, rr.Header().Get("Content-Type"))
+				assert.Equal(t, tt.expectedStatus, res.Status)
+				assert.NotEmpty(t, res.Code)
+				assert.NotEmpty(t, res.Detail)
 			} else {
-				var users []models.User
-				err := json.Unmarshal(rr.Body.Bytes(), &users)
+				var page struct {
+					Items      []dto.User `json:"items"`
+					NextCursor string     `json:"nextCursor"`
+					PrevCursor string     `json:"prevCursor"`
+					Total      *int64     `json:"total"`
+				}
+				err := json.Unmarshal(rr.Body.Bytes(), &page)
 				assert.NoError(t, err)
-				assert.Len(t, users, len(tt.mockReturn))
+				assert.Len(t, page.Items, len(tt.mockReturn.Users))
+				assert.Equal(t, tt.mockReturn.NextCursor, page.NextCursor)
+				assert.Equal(t, tt.mockReturn.PrevCursor, page.PrevCursor)
+				assert.Equal(t, tt.mockReturn.Total, page.Total)
 			}
 		})
 	}
@@ -0,0 +1,55 @@
+package getDataExports
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Lister interface {
+	ListDataExports(ctx context.Context, userID uint) ([]models.DataExport, error)
+}
+
+// New godoc
+// @Summary Get data exports
+// @Description Returns the current user's data exports, newest first
+// @Tags privacy
+// @Produce json
+// @Success 200 {array} dto.DataExport
+// @Failure 401 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/me/data-exports [get]
+func New(log *slog.Logger, lister Lister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.GetDataExports"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		exports, err := lister.ListDataExports(r.Context(), authMiddleware.UserID(r.Context()))
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to list data exports", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to get data exports")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, dto.NewDataExports(exports))
+	}
+}
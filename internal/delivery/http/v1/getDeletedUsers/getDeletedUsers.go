@@ -0,0 +1,95 @@
+package getDeletedUsers
+
+import (
+	"backend-app/internal/country"
+	"backend-app/internal/delivery/http/dto"
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"backend-app/pkg/sl"
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Getter interface {
+	GetDeletedUsers(ctx context.Context, orgID uint, offset int, limit int) ([]models.User, error)
+}
+
+// DeletedUser - пользователь вместе с информацией об удалении.
+type DeletedUser struct {
+	dto.User
+	DeletedAt    time.Time `json:"deletedAt"`
+	DeletedBy    *uint     `json:"deletedBy,omitempty"`
+	DeleteReason string    `json:"deleteReason,omitempty"`
+}
+
+// New godoc
+// @Summary Get deleted users
+// @Description Returns soft-deleted users, most recently deleted first. Organization admins see only members of their organization
+// @Tags users
+// @Produce json
+// @Param Accept-Language header string false "Language of country names, English by default"
+// @Param limit query int true "Limit"
+// @Param offset query int true "Offset"
+// @Success 200 {array} getDeletedUsers.DeletedUser
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/user/deleted [get]
+func New(log *slog.Logger, getter Getter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.GetDeletedUsers"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+		if err != nil {
+			log.Error("invalid query parameter", sl.Error(err))
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, "invalid offset")
+			return
+		}
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil {
+			log.Error("invalid query parameter", sl.Error(err))
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, "invalid limit")
+			return
+		}
+
+		users, err := getter.GetDeletedUsers(r.Context(), authMiddleware.Tenant(r.Context()), offset, limit)
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to get deleted users", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to get deleted users")
+			return
+		}
+
+		resp := make([]DeletedUser, 0, len(users))
+		lang := country.Language(r.Header.Get("Accept-Language"))
+		for _, user := range users {
+			resp = append(resp, DeletedUser{
+				User:         dto.NewUser(&user).Localize(lang),
+				DeletedAt:    user.DeletedAt.Time,
+				DeletedBy:    user.DeletedBy,
+				DeleteReason: user.DeleteReason,
+			})
+		}
+
+		log.Info("deleted users retrieved successfully", "count", len(resp))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, resp)
+	}
+}
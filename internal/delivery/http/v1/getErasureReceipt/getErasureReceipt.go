@@ -0,0 +1,79 @@
+package getErasureReceipt
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Getter interface {
+	GetErasureRequest(ctx context.Context, id uint) (*models.ErasureRequest, error)
+}
+
+// New godoc
+// @Summary Get erasure receipt
+// @Description Returns the completion receipt of an approved erasure request
+// @Tags privacy
+// @Produce json
+// @Param id path int true "Erasure request ID"
+// @Success 200 {object} dto.ErasureReceipt
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 409 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/erasure-requests/{id}/receipt [get]
+func New(log *slog.Logger, getter Getter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.GetErasureReceipt"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			log.Info("invalid erasure request id", "param", idStr)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid erasure request id")
+			return
+		}
+
+		erasure, err := getter.GetErasureRequest(r.Context(), uint(id))
+		if errors.Is(err, storage.ErrErasureRequestNotFound) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeErasureNotFound, "erasure request not found")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to get erasure request", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to get erasure request")
+			return
+		}
+
+		receipt, ok := dto.NewErasureReceipt(erasure)
+		if !ok {
+			response.Fail(w, r, http.StatusConflict, response.CodeErasureNotCompleted, "erasure request is "+erasure.Status)
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, receipt)
+	}
+}
@@ -0,0 +1,87 @@
+package getErasureRequests
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 100
+)
+
+type Lister interface {
+	ListErasureRequests(ctx context.Context, status string, offset int, limit int) ([]models.ErasureRequest, error)
+}
+
+// New godoc
+// @Summary Get erasure requests
+// @Description Returns users' erasure requests, newest first
+// @Tags privacy
+// @Produce json
+// @Param status query string false "Request status" Enums(pending, rejected, completed)
+// @Param limit query int false "Limit, 1-100, default 50"
+// @Param offset query int false "Offset"
+// @Success 200 {array} dto.ErasureRequest
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/erasure-requests [get]
+func New(log *slog.Logger, lister Lister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.GetErasureRequests"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		query := r.URL.Query()
+		status := query.Get("status")
+		switch status {
+		case "", models.ErasurePending, models.ErasureRejected, models.ErasureCompleted:
+		default:
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, "invalid status")
+			return
+		}
+		var err error
+		limit := DefaultLimit
+		if v := query.Get("limit"); v != "" {
+			if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > MaxLimit {
+				response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, "invalid limit")
+				return
+			}
+		}
+		offset := 0
+		if v := query.Get("offset"); v != "" {
+			if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+				response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, "invalid offset")
+				return
+			}
+		}
+
+		reqs, err := lister.ListErasureRequests(r.Context(), status, offset, limit)
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to list erasure requests", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to list erasure requests")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, dto.NewErasureRequests(reqs))
+	}
+}
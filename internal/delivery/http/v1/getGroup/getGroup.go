@@ -0,0 +1,72 @@
+package getGroup
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Getter interface {
+	GetGroup(ctx context.Context, id uint) (*models.Group, error)
+}
+
+// New godoc
+// @Summary Get group
+// @Description Returns a group with its roles and permissions
+// @Tags groups
+// @Produce json
+// @Param id path int true "Group ID"
+// @Success 200 {object} dto.Group
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/groups/{id} [get]
+func New(log *slog.Logger, getter Getter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.GetGroup"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			log.Info("invalid group id", "param", idStr)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid group id")
+			return
+		}
+
+		group, err := getter.GetGroup(r.Context(), uint(id))
+		if errors.Is(err, storage.ErrGroupNotFound) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeGroupNotFound, err.Error())
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to get group", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to get group")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, dto.NewGroup(group))
+	}
+}
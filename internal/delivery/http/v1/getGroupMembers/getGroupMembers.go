@@ -0,0 +1,87 @@
+package getGroupMembers
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Lister interface {
+	ListGroupMembers(ctx context.Context, groupID uint, offset int, limit int) ([]models.GroupMember, error)
+}
+
+// New godoc
+// @Summary List group members
+// @Description Returns users added to the group directly, ordered by user ID. Members of child groups are not listed
+// @Tags groups
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param limit query int true "Limit"
+// @Param offset query int true "Offset"
+// @Success 200 {array} dto.GroupMember
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/groups/{id}/members [get]
+func New(log *slog.Logger, lister Lister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.GetGroupMembers"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			log.Info("invalid group id", "param", idStr)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid group id")
+			return
+		}
+		offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+		if err != nil || offset < 0 {
+			log.Info("invalid offset", "offset", r.URL.Query().Get("offset"))
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, "invalid offset")
+			return
+		}
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil || limit <= 0 {
+			log.Info("invalid limit", "limit", r.URL.Query().Get("limit"))
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, "invalid limit")
+			return
+		}
+
+		members, err := lister.ListGroupMembers(r.Context(), uint(id), offset, limit)
+		if errors.Is(err, storage.ErrGroupNotFound) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeGroupNotFound, err.Error())
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to list group members", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to list group members")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, dto.NewGroupMembers(members))
+	}
+}
@@ -0,0 +1,53 @@
+package getGroups
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Lister interface {
+	ListGroups(ctx context.Context) ([]models.Group, error)
+}
+
+// New godoc
+// @Summary List groups
+// @Description Returns all groups ordered by ID
+// @Tags groups
+// @Produce json
+// @Success 200 {array} dto.Group
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/groups [get]
+func New(log *slog.Logger, lister Lister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.GetGroups"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		groups, err := lister.ListGroups(r.Context())
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to list groups", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to list groups")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, dto.NewGroups(groups))
+	}
+}
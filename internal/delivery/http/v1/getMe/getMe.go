@@ -0,0 +1,73 @@
+package getMe
+
+import (
+	"backend-app/internal/country"
+	"backend-app/internal/delivery/http/dto"
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/profile"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Getter interface {
+	GetUserByID(ctx context.Context, id uint) (*models.User, error)
+	profile.Store
+}
+
+// New godoc
+// @Summary Get me
+// @Description Returns the current user together with the profile. Only attributes of the current schema are returned
+// @Tags profile
+// @Produce json
+// @Param Accept-Language header string false "Language of country names, English by default"
+// @Success 200 {object} dto.Me
+// @Failure 401 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/me [get]
+func New(log *slog.Logger, getter Getter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.GetMe"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id := authMiddleware.UserID(r.Context())
+		user, err := getter.GetUserByID(r.Context(), id)
+		var p *models.Profile
+		if err == nil {
+			p, err = profile.Get(r.Context(), getter, id)
+		}
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Info("user not found", "id", id)
+			response.Fail(w, r, http.StatusNotFound, response.CodeUserNotFound, "user not found")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to get profile", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to get profile")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		lang := country.Language(r.Header.Get("Accept-Language"))
+		render.JSON(w, r, dto.Me{User: dto.NewUser(user).Localize(lang), Profile: dto.NewProfile(p)})
+	}
+}
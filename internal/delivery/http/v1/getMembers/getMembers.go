@@ -0,0 +1,87 @@
+package getMembers
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Lister interface {
+	ListMembers(ctx context.Context, orgID uint, offset int, limit int) ([]models.Membership, error)
+}
+
+// New godoc
+// @Summary List organization members
+// @Description Returns members of an organization with their roles in it, ordered by user ID. Available to a superadmin and to admins of this organization
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param limit query int true "Limit"
+// @Param offset query int true "Offset"
+// @Success 200 {array} dto.Membership
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/orgs/{id}/members [get]
+func New(log *slog.Logger, lister Lister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.GetMembers"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			log.Info("invalid organization id", "param", idStr)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid organization id")
+			return
+		}
+		offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+		if err != nil || offset < 0 {
+			log.Info("invalid offset", "offset", r.URL.Query().Get("offset"))
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, "invalid offset")
+			return
+		}
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil || limit <= 0 {
+			log.Info("invalid limit", "limit", r.URL.Query().Get("limit"))
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, "invalid limit")
+			return
+		}
+
+		members, err := lister.ListMembers(r.Context(), uint(id), offset, limit)
+		if errors.Is(err, storage.ErrOrgNotFound) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeOrganizationNotFound, err.Error())
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to list members", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to list members")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, dto.NewMemberships(members))
+	}
+}
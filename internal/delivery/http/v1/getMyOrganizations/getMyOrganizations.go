@@ -0,0 +1,55 @@
+package getMyOrganizations
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Lister interface {
+	ListUserMemberships(ctx context.Context, userID uint) ([]models.Membership, error)
+}
+
+// New godoc
+// @Summary Get my organizations
+// @Description Returns organizations of the current user with their roles in them. Any of them can be passed as org_id to /v1/login or /v1/refresh
+// @Tags organizations
+// @Produce json
+// @Success 200 {array} dto.Membership
+// @Failure 401 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/me/orgs [get]
+func New(log *slog.Logger, lister Lister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.GetMyOrganizations"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		members, err := lister.ListUserMemberships(r.Context(), authMiddleware.UserID(r.Context()))
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to list memberships", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to get organizations")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, dto.NewMemberships(members))
+	}
+}
@@ -0,0 +1,70 @@
+package getOrganizations
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Lister interface {
+	ListOrganizations(ctx context.Context, offset int, limit int) ([]models.Organization, error)
+}
+
+// New godoc
+// @Summary List organizations
+// @Description Returns organizations ordered by ID
+// @Tags organizations
+// @Produce json
+// @Param limit query int true "Limit"
+// @Param offset query int true "Offset"
+// @Success 200 {array} dto.Organization
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/orgs [get]
+func New(log *slog.Logger, lister Lister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.GetOrganizations"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+		if err != nil || offset < 0 {
+			log.Info("invalid offset", "offset", r.URL.Query().Get("offset"))
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, "invalid offset")
+			return
+		}
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil || limit <= 0 {
+			log.Info("invalid limit", "limit", r.URL.Query().Get("limit"))
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, "invalid limit")
+			return
+		}
+
+		orgs, err := lister.ListOrganizations(r.Context(), offset, limit)
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to list organizations", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to list organizations")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, dto.NewOrganizations(orgs))
+	}
+}
@@ -0,0 +1,54 @@
+package getProfileAttributes
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Lister interface {
+	ListAttributeDefinitions(ctx context.Context) ([]models.AttributeDefinition, error)
+}
+
+// New godoc
+// @Summary Get profile attributes
+// @Description Returns the schema of profile attributes ordered by key, so that clients can build profile forms
+// @Tags profile
+// @Produce json
+// @Success 200 {array} dto.AttributeDefinition
+// @Failure 401 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/profile/attributes [get]
+func New(log *slog.Logger, lister Lister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.GetProfileAttributes"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		defs, err := lister.ListAttributeDefinitions(r.Context())
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to list attributes", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to get attributes")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, dto.NewAttributeDefinitions(defs))
+	}
+}
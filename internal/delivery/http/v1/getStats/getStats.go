@@ -0,0 +1,116 @@
+package getStats
+
+import (
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/stats"
+	"backend-app/internal/storage"
+	"backend-app/pkg/api/response"
+	"backend-app/pkg/sl"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+const (
+	// defaultDays - период по умолчанию, заканчивающийся сегодняшним днём
+	defaultDays = 30
+	// maxDays ограничивает период, чтобы один запрос не пересчитывал всю историю
+	maxDays = 366
+)
+
+type Reporter interface {
+	Report(ctx context.Context, q storage.StatsQuery) (*stats.Report, error)
+}
+
+// New godoc
+// @Summary Get registration and login statistics
+// @Description Returns registrations per day or week by country and role, login success and failure counts, active users (DAU and MAU from successful logins up to the end of the period) and the share of verified users among the registered ones. Days are UTC, weeks start on Monday. Organization admins see only members of their organization. Reports are cached for a few minutes
+// @Tags stats
+// @Produce json
+// @Param from query string false "First day, YYYY-MM-DD, 29 days before to by default"
+// @Param to query string false "Last day inclusive, YYYY-MM-DD, today by default"
+// @Param interval query string false "day (default) or week"
+// @Success 200 {object} stats.Report
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/admin/stats [get]
+func New(log *slog.Logger, reporter Reporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.GetStats"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		q, err := parseQuery(r.URL.Query(), time.Now())
+		if err != nil {
+			log.Error("invalid query parameter", sl.Error(err))
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, err.Error())
+			return
+		}
+		q.OrgID = authMiddleware.Tenant(r.Context())
+
+		report, err := reporter.Report(r.Context(), q)
+		if err != nil {
+			log.Error("failed to build stats", sl.Error(err))
+			if problem, ok := response.ContextError(err); ok {
+				response.WriteProblem(w, r, problem)
+				return
+			}
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to build stats")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, report)
+	}
+}
+
+// parseQuery читает период из запроса. Дни переводятся в полуинтервал [from, to) по UTC.
+func parseQuery(values url.Values, now time.Time) (storage.StatsQuery, error) {
+	q := storage.StatsQuery{Interval: values.Get("interval")}
+	switch q.Interval {
+	case "":
+		q.Interval = storage.IntervalDay
+	case storage.IntervalDay, storage.IntervalWeek:
+	default:
+		return q, errors.New("interval must be day or week")
+	}
+
+	now = now.UTC()
+	last := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if s := values.Get("to"); s != "" {
+		t, err := time.Parse(time.DateOnly, s)
+		if err != nil {
+			return q, errors.New("to must be a date in YYYY-MM-DD format")
+		}
+		last = t
+	}
+	first := last.AddDate(0, 0, 1-defaultDays)
+	if s := values.Get("from"); s != "" {
+		t, err := time.Parse(time.DateOnly, s)
+		if err != nil {
+			return q, errors.New("from must be a date in YYYY-MM-DD format")
+		}
+		first = t
+	}
+
+	q.From, q.To = first, last.AddDate(0, 0, 1)
+	if !q.From.Before(q.To) {
+		return q, errors.New("from must not be after to")
+	}
+	if q.To.Sub(q.From) > maxDays*24*time.Hour {
+		return q, fmt.Errorf("period must not exceed %d days", maxDays)
+	}
+	return q, nil
+}
@@ -0,0 +1,120 @@
+package getStats_test
+
+import (
+	"backend-app/internal/delivery/http/v1/getStats"
+	"backend-app/internal/stats"
+	"backend-app/internal/storage"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockReporter struct {
+	err   error
+	query *storage.StatsQuery
+}
+
+func (m *mockReporter) Report(ctx context.Context, q storage.StatsQuery) (*stats.Report, error) {
+	m.query = &q
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &stats.Report{From: q.From, To: q.To, Interval: q.Interval}, nil
+}
+
+func date(s string) time.Time {
+	t, _ := time.Parse(time.DateOnly, s)
+	return t
+}
+
+func TestGetStatsHandler(t *testing.T) {
+	now := time.Now().UTC()
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		query          string
+		err            error
+		expectedStatus int
+		expectedQuery  *storage.StatsQuery
+	}{
+		{
+			name:           "defaults",
+			expectedStatus: http.StatusOK,
+			expectedQuery:  &storage.StatsQuery{From: tomorrow.AddDate(0, 0, -30), To: tomorrow, Interval: storage.IntervalDay},
+		},
+		{
+			name:           "period",
+			query:          "?from=2026-01-05&to=2026-03-01&interval=week",
+			expectedStatus: http.StatusOK,
+			expectedQuery:  &storage.StatsQuery{From: date("2026-01-05"), To: date("2026-03-02"), Interval: storage.IntervalWeek},
+		},
+		{
+			name:           "single_day",
+			query:          "?from=2026-03-01&to=2026-03-01",
+			expectedStatus: http.StatusOK,
+			expectedQuery:  &storage.StatsQuery{From: date("2026-03-01"), To: date("2026-03-02"), Interval: storage.IntervalDay},
+		},
+		{
+			name:           "invalid_interval",
+			query:          "?interval=month",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "invalid_date",
+			query:          "?from=01.03.2026",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "reversed",
+			query:          "?from=2026-03-02&to=2026-03-01",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "too_long",
+			query:          "?from=2024-01-01&to=2026-01-01",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "internal_error",
+			err:            errors.New("db failure"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "timeout",
+			err:            context.DeadlineExceeded,
+			expectedStatus: http.StatusGatewayTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockReporter{err: tt.err}
+			router := chi.NewRouter()
+			router.Use(middleware.RequestID)
+			router.Get("/admin/stats", getStats.New(slog.Default(), m))
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/stats"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedStatus == http.StatusUnprocessableEntity {
+				assert.Nil(t, m.query, "invalid periods do not reach the storage")
+			}
+			if tt.expectedQuery != nil {
+				require.NotNil(t, m.query)
+				assert.Equal(t, *tt.expectedQuery, *m.query)
+			}
+		})
+	}
+}
@@ -1,8 +1,14 @@
 package getUser
 
 import (
+	"backend-app/internal/country"
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/delivery/http/etag"
+	"backend-app/internal/delivery/http/tenant"
+	"backend-app/internal/storage"
 	"backend-app/internal/storage/models"
 	"backend-app/pkg/api/response"
+	"context"
 	"errors"
 	"log/slog"
 	"net/http"
@@ -11,25 +17,29 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
-	"gorm.io/gorm"
 )
 
-type storage interface {
-	GetUserByID(id uint) (*models.User, error)
+type userGetter interface {
+	GetUserByID(ctx context.Context, id uint) (*models.User, error)
+	tenant.Members
 }
 
 // New godoc
 // @Summary Get user by ID
-// @Description Returns user data by ID
+// @Description Returns user data by ID. Organization admins get 404 for users outside their organization
 // @Tags users
 // @Produce json
+// @Param Accept-Language header string false "Language of country names, English by default"
 // @Param id path int true "User ID"
-// @Success 200 {object} models.User
-// @Failure 400 {object} response.Response
-// @Failure 404 {object} response.Response
-// @Failure 500 {object} response.Response
+// @Success 200 {object} dto.User
+// @Header 200 {string} ETag "User version, send it back in If-Match to update the user"
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
 // @Router /v1/user/{id} [get]
-func New(log *slog.Logger, storage storage) http.HandlerFunc {
+func New(log *slog.Logger, getter userGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.v1.GetUserByID"
 
@@ -42,27 +52,35 @@ func New(log *slog.Logger, storage storage) http.HandlerFunc {
 		id, err := strconv.ParseUint(idParam, 10, 64)
 		if err != nil {
 			log.Error("invalid user id", "param", idParam, "error", err)
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, response.Error("invalid user id"))
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid user id")
 			return
 		}
 
-		user, err := storage.GetUserByID(uint(id))
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		var user *models.User
+		err = tenant.Check(r.Context(), getter, uint(id))
+		if err == nil {
+			// ETag с реплики мог бы устареть, и следующий If-Match получил бы 412
+			user, err = getter.GetUserByID(storage.WithPrimary(r.Context()), uint(id))
+		}
+		if errors.Is(err, storage.ErrUserNotFound) {
 			log.Info("user not found", "id", id)
-			render.Status(r, http.StatusNotFound)
-			render.JSON(w, r, response.Error("user not found"))
+			response.Fail(w, r, http.StatusNotFound, response.CodeUserNotFound, "user not found")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
 			return
 		}
 		if err != nil {
 			log.Error("failed to get user", "error", err)
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.Error("failed to get user"))
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to get user")
 			return
 		}
 
-		log.Info("user retrieved successfully", slog.Any("user", user))
+		log.Info("user retrieved successfully", "id", user.ID)
+		w.Header().Set("ETag", etag.Format(user.Version))
 		render.Status(r, http.StatusOK)
-		render.JSON(w, r, user)
+		render.JSON(w, r, dto.NewUser(user).Localize(country.Language(r.Header.Get("Accept-Language"))))
 	}
 }
@@ -2,10 +2,12 @@ package getUser_test
 
 import (
 	v1Router "backend-app/internal/delivery/http/v1/getUser"
+	"backend-app/internal/storage"
 	"backend-app/internal/storage/models"
 	_ "bytes"
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -13,7 +15,6 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/gorm"
 )
 
 // mockStorage implements the method needed for testing
@@ -22,13 +23,17 @@ type mockStorage struct {
 	err  error
 }
 
-func (m *mockStorage) GetUserByID(id uint) (*models.User, error) {
+func (m *mockStorage) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
 	return m.user, nil
 }
 
+func (m *mockStorage) GetMembership(ctx context.Context, orgID uint, userID uint) (*models.Membership, error) {
+	return nil, storage.ErrMembershipNotFound
+}
+
 func TestGetUserByID(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -42,34 +47,49 @@ func TestGetUserByID(t *testing.T) {
 			name:           "invalid id",
 			paramID:        "abc",
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"status":"Error","error":"invalid user id"}`,
+			expectedBody:   `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_parameter","detail":"invalid user id","instance":"/users/abc"}`,
 		},
 		{
 			name:           "user not found",
 			paramID:        "1",
-			mockError:      gorm.ErrRecordNotFound,
+			mockError:      storage.ErrUserNotFound,
 			expectedStatus: http.StatusNotFound,
-			expectedBody:   `{"status":"Error","error":"user not found"}`,
+			expectedBody:   `{"type":"about:blank","title":"Not Found","status":404,"code":"user_not_found","detail":"user not found","instance":"/users/1"}`,
 		},
 		{
 			name:           "internal error",
 			paramID:        "1",
 			mockError:      errors.New("db failure"),
 			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   `{"status":"Error","error":"failed to get user"}`,
+			expectedBody:   `{"type":"about:blank","title":"Internal Server Error","status":500,"code":"internal_error","detail":"failed to get user","instance":"/users/1"}`,
+		},
+		{
+			name:           "storage timeout",
+			paramID:        "1",
+			mockError:      fmt.Errorf("%w: canceling statement", context.DeadlineExceeded),
+			expectedStatus: http.StatusGatewayTimeout,
+			expectedBody:   `{"type":"about:blank","title":"Gateway Timeout","status":504,"code":"timeout","detail":"request timed out","instance":"/users/1"}`,
+		},
+		{
+			name:           "request canceled",
+			paramID:        "1",
+			mockError:      context.Canceled,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"type":"about:blank","title":"Service Unavailable","status":503,"code":"request_canceled","detail":"request canceled","instance":"/users/1"}`,
 		},
 		{
 			name:           "success",
 			paramID:        "1",
-			mockUser:       &models.User{ID: 1, Email: "test@example.com"},
+			mockUser:       &models.User{ID: 1, Email: "test@example.com", Password: "hash", RefreshToken: "token", Version: 3},
 			expectedStatus: http.StatusOK,
 			expectedBody: `{
 		"id": 1,
 		"username": "",
-		"password": "",
 		"email": "test@example.com",
 		"role": "",
 		"country": "",
+		"verified": false,
+		"version": 3,
 		"createdAt": "0001-01-01T00:00:00Z",
 		"updatedAt": "0001-01-01T00:00:00Z"
 	}`,
@@ -97,6 +117,9 @@ func TestGetUserByID(t *testing.T) {
 			if tt.expectedBody != "" {
 				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
 			}
+			if tt.mockUser != nil {
+				assert.Equal(t, `"3"`, rr.Header().Get("ETag"))
+			}
 		})
 
 	}
@@ -0,0 +1,79 @@
+package getUserProfile
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/delivery/http/tenant"
+	"backend-app/internal/profile"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Getter interface {
+	tenant.Members
+	profile.Store
+}
+
+// New godoc
+// @Summary Get user profile
+// @Description Returns the profile of a user. Organization admins get 404 for users outside their organization
+// @Tags profile
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.Profile
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/user/{id}/profile [get]
+func New(log *slog.Logger, getter Getter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.GetUserProfile"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idParam := chi.URLParam(r, "id")
+		id, err := strconv.ParseUint(idParam, 10, 32)
+		if err != nil {
+			log.Info("invalid user id", "param", idParam)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid user id")
+			return
+		}
+
+		var p *models.Profile
+		err = tenant.Check(r.Context(), getter, uint(id))
+		if err == nil {
+			p, err = profile.Get(r.Context(), getter, uint(id))
+		}
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Info("user not found", "id", id)
+			response.Fail(w, r, http.StatusNotFound, response.CodeUserNotFound, "user not found")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to get profile", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to get profile")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, dto.NewProfile(p))
+	}
+}
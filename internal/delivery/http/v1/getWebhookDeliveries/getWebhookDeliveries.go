@@ -0,0 +1,109 @@
+package getWebhookDeliveries
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 100
+)
+
+type Getter interface {
+	GetWebhook(ctx context.Context, id uint) (*models.Webhook, error)
+	ListDeliveries(ctx context.Context, webhookID uint, status string, offset int, limit int) ([]models.WebhookDelivery, error)
+}
+
+// New godoc
+// @Summary Get webhook deliveries
+// @Description Returns the delivery log of a webhook, newest first
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param status query string false "Delivery status" Enums(pending, delivered, dead)
+// @Param limit query int false "Limit, 1-100, default 50"
+// @Param offset query int false "Offset"
+// @Success 200 {array} dto.WebhookDelivery
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/webhooks/{id}/deliveries [get]
+func New(log *slog.Logger, getter Getter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.GetWebhookDeliveries"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			log.Info("invalid webhook id", "param", idStr)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid webhook id")
+			return
+		}
+
+		query := r.URL.Query()
+		status := query.Get("status")
+		switch status {
+		case "", models.DeliveryPending, models.DeliveryDelivered, models.DeliveryDead:
+		default:
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, "invalid status")
+			return
+		}
+		limit := DefaultLimit
+		if v := query.Get("limit"); v != "" {
+			if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > MaxLimit {
+				response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, "invalid limit")
+				return
+			}
+		}
+		offset := 0
+		if v := query.Get("offset"); v != "" {
+			if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+				response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, "invalid offset")
+				return
+			}
+		}
+
+		var deliveries []models.WebhookDelivery
+		_, err = getter.GetWebhook(r.Context(), uint(id))
+		if err == nil {
+			deliveries, err = getter.ListDeliveries(r.Context(), uint(id), status, offset, limit)
+		}
+		if errors.Is(err, storage.ErrWebhookNotFound) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeWebhookNotFound, "webhook not found")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to list webhook deliveries", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to list webhook deliveries")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, dto.NewWebhookDeliveries(deliveries))
+	}
+}
@@ -0,0 +1,114 @@
+package importUsers
+
+import (
+	"backend-app/internal/account"
+	"backend-app/internal/bulk"
+	"backend-app/internal/config"
+	"backend-app/pkg/api/response"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// New godoc
+// @Summary Import users
+// @Description Creates users from a CSV file with a header row or from NDJSON, one JSON object per line. Fields: username, email, password or passwordHash (bcrypt), role, country (ISO 3166-1 code or English name, stored as alpha-2), status, verified. Users are saved in batches, each batch in its own transaction; rows with errors are skipped and listed in the report. With dryRun=true the file is checked against the database and nothing is saved. For files larger than the API limit use the import command
+// @Tags users
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param format query string false "csv or ndjson, by default taken from Content-Type" Enums(csv, ndjson)
+// @Param dryRun query bool false "Validate only"
+// @Success 200 {object} bulk.Report
+// @Failure 400 {object} response.Problem
+// @Failure 413 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/users/import [post]
+func New(log *slog.Logger, importer bulk.Importer, cfg config.Bulk, accounts account.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.ImportUsers"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = formatFromContentType(r.Header.Get("Content-Type"))
+		}
+		dryRun := false
+		if v := r.URL.Query().Get("dryRun"); v != "" {
+			var err error
+			if dryRun, err = strconv.ParseBool(v); err != nil {
+				response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "dryRun must be a boolean")
+				return
+			}
+		}
+
+		// таймауты http_server рассчитаны на обычные запросы, а импорт читает файл целиком
+		rc := http.NewResponseController(w)
+		deadline := time.Now().Add(cfg.RequestTimeout)
+		_ = rc.SetReadDeadline(deadline)
+		_ = rc.SetWriteDeadline(deadline)
+		body := http.MaxBytesReader(w, r.Body, cfg.MaxImportSize)
+
+		reader, err := bulk.NewReader(body, format)
+		if err != nil {
+			importError(w, r, log, err, "")
+			return
+		}
+		report, err := bulk.Import(r.Context(), importer, reader, bulk.ImportOptions{BatchSize: cfg.BatchSize, DryRun: dryRun, Accounts: accounts})
+		if err != nil {
+			// пачки до ошибки уже сохранены, клиенту нужно знать, с какого места продолжать
+			importError(w, r, log, err, fmt.Sprintf(" (import stopped after %d rows, %d users saved)", report.Total, report.Created))
+			return
+		}
+
+		log.Info("users imported", "dry_run", dryRun, "total", report.Total, "created", report.Created, "failed", report.Failed)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, report)
+	}
+}
+
+func importError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error, progress string) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		log.Info("import file is too large", "limit", tooLarge.Limit)
+		response.Fail(w, r, http.StatusRequestEntityTooLarge, response.CodePayloadTooLarge, fmt.Sprintf("file is larger than %d bytes, use the import command", tooLarge.Limit)+progress)
+		return
+	}
+	if problem, ok := response.ContextError(err); ok {
+		log.Error("storage call interrupted", "error", err)
+		response.WriteProblem(w, r, problem)
+		return
+	}
+	if errors.Is(err, bulk.ErrStorage) {
+		log.Error("failed to import users", "error", err)
+		response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to import users"+progress)
+		return
+	}
+	log.Info("invalid import file", "error", err)
+	response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidImportFile, err.Error()+progress)
+}
+
+func formatFromContentType(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return bulk.FormatCSV
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return bulk.FormatNDJSON
+	default:
+		return ""
+	}
+}
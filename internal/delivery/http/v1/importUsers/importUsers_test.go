@@ -0,0 +1,136 @@
+package importUsers_test
+
+import (
+	"backend-app/internal/account"
+	"backend-app/internal/bulk"
+	"backend-app/internal/config"
+	"backend-app/internal/delivery/http/v1/importUsers"
+	"backend-app/internal/storage/memory"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var cfg = config.Bulk{BatchSize: 10, MaxImportSize: 1 << 10, RequestTimeout: time.Minute}
+
+type failingImporter struct{}
+
+func (failingImporter) ImportUsers(ctx context.Context, users []*models.User, dryRun bool) ([]error, error) {
+	return nil, errors.New("db error")
+}
+
+func TestImportUsersHandler(t *testing.T) {
+	const csv = "username,email,password\nalice,alice@example.com,password1\nbob,bob-at-example.com,password1\n"
+
+	tests := []struct {
+		name           string
+		query          string
+		contentType    string
+		body           string
+		importer       bulk.Importer
+		expectedStatus int
+		expectedError  string
+		wantCreated    int
+		wantSaved      bool
+	}{
+		{
+			name:           "csv",
+			contentType:    "text/csv; charset=utf-8",
+			body:           csv,
+			expectedStatus: http.StatusOK,
+			wantCreated:    1,
+			wantSaved:      true,
+		},
+		{
+			name:           "dry_run",
+			query:          "?format=csv&dryRun=true",
+			body:           csv,
+			expectedStatus: http.StatusOK,
+			wantCreated:    1,
+		},
+		{
+			name:           "ndjson",
+			contentType:    "application/x-ndjson",
+			body:           `{"username":"alice","email":"alice@example.com","password":"password1"}`,
+			expectedStatus: http.StatusOK,
+			wantCreated:    1,
+			wantSaved:      true,
+		},
+		{
+			name:           "unknown_format",
+			contentType:    "application/json",
+			body:           `[]`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  bulk.ErrUnknownFormat.Error(),
+		},
+		{
+			name:           "invalid_dry_run",
+			query:          "?format=csv&dryRun=maybe",
+			body:           csv,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "dryRun must be a boolean",
+		},
+		{
+			name:           "too_large",
+			query:          "?format=ndjson",
+			body:           strings.Repeat(`{"username":"alice","email":"alice@example.com","password":"password1"}`+"\n", 20),
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedError:  "file is larger than 1024 bytes, use the import command (import stopped after 15 rows, 0 users saved)",
+		},
+		{
+			name:           "storage_error",
+			query:          "?format=csv",
+			body:           csv,
+			importer:       failingImporter{},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "failed to import users (import stopped after 2 rows, 0 users saved)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := memory.New()
+			importer := tt.importer
+			if importer == nil {
+				importer = store
+			}
+			router := chi.NewRouter()
+			router.Use(middleware.RequestID)
+			router.Post("/users/import", importUsers.New(slog.Default(), importer, cfg, account.Policy{}))
+
+			req := httptest.NewRequest(http.MethodPost, "/users/import"+tt.query, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedError != "" {
+				var res response.Problem
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				assert.Equal(t, tt.expectedError, res.Detail)
+				return
+			}
+
+			var report bulk.Report
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+			assert.Equal(t, tt.wantCreated, report.Created)
+			_, err := store.GetUserByUsername(t.Context(), "alice")
+			assert.Equal(t, tt.wantSaved, err == nil)
+		})
+	}
+}
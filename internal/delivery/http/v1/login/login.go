@@ -2,70 +2,150 @@ package login
 
 import (
 	"backend-app/internal/config"
-	"backend-app/internal/storage/postgres"
+	"backend-app/internal/delivery/http/cookie"
+	"backend-app/internal/delivery/http/tenant"
+	"backend-app/internal/events"
+	"backend-app/internal/permissions"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
 	"backend-app/pkg/api/response"
 	"backend-app/pkg/jwt/generator"
 	"backend-app/pkg/sl"
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-chi/render"
 )
 
+type UserProvider interface {
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	SetRefreshToken(ctx context.Context, id uint, token string, expiry time.Time) error
+	AddEvents(ctx context.Context, events []models.OutboxEvent) error
+	RecordLogin(ctx context.Context, login *models.Login) error
+	tenant.Members
+	permissions.Groups
+}
+
 type LoginRequest struct {
+	// Username - имя без учёта регистра или email
 	Username string `json:"username"`
 	Password string `json:"password"`
+	// OrgID - организация, для которой выпускаются токены, пользователь должен в ней состоять
+	OrgID uint `json:"org_id,omitempty"`
 }
 
 // New godoc
 // @Summary Login
-// @Description Authenticates user and returns token pair
+// @Description Authenticates user and returns token pair. The username field accepts the username or the email, both regardless of case. Attempts are recorded for login statistics. With org_id the tokens are issued for that organization and carry the user's role in it. The access token lists effective permissions of the user, including those granted by groups, in the perms claim. In cookie mode tokens are set as HttpOnly cookies and the body holds cookie.TokenResponse
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param input body login.LoginRequest true "Credentials"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} response.Response
-// @Failure 401 {object} response.Response
-// @Failure 500 {object} response.Response
+// @Failure 400 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 403 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
 // @Router /v1/login [post]
-func New(log *slog.Logger, storage *postgres.Storage) http.HandlerFunc {
+func New(log *slog.Logger, users UserProvider, cookieCfg config.Cookie) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var credentials LoginRequest
 
 		if err := render.DecodeJSON(r.Body, &credentials); err != nil {
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, map[string]string{"error": "Invalid request"})
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidBody, "Invalid request")
 			return
 		}
+		// статистика входов не важнее самого входа, ошибка записи только логируется
+		record := func(userID uint, succeeded bool) {
+			if err := users.RecordLogin(r.Context(), &models.Login{UserID: userID, Succeeded: succeeded}); err != nil {
+				log.Error("failed to record login", sl.Error(err))
+			}
+		}
 
-		user, err := storage.GetUserByUsername(credentials.Username)
+		user, err := users.GetUserByUsername(r.Context(), credentials.Username)
+		// в именах '@' запрещён, поэтому такой логин - email
+		if errors.Is(err, storage.ErrUserNotFound) && strings.Contains(credentials.Username, "@") {
+			user, err = users.GetUserByEmail(r.Context(), credentials.Username)
+		}
+		if errors.Is(err, storage.ErrUserNotFound) {
+			record(0, false)
+		}
 		if err != nil {
 			log.Error("invalid request", sl.Error(err))
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, map[string]string{"error": "Invalid username"})
+			if problem, ok := response.ContextError(err); ok {
+				response.WriteProblem(w, r, problem)
+				return
+			}
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidCredentials, "Invalid username")
+			return
 		}
 
 		if err := user.CheckPassword(credentials.Password); err != nil {
-			render.Status(r, http.StatusUnauthorized)
-			render.JSON(w, r, map[string]string{"error": "Invalid credentials"})
+			// ответ от записи события не зависит: клиент всё равно получает 401
+			if err := users.AddEvents(r.Context(), events.LoginFailed(user)); err != nil {
+				log.Error("failed to record failed login", sl.Error(err))
+			}
+			record(user.ID, false)
+			response.Fail(w, r, http.StatusUnauthorized, response.CodeInvalidCredentials, "Invalid credentials")
+			return
+		}
+		if user.Status == models.StatusSuspended {
+			record(user.ID, false)
+			response.Fail(w, r, http.StatusForbidden, response.CodeUserSuspended, "user is suspended")
+			return
+		}
+		role, err := tenant.TokenRole(r.Context(), users, user, credentials.OrgID)
+		var grants []permissions.Grant
+		if err == nil {
+			grants, err = permissions.Effective(r.Context(), users, user.ID, role)
+		}
+		if errors.Is(err, storage.ErrMembershipNotFound) {
+			response.Fail(w, r, http.StatusForbidden, response.CodeNotMember, "user is not a member of the organization")
 			return
 		}
-		tokens, err := generator.GenerateTokenPair(user.ID, user.Role)
 		if err != nil {
-			log.Error("error", sl.Error(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.Error("failed to create token pair"))
+			log.Error("failed to get role and permissions", sl.Error(err))
+			if problem, ok := response.ContextError(err); ok {
+				response.WriteProblem(w, r, problem)
+				return
+			}
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to create token pair")
+			return
 		}
 
-		user.RefreshToken = tokens.RefreshToken
-		user.TokenExpiry = time.Now().Add(config.RefreshTokenExpiry)
+		tokens, err := generator.GenerateTokenPair(user.ID, role, credentials.OrgID, user.TokenVersion, permissions.Names(grants))
+		if err != nil {
+			log.Error("error", sl.Error(err))
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to create token pair")
+			return
+		}
 
-		if err := storage.UpdateUser(user); err != nil {
+		if err := users.SetRefreshToken(r.Context(), user.ID, tokens.RefreshToken, time.Now().Add(config.RefreshTokenExpiry)); err != nil {
 			log.Error("err", sl.Error(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.Error(err.Error()))
+			if problem, ok := response.ContextError(err); ok {
+				response.WriteProblem(w, r, problem)
+				return
+			}
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to create token pair")
+			return
+		}
+		record(user.ID, true)
+
+		if cookieCfg.Enabled {
+			resp, err := cookie.SetTokens(w, cookieCfg, tokens)
+			if err != nil {
+				log.Error("failed to set cookies", sl.Error(err))
+				response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to create token pair")
+				return
+			}
+			render.JSON(w, r, resp)
 			return
 		}
 
@@ -0,0 +1,243 @@
+package patch
+
+import (
+	"backend-app/internal/account"
+	"backend-app/internal/country"
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/delivery/http/etag"
+	"backend-app/internal/delivery/http/tenant"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+type Patcher interface {
+	PatchUser(ctx context.Context, id uint, version uint, patch storage.UserPatch) (*models.User, error)
+	tenant.Users
+}
+
+// fields - поля, которые можно менять через PATCH, и правила их проверки.
+// Правила повторяют теги validate у models.User, bcrypt не принимает пароли длиннее 72 байт.
+var fields = map[string]string{
+	"username": "required",
+	"email":    "required,email",
+	"password": "required,max=72",
+	"role":     "required,oneof=user creator combined admin superadmin",
+	"country":  "",
+	"status":   "required,oneof=active suspended",
+	"verified": "",
+}
+
+var validate = validator.New()
+
+// New godoc
+// @Summary Patch user
+// @Description Applies a JSON Merge Patch (RFC 7396) to the user. Only username, email, password, role, country, status and verified can be changed; omitted fields stay as they are. The country may be given as an ISO 3166-1 alpha-2, alpha-3 or numeric code or as an English name and is stored as alpha-2. If-Match must carry the ETag from GET /v1/user/{id}. Only a superadmin can grant or revoke the superadmin role; organization admins cannot change roles and can patch only members of their organization that belong to no other organization
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "Language of country names, English by default"
+// @Param id path int true "User ID"
+// @Param If-Match header string true "ETag of the user version being patched"
+// @Param input body object true "Merge patch with the fields to change"
+// @Success 200 {object} dto.User
+// @Header 200 {string} ETag "New user version"
+// @Failure 400 {object} response.Problem
+// @Failure 403 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 409 {object} response.Problem
+// @Failure 412 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 428 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/user/{id} [patch]
+func New(log *slog.Logger, patcher Patcher, accounts account.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.PatchUser"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idParam := chi.URLParam(r, "id")
+		id, err := strconv.ParseUint(idParam, 10, 64)
+		if err != nil {
+			log.Error("invalid user id", "param", idParam, "error", err)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid user id")
+			return
+		}
+
+		ifMatch := r.Header.Get("If-Match")
+		if ifMatch == "" {
+			log.Info("patch without If-Match")
+			response.Fail(w, r, http.StatusPreconditionRequired, response.CodePreconditionRequired, "If-Match header is required")
+			return
+		}
+		version, err := etag.Parse(ifMatch)
+		if err != nil {
+			log.Info("invalid If-Match", slog.String("if_match", ifMatch))
+			response.Fail(w, r, http.StatusPreconditionFailed, response.CodePreconditionFailed, "precondition failed")
+			return
+		}
+
+		var doc map[string]json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&doc); err != nil || doc == nil {
+			log.Error("failed to decode merge patch", "error", err)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidBody, "request body must be a JSON object")
+			return
+		}
+
+		patch, fieldErrs := parsePatch(doc, accounts)
+		if len(fieldErrs) > 0 {
+			log.Info("invalid merge patch", "errors", fieldErrs)
+			problem := response.NewProblem(http.StatusUnprocessableEntity, response.CodeValidationFailed, "validation failed")
+			problem.Errors = fieldErrs
+			response.WriteProblem(w, r, problem)
+			return
+		}
+
+		var role string
+		if patch.Role != nil {
+			role = *patch.Role
+		}
+		var user *models.User
+		err = tenant.CheckWrite(r.Context(), patcher, uint(id), role)
+		if err == nil {
+			user, err = patcher.PatchUser(r.Context(), uint(id), version, patch)
+		}
+		if errors.Is(err, tenant.ErrForbidden) {
+			log.Info("forbidden for this admin", "id", id)
+			response.Fail(w, r, http.StatusForbidden, response.CodeForbidden, err.Error())
+			return
+		}
+		if errors.Is(err, storage.ErrVersionConflict) {
+			log.Info("user was modified concurrently", "id", id, "version", version)
+			response.Fail(w, r, http.StatusPreconditionFailed, response.CodeVersionConflict, "user was modified by another request")
+			return
+		}
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Info("user not found", "id", id)
+			response.Fail(w, r, http.StatusNotFound, response.CodeUserNotFound, "user not found")
+			return
+		}
+		if errors.Is(err, storage.ErrUserExists) {
+			log.Info("username or email already taken", "id", id)
+			response.Fail(w, r, http.StatusConflict, response.CodeUserExists, "user already exists")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to patch user", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to update user")
+			return
+		}
+
+		log.Info("user patched successfully", "id", id, "version", user.Version)
+		w.Header().Set("ETag", etag.Format(user.Version))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, dto.NewUser(user).Localize(country.Language(r.Header.Get("Accept-Language"))))
+	}
+}
+
+// parsePatch проверяет документ merge patch и переводит его в storage.UserPatch. Ошибки
+// собираются по всем полям. null по RFC 7396 удаляет поле, но у пользователя все поля
+// обязательные, поэтому он запрещён.
+func parsePatch(doc map[string]json.RawMessage, accounts account.Policy) (storage.UserPatch, []response.FieldError) {
+	var (
+		patch storage.UserPatch
+		errs  []response.FieldError
+	)
+	fail := func(name, code, detail string) {
+		errs = append(errs, response.FieldError{Field: name, Code: code, Detail: detail})
+	}
+	for name, raw := range doc {
+		rule, ok := fields[name]
+		if !ok {
+			fail(name, "not_patchable", "cannot be patched")
+			continue
+		}
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			fail(name, "not_null", "cannot be null")
+			continue
+		}
+
+		if name == "verified" {
+			var v bool
+			if err := json.Unmarshal(raw, &v); err != nil {
+				fail(name, "boolean", "must be a boolean")
+				continue
+			}
+			patch.Verified = &v
+			continue
+		}
+
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			fail(name, "string", "must be a string")
+			continue
+		}
+		if rule != "" {
+			if err := validate.Var(v, rule); err != nil {
+				errs = append(errs, response.FieldErrors(name, err)...)
+				continue
+			}
+		}
+
+		switch name {
+		case "username":
+			if err := accounts.CheckUsername(v); err != nil {
+				code := response.CodeInvalidUsername
+				if errors.Is(err, account.ErrReservedUsername) {
+					code = response.CodeReservedUsername
+				}
+				fail(name, code, err.Error())
+				continue
+			}
+			patch.Username = &v
+		case "email":
+			patch.Email = &v
+		case "password":
+			u := models.User{Password: v}
+			if err := u.HashPassword(); err != nil {
+				fail(name, "invalid", "is invalid")
+				continue
+			}
+			patch.Password = &u.Password
+		case "role":
+			patch.Role = &v
+		case "country":
+			code, err := country.Normalize(v)
+			if err != nil {
+				fail(name, response.CodeUnknownCountry, "must be an ISO 3166-1 country")
+				continue
+			}
+			patch.Country = &code
+		case "status":
+			patch.Status = &v
+		}
+	}
+	// порядок полей в map случаен, а ответ должен быть одинаковым
+	slices.SortFunc(errs, func(a, b response.FieldError) int { return cmp.Compare(a.Field, b.Field) })
+	return patch, errs
+}
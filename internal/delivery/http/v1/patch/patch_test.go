@@ -0,0 +1,251 @@
+package patch_test
+
+import (
+	"backend-app/internal/account"
+	"backend-app/internal/delivery/http/v1/patch"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type mockPatcher struct {
+	PatchFn func(id uint, version uint, p storage.UserPatch) (*models.User, error)
+}
+
+func (m *mockPatcher) PatchUser(ctx context.Context, id uint, version uint, p storage.UserPatch) (*models.User, error) {
+	return m.PatchFn(id, version, p)
+}
+
+func (m *mockPatcher) GetMembership(ctx context.Context, orgID uint, userID uint) (*models.Membership, error) {
+	return nil, storage.ErrMembershipNotFound
+}
+
+func (m *mockPatcher) ListUserMemberships(ctx context.Context, userID uint) ([]models.Membership, error) {
+	return nil, nil
+}
+
+func (m *mockPatcher) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	return &models.User{ID: id, Role: "user"}, nil
+}
+
+func TestPatchUserHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		urlParam       string
+		ifMatch        string
+		body           string
+		mockErr        error
+		expectedStatus int
+		expectedError  string
+		expectedErrors []response.FieldError
+		checkPatch     func(t *testing.T, p storage.UserPatch)
+	}{
+		{
+			name:           "invalid_id",
+			urlParam:       "abc",
+			ifMatch:        `"1"`,
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid user id",
+		},
+		{
+			name:           "missing_if_match",
+			urlParam:       "1",
+			body:           `{"country":"DE"}`,
+			expectedStatus: http.StatusPreconditionRequired,
+			expectedError:  "If-Match header is required",
+		},
+		{
+			name:           "not_an_object",
+			urlParam:       "1",
+			ifMatch:        `"1"`,
+			body:           `["country"]`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "request body must be a JSON object",
+		},
+		{
+			name:           "field_not_allowed",
+			urlParam:       "1",
+			ifMatch:        `"1"`,
+			body:           `{"id":2}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "validation failed",
+			expectedErrors: []response.FieldError{{Field: "id", Code: "not_patchable", Detail: "cannot be patched"}},
+		},
+		{
+			name:           "null_field",
+			urlParam:       "1",
+			ifMatch:        `"1"`,
+			body:           `{"email":null}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "validation failed",
+			expectedErrors: []response.FieldError{{Field: "email", Code: "not_null", Detail: "cannot be null"}},
+		},
+		{
+			name:           "wrong_type",
+			urlParam:       "1",
+			ifMatch:        `"1"`,
+			body:           `{"verified":"yes"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "validation failed",
+			expectedErrors: []response.FieldError{{Field: "verified", Code: "boolean", Detail: "must be a boolean"}},
+		},
+		{
+			name:           "invalid_value",
+			urlParam:       "1",
+			ifMatch:        `"1"`,
+			body:           `{"role":"root"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "validation failed",
+			expectedErrors: []response.FieldError{{Field: "role", Code: "oneof", Detail: "must be one of: user creator combined admin superadmin"}},
+		},
+		{
+			name:           "several_fields",
+			urlParam:       "1",
+			ifMatch:        `"1"`,
+			body:           `{"role":"root","email":"not-an-email","country":"Atlantis"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "validation failed",
+			expectedErrors: []response.FieldError{
+				{Field: "country", Code: response.CodeUnknownCountry, Detail: "must be an ISO 3166-1 country"},
+				{Field: "email", Code: "email", Detail: "must be a valid email"},
+				{Field: "role", Code: "oneof", Detail: "must be one of: user creator combined admin superadmin"},
+			},
+		},
+		{
+			name:           "email_as_username",
+			urlParam:       "1",
+			ifMatch:        `"1"`,
+			body:           `{"username":"bob@example.com"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "validation failed",
+			expectedErrors: []response.FieldError{{Field: "username", Code: response.CodeInvalidUsername, Detail: account.ErrInvalidUsername.Error()}},
+		},
+		{
+			name:           "reserved_username",
+			urlParam:       "1",
+			ifMatch:        `"1"`,
+			body:           `{"username":"Root"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "validation failed",
+			expectedErrors: []response.FieldError{{Field: "username", Code: response.CodeReservedUsername, Detail: account.ErrReservedUsername.Error()}},
+		},
+		{
+			name:           "version_conflict",
+			urlParam:       "1",
+			ifMatch:        `"1"`,
+			body:           `{"country":"DE"}`,
+			mockErr:        storage.ErrVersionConflict,
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedError:  "user was modified by another request",
+		},
+		{
+			name:           "not_found",
+			urlParam:       "1",
+			ifMatch:        `"1"`,
+			body:           `{"country":"DE"}`,
+			mockErr:        storage.ErrUserNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "user not found",
+		},
+		{
+			name:           "duplicate",
+			urlParam:       "1",
+			ifMatch:        `"1"`,
+			body:           `{"username":"bob"}`,
+			mockErr:        storage.ErrUserExists,
+			expectedStatus: http.StatusConflict,
+			expectedError:  "user already exists",
+		},
+		{
+			name:           "internal_error",
+			urlParam:       "1",
+			ifMatch:        `"1"`,
+			body:           `{"country":"DE"}`,
+			mockErr:        errors.New("db failure"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "failed to update user",
+		},
+		{
+			name:           "success",
+			urlParam:       "1",
+			ifMatch:        `"1"`,
+			body:           `{"country":"DE","verified":true,"password":"secret123"}`,
+			expectedStatus: http.StatusOK,
+			checkPatch: func(t *testing.T, p storage.UserPatch) {
+				assert.Nil(t, p.Username)
+				assert.Nil(t, p.Email)
+				if assert.NotNil(t, p.Country) {
+					assert.Equal(t, "DE", *p.Country)
+				}
+				if assert.NotNil(t, p.Verified) {
+					assert.True(t, *p.Verified)
+				}
+				if assert.NotNil(t, p.Password) {
+					assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(*p.Password), []byte("secret123")),
+						"password must be hashed")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/user/"+tt.urlParam, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/merge-patch+json")
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			rr := httptest.NewRecorder()
+
+			router := chi.NewRouter()
+			router.Use(middleware.RequestID)
+			router.Use(render.SetContentType(render.ContentTypeJSON))
+			router.Patch("/user/{id}", patch.New(slog.Default(), &mockPatcher{
+				PatchFn: func(id uint, version uint, p storage.UserPatch) (*models.User, error) {
+					assert.Equal(t, uint(1), id)
+					assert.Equal(t, uint(1), version)
+					if tt.checkPatch != nil {
+						tt.checkPatch(t, p)
+					}
+					if tt.mockErr != nil {
+						return nil, tt.mockErr
+					}
+					return &models.User{ID: id, Country: *p.Country, Version: version + 1}, nil
+				},
+			}, account.Policy{Reserved: []string{"root"}}))
+
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedError != "" {
+				var res response.Problem
+				assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+				assert.Equal(t, response.ProblemContentType, rr.Header().Get("Content-Type"))
+				assert.Equal(t, tt.expectedError, res.Detail)
+				assert.Equal(t, tt.expectedErrors, res.Errors)
+				return
+			}
+
+			var user models.User
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &user))
+			assert.Equal(t, "DE", user.Country)
+			assert.Equal(t, `"2"`, rr.Header().Get("ETag"))
+		})
+	}
+}
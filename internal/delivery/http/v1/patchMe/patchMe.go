@@ -0,0 +1,97 @@
+package patchMe
+
+import (
+	"backend-app/internal/country"
+	"backend-app/internal/delivery/http/dto"
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/profile"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"backend-app/pkg/api/validate"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Updater interface {
+	GetUserByID(ctx context.Context, id uint) (*models.User, error)
+	profile.Store
+}
+
+// New godoc
+// @Summary Update my profile
+// @Description Changes the profile of the current user. Absent fields stay as they are, empty strings and lists clear them. In attributes null removes a value. Only attributes marked userEditable can be changed here, the rest are changed by administrators
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "Language of country names, English by default"
+// @Param input body profile.Patch true "Profile changes"
+// @Success 200 {object} dto.Me
+// @Failure 400 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/me [patch]
+func New(log *slog.Logger, updater Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.PatchMe"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var patch profile.Patch
+		if err := render.DecodeJSON(r.Body, &patch); err != nil {
+			log.Error("failed to decode request body", "error", err)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidBody, "invalid request body")
+			return
+		}
+		if err := validate.New().Struct(patch); err != nil {
+			log.Info("validation failed", "error", err)
+			response.WriteProblem(w, r, response.ValidationFailed(err))
+			return
+		}
+
+		id := authMiddleware.UserID(r.Context())
+		user, err := updater.GetUserByID(r.Context(), id)
+		var p *models.Profile
+		if err == nil {
+			p, err = profile.Update(r.Context(), updater, id, patch, false)
+		}
+		var attrErr *profile.AttributeError
+		if errors.As(err, &attrErr) {
+			log.Info("invalid attribute", "error", err)
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidAttribute, err.Error())
+			return
+		}
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Info("user not found", "id", id)
+			response.Fail(w, r, http.StatusNotFound, response.CodeUserNotFound, "user not found")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to update profile", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to update profile")
+			return
+		}
+
+		log.Info("profile updated", "id", id)
+		render.Status(r, http.StatusOK)
+		lang := country.Language(r.Header.Get("Accept-Language"))
+		render.JSON(w, r, dto.Me{User: dto.NewUser(user).Localize(lang), Profile: dto.NewProfile(p)})
+	}
+}
@@ -0,0 +1,152 @@
+package patchMe_test
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/delivery/http/v1/patchMe"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockStorage struct {
+	err   error
+	saved *models.Profile
+}
+
+func (m *mockStorage) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.User{ID: id, Username: "alice"}, nil
+}
+
+func (m *mockStorage) GetProfile(ctx context.Context, userID uint) (*models.Profile, error) {
+	return &models.Profile{UserID: userID, Bio: "hello", Attributes: map[string]interface{}{"team": "core"}}, nil
+}
+
+func (m *mockStorage) SaveProfile(ctx context.Context, profile *models.Profile) error {
+	m.saved = profile
+	return nil
+}
+
+func (m *mockStorage) ListAttributeDefinitions(ctx context.Context) ([]models.AttributeDefinition, error) {
+	return []models.AttributeDefinition{
+		{Key: "team", Type: models.AttributeString},
+		{Key: "tshirt", Type: models.AttributeEnum, Enum: "S,M,L", UserEditable: true},
+	}, nil
+}
+
+func TestPatchMeHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		err            error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "invalid_body",
+			body:           "{",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid request body",
+		},
+		{
+			name:           "invalid_time_zone",
+			body:           `{"timeZone":"Mars/Olympus"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   "validation failed",
+		},
+		{
+			name:           "invalid_level",
+			body:           `{"learningLanguages":[{"language":"de","level":"native"}]}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   "validation failed",
+		},
+		{
+			name:           "admin_attribute",
+			body:           `{"attributes":{"team":"other"}}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `attribute "team": can be changed only by an administrator`,
+		},
+		{
+			name:           "unknown_attribute",
+			body:           `{"attributes":{"shoe_size":42}}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `attribute "shoe_size": unknown attribute`,
+		},
+		{
+			name:           "user_not_found",
+			body:           `{}`,
+			err:            storage.ErrUserNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "user not found",
+		},
+		{
+			name:           "storage_error",
+			body:           `{}`,
+			err:            errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to update profile",
+		},
+		{
+			name: "success",
+			body: `{"displayName":"Alice","timeZone":"Europe/Berlin","nativeLanguages":["ru"],` +
+				`"learningLanguages":[{"language":"de","level":"B1"}],"attributes":{"tshirt":"M"}}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	_, token, err := authMiddleware.AccessTokenAuth.Encode(map[string]interface{}{"user_id": 4, "role": "user"})
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockStorage{err: tt.err}
+			router := chi.NewRouter()
+			router.Use(middleware.RequestID)
+			router.Use(jwtauth.Verifier(authMiddleware.AccessTokenAuth))
+			router.Patch("/me", patchMe.New(slog.Default(), m))
+
+			req := httptest.NewRequest(http.MethodPatch, "/me", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				var res response.Problem
+				_ = render.DecodeJSON(rr.Body, &res)
+				assert.Equal(t, tt.expectedBody, res.Detail)
+				assert.Nil(t, m.saved)
+				return
+			}
+
+			require.NotNil(t, m.saved)
+			assert.Equal(t, uint(4), m.saved.UserID, "the profile of the token's user is changed")
+			var res dto.Me
+			require.NoError(t, render.DecodeJSON(rr.Body, &res))
+			assert.Equal(t, "alice", res.Username)
+			assert.Equal(t, "Alice", res.Profile.DisplayName)
+			assert.Equal(t, "hello", res.Profile.Bio, "absent fields stay")
+			assert.Equal(t, "Europe/Berlin", res.Profile.TimeZone)
+			assert.Equal(t, []string{"ru"}, res.Profile.NativeLanguages)
+			assert.Equal(t, []models.LanguageSkill{{Language: "de", Level: "B1"}}, res.Profile.LearningLanguages)
+			assert.Equal(t, map[string]interface{}{"team": "core", "tshirt": "M"}, res.Profile.Attributes)
+		})
+	}
+}
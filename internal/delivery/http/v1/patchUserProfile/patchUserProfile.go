@@ -0,0 +1,108 @@
+package patchUserProfile
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/delivery/http/tenant"
+	"backend-app/internal/profile"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"backend-app/pkg/api/validate"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Updater interface {
+	tenant.Users
+	profile.Store
+}
+
+// New godoc
+// @Summary Update user profile
+// @Description Changes the profile of a user the same way as PATCH /v1/me, but any attribute of the schema can be changed. Organization admins change only members of their organization who are in no other organization
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param input body profile.Patch true "Profile changes"
+// @Success 200 {object} dto.Profile
+// @Failure 400 {object} response.Problem
+// @Failure 403 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/user/{id}/profile [patch]
+func New(log *slog.Logger, updater Updater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.PatchUserProfile"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idParam := chi.URLParam(r, "id")
+		id, err := strconv.ParseUint(idParam, 10, 32)
+		if err != nil {
+			log.Info("invalid user id", "param", idParam)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid user id")
+			return
+		}
+
+		var patch profile.Patch
+		if err := render.DecodeJSON(r.Body, &patch); err != nil {
+			log.Error("failed to decode request body", "error", err)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidBody, "invalid request body")
+			return
+		}
+		if err := validate.New().Struct(patch); err != nil {
+			log.Info("validation failed", "error", err)
+			response.WriteProblem(w, r, response.ValidationFailed(err))
+			return
+		}
+
+		var p *models.Profile
+		err = tenant.CheckWrite(r.Context(), updater, uint(id), "")
+		if err == nil {
+			p, err = profile.Update(r.Context(), updater, uint(id), patch, true)
+		}
+		var attrErr *profile.AttributeError
+		if errors.As(err, &attrErr) {
+			log.Info("invalid attribute", "error", err)
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidAttribute, err.Error())
+			return
+		}
+		if errors.Is(err, tenant.ErrForbidden) {
+			log.Info("forbidden for this admin", "id", id)
+			response.Fail(w, r, http.StatusForbidden, response.CodeForbidden, err.Error())
+			return
+		}
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Info("user not found", "id", id)
+			response.Fail(w, r, http.StatusNotFound, response.CodeUserNotFound, "user not found")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to update profile", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to update profile")
+			return
+		}
+
+		log.Info("profile updated", "id", id)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, dto.NewProfile(p))
+	}
+}
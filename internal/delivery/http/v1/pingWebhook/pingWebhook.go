@@ -0,0 +1,93 @@
+package pingWebhook
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/internal/webhooks"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Getter interface {
+	GetWebhook(ctx context.Context, id uint) (*models.Webhook, error)
+}
+
+// Result - итог тестовой отправки.
+type Result struct {
+	Delivered      bool   `json:"delivered"`
+	ResponseStatus int    `json:"responseStatus,omitempty"`
+	Error          string `json:"error,omitempty"`
+	DurationMs     int64  `json:"durationMs"`
+}
+
+// New godoc
+// @Summary Ping webhook
+// @Description Synchronously sends a signed webhook.ping event to the webhook, even if it is disabled, and reports the result. The ping is not written to the delivery log
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} pingWebhook.Result
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/webhooks/{id}/ping [post]
+func New(log *slog.Logger, getter Getter, client *http.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.PingWebhook"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			log.Info("invalid webhook id", "param", idStr)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid webhook id")
+			return
+		}
+
+		hook, err := getter.GetWebhook(r.Context(), uint(id))
+		if errors.Is(err, storage.ErrWebhookNotFound) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeWebhookNotFound, "webhook not found")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to get webhook", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to get webhook")
+			return
+		}
+
+		start := time.Now()
+		status, err := webhooks.Ping(r.Context(), client, hook)
+		res := Result{
+			Delivered:      err == nil,
+			ResponseStatus: status,
+			DurationMs:     time.Since(start).Milliseconds(),
+		}
+		if err != nil {
+			res.Error = err.Error()
+		}
+
+		log.Info("webhook pinged", "id", id, "delivered", res.Delivered, "response_status", status)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, res)
+	}
+}
@@ -0,0 +1,80 @@
+package pingWebhook_test
+
+import (
+	"backend-app/internal/delivery/http/v1/pingWebhook"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/internal/webhooks"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockGetter struct {
+	hook *models.Webhook
+}
+
+func (m *mockGetter) GetWebhook(ctx context.Context, id uint) (*models.Webhook, error) {
+	if m.hook == nil || m.hook.ID != id {
+		return nil, storage.ErrWebhookNotFound
+	}
+	return m.hook, nil
+}
+
+func TestPingWebhookHandler(t *testing.T) {
+	var event string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event = r.Header.Get(webhooks.HeaderEvent)
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer receiver.Close()
+
+	tests := []struct {
+		name           string
+		id             string
+		hookURL        string
+		expectedStatus int
+		wantDelivered  bool
+		wantResponse   int
+	}{
+		{"invalid_id", "abc", receiver.URL, http.StatusBadRequest, false, 0},
+		{"not_found", "2", receiver.URL, http.StatusNotFound, false, 0},
+		{"delivered", "1", receiver.URL, http.StatusOK, true, http.StatusOK},
+		{"receiver_error", "1", receiver.URL + "/broken", http.StatusOK, false, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getter := &mockGetter{hook: &models.Webhook{ID: 1, URL: tt.hookURL, Secret: "secret"}}
+			router := chi.NewRouter()
+			router.Use(middleware.RequestID)
+			router.Post("/webhooks/{id}/ping", pingWebhook.New(slog.Default(), getter, receiver.Client()))
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks/"+tt.id+"/ping", nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if rr.Code != http.StatusOK {
+				return
+			}
+
+			var res pingWebhook.Result
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+			assert.Equal(t, tt.wantDelivered, res.Delivered)
+			assert.Equal(t, tt.wantResponse, res.ResponseStatus)
+			assert.Equal(t, !tt.wantDelivered, res.Error != "")
+			assert.Equal(t, webhooks.TypePing, event)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package redeliverWebhook
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Redeliverer interface {
+	GetDelivery(ctx context.Context, id uint) (*models.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+}
+
+// New godoc
+// @Summary Redeliver webhook delivery
+// @Description Puts a delivered or dead delivery back in the queue with a fresh set of attempts. The payload and event ID stay the same, so receivers can deduplicate it
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param deliveryId path int true "Delivery ID"
+// @Success 202 {object} dto.WebhookDelivery
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 409 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+func New(log *slog.Logger, redeliverer Redeliverer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.RedeliverWebhook"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		webhookID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+		if err != nil {
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid webhook id")
+			return
+		}
+		deliveryID, err := strconv.ParseUint(chi.URLParam(r, "deliveryId"), 10, 32)
+		if err != nil {
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid delivery id")
+			return
+		}
+
+		delivery, err := redeliverer.GetDelivery(r.Context(), uint(deliveryID))
+		if err == nil && delivery.WebhookID != uint(webhookID) {
+			err = storage.ErrDeliveryNotFound
+		}
+		if err == nil {
+			if delivery.Status == models.DeliveryPending {
+				response.Fail(w, r, http.StatusConflict, response.CodeDeliveryPending, "delivery is already pending")
+				return
+			}
+			delivery.Status = models.DeliveryPending
+			delivery.Attempts = 0
+			delivery.NextAttemptAt = time.Now()
+			err = redeliverer.UpdateDelivery(r.Context(), delivery)
+		}
+		if errors.Is(err, storage.ErrDeliveryNotFound) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeDeliveryNotFound, "delivery not found")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to redeliver", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to redeliver")
+			return
+		}
+
+		log.Info("delivery queued again", "webhook_id", webhookID, "delivery_id", deliveryID)
+		render.Status(r, http.StatusAccepted)
+		render.JSON(w, r, dto.NewWebhookDelivery(delivery))
+	}
+}
@@ -0,0 +1,127 @@
+package redeliverWebhook_test
+
+import (
+	"backend-app/internal/delivery/http/v1/redeliverWebhook"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockRedeliverer struct {
+	delivery  *models.WebhookDelivery
+	getErr    error
+	updateErr error
+	updated   *models.WebhookDelivery
+}
+
+func (m *mockRedeliverer) GetDelivery(ctx context.Context, id uint) (*models.WebhookDelivery, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	d := *m.delivery
+	return &d, nil
+}
+
+func (m *mockRedeliverer) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	m.updated = delivery
+	return m.updateErr
+}
+
+func TestRedeliverWebhookHandler(t *testing.T) {
+	dead := &models.WebhookDelivery{ID: 5, WebhookID: 1, Payload: `{}`, Status: models.DeliveryDead, Attempts: 10}
+
+	tests := []struct {
+		name           string
+		url            string
+		delivery       *models.WebhookDelivery
+		getErr         error
+		updateErr      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "invalid_webhook_id",
+			url:            "/webhooks/abc/deliveries/5/redeliver",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid webhook id",
+		},
+		{
+			name:           "invalid_delivery_id",
+			url:            "/webhooks/1/deliveries/abc/redeliver",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid delivery id",
+		},
+		{
+			name:           "not_found",
+			url:            "/webhooks/1/deliveries/5/redeliver",
+			getErr:         storage.ErrDeliveryNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "delivery not found",
+		},
+		{
+			name:           "other_webhook",
+			url:            "/webhooks/2/deliveries/5/redeliver",
+			delivery:       dead,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "delivery not found",
+		},
+		{
+			name:           "already_pending",
+			url:            "/webhooks/1/deliveries/5/redeliver",
+			delivery:       &models.WebhookDelivery{ID: 5, WebhookID: 1, Status: models.DeliveryPending},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "delivery is already pending",
+		},
+		{
+			name:           "update_error",
+			url:            "/webhooks/1/deliveries/5/redeliver",
+			delivery:       dead,
+			updateErr:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to redeliver",
+		},
+		{
+			name:           "success",
+			url:            "/webhooks/1/deliveries/5/redeliver",
+			delivery:       dead,
+			expectedStatus: http.StatusAccepted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockRedeliverer{delivery: tt.delivery, getErr: tt.getErr, updateErr: tt.updateErr}
+			router := chi.NewRouter()
+			router.Use(middleware.RequestID)
+			router.Post("/webhooks/{id}/deliveries/{deliveryId}/redeliver", redeliverWebhook.New(slog.Default(), m))
+
+			req := httptest.NewRequest(http.MethodPost, tt.url, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				var res response.Problem
+				_ = render.DecodeJSON(rr.Body, &res)
+				assert.Equal(t, tt.expectedBody, res.Detail)
+				return
+			}
+
+			if assert.NotNil(t, m.updated) {
+				assert.Equal(t, models.DeliveryPending, m.updated.Status)
+				assert.Zero(t, m.updated.Attempts, "redelivery gets a fresh set of attempts")
+			}
+		})
+	}
+}
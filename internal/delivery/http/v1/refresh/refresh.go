@@ -2,10 +2,16 @@ package refresh
 
 import (
 	"backend-app/internal/config"
-	"backend-app/internal/storage/postgres"
+	"backend-app/internal/delivery/http/cookie"
+	"backend-app/internal/delivery/http/tenant"
+	"backend-app/internal/permissions"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
 	"backend-app/pkg/api/response"
 	"backend-app/pkg/jwt/generator"
 	"backend-app/pkg/sl"
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"time"
@@ -14,31 +20,50 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+type UserProvider interface {
+	GetUserByID(ctx context.Context, id uint) (*models.User, error)
+	SetRefreshToken(ctx context.Context, id uint, token string, expiry time.Time) error
+	tenant.Members
+	permissions.Groups
+}
+
 type RefreshRequest struct {
 	RefreshToken string `json:"refresh_token"`
+	// OrgID переключает токены на другую организацию пользователя, без него остаётся
+	// организация из refresh token
+	OrgID *uint `json:"org_id,omitempty"`
 }
 
 // New godoc
 // @Summary Refresh token pair
-// @Description Generates new access and refresh tokens using valid refresh token
+// @Description Generates new access and refresh tokens using valid refresh token. The tokens keep the organization of the refresh token unless org_id switches to another organization of the user. Role and permissions are read again, so changes of groups apply from here on. In cookie mode the refresh token is read from the cookie and the body holds cookie.TokenResponse
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param input body refresh.RefreshRequest true "Refresh token"
 // @Success 200 {object} config.TokenPair
-// @Failure 400 {object} response.Response
-// @Failure 401 {object} response.Response
-// @Failure 422 {object} response.Response
-// @Failure 500 {object} response.Response
+// @Failure 400 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 403 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
 // @Router /v1/refresh [post]
-func New(log *slog.Logger, storage *postgres.Storage) http.HandlerFunc {
+func New(log *slog.Logger, users UserProvider, cookieCfg config.Cookie) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
 		var request RefreshRequest
-		if err := render.DecodeJSON(r.Body, &request); err != nil {
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, map[string]string{"error": "Invalid request"})
-			return
+		// в режиме cookie браузер присылает refresh token сам, тело запроса пустое
+		if cookieCfg.Enabled {
+			request.RefreshToken = cookie.RefreshTokenFromCookie(r)
+		}
+		// в режиме cookie тело может прийти ради org_id
+		if request.RefreshToken == "" || r.ContentLength > 0 {
+			if err := render.DecodeJSON(r.Body, &request); err != nil {
+				response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidBody, "Invalid request")
+				return
+			}
 		}
 
 		// Парсим refresh token
@@ -47,46 +72,92 @@ func New(log *slog.Logger, storage *postgres.Storage) http.HandlerFunc {
 		})
 
 		if err != nil || !token.Valid {
-			render.Status(r, http.StatusUnauthorized)
-			render.JSON(w, r, map[string]string{"error": "Invalid refresh token"})
+			response.Fail(w, r, http.StatusUnauthorized, response.CodeInvalidRefreshToken, "Invalid refresh token")
 			return
 		}
 
 		claims, ok := token.Claims.(*config.Claims)
 		if !ok {
-			render.Status(r, http.StatusUnauthorized)
-			render.JSON(w, r, map[string]string{"error": "Invalid token claims"})
+			response.Fail(w, r, http.StatusUnauthorized, response.CodeInvalidRefreshToken, "Invalid token claims")
 			return
 		}
 
-		tokenPair, err := generator.GenerateTokenPair(claims.UserID, claims.Role)
+		// на реплике может не оказаться только что выданного refresh token
+		user, err := users.GetUserByID(storage.WithPrimary(r.Context()), claims.UserID)
 		if err != nil {
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, map[string]string{"error": "Could not generate tokens"})
+			log.Error("err", sl.Error(err))
+			if problem, ok := response.ContextError(err); ok {
+				response.WriteProblem(w, r, problem)
+				return
+			}
+			if errors.Is(err, storage.ErrUserNotFound) {
+				response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeUserNotFound, err.Error())
+				return
+			}
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "Could not generate tokens")
+			return
+		}
+
+		if request.RefreshToken != user.RefreshToken {
+			log.Error("refresh token does not match stored one", slog.Uint64("user_id", uint64(user.ID)))
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidRefreshToken, "invalid refresh token")
+			return
+		}
+
+		if claims.TokenVersion != user.TokenVersion || user.Status == models.StatusSuspended {
+			log.Info("refresh token revoked", slog.Uint64("user_id", uint64(user.ID)))
+			response.Fail(w, r, http.StatusUnauthorized, response.CodeTokenRevoked, "token revoked")
+			return
+		}
+
+		orgID := claims.OrgID
+		if request.OrgID != nil {
+			orgID = *request.OrgID
+		}
+		// роль берём из базы, а не из токена: её могли поменять после выдачи
+		role, err := tenant.TokenRole(r.Context(), users, user, orgID)
+		var grants []permissions.Grant
+		if err == nil {
+			grants, err = permissions.Effective(r.Context(), users, user.ID, role)
+		}
+		if errors.Is(err, storage.ErrMembershipNotFound) {
+			response.Fail(w, r, http.StatusForbidden, response.CodeNotMember, "user is not a member of the organization")
 			return
 		}
-		user, err := storage.GetUserByID(claims.UserID)
 		if err != nil {
 			log.Error("err", sl.Error(err))
-			render.Status(r, http.StatusUnprocessableEntity)
-			render.JSON(w, r, response.Error(err.Error()))
+			if problem, ok := response.ContextError(err); ok {
+				response.WriteProblem(w, r, problem)
+				return
+			}
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "Could not generate tokens")
 			return
 		}
 
-		if request.RefreshToken != user.RefreshToken {
-			log.Error("err", "refresh token закончился хули")
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, response.Error("invalid refresh token"))
+		tokenPair, err := generator.GenerateTokenPair(user.ID, role, orgID, user.TokenVersion, permissions.Names(grants))
+		if err != nil {
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "Could not generate tokens")
 			return
 		}
 
-		user.RefreshToken = tokenPair.RefreshToken
-		user.TokenExpiry = time.Now().Add(config.RefreshTokenExpiry)
-
-		if err := storage.UpdateUser(user); err != nil {
+		if err := users.SetRefreshToken(r.Context(), user.ID, tokenPair.RefreshToken, time.Now().Add(config.RefreshTokenExpiry)); err != nil {
 			log.Error("err", sl.Error(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.Error(err.Error()))
+			if problem, ok := response.ContextError(err); ok {
+				response.WriteProblem(w, r, problem)
+				return
+			}
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "Could not generate tokens")
+			return
+		}
+
+		if cookieCfg.Enabled {
+			resp, err := cookie.SetTokens(w, cookieCfg, tokenPair)
+			if err != nil {
+				log.Error("failed to set cookies", sl.Error(err))
+				response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "Could not generate tokens")
+				return
+			}
+			render.JSON(w, r, resp)
 			return
 		}
 		render.JSON(w, r, tokenPair)
@@ -1,34 +1,61 @@
 package register
 
 import (
+	"backend-app/internal/account"
+	"backend-app/internal/country"
+	"backend-app/internal/storage"
 	"backend-app/internal/storage/models"
 	"backend-app/pkg/api/response"
+	"backend-app/pkg/api/validate"
+	"context"
 	"errors"
 	"log/slog"
 	"net/http"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
-	validator2 "github.com/go-playground/validator/v10"
-	"gorm.io/gorm"
 )
 
 type Saver interface {
-	CreateUser(user *models.User) error
+	CreateUser(ctx context.Context, user *models.User) error
+}
+
+// Request - данные для регистрации, пароль хешируется перед сохранением.
+type Request struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required,max=72"`
+	Email    string `json:"email" validate:"required,email"`
+	Role     string `json:"role" validate:"required,oneof=user creator combined admin"`
+	// Country - код ISO 3166-1 alpha-2, alpha-3, числовой код или английское название,
+	// сохраняется кодом alpha-2
+	Country string `json:"country"`
+}
+
+func (req Request) toModel() models.User {
+	return models.User{
+		Username: req.Username,
+		Password: req.Password,
+		Email:    req.Email,
+		Role:     req.Role,
+		Country:  req.Country,
+	}
 }
 
 // New godoc
 // @Summary Register new user
-// @Description Create user account
+// @Description Create user account. The country may be given as an ISO 3166-1 alpha-2, alpha-3 or numeric code or as an English name and is stored as alpha-2. Usernames and emails are unique regardless of case; reserved usernames and usernames with spaces, control characters or '@' are rejected with 422. Registration from countries outside the configured allow list or in the deny list is rejected with 403
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param input body models.User true "User data"
+// @Param input body register.Request true "User data"
 // @Success 201 {object} response.Response
-// @Failure 422 {object} response.Response
-// @Failure 409 {object} response.Response
+// @Failure 403 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 409 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
 // @Router /v1/register [post]
-func New(log *slog.Logger, saver Saver) http.HandlerFunc {
+func New(log *slog.Logger, saver Saver, countries country.Policy, accounts account.Policy) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.v1.New"
 		response.OK()
@@ -37,40 +64,61 @@ func New(log *slog.Logger, saver Saver) http.HandlerFunc {
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
-		var req models.User
+		var req Request
 		err := render.DecodeJSON(r.Body, &req)
 		if err != nil {
 			log.Error("failed to decode body", "error", err)
-			render.Status(r, http.StatusUnprocessableEntity)
-			render.JSON(w, r, response.Error("failed to decode body"))
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidBody, "failed to decode body")
 			return
 		}
-		log.Info("request body decoded", slog.Any("body", req))
+		log.Info("request body decoded", slog.String("username", req.Username))
 
-		if err := validator2.New().Struct(req); err != nil {
+		if err := validate.New().Struct(req); err != nil {
 			log.Error("failed to validate body", "error", err)
-			render.Status(r, http.StatusUnprocessableEntity)
-			render.JSON(w, r, response.Error("failed to validate body"))
+			response.WriteProblem(w, r, response.ValidationFailed(err))
+			return
+		}
+		if err := accounts.CheckUsername(req.Username); err != nil {
+			log.Info("username rejected", slog.String("username", req.Username), "error", err)
+			code := response.CodeInvalidUsername
+			if errors.Is(err, account.ErrReservedUsername) {
+				code = response.CodeReservedUsername
+			}
+			response.Fail(w, r, http.StatusUnprocessableEntity, code, err.Error())
 			return
 		}
-		if err := req.HashPassword(); err != nil {
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, map[string]string{"error": "Could not hash password"})
+		user := req.toModel()
+		user.Country, err = country.Normalize(req.Country)
+		if err != nil {
+			log.Info("unknown country", slog.String("country", req.Country))
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeUnknownCountry, err.Error())
+			return
+		}
+		if err := countries.Check(user.Country); err != nil {
+			log.Info("country not allowed", slog.String("country", user.Country))
+			response.Fail(w, r, http.StatusForbidden, response.CodeCountryNotAllowed, err.Error())
+			return
+		}
+		if err := user.HashPassword(); err != nil {
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "Could not hash password")
 			return
 		}
 
-		err = saver.CreateUser(&req)
-		if errors.Is(err, gorm.ErrDuplicatedKey) {
+		err = saver.CreateUser(r.Context(), &user)
+		if errors.Is(err, storage.ErrUserExists) {
 			log.Info("user already exists", "error", err)
-			render.Status(r, http.StatusConflict)
-			render.JSON(w, r, response.Error("user already exists"))
+			response.Fail(w, r, http.StatusConflict, response.CodeUserExists, "user already exists")
 
 			return
 		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
 		if err != nil {
 			log.Error("failed to create user", "error", err)
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.Error("failed to create user"))
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to create user")
 			return
 		}
 
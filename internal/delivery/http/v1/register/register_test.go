@@ -0,0 +1,138 @@
+package register_test
+
+import (
+	"backend-app/internal/account"
+	"backend-app/internal/country"
+	"backend-app/internal/delivery/http/v1/register"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSaver struct {
+	err   error
+	saved *models.User
+}
+
+func (m *mockSaver) CreateUser(ctx context.Context, user *models.User) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.saved = user
+	return nil
+}
+
+func TestRegisterHandler(t *testing.T) {
+	tests := []struct {
+		name            string
+		username        string
+		country         string
+		policy          country.Policy
+		err             error
+		expectedStatus  int
+		expectedBody    string
+		expectedCountry string
+	}{
+		{
+			name:            "alpha2",
+			country:         "nl",
+			expectedStatus:  http.StatusCreated,
+			expectedCountry: "NL",
+		},
+		{
+			name:            "english_name",
+			country:         "Norway",
+			expectedStatus:  http.StatusCreated,
+			expectedCountry: "NO",
+		},
+		{
+			name:           "without_country",
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "unknown_country",
+			country:        "Atlantis",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   country.ErrUnknown.Error(),
+		},
+		{
+			name:           "denied",
+			country:        "PRK",
+			policy:         country.Policy{Deny: []string{"KP"}},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   country.ErrNotAllowed.Error(),
+		},
+		{
+			name:           "not_allowed",
+			country:        "DE",
+			policy:         country.Policy{Allow: []string{"NL", "BE"}},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   country.ErrNotAllowed.Error(),
+		},
+		{
+			name:           "allow_list_requires_country",
+			policy:         country.Policy{Allow: []string{"NL"}},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   country.ErrNotAllowed.Error(),
+		},
+		{
+			name:           "reserved_username",
+			username:       "Admin",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   account.ErrReservedUsername.Error(),
+		},
+		{
+			name:           "username_with_at",
+			username:       "alice@example.com",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   account.ErrInvalidUsername.Error(),
+		},
+		{
+			name:           "exists",
+			country:        "NL",
+			err:            storage.ErrUserExists,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "user already exists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockSaver{err: tt.err}
+			router := chi.NewRouter()
+			router.Use(middleware.RequestID)
+			router.Post("/register", register.New(slog.Default(), m, tt.policy, account.Policy{Reserved: []string{"admin"}}))
+
+			username := tt.username
+			if username == "" {
+				username = "alice"
+			}
+			body := `{"username":"` + username + `","password":"secret123","email":"alice@example.com","role":"user","country":"` + tt.country + `"}`
+			req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(body))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				var res response.Problem
+				_ = render.DecodeJSON(rr.Body, &res)
+				assert.Equal(t, tt.expectedBody, res.Detail)
+				return
+			}
+			require.NotNil(t, m.saved)
+			assert.Equal(t, tt.expectedCountry, m.saved.Country)
+		})
+	}
+}
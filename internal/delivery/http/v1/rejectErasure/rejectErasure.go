@@ -0,0 +1,100 @@
+package rejectErasure
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"backend-app/pkg/api/validate"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Rejecter interface {
+	RejectErasureRequest(ctx context.Context, id uint, reviewedBy uint, note string) (*models.ErasureRequest, error)
+}
+
+type Request struct {
+	// Note - причина отказа, её увидит пользователь
+	Note string `json:"note" validate:"required,max=500"`
+}
+
+// New godoc
+// @Summary Reject erasure
+// @Description Rejects a pending erasure request. The user may file a new one afterwards
+// @Tags privacy
+// @Accept json
+// @Produce json
+// @Param id path int true "Erasure request ID"
+// @Param input body rejectErasure.Request true "Reason for rejection"
+// @Success 200 {object} dto.ErasureRequest
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 409 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/erasure-requests/{id}/reject [post]
+func New(log *slog.Logger, rejecter Rejecter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.RejectErasure"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			log.Info("invalid erasure request id", "param", idStr)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid erasure request id")
+			return
+		}
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", "error", err)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidBody, "invalid request body")
+			return
+		}
+		if err := validate.New().Struct(req); err != nil {
+			log.Info("validation failed", "error", err)
+			response.WriteProblem(w, r, response.ValidationFailed(err))
+			return
+		}
+
+		erasure, err := rejecter.RejectErasureRequest(r.Context(), uint(id), authMiddleware.UserID(r.Context()), req.Note)
+		if errors.Is(err, storage.ErrErasureRequestNotFound) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeErasureNotFound, "erasure request not found")
+			return
+		}
+		if errors.Is(err, storage.ErrErasureNotPending) {
+			response.Fail(w, r, http.StatusConflict, response.CodeErasureNotPending, "erasure request is not pending")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to reject erasure request", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to reject erasure request")
+			return
+		}
+
+		log.Info("erasure request rejected", "id", erasure.ID, "user_id", erasure.UserID)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, dto.NewErasureRequest(erasure))
+	}
+}
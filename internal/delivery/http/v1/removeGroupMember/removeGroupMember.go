@@ -0,0 +1,79 @@
+package removeGroupMember
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Remover interface {
+	RemoveGroupMember(ctx context.Context, groupID uint, userID uint) error
+}
+
+// New godoc
+// @Summary Remove group member
+// @Description Removes a user from a group. Permissions the user had through it stay in tokens issued before
+// @Tags groups
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param userId path int true "User ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/groups/{id}/members/{userId} [delete]
+func New(log *slog.Logger, remover Remover) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.RemoveGroupMember"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		groupStr := chi.URLParam(r, "id")
+		groupID, err := strconv.ParseUint(groupStr, 10, 32)
+		if err != nil {
+			log.Info("invalid group id", "param", groupStr)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid group id")
+			return
+		}
+		userStr := chi.URLParam(r, "userId")
+		userID, err := strconv.ParseUint(userStr, 10, 32)
+		if err != nil {
+			log.Info("invalid user id", "param", userStr)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid user id")
+			return
+		}
+
+		err = remover.RemoveGroupMember(r.Context(), uint(groupID), uint(userID))
+		if errors.Is(err, storage.ErrGroupMemberNotFound) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeGroupMemberNotFound, err.Error())
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to remove group member", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to remove group member")
+			return
+		}
+
+		log.Info("group member removed", "group_id", groupID, "user_id", userID)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, response.OK())
+	}
+}
@@ -0,0 +1,79 @@
+package removeMember
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Remover interface {
+	RemoveMembership(ctx context.Context, orgID uint, userID uint) error
+}
+
+// New godoc
+// @Summary Remove organization member
+// @Description Removes a user from an organization and revokes their tokens. The user account itself stays
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param userId path int true "User ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/orgs/{id}/members/{userId} [delete]
+func New(log *slog.Logger, remover Remover) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.RemoveMember"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		orgStr := chi.URLParam(r, "id")
+		orgID, err := strconv.ParseUint(orgStr, 10, 32)
+		if err != nil {
+			log.Info("invalid organization id", "param", orgStr)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid organization id")
+			return
+		}
+		userStr := chi.URLParam(r, "userId")
+		userID, err := strconv.ParseUint(userStr, 10, 32)
+		if err != nil {
+			log.Info("invalid user id", "param", userStr)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid user id")
+			return
+		}
+
+		err = remover.RemoveMembership(r.Context(), uint(orgID), uint(userID))
+		if errors.Is(err, storage.ErrMembershipNotFound) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeMembershipNotFound, err.Error())
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to remove membership", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to remove membership")
+			return
+		}
+
+		log.Info("membership removed", "org_id", orgID, "user_id", userID)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, response.OK())
+	}
+}
@@ -0,0 +1,170 @@
+package requestEmailChange
+
+import (
+	"backend-app/internal/account"
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/events"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"backend-app/pkg/api/validate"
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Requester interface {
+	GetUserByID(ctx context.Context, id uint) (*models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	SaveEmailChange(ctx context.Context, change *models.EmailChange) error
+	DeleteEmailChange(ctx context.Context, userID uint) error
+	AddEvents(ctx context.Context, events []models.OutboxEvent) error
+}
+
+// Request - новый email и текущий пароль для подтверждения.
+type Request struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+// Response - запрос смены email принят и ждёт подтверждения до ExpiresAt.
+type Response struct {
+	NewEmail  string    `json:"newEmail"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// New godoc
+// @Summary Request email change
+// @Description Starts a change of the email of the current user. The email stays the same until the new address is confirmed with POST /v1/email/confirm. The confirmation token is sent to the new address by the configured mail service, without one the answer is 503. A new request replaces the previous one. After a change the next one is possible only after the configured cooldown, until then the answer is 429 with Retry-After
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param input body requestEmailChange.Request true "New email and current password"
+// @Success 202 {object} requestEmailChange.Response
+// @Failure 400 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 403 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 409 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 429 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/me/email [post]
+func New(log *slog.Logger, requester Requester, policy account.Policy, mailer account.Mailer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.RequestEmailChange"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", "error", err)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidBody, "invalid request body")
+			return
+		}
+		if err := validate.New().Struct(req); err != nil {
+			log.Info("validation failed", "error", err)
+			response.WriteProblem(w, r, response.ValidationFailed(err))
+			return
+		}
+		newEmail := models.NormalizeEmail(req.Email)
+
+		id := authMiddleware.UserID(r.Context())
+		user, err := requester.GetUserByID(r.Context(), id)
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Info("user not found", "id", id)
+			response.Fail(w, r, http.StatusNotFound, response.CodeUserNotFound, "user not found")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to get user", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to request email change")
+			return
+		}
+		if err := user.CheckPassword(req.Password); err != nil {
+			log.Info("wrong password", "id", id)
+			response.Fail(w, r, http.StatusForbidden, response.CodeInvalidPassword, "invalid password")
+			return
+		}
+		if next := account.NextChange(user.EmailChangedAt, policy.EmailCooldown); time.Now().Before(next) {
+			log.Info("email changed too recently", "id", id)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(time.Until(next).Seconds()))))
+			response.Fail(w, r, http.StatusTooManyRequests, response.CodeChangeCooldown, "email was changed recently")
+			return
+		}
+		if newEmail == models.NormalizeEmail(user.Email) {
+			log.Info("email not changed", "id", id)
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeEmailUnchanged, "new email is the same as the current one")
+			return
+		}
+
+		// занятость проверяется и при подтверждении, здесь - чтобы не слать письмо зря
+		owner, err := requester.GetUserByEmail(r.Context(), newEmail)
+		if err == nil && owner.ID != id {
+			log.Info("email already taken", "id", id)
+			response.Fail(w, r, http.StatusConflict, response.CodeEmailTaken, "email already taken")
+			return
+		}
+		if errors.Is(err, storage.ErrUserNotFound) {
+			err = nil
+		}
+		token, hash := account.NewToken()
+		change := &models.EmailChange{
+			UserID:    id,
+			NewEmail:  newEmail,
+			TokenHash: hash,
+			ExpiresAt: time.Now().Add(policy.ConfirmationTTL),
+		}
+		if err == nil {
+			err = requester.SaveEmailChange(r.Context(), change)
+		}
+		if err == nil {
+			err = mailer.SendEmailConfirmation(r.Context(), newEmail, token, change.ExpiresAt)
+			// без письма запрос не подтвердить, а висящий запрос выглядел бы отправленным
+			if err != nil {
+				if err := requester.DeleteEmailChange(context.WithoutCancel(r.Context()), id); err != nil && !errors.Is(err, storage.ErrEmailChangeNotFound) {
+					log.Error("failed to delete unsent email change", "error", err)
+				}
+			}
+		}
+		if errors.Is(err, account.ErrMailerDisabled) {
+			log.Error("mailer is not configured")
+			response.Fail(w, r, http.StatusServiceUnavailable, response.CodeMailerUnavailable, "email change is unavailable")
+			return
+		}
+		if err == nil {
+			err = requester.AddEvents(r.Context(), events.EmailChangeRequested(user, newEmail, change.ExpiresAt))
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to request email change", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to request email change")
+			return
+		}
+
+		log.Info("email change requested", "id", id)
+		render.Status(r, http.StatusAccepted)
+		render.JSON(w, r, Response{NewEmail: newEmail, ExpiresAt: change.ExpiresAt})
+	}
+}
@@ -0,0 +1,149 @@
+package requestEmailChange_test
+
+import (
+	"backend-app/internal/account"
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/delivery/http/v1/requestEmailChange"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockStorage struct {
+	user   models.User
+	change *models.EmailChange
+	events []models.OutboxEvent
+}
+
+func (m *mockStorage) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	u := m.user
+	u.ID = id
+	return &u, nil
+}
+
+func (m *mockStorage) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	return nil, storage.ErrUserNotFound
+}
+
+func (m *mockStorage) SaveEmailChange(ctx context.Context, change *models.EmailChange) error {
+	m.change = change
+	return nil
+}
+
+func (m *mockStorage) DeleteEmailChange(ctx context.Context, userID uint) error {
+	if m.change == nil {
+		return storage.ErrEmailChangeNotFound
+	}
+	m.change = nil
+	return nil
+}
+
+func (m *mockStorage) AddEvents(ctx context.Context, events []models.OutboxEvent) error {
+	m.events = append(m.events, events...)
+	return nil
+}
+
+// mockMailer запоминает отправленный токен.
+type mockMailer struct {
+	err   error
+	to    string
+	token string
+}
+
+func (m *mockMailer) SendEmailConfirmation(ctx context.Context, to string, token string, expiresAt time.Time) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.to, m.token = to, token
+	return nil
+}
+
+func TestRequestEmailChangeHandler(t *testing.T) {
+	hashed := models.User{Password: "secret123"}
+	require.NoError(t, hashed.HashPassword())
+
+	tests := []struct {
+		name           string
+		body           string
+		mailerErr      error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "wrong_password",
+			body:           `{"email":"new@example.com","password":"wrong"}`,
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   response.CodeInvalidPassword,
+		},
+		{
+			name:           "mailer_disabled",
+			body:           `{"email":"new@example.com","password":"secret123"}`,
+			mailerErr:      account.ErrMailerDisabled,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedCode:   response.CodeMailerUnavailable,
+		},
+		{
+			name:           "mailer_failed",
+			body:           `{"email":"new@example.com","password":"secret123"}`,
+			mailerErr:      errors.New("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   response.CodeInternal,
+		},
+		{
+			name:           "success",
+			body:           `{"email":"New@Example.com","password":"secret123"}`,
+			expectedStatus: http.StatusAccepted,
+		},
+	}
+
+	_, token, err := authMiddleware.AccessTokenAuth.Encode(map[string]interface{}{"user_id": 4, "role": "user"})
+	require.NoError(t, err)
+	policy := account.Policy{ConfirmationTTL: time.Hour}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockStorage{user: models.User{Username: "alice", Email: "alice@example.com", Password: hashed.Password}}
+			mailer := &mockMailer{err: tt.mailerErr}
+			router := chi.NewRouter()
+			router.Use(middleware.RequestID)
+			router.Use(jwtauth.Verifier(authMiddleware.AccessTokenAuth))
+			router.Post("/me/email", requestEmailChange.New(slog.Default(), m, policy, mailer))
+
+			req := httptest.NewRequest(http.MethodPost, "/me/email", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedCode != "" {
+				var res response.Problem
+				_ = render.DecodeJSON(rr.Body, &res)
+				assert.Equal(t, tt.expectedCode, res.Code)
+				assert.Nil(t, m.change, "a change without a sent email must not stay saved")
+				assert.Empty(t, m.events)
+				return
+			}
+
+			assert.Equal(t, "new@example.com", mailer.to)
+			require.NotNil(t, m.change)
+			assert.Equal(t, account.HashToken(mailer.token), m.change.TokenHash)
+			require.Len(t, m.events, 1)
+			assert.NotContains(t, m.events[0].Payload, mailer.token, "the token goes only to the mailer")
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package requestErasure
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"backend-app/pkg/api/validate"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Creator interface {
+	CreateErasureRequest(ctx context.Context, req *models.ErasureRequest) error
+}
+
+type Request struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+// New godoc
+// @Summary Request erasure
+// @Description Asks to erase the current user's personal data. An administrator reviews the request; once approved, the account is anonymized and can no longer be used. The body is optional
+// @Tags privacy
+// @Accept json
+// @Produce json
+// @Param input body requestErasure.Request false "Reason"
+// @Success 202 {object} dto.ErasureRequest
+// @Failure 400 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 409 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/me/erasure-request [post]
+func New(log *slog.Logger, creator Creator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.RequestErasure"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil && !errors.Is(err, io.EOF) {
+			log.Error("failed to decode request body", "error", err)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidBody, "invalid request body")
+			return
+		}
+		if err := validate.New().Struct(req); err != nil {
+			log.Info("validation failed", "error", err)
+			response.WriteProblem(w, r, response.ValidationFailed(err))
+			return
+		}
+
+		erasure := models.ErasureRequest{
+			UserID: authMiddleware.UserID(r.Context()),
+			Reason: req.Reason,
+			Status: models.ErasurePending,
+		}
+		err := creator.CreateErasureRequest(r.Context(), &erasure)
+		if errors.Is(err, storage.ErrErasurePending) {
+			response.Fail(w, r, http.StatusConflict, response.CodeErasurePending, "erasure request is already pending")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to create erasure request", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to request erasure")
+			return
+		}
+
+		log.Info("erasure requested", "id", erasure.ID, "user_id", erasure.UserID)
+		render.Status(r, http.StatusAccepted)
+		render.JSON(w, r, dto.NewErasureRequest(&erasure))
+	}
+}
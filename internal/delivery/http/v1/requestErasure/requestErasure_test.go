@@ -0,0 +1,115 @@
+package requestErasure_test
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/delivery/http/v1/requestErasure"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCreator struct {
+	err     error
+	created *models.ErasureRequest
+}
+
+func (m *mockCreator) CreateErasureRequest(ctx context.Context, req *models.ErasureRequest) error {
+	if m.err != nil {
+		return m.err
+	}
+	req.ID = 1
+	m.created = req
+	return nil
+}
+
+func TestRequestErasureHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		err            error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "invalid_body",
+			body:           "{",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid request body",
+		},
+		{
+			name:           "reason_too_long",
+			body:           `{"reason":"` + strings.Repeat("a", 501) + `"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   "validation failed",
+		},
+		{
+			name:           "already_pending",
+			err:            storage.ErrErasurePending,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "erasure request is already pending",
+		},
+		{
+			name:           "storage_error",
+			err:            errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to request erasure",
+		},
+		{
+			name:           "success_without_body",
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "success_with_reason",
+			body:           `{"reason":"closing my account"}`,
+			expectedStatus: http.StatusAccepted,
+		},
+	}
+
+	_, token, err := authMiddleware.AccessTokenAuth.Encode(map[string]interface{}{"user_id": 4, "role": "user"})
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockCreator{err: tt.err}
+			router := chi.NewRouter()
+			router.Use(middleware.RequestID)
+			router.Use(jwtauth.Verifier(authMiddleware.AccessTokenAuth))
+			router.Post("/me/erasure-request", requestErasure.New(slog.Default(), m))
+
+			req := httptest.NewRequest(http.MethodPost, "/me/erasure-request", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				var res response.Problem
+				_ = render.DecodeJSON(rr.Body, &res)
+				assert.Equal(t, tt.expectedBody, res.Detail)
+				return
+			}
+
+			require.NotNil(t, m.created)
+			assert.Equal(t, uint(4), m.created.UserID, "the request is filed for the token's user")
+			assert.Equal(t, models.ErasurePending, m.created.Status)
+			var res dto.ErasureRequest
+			require.NoError(t, render.DecodeJSON(rr.Body, &res))
+			assert.Equal(t, uint(1), res.ID)
+		})
+	}
+}
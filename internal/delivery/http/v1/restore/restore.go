@@ -0,0 +1,77 @@
+package restore
+
+import (
+	"backend-app/internal/delivery/http/tenant"
+	"backend-app/internal/storage"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type restorer interface {
+	RestoreUser(ctx context.Context, id uint) error
+	tenant.Members
+}
+
+// New godoc
+// @Summary Restore user
+// @Description Restores a soft-deleted user by ID. Organization admins can restore only members of their organization
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/user/{id}/restore [post]
+func New(log *slog.Logger, restorer restorer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.RestoreUser"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		idStr := chi.URLParam(r, "id")
+		idUint, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			log.Error("invalid user id", "param", idStr, "error", err)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid user id")
+			return
+		}
+
+		err = tenant.Check(r.Context(), restorer, uint(idUint))
+		if err == nil {
+			err = restorer.RestoreUser(r.Context(), uint(idUint))
+		}
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Info("deleted user not found", "id", idUint)
+			response.Fail(w, r, http.StatusNotFound, response.CodeUserNotFound, "deleted user not found")
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to restore user", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to restore user")
+			return
+		}
+
+		log.Info("user restored successfully", "id", idUint)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, response.OK())
+	}
+}
@@ -0,0 +1,98 @@
+package restore_test
+
+import (
+	"backend-app/internal/delivery/http/v1/restore"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockRestorer struct {
+	RestoreFn func(id uint) error
+}
+
+func (m *mockRestorer) RestoreUser(ctx context.Context, id uint) error {
+	return m.RestoreFn(id)
+}
+
+func (m *mockRestorer) GetMembership(ctx context.Context, orgID uint, userID uint) (*models.Membership, error) {
+	return nil, storage.ErrMembershipNotFound
+}
+
+func TestRestoreUserHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		urlParam       string
+		mockRestoreErr error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "invalid_id",
+			urlParam:       "abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid user id",
+		},
+		{
+			name:           "not_deleted",
+			urlParam:       "123",
+			mockRestoreErr: storage.ErrUserNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "deleted user not found",
+		},
+		{
+			name:           "internal_error",
+			urlParam:       "123",
+			mockRestoreErr: errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to restore user",
+		},
+		{
+			name:           "success",
+			urlParam:       "123",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/users/"+tt.urlParam+"/restore", nil)
+			rr := httptest.NewRecorder()
+
+			router := chi.NewRouter()
+			router.Use(middleware.RequestID)
+			router.Use(render.SetContentType(render.ContentTypeJSON))
+			router.Post("/users/{id}/restore", restore.New(slog.Default(), &mockRestorer{
+				RestoreFn: func(id uint) error {
+					return tt.mockRestoreErr
+				},
+			}))
+
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedBody != "" {
+				var res response.Problem
+				_ = render.DecodeJSON(rr.Body, &res)
+				assert.Equal(t, response.ProblemContentType, rr.Header().Get("Content-Type"))
+				assert.Equal(t, tt.expectedBody, res.Detail)
+			} else {
+				var res response.Response
+				_ = render.DecodeJSON(rr.Body, &res)
+				assert.Equal(t, "OK", res.Status)
+			}
+		})
+	}
+}
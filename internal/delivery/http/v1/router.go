@@ -1,15 +1,74 @@
 package v1Router
 
 import (
+	"backend-app/internal/account"
+	"backend-app/internal/cache"
+	"backend-app/internal/config"
+	"backend-app/internal/country"
+	"backend-app/internal/delivery/http/cookie"
 	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	csrfMiddleware "backend-app/internal/delivery/http/middleware/csrf"
+	problemMiddleware "backend-app/internal/delivery/http/middleware/problem"
+	"backend-app/internal/delivery/http/v1/addGroupMember"
+	"backend-app/internal/delivery/http/v1/approveErasure"
+	"backend-app/internal/delivery/http/v1/changeUsername"
+	"backend-app/internal/delivery/http/v1/confirmEmailChange"
+	"backend-app/internal/delivery/http/v1/createDataExport"
+	"backend-app/internal/delivery/http/v1/createGroup"
+	"backend-app/internal/delivery/http/v1/createOrganization"
+	"backend-app/internal/delivery/http/v1/createProfileAttribute"
+	"backend-app/internal/delivery/http/v1/createWebhook"
 	delete2 "backend-app/internal/delivery/http/v1/delete"
+	"backend-app/internal/delivery/http/v1/deleteGroup"
+	"backend-app/internal/delivery/http/v1/deleteOrganization"
+	"backend-app/internal/delivery/http/v1/deleteProfileAttribute"
+	"backend-app/internal/delivery/http/v1/deleteWebhook"
+	"backend-app/internal/delivery/http/v1/downloadDataExport"
 	"backend-app/internal/delivery/http/v1/edit"
+	"backend-app/internal/delivery/http/v1/editGroup"
+	"backend-app/internal/delivery/http/v1/editProfileAttribute"
+	"backend-app/internal/delivery/http/v1/editWebhook"
+	"backend-app/internal/delivery/http/v1/explainPermission"
+	"backend-app/internal/delivery/http/v1/exportUsers"
 	"backend-app/internal/delivery/http/v1/getAllUsers"
+	"backend-app/internal/delivery/http/v1/getDataExports"
+	"backend-app/internal/delivery/http/v1/getDeletedUsers"
+	"backend-app/internal/delivery/http/v1/getErasureReceipt"
+	"backend-app/internal/delivery/http/v1/getErasureRequests"
+	"backend-app/internal/delivery/http/v1/getGroup"
+	"backend-app/internal/delivery/http/v1/getGroupMembers"
+	"backend-app/internal/delivery/http/v1/getGroups"
+	"backend-app/internal/delivery/http/v1/getMe"
+	"backend-app/internal/delivery/http/v1/getMembers"
+	"backend-app/internal/delivery/http/v1/getMyOrganizations"
+	"backend-app/internal/delivery/http/v1/getOrganizations"
+	"backend-app/internal/delivery/http/v1/getProfileAttributes"
+	"backend-app/internal/delivery/http/v1/getStats"
 	"backend-app/internal/delivery/http/v1/getUser"
+	"backend-app/internal/delivery/http/v1/getUserPermissions"
+	"backend-app/internal/delivery/http/v1/getUserProfile"
+	"backend-app/internal/delivery/http/v1/getWebhookDeliveries"
+	"backend-app/internal/delivery/http/v1/getWebhooks"
+	"backend-app/internal/delivery/http/v1/importUsers"
 	"backend-app/internal/delivery/http/v1/login"
+	"backend-app/internal/delivery/http/v1/patch"
+	"backend-app/internal/delivery/http/v1/patchMe"
+	"backend-app/internal/delivery/http/v1/patchUserProfile"
+	"backend-app/internal/delivery/http/v1/pingWebhook"
+	"backend-app/internal/delivery/http/v1/redeliverWebhook"
 	"backend-app/internal/delivery/http/v1/refresh"
 	"backend-app/internal/delivery/http/v1/register"
-	"backend-app/internal/storage/postgres"
+	"backend-app/internal/delivery/http/v1/rejectErasure"
+	"backend-app/internal/delivery/http/v1/removeGroupMember"
+	"backend-app/internal/delivery/http/v1/removeMember"
+	"backend-app/internal/delivery/http/v1/requestEmailChange"
+	"backend-app/internal/delivery/http/v1/requestErasure"
+	"backend-app/internal/delivery/http/v1/restore"
+	"backend-app/internal/delivery/http/v1/searchUsers"
+	"backend-app/internal/delivery/http/v1/setMember"
+	"backend-app/internal/stats"
+	"backend-app/internal/storage"
+	"expvar"
 	"log/slog"
 	"net/http"
 
@@ -18,13 +77,26 @@ import (
 	"github.com/go-chi/jwtauth/v5"
 )
 
-func New(log *slog.Logger, storage *postgres.Storage) http.Handler {
+func New(log *slog.Logger, storage storage.Store, cfg *config.Config) http.Handler {
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
-	r.Use(middleware.Throttle(100))
+	r.Use(problemMiddleware.Throttle(100))
+	r.NotFound(problemMiddleware.NotFound)
+	r.MethodNotAllowed(problemMiddleware.MethodNotAllowed)
+	if cfg.Cookie.Enabled {
+		r.Use(csrfMiddleware.DoubleSubmit)
+	}
 
-	r.Post("/refresh", refresh.New(log, storage))
-	r.Post("/register", register.New(log, storage))
+	accounts := account.Policy{
+		Reserved:         cfg.Account.ReservedUsernames,
+		UsernameCooldown: cfg.Account.UsernameCooldown,
+		EmailCooldown:    cfg.Account.EmailCooldown,
+		ConfirmationTTL:  cfg.Account.EmailConfirmationTTL,
+	}
+
+	r.Post("/refresh", refresh.New(log, storage, cfg.Cookie))
+	r.Post("/register", register.New(log, storage, country.Policy{Allow: cfg.Countries.Allow, Deny: cfg.Countries.Deny}, accounts))
+	r.Post("/email/confirm", confirmEmailChange.New(log, storage))
 	r.Group(func(r chi.Router) {
 
 		r.Use(jwtauth.Verifier(authMiddleware.RefreshTokenAuth))
@@ -33,16 +105,100 @@ func New(log *slog.Logger, storage *postgres.Storage) http.Handler {
 
 	})
 	r.Group(func(r chi.Router) {
-		r.Use(jwtauth.Verifier(authMiddleware.AccessTokenAuth))
+		r.Use(jwtauth.Verify(authMiddleware.AccessTokenAuth, jwtauth.TokenFromHeader, cookie.TokenFromCookie))
 		r.Use(authMiddleware.Authenticator)
+		r.Use(authMiddleware.TokenVersion(storage))
+
+		r.Post("/me/data-exports", createDataExport.New(log, storage))
+		r.Get("/me/data-exports", getDataExports.New(log, storage))
+		r.Get("/me/data-exports/{id}/archive", downloadDataExport.New(log, storage))
+		r.Post("/me/erasure-request", requestErasure.New(log, storage))
+		r.Get("/me/orgs", getMyOrganizations.New(log, storage))
+		r.Get("/me", getMe.New(log, storage))
+		r.Patch("/me", patchMe.New(log, storage))
+		r.Put("/me/username", changeUsername.New(log, storage, accounts))
+		r.Post("/me/email", requestEmailChange.New(log, storage, accounts, account.NewMailer(cfg.Account.MailerURL)))
+		r.Get("/profile/attributes", getProfileAttributes.New(log, storage))
+
+		// администраторы других организаций получат 404
+		orgAdmin := r.With(authMiddleware.OrgAdminOnly("id"))
+		orgAdmin.Get("/orgs/{id}/members", getMembers.New(log, storage))
+		orgAdmin.Put("/orgs/{id}/members/{userId}", setMember.New(log, storage))
+		orgAdmin.Delete("/orgs/{id}/members/{userId}", removeMember.New(log, storage))
+
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(jwtauth.Verify(authMiddleware.AccessTokenAuth, jwtauth.TokenFromHeader, cookie.TokenFromCookie))
+		r.Use(authMiddleware.Authenticator)
+		r.Use(authMiddleware.TokenVersion(storage))
 		r.Use(authMiddleware.AdminOnly)
 
 		r.Delete("/user/{id}", delete2.New(log, storage))
 		r.Get("/user/all", getAllUsers.New(log, storage))
+		r.Get("/user/deleted", getDeletedUsers.New(log, storage))
+		r.Get("/users/search", searchUsers.New(log, storage))
+		r.Get("/users/export", exportUsers.New(log, storage, cfg.Bulk))
+		r.Post("/user/{id}/restore", restore.New(log, storage))
 		r.Get("/user/{id}", getUser.New(log, storage))
-		r.Put("/user", edit.New(log, storage))
+		r.Patch("/user/{id}", patch.New(log, storage, accounts))
+		r.Put("/user", edit.New(log, storage, accounts))
+		r.Get("/user/{id}/permissions", getUserPermissions.New(log, storage))
+		r.Get("/user/{id}/permissions/{permission}", explainPermission.New(log, storage))
+		r.Get("/user/{id}/profile", getUserProfile.New(log, storage))
+		r.Patch("/user/{id}/profile", patchUserProfile.New(log, storage))
+		r.Get("/admin/stats", getStats.New(log, stats.New(storage, cache.NewLRU(cfg.Stats.CacheSize), cfg.Stats.CacheTTL)))
+
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(jwtauth.Verify(authMiddleware.AccessTokenAuth, jwtauth.TokenFromHeader, cookie.TokenFromCookie))
+		r.Use(authMiddleware.Authenticator)
+		r.Use(authMiddleware.TokenVersion(storage))
+		r.Use(authMiddleware.ServiceAdminOnly)
+
+		// импорт создаёт пользователей вне организаций и с любыми глобальными ролями
+		r.Post("/users/import", importUsers.New(log, storage, cfg.Bulk, accounts))
+
+		r.Get("/webhooks", getWebhooks.New(log, storage))
+		r.Post("/webhooks", createWebhook.New(log, storage))
+		r.Put("/webhooks/{id}", editWebhook.New(log, storage))
+		r.Delete("/webhooks/{id}", deleteWebhook.New(log, storage))
+		r.Get("/webhooks/{id}/deliveries", getWebhookDeliveries.New(log, storage))
+		r.Post("/webhooks/{id}/deliveries/{deliveryId}/redeliver", redeliverWebhook.New(log, storage))
+		r.Post("/webhooks/{id}/ping", pingWebhook.New(log, storage, &http.Client{Timeout: cfg.Webhooks.RequestTimeout}))
+
+		r.Get("/groups", getGroups.New(log, storage))
+		r.Post("/groups", createGroup.New(log, storage))
+		r.Get("/groups/{id}", getGroup.New(log, storage))
+		r.Put("/groups/{id}", editGroup.New(log, storage))
+		r.Delete("/groups/{id}", deleteGroup.New(log, storage))
+		r.Get("/groups/{id}/members", getGroupMembers.New(log, storage))
+		r.Put("/groups/{id}/members/{userId}", addGroupMember.New(log, storage))
+		r.Delete("/groups/{id}/members/{userId}", removeGroupMember.New(log, storage))
+
+		r.Post("/profile/attributes", createProfileAttribute.New(log, storage))
+		r.Put("/profile/attributes/{key}", editProfileAttribute.New(log, storage))
+		r.Delete("/profile/attributes/{key}", deleteProfileAttribute.New(log, storage))
+
+		r.Get("/erasure-requests", getErasureRequests.New(log, storage))
+		r.Post("/erasure-requests/{id}/approve", approveErasure.New(log, storage))
+		r.Post("/erasure-requests/{id}/reject", rejectErasure.New(log, storage))
+		r.Get("/erasure-requests/{id}/receipt", getErasureReceipt.New(log, storage))
+
+		// счётчики процесса, в том числе кеша пользователей
+		r.Get("/debug/vars", expvar.Handler().ServeHTTP)
+
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(jwtauth.Verify(authMiddleware.AccessTokenAuth, jwtauth.TokenFromHeader, cookie.TokenFromCookie))
+		r.Use(authMiddleware.Authenticator)
+		r.Use(authMiddleware.TokenVersion(storage))
+		r.Use(authMiddleware.SuperAdminOnly)
+
+		r.Get("/orgs", getOrganizations.New(log, storage))
+		r.Post("/orgs", createOrganization.New(log, storage))
+		r.Delete("/orgs/{id}", deleteOrganization.New(log, storage))
 
 	})
-	r.Post("/login", login.New(log, storage))
+	r.Post("/login", login.New(log, storage, cfg.Cookie))
 	return r
 }
@@ -0,0 +1,116 @@
+package searchUsers
+
+import (
+	"backend-app/internal/country"
+	"backend-app/internal/delivery/http/dto"
+	authMiddleware "backend-app/internal/delivery/http/middleware/auth"
+	"backend-app/internal/search"
+	"backend-app/internal/storage"
+	"backend-app/pkg/api/response"
+	"backend-app/pkg/sl"
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// MinQueryLength - запросы короче почти всегда совпадают со всеми пользователями.
+const MinQueryLength = 2
+
+// MaxQueryLength ограничивает нечёткое сравнение: оно квадратично по длине строк,
+// а levenshtein в postgres не принимает строки длиннее 255 символов.
+const MaxQueryLength = 100
+
+type Searcher interface {
+	SearchUsers(ctx context.Context, query string, orgID uint, limit int) ([]storage.UserMatch, error)
+}
+
+// Result - найденный пользователь. Highlights содержит значения совпавших полей,
+// где совпавший фрагмент обёрнут в <em>, а остальной текст экранирован.
+type Result struct {
+	User       dto.User          `json:"user"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+// New godoc
+// @Summary Search users
+// @Description Finds users by username, email or country: prefix, case-insensitive and typo-tolerant. Results are ranked by relevance, best first. Organization admins find only members of their organization. With field encryption on PostgreSQL, email and country match only exactly
+// @Tags users
+// @Produce json
+// @Param Accept-Language header string false "Language of country names, English by default"
+// @Param q query string true "Search query, 2-100 characters"
+// @Param limit query int false "Max results, 1-100, default 20"
+// @Success 200 {object} response.Page{items=[]searchUsers.Result}
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/users/search [get]
+func New(log *slog.Logger, searcher Searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.SearchUsers"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		q := search.Normalize(r.URL.Query().Get("q"))
+		if utf8.RuneCountInString(q) < MinQueryLength {
+			log.Info("search query is too short", slog.String("q", q))
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, "query must be at least 2 characters")
+			return
+		}
+		if utf8.RuneCountInString(q) > MaxQueryLength {
+			log.Info("search query is too long", slog.Int("length", utf8.RuneCountInString(q)))
+			response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, "query must be at most 100 characters")
+			return
+		}
+
+		limit := storage.DefaultPageSize
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > storage.MaxPageSize {
+				log.Info("invalid query parameter", slog.String("limit", v))
+				response.Fail(w, r, http.StatusUnprocessableEntity, response.CodeInvalidParameter, "invalid limit")
+				return
+			}
+			limit = n
+		}
+
+		matches, err := searcher.SearchUsers(r.Context(), q, authMiddleware.Tenant(r.Context()), limit)
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to search users", sl.Error(err))
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to search users")
+			return
+		}
+
+		results := make([]Result, 0, len(matches))
+		lang := country.Language(r.Header.Get("Accept-Language"))
+		for _, m := range matches {
+			res := Result{
+				User:       dto.NewUser(&m.User).Localize(lang),
+				Score:      m.Score,
+				Highlights: make(map[string]string, len(m.Matches)),
+			}
+			for _, fm := range m.Matches {
+				res.Highlights[fm.Field] = fm.Highlight()
+			}
+			results = append(results, res)
+		}
+
+		log.Info("users found", "count", len(results))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, response.Page{Items: results})
+	}
+}
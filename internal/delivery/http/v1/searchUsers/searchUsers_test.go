@@ -0,0 +1,141 @@
+package searchUsers_test
+
+import (
+	"backend-app/internal/delivery/http/v1/searchUsers"
+	"backend-app/internal/search"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSearcher struct {
+	SearchUsersFn func(query string, limit int) ([]storage.UserMatch, error)
+}
+
+func (m *mockSearcher) SearchUsers(ctx context.Context, query string, orgID uint, limit int) ([]storage.UserMatch, error) {
+	return m.SearchUsersFn(query, limit)
+}
+
+func TestSearchUsersHandler(t *testing.T) {
+	alice := storage.UserMatch{
+		User:  models.User{ID: 1, Username: "Alice", Email: "alice@example.com"},
+		Score: 0.9,
+		Matches: []search.FieldMatch{
+			{Field: "username", Value: "Alice", Score: 0.9, Start: 0, End: 3},
+			{Field: "email", Value: "alice@example.com", Score: 0.9, Start: 0, End: 3},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		mockReturn     []storage.UserMatch
+		mockError      error
+		expectedStatus int
+		expectedQuery  string
+		expectedLimit  int
+		expectError    bool
+	}{
+		{
+			name:           "missing_query",
+			query:          "",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectError:    true,
+		},
+		{
+			name:           "query_too_short",
+			query:          "?q=%20a%20",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectError:    true,
+		},
+		{
+			name:           "query_too_long",
+			query:          "?q=" + strings.Repeat("a", searchUsers.MaxQueryLength+1),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectError:    true,
+		},
+		{
+			name:           "invalid_limit",
+			query:          "?q=ali&limit=500",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectError:    true,
+		},
+		{
+			name:           "internal_error",
+			query:          "?q=ali",
+			mockError:      errors.New("db failure"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedQuery:  "ali",
+			expectedLimit:  storage.DefaultPageSize,
+			expectError:    true,
+		},
+		{
+			name:           "success",
+			query:          "?q=ALI&limit=5",
+			mockReturn:     []storage.UserMatch{alice},
+			expectedStatus: http.StatusOK,
+			expectedQuery:  "ali",
+			expectedLimit:  5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users/search"+tt.query, nil)
+			rr := httptest.NewRecorder()
+
+			router := chi.NewRouter()
+			router.Use(middleware.RequestID)
+			router.Use(render.SetContentType(render.ContentTypeJSON))
+			router.Get("/users/search", searchUsers.New(slog.Default(), &mockSearcher{
+				SearchUsersFn: func(query string, limit int) ([]storage.UserMatch, error) {
+					assert.Equal(t, tt.expectedQuery, query)
+					assert.Equal(t, tt.expectedLimit, limit)
+					return tt.mockReturn, tt.mockError
+				},
+			}))
+
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectError {
+				var res response.Problem
+				err := json.Unmarshal(rr.Body.Bytes(), &res)
+				assert.NoError(t, err)
+				assert.Equal(t, response.ProblemContentType, rr.Header().Get("Content-Type"))
+				assert.Equal(t, tt.expectedStatus, res.Status)
+				assert.NotEmpty(t, res.Code)
+				assert.NotEmpty(t, res.Detail)
+				return
+			}
+
+			var page struct {
+				Items []searchUsers.Result `json:"items"`
+			}
+			err := json.Unmarshal(rr.Body.Bytes(), &page)
+			assert.NoError(t, err)
+			if assert.Len(t, page.Items, 1) {
+				assert.Equal(t, "Alice", page.Items[0].User.Username)
+				assert.Equal(t, 0.9, page.Items[0].Score)
+				assert.Equal(t, map[string]string{
+					"username": "<em>Ali</em>ce",
+					"email":    "<em>ali</em>ce@example.com",
+				}, page.Items[0].Highlights)
+			}
+		})
+	}
+}
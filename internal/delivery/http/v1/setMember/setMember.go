@@ -0,0 +1,113 @@
+package setMember
+
+import (
+	"backend-app/internal/delivery/http/dto"
+	"backend-app/internal/delivery/http/tenant"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/api/response"
+	"backend-app/pkg/api/validate"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Setter interface {
+	SetMembership(ctx context.Context, m *models.Membership) error
+	tenant.Members
+}
+
+type Request struct {
+	// Role действует только в токенах, выпущенных для этой организации
+	Role string `json:"role" validate:"required,oneof=user creator combined admin"`
+}
+
+// New godoc
+// @Summary Add organization member
+// @Description Adds a user to an organization or changes their role in it. A role change revokes the user's tokens. Admins of the organization can only change roles of its members; adding new members is up to a superadmin
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param userId path int true "User ID"
+// @Param input body setMember.Request true "Role in the organization"
+// @Success 200 {object} dto.Membership
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Failure 503 {object} response.Problem
+// @Failure 504 {object} response.Problem
+// @Router /v1/orgs/{id}/members/{userId} [put]
+func New(log *slog.Logger, setter Setter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.v1.SetMember"
+
+		log = log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		orgStr := chi.URLParam(r, "id")
+		orgID, err := strconv.ParseUint(orgStr, 10, 32)
+		if err != nil {
+			log.Info("invalid organization id", "param", orgStr)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid organization id")
+			return
+		}
+		userStr := chi.URLParam(r, "userId")
+		userID, err := strconv.ParseUint(userStr, 10, 32)
+		if err != nil {
+			log.Info("invalid user id", "param", userStr)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidParameter, "invalid user id")
+			return
+		}
+
+		var req Request
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", "error", err)
+			response.Fail(w, r, http.StatusBadRequest, response.CodeInvalidBody, "invalid request body")
+			return
+		}
+		if err := validate.New().Struct(req); err != nil {
+			log.Info("validation failed", "error", err)
+			response.WriteProblem(w, r, response.ValidationFailed(err))
+			return
+		}
+
+		m := models.Membership{OrgID: uint(orgID), UserID: uint(userID), Role: req.Role}
+		// администратор организации не видит тех, кто в ней не состоит, и не может их добавить
+		err = tenant.Check(r.Context(), setter, m.UserID)
+		if err == nil {
+			err = setter.SetMembership(r.Context(), &m)
+		}
+		if errors.Is(err, storage.ErrOrgNotFound) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeOrganizationNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, storage.ErrUserNotFound) {
+			response.Fail(w, r, http.StatusNotFound, response.CodeUserNotFound, err.Error())
+			return
+		}
+		if problem, ok := response.ContextError(err); ok {
+			log.Error("storage call interrupted", "error", err)
+			response.WriteProblem(w, r, problem)
+			return
+		}
+		if err != nil {
+			log.Error("failed to set membership", "error", err)
+			response.Fail(w, r, http.StatusInternalServerError, response.CodeInternal, "failed to set membership")
+			return
+		}
+
+		log.Info("membership set", "org_id", m.OrgID, "user_id", m.UserID, "role", m.Role)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, dto.NewMembership(&m))
+	}
+}
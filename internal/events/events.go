@@ -0,0 +1,221 @@
+// Package events описывает доменные события жизненного цикла пользователя,
+// которые хранилище пишет в outbox, и способы их публикации другим сервисам.
+package events
+
+import (
+	"backend-app/internal/storage/models"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	TypeUserCreated     = "user.created"
+	TypeUserUpdated     = "user.updated"
+	TypeUserRoleChanged = "user.role_changed"
+	TypeUserVerified    = "user.verified"
+	TypeUserDeleted     = "user.deleted"
+	TypeUserRestored    = "user.restored"
+	TypeUserLoginFailed = "user.login_failed"
+	TypeUserErased      = "user.erased"
+
+	TypeUserEmailChangeRequested = "user.email_change_requested"
+)
+
+// Types - все типы событий, на них можно подписать webhook.
+var Types = []string{
+	TypeUserCreated,
+	TypeUserUpdated,
+	TypeUserRoleChanged,
+	TypeUserVerified,
+	TypeUserDeleted,
+	TypeUserRestored,
+	TypeUserLoginFailed,
+	TypeUserErased,
+	TypeUserEmailChangeRequested,
+}
+
+// IsType сообщает, есть ли такой тип события.
+func IsType(typ string) bool {
+	for _, t := range Types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// Event - сообщение, которое получают подписчики.
+type Event struct {
+	// ID одинаков у повторных публикаций одного события, по нему подписчики отбрасывают дубли
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	UserID     uint            `json:"userId"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// User - состояние пользователя после изменения, без пароля и токенов.
+// Доставка не сохраняет порядок при повторах, поэтому подписчикам стоит сравнивать Version.
+type User struct {
+	ID           uint   `json:"id"`
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	Role         string `json:"role"`
+	Country      string `json:"country"`
+	Status       string `json:"status"`
+	Verified     bool   `json:"verified"`
+	Version      uint   `json:"version"`
+	PreviousRole string `json:"previousRole,omitempty"`
+	DeletedBy    *uint  `json:"deletedBy,omitempty"`
+	DeleteReason string `json:"deleteReason,omitempty"`
+	// NewEmail и ConfirmationExpiresAt есть только в user.email_change_requested. Токена
+	// подтверждения в событии нет: его получает только account.Mailer
+	NewEmail              string     `json:"newEmail,omitempty"`
+	ConfirmationExpiresAt *time.Time `json:"confirmationExpiresAt,omitempty"`
+}
+
+// Publisher доставляет событие во внешнюю систему. Ошибка означает, что событие
+// нужно отправить ещё раз, поэтому Publish должен быть готов к повторам.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// FromOutbox превращает запись outbox в сообщение для подписчиков.
+func FromOutbox(e models.OutboxEvent) Event {
+	return Event{
+		ID:         e.Key,
+		Type:       e.Type,
+		UserID:     e.UserID,
+		OccurredAt: e.CreatedAt,
+		Data:       json.RawMessage(e.Payload),
+	}
+}
+
+// UserCreated - событие о регистрации пользователя.
+func UserCreated(u *models.User) []models.OutboxEvent {
+	return []models.OutboxEvent{newOutboxEvent(TypeUserCreated, newUser(u))}
+}
+
+// UserChanged - события об изменении пользователя из prev в u: user.updated,
+// user.role_changed, если сменилась роль, и user.verified, если пользователя подтвердили.
+func UserChanged(prev, u *models.User) []models.OutboxEvent {
+	data := newUser(u)
+	result := []models.OutboxEvent{newOutboxEvent(TypeUserUpdated, data)}
+	if prev.Role != u.Role {
+		roleChanged := data
+		roleChanged.PreviousRole = prev.Role
+		result = append(result, newOutboxEvent(TypeUserRoleChanged, roleChanged))
+	}
+	if !prev.Verified && u.Verified {
+		result = append(result, newOutboxEvent(TypeUserVerified, data))
+	}
+	return result
+}
+
+// UserDeleted - событие о мягком удалении, u - пользователь после удаления.
+func UserDeleted(u *models.User) []models.OutboxEvent {
+	data := newUser(u)
+	data.DeletedBy = u.DeletedBy
+	data.DeleteReason = u.DeleteReason
+	return []models.OutboxEvent{newOutboxEvent(TypeUserDeleted, data)}
+}
+
+// UserRestored - событие о восстановлении удалённого пользователя.
+func UserRestored(u *models.User) []models.OutboxEvent {
+	return []models.OutboxEvent{newOutboxEvent(TypeUserRestored, newUser(u))}
+}
+
+// LoginFailed - событие о входе с неверным паролем. Версия пользователя при этом
+// не меняется, поэтому ключ дополняется случайной частью.
+func LoginFailed(u *models.User) []models.OutboxEvent {
+	return []models.OutboxEvent{withNonce(newOutboxEvent(TypeUserLoginFailed, newUser(u)))}
+}
+
+// EmailChangeRequested - событие о запросе смены email. Пользователь при этом ещё не
+// изменился, поэтому ключ дополняется случайной частью.
+func EmailChangeRequested(u *models.User, newEmail string, expiresAt time.Time) []models.OutboxEvent {
+	data := newUser(u)
+	data.NewEmail = newEmail
+	data.ConfirmationExpiresAt = &expiresAt
+	return []models.OutboxEvent{withNonce(newOutboxEvent(TypeUserEmailChangeRequested, data))}
+}
+
+// UserErased - событие об обезличивании пользователя по его запросу. Подписчикам
+// стоит удалить у себя его персональные данные, в самом событии их уже нет.
+func UserErased(u *models.User) []models.OutboxEvent {
+	return []models.OutboxEvent{newOutboxEvent(TypeUserErased, newUser(u))}
+}
+
+// ScrubPayload заменяет персональные данные в теле события о пользователе значениями
+// из обезличенного u. Остальные поля, например роль и версия, остаются как были.
+func ScrubPayload(payload string, u *models.User) string {
+	data := make(map[string]any)
+	dec := json.NewDecoder(strings.NewReader(payload))
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
+		// тело, которое не разобрать, не сохраняем вовсе
+		data = map[string]any{"id": u.ID}
+	}
+	data["username"] = u.Username
+	data["email"] = u.Email
+	data["country"] = u.Country
+	delete(data, "deleteReason")
+	delete(data, "newEmail")
+	b, _ := json.Marshal(data)
+	return string(b)
+}
+
+// ScrubEvent делает то же, что ScrubPayload, с сообщением Event целиком, как его
+// хранят доставки webhooks.
+func ScrubEvent(payload string, u *models.User) string {
+	var e Event
+	if err := json.Unmarshal([]byte(payload), &e); err != nil {
+		e = Event{UserID: u.ID}
+	}
+	e.Data = json.RawMessage(ScrubPayload(string(e.Data), u))
+	b, _ := json.Marshal(e)
+	return string(b)
+}
+
+func newUser(u *models.User) User {
+	return User{
+		ID:       u.ID,
+		Username: u.Username,
+		Email:    u.Email,
+		Role:     u.Role,
+		Country:  u.Country,
+		Status:   u.Status,
+		Verified: u.Verified,
+		Version:  u.Version,
+	}
+}
+
+// withNonce дополняет ключ события случайной частью для событий, которые не меняют версию пользователя.
+func withNonce(e models.OutboxEvent) models.OutboxEvent {
+	nonce := make([]byte, 8)
+	// crypto/rand.Read не возвращает ошибок
+	_, _ = rand.Read(nonce)
+	e.Key += ":" + hex.EncodeToString(nonce)
+	return e
+}
+
+// newOutboxEvent строит запись outbox. Ключ зависит от версии пользователя,
+// поэтому у каждого изменения он свой, а у повторов одного события - общий.
+func newOutboxEvent(typ string, data User) models.OutboxEvent {
+	// структура без map и интерфейсов всегда сериализуется
+	payload, _ := json.Marshal(data)
+	now := time.Now()
+	return models.OutboxEvent{
+		Key:           fmt.Sprintf("%s:%d:%d", typ, data.ID, data.Version),
+		Type:          typ,
+		UserID:        data.ID,
+		Payload:       string(payload),
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+}
@@ -0,0 +1,82 @@
+package events_test
+
+import (
+	"backend-app/internal/events"
+	"backend-app/internal/storage/models"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrubPayload(t *testing.T) {
+	u := &models.User{ID: 7, Username: "erased-7", Email: "erased-7@erased.invalid"}
+
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{
+			name:    "user fields replaced",
+			payload: `{"id":7,"username":"alice","email":"alice@example.com","country":"NL","role":"user","version":12345678901234567}`,
+			want:    `{"id":7,"username":"erased-7","email":"erased-7@erased.invalid","country":"","role":"user","version":12345678901234567}`,
+		},
+		{
+			name:    "delete reason dropped",
+			payload: `{"id":7,"username":"alice","deleteReason":"moving away"}`,
+			want:    `{"id":7,"username":"erased-7","email":"erased-7@erased.invalid","country":""}`,
+		},
+		{
+			name:    "email change dropped",
+			payload: `{"id":7,"username":"alice","newEmail":"new@example.com","confirmationExpiresAt":"2026-01-02T00:00:00Z"}`,
+			want:    `{"id":7,"username":"erased-7","email":"erased-7@erased.invalid","country":"","confirmationExpiresAt":"2026-01-02T00:00:00Z"}`,
+		},
+		{
+			name:    "invalid payload",
+			payload: `not json`,
+			want:    `{"id":7,"username":"erased-7","email":"erased-7@erased.invalid","country":""}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.JSONEq(t, tt.want, events.ScrubPayload(tt.payload, u))
+		})
+	}
+}
+
+func TestEmailChangeRequested(t *testing.T) {
+	u := &models.User{ID: 3, Username: "alice", Email: "alice@example.com", Version: 5}
+	expires := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	first := events.EmailChangeRequested(u, "new@example.com", expires)
+	second := events.EmailChangeRequested(u, "new@example.com", expires)
+	require.Len(t, first, 1)
+	assert.Equal(t, events.TypeUserEmailChangeRequested, first[0].Type)
+	assert.NotEqual(t, first[0].Key, second[0].Key, "the user version does not change, keys must differ")
+
+	var data events.User
+	require.NoError(t, json.Unmarshal([]byte(first[0].Payload), &data))
+	assert.Equal(t, "alice@example.com", data.Email)
+	assert.Equal(t, "new@example.com", data.NewEmail)
+	assert.NotContains(t, first[0].Payload, "token", "the confirmation token goes only to the mailer")
+	require.NotNil(t, data.ConfirmationExpiresAt)
+	assert.True(t, expires.Equal(*data.ConfirmationExpiresAt))
+}
+
+func TestScrubEvent(t *testing.T) {
+	u := &models.User{ID: 1, Username: "erased-1", Email: "erased-1@erased.invalid"}
+	e := event
+	e.Data = json.RawMessage(`{"id":1,"username":"alice","email":"alice@example.com"}`)
+	payload, err := json.Marshal(e)
+	require.NoError(t, err)
+
+	var got events.Event
+	require.NoError(t, json.Unmarshal([]byte(events.ScrubEvent(string(payload), u)), &got))
+	assert.Equal(t, e.ID, got.ID)
+	assert.Equal(t, e.Type, got.Type)
+	assert.True(t, e.OccurredAt.Equal(got.OccurredAt))
+	assert.JSONEq(t, `{"id":1,"username":"erased-1","email":"erased-1@erased.invalid","country":""}`, string(got.Data))
+}
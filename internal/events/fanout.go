@@ -0,0 +1,21 @@
+package events
+
+import (
+	"context"
+	"errors"
+)
+
+// Fanout публикует каждое событие во все Publisher по очереди. Если хотя бы один
+// из них не справился, событие будет отправлено повторно во все, поэтому каждый
+// Publisher должен отбрасывать дубли или быть к ним готов.
+type Fanout []Publisher
+
+func (f Fanout) Publish(ctx context.Context, event Event) error {
+	var errs []error
+	for _, p := range f {
+		if err := p.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,22 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogPublisher пишет события в лог. Подходит для локальной разработки и как
+// запасной вариант, когда брокер ещё не настроен.
+type LogPublisher struct {
+	Log *slog.Logger
+}
+
+func (p LogPublisher) Publish(ctx context.Context, event Event) error {
+	p.Log.InfoContext(ctx, "event published",
+		slog.String("id", event.ID),
+		slog.String("type", event.Type),
+		slog.Uint64("user_id", uint64(event.UserID)),
+		slog.String("data", string(event.Data)),
+	)
+	return nil
+}
@@ -0,0 +1,130 @@
+package events_test
+
+import (
+	"backend-app/internal/events"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var event = events.Event{
+	ID:         "user.created:1:1",
+	Type:       events.TypeUserCreated,
+	UserID:     1,
+	OccurredAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	Data:       json.RawMessage(`{"id":1}`),
+}
+
+func TestWebhookPublisher(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "accepted", status: http.StatusNoContent},
+		{name: "server error", status: http.StatusBadGateway, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, event.ID, r.Header.Get("Idempotency-Key"))
+				assert.Equal(t, event.Type, r.Header.Get("X-Event-Type"))
+
+				var got events.Event
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+				assert.Equal(t, event.ID, got.ID)
+				assert.JSONEq(t, `{"id":1}`, string(got.Data))
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			err := events.WebhookPublisher{URL: srv.URL}.Publish(t.Context(), event)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// serveNATS отвечает как сервер NATS на одно подключение и возвращает принятые HPUB.
+func serveNATS(t *testing.T, ln net.Listener, reply string) <-chan string {
+	msgs := make(chan string, 1)
+	go func() {
+		defer close(msgs)
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		fmt.Fprint(conn, "INFO {\"headers\":true}\r\n")
+
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch {
+			case strings.HasPrefix(line, "PING"):
+				fmt.Fprint(conn, "PONG\r\n")
+			case strings.HasPrefix(line, "HPUB"):
+				var subject string
+				var hdrLen, total int
+				fmt.Sscanf(line, "HPUB %s %d %d", &subject, &hdrLen, &total)
+				body := make([]byte, total+2)
+				if _, err := io.ReadFull(r, body); err != nil {
+					return
+				}
+				msgs <- subject + "\n" + string(body[:total])
+				if reply != "" {
+					fmt.Fprint(conn, reply)
+				}
+			}
+		}
+	}()
+	return msgs
+}
+
+func TestNATSPublisher(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	msgs := serveNATS(t, ln, "")
+
+	p := &events.NATSPublisher{URL: "nats://" + ln.Addr().String(), Subject: "users", Timeout: time.Second}
+	defer p.Close()
+	require.NoError(t, p.Publish(t.Context(), event))
+
+	msg := <-msgs
+	subject, rest, _ := strings.Cut(msg, "\n")
+	assert.Equal(t, "users.user.created", subject)
+	header, body, _ := strings.Cut(rest, "\r\n\r\n")
+	assert.Contains(t, header, "Nats-Msg-Id: user.created:1:1")
+	assert.Contains(t, body, `"id":"user.created:1:1"`)
+}
+
+func TestNATSPublisherError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	serveNATS(t, ln, "-ERR 'Permissions Violation for Publish to users.user.created'\r\n")
+
+	p := &events.NATSPublisher{URL: "nats://" + ln.Addr().String(), Subject: "users", Timeout: time.Second}
+	defer p.Close()
+	err = p.Publish(t.Context(), event)
+	assert.ErrorContains(t, err, "Permissions Violation")
+}
@@ -0,0 +1,101 @@
+package fieldcrypt
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// SerializerName - сериализатор gorm для шифруемых строковых полей:
+//
+//	Email string `gorm:"serializer:encrypted"`
+const SerializerName = "encrypted"
+
+func init() {
+	schema.RegisterSerializer(SerializerName, Serializer{})
+}
+
+type ctxKey struct{}
+
+// Plugin передаёт Keyring сериализатору через контекст запросов gorm, поэтому
+// у каждого подключения могут быть свои ключи.
+type Plugin struct {
+	Keyring *Keyring
+}
+
+func (Plugin) Name() string {
+	return "fieldcrypt"
+}
+
+func (p Plugin) Initialize(db *gorm.DB) error {
+	const name = "fieldcrypt:keyring"
+	attach := func(db *gorm.DB) {
+		db.Statement.Context = context.WithValue(db.Statement.Context, ctxKey{}, p.Keyring)
+	}
+
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().Before("gorm:create").Register(name, attach),
+		cb.Query().Before("gorm:query").Register(name, attach),
+		cb.Update().Before("gorm:update").Register(name, attach),
+		cb.Row().Before("gorm:row").Register(name, attach),
+		cb.Raw().Before("gorm:raw").Register(name, attach),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func keyringFrom(ctx context.Context) *Keyring {
+	k, _ := ctx.Value(ctxKey{}).(*Keyring)
+	return k
+}
+
+// Serializer шифрует строковое поле при записи и расшифровывает при чтении. Без
+// Plugin значения пишутся открыто, а открытые значения читаются всегда: так
+// шифрование можно включить на уже заполненной базе.
+type Serializer struct{}
+
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var value string
+	switch v := dbValue.(type) {
+	case nil:
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("fieldcrypt: unsupported value type %T for %s", dbValue, field.Name)
+	}
+
+	if IsEncrypted(value) {
+		k := keyringFrom(ctx)
+		if k == nil {
+			return fmt.Errorf("%s: %w", field.DBName, ErrNoKeyring)
+		}
+		var err error
+		if value, err = k.Decrypt(field.DBName, value); err != nil {
+			return fmt.Errorf("%s: %w", field.DBName, err)
+		}
+	}
+	field.ReflectValueOf(ctx, dst).SetString(value)
+	return nil
+}
+
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	value, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("fieldcrypt: unsupported field type %T for %s", fieldValue, field.Name)
+	}
+	k := keyringFrom(ctx)
+	// пустые строки не шифруем: в них нечего прятать
+	if k == nil || value == "" {
+		return value, nil
+	}
+	return k.Encrypt(field.DBName, value)
+}
@@ -0,0 +1,218 @@
+// Package fieldcrypt шифрует отдельные колонки с персональными данными (envelope
+// encryption) и строит для них слепые индексы, по которым работают поиск на
+// равенство и ограничения unique.
+//
+// Каждое значение шифруется своим случайным ключом данных (DEK, AES-256-GCM), а DEK
+// шифруется ключом шифрования ключей (KEK) из конфигурации. В значении хранится
+// идентификатор KEK, поэтому после смены текущего ключа старые строки читаются, пока
+// их не перешифрует команда rotate-keys.
+package fieldcrypt
+
+import (
+	"backend-app/internal/config"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// prefix отличает зашифрованные значения от открытых, записанных до включения шифрования.
+const prefix = "enc:v1:"
+
+const keySize = 32
+
+var (
+	ErrUnknownKey = errors.New("unknown encryption key")
+	ErrMalformed  = errors.New("malformed encrypted value")
+	// ErrNoKeyring - в базе зашифрованное значение, а ключи не настроены.
+	ErrNoKeyring = errors.New("value is encrypted but encryption is not configured")
+)
+
+// Keyring хранит KEK по идентификаторам и ключ слепых индексов.
+type Keyring struct {
+	keks    map[string]cipher.AEAD
+	current string
+	index   []byte
+}
+
+// New создаёт Keyring. Ключи - 32 байта, current - идентификатор ключа для новых записей.
+// Ключ индексов не ротируется: от него зависят все сохранённые индексы.
+func New(keys map[string][]byte, current string, indexKey []byte) (*Keyring, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("%w: current key %q", ErrUnknownKey, current)
+	}
+	if len(indexKey) != keySize {
+		return nil, fmt.Errorf("index key must be %d bytes", keySize)
+	}
+	k := &Keyring{keks: make(map[string]cipher.AEAD, len(keys)), current: current, index: indexKey}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid key id %q", id)
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("key %q must be %d bytes", id, keySize)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		k.keks[id] = aead
+	}
+	return k, nil
+}
+
+// keyFile - формат файла encryption.key_file, ключи в base64.
+type keyFile struct {
+	Current  string            `json:"current"`
+	Keys     map[string]string `json:"keys"`
+	IndexKey string            `json:"indexKey"`
+}
+
+// Load читает ключи из файла cfg.KeyFile или из самой конфигурации. Если ключи не
+// заданы, шифрование выключено и Load возвращает nil.
+func Load(cfg config.Encryption) (*Keyring, error) {
+	kf := keyFile{Current: cfg.CurrentKey, Keys: cfg.Keys, IndexKey: cfg.IndexKey}
+	if cfg.KeyFile != "" {
+		b, err := os.ReadFile(cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read key file: %w", err)
+		}
+		if err := json.Unmarshal(b, &kf); err != nil {
+			return nil, fmt.Errorf("parse key file: %w", err)
+		}
+	}
+	if len(kf.Keys) == 0 {
+		return nil, nil
+	}
+
+	keys := make(map[string][]byte, len(kf.Keys))
+	for id, s := range kf.Keys {
+		key, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("key %q is not valid base64: %w", id, err)
+		}
+		keys[id] = key
+	}
+	indexKey, err := base64.StdEncoding.DecodeString(kf.IndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("index key is not valid base64: %w", err)
+	}
+	return New(keys, kf.Current, indexKey)
+}
+
+// Encrypt шифрует значение колонки column. Имя колонки входит в аутентифицируемые
+// данные, так что шифртекст нельзя незаметно перенести в другую колонку.
+func (k *Keyring) Encrypt(column, plaintext string) (string, error) {
+	dek := make([]byte, keySize)
+	if _, err := rand.Read(dek); err != nil {
+		return "", err
+	}
+	data, err := newAEAD(dek)
+	if err != nil {
+		return "", err
+	}
+
+	kek := k.keks[k.current]
+	wrapped, err := seal(kek, dek, nil)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(data, []byte(plaintext), []byte(column))
+	if err != nil {
+		return "", err
+	}
+	return prefix + k.current + ":" + base64.RawStdEncoding.EncodeToString(append(wrapped, sealed...)), nil
+}
+
+// Decrypt расшифровывает значение колонки column. Открытые значения, записанные до
+// включения шифрования, возвращаются как есть.
+func (k *Keyring) Decrypt(column, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	id, body, ok := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !ok {
+		return "", ErrMalformed
+	}
+	kek, ok := k.keks[id]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+	raw, err := base64.RawStdEncoding.DecodeString(body)
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	wrappedSize := kek.NonceSize() + keySize + kek.Overhead()
+	if len(raw) < wrappedSize {
+		return "", ErrMalformed
+	}
+	dek, err := open(kek, raw[:wrappedSize], nil)
+	if err != nil {
+		return "", err
+	}
+	data, err := newAEAD(dek)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(data, raw[wrappedSize:], []byte(column))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Current сообщает, зашифровано ли значение текущим ключом, то есть не нужно ли
+// его перешифровать.
+func (k *Keyring) Current(value string) bool {
+	return strings.HasPrefix(value, prefix+k.current+":")
+}
+
+// BlindIndex - детерминированный HMAC-SHA256 значения колонки column. По нему ищут
+// на точное совпадение, само значение из индекса не восстановить.
+func (k *Keyring) BlindIndex(column, value string) string {
+	mac := hmac.New(sha256.New, k.index)
+	mac.Write([]byte(column))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return base64.RawStdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal возвращает nonce и шифртекст одним срезом.
+func seal(aead cipher.AEAD, plaintext, ad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, ad), nil
+}
+
+func open(aead cipher.AEAD, sealed, ad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], ad)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return plaintext, nil
+}
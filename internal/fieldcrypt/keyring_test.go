@@ -0,0 +1,138 @@
+package fieldcrypt_test
+
+import (
+	"backend-app/internal/config"
+	"backend-app/internal/fieldcrypt"
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func key(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func newKeyring(t *testing.T, current string) *fieldcrypt.Keyring {
+	k, err := fieldcrypt.New(map[string][]byte{"k1": key(1), "k2": key(2)}, current, key(9))
+	require.NoError(t, err)
+	return k
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	k := newKeyring(t, "k1")
+
+	a, err := k.Encrypt("email", "alice@example.com")
+	require.NoError(t, err)
+	b, err := k.Encrypt("email", "alice@example.com")
+	require.NoError(t, err)
+	assert.NotEqual(t, a, b, "every value gets its own data key and nonce")
+	assert.NotContains(t, a, "alice")
+	assert.True(t, fieldcrypt.IsEncrypted(a))
+
+	got, err := k.Decrypt("email", a)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", got)
+
+	_, err = k.Decrypt("country", a)
+	assert.ErrorIs(t, err, fieldcrypt.ErrMalformed, "ciphertext is bound to its column")
+
+	got, err = k.Decrypt("email", "plain@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "plain@example.com", got, "values written before encryption are read as is")
+}
+
+func TestDecryptErrors(t *testing.T) {
+	k := newKeyring(t, "k1")
+	v, err := k.Encrypt("email", "alice@example.com")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		value string
+		err   error
+	}{
+		{"unknown key", strings.Replace(v, ":k1:", ":k3:", 1), fieldcrypt.ErrUnknownKey},
+		{"no key id", "enc:v1:k1", fieldcrypt.ErrMalformed},
+		{"bad base64", "enc:v1:k1:!!!", fieldcrypt.ErrMalformed},
+		{"truncated", v[:len(v)-10], fieldcrypt.ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := k.Decrypt("email", tt.value)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestRotation(t *testing.T) {
+	old := newKeyring(t, "k1")
+	v, err := old.Encrypt("email", "alice@example.com")
+	require.NoError(t, err)
+	assert.True(t, old.Current(v))
+
+	rotated := newKeyring(t, "k2")
+	assert.False(t, rotated.Current(v))
+	got, err := rotated.Decrypt("email", v)
+	require.NoError(t, err, "old keys keep working after rotation")
+	assert.Equal(t, "alice@example.com", got)
+	assert.False(t, rotated.Current("alice@example.com"), "plaintext needs encryption")
+}
+
+func TestBlindIndex(t *testing.T) {
+	k1, k2 := newKeyring(t, "k1"), newKeyring(t, "k2")
+
+	assert.Equal(t, k1.BlindIndex("email", "alice@example.com"), k2.BlindIndex("email", "alice@example.com"),
+		"indexes do not depend on the current key")
+	assert.NotEqual(t, k1.BlindIndex("email", "alice@example.com"), k1.BlindIndex("email", "bob@example.com"))
+	assert.NotEqual(t, k1.BlindIndex("email", "NL"), k1.BlindIndex("country", "NL"))
+}
+
+func TestNew(t *testing.T) {
+	_, err := fieldcrypt.New(map[string][]byte{"k1": key(1)}, "k2", key(9))
+	assert.ErrorIs(t, err, fieldcrypt.ErrUnknownKey)
+	_, err = fieldcrypt.New(map[string][]byte{"k1": key(1)[:16]}, "k1", key(9))
+	assert.Error(t, err)
+	_, err = fieldcrypt.New(map[string][]byte{"k:1": key(1)}, "k:1", key(9))
+	assert.Error(t, err)
+	_, err = fieldcrypt.New(map[string][]byte{"k1": key(1)}, "k1", nil)
+	assert.Error(t, err)
+}
+
+func TestLoad(t *testing.T) {
+	b64 := base64.StdEncoding.EncodeToString
+
+	k, err := fieldcrypt.Load(config.Encryption{})
+	require.NoError(t, err)
+	assert.Nil(t, k, "no keys - encryption is disabled")
+
+	k, err = fieldcrypt.Load(config.Encryption{
+		Keys:       map[string]string{"k1": b64(key(1))},
+		CurrentKey: "k1",
+		IndexKey:   b64(key(9)),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, k)
+
+	path := filepath.Join(t.TempDir(), "keys.json")
+	file := `{"current": "k2", "keys": {"k1": "` + b64(key(1)) + `", "k2": "` + b64(key(2)) + `"}, "indexKey": "` + b64(key(9)) + `"}`
+	require.NoError(t, os.WriteFile(path, []byte(file), 0o600))
+	fromFile, err := fieldcrypt.Load(config.Encryption{KeyFile: path})
+	require.NoError(t, err)
+	v, err := k.Encrypt("email", "alice@example.com")
+	require.NoError(t, err)
+	assert.False(t, fromFile.Current(v), "the file sets k2 as current")
+	got, err := fromFile.Decrypt("email", v)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", got)
+
+	_, err = fieldcrypt.Load(config.Encryption{Keys: map[string]string{"k1": "not base64"}, CurrentKey: "k1"})
+	assert.Error(t, err)
+	_, err = fieldcrypt.Load(config.Encryption{KeyFile: filepath.Join(t.TempDir(), "missing.json")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package dataexport
+
+import (
+	"backend-app/internal/privacy"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/sl"
+	"context"
+	"log/slog"
+	"time"
+)
+
+type Store interface {
+	privacy.Source
+	PendingDataExports(ctx context.Context, limit int) ([]models.DataExport, error)
+	CompleteDataExport(ctx context.Context, export *models.DataExport) error
+	DeleteExpiredDataExports(ctx context.Context, before time.Time) (int64, error)
+}
+
+type Options struct {
+	Interval  time.Duration
+	BatchSize int
+	// Retention - сколько готовый архив доступен для скачивания
+	Retention time.Duration
+}
+
+// Run раз в interval собирает архивы для ожидающих выгрузок и удаляет просроченные,
+// пока не отменён ctx. Если запущено несколько экземпляров, архив может быть собран
+// дважды, это безопасно: результат одинаковый.
+func Run(ctx context.Context, log *slog.Logger, store Store, opts Options) {
+	const op = "jobs.dataexport.Run"
+
+	log = log.With(slog.String("op", op))
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := Flush(ctx, log, store, opts)
+			if err != nil {
+				log.Error("failed to get pending data exports", sl.Error(err))
+			}
+			if err != nil || n < opts.BatchSize {
+				break
+			}
+		}
+		deleted, err := store.DeleteExpiredDataExports(ctx, time.Now())
+		if err != nil {
+			log.Error("failed to delete expired data exports", sl.Error(err))
+		} else if deleted > 0 {
+			log.Info("expired data exports deleted", slog.Int64("count", deleted))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush собирает архивы для одной пачки выгрузок и возвращает, сколько из них сохранено.
+func Flush(ctx context.Context, log *slog.Logger, store Store, opts Options) (int, error) {
+	pending, err := store.PendingDataExports(ctx, opts.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	saved := 0
+	for i := range pending {
+		export := &pending[i]
+		log := log.With(slog.Uint64("export_id", uint64(export.ID)), slog.Uint64("user_id", uint64(export.UserID)))
+
+		now := time.Now()
+		expires := now.Add(opts.Retention)
+		export.CompletedAt = &now
+		export.ExpiresAt = &expires
+
+		archive, err := privacy.Collect(ctx, store, export.UserID)
+		if err == nil {
+			export.Archive, err = archive.Encode(export.Format)
+		}
+		if err != nil {
+			// ошибку видит пользователь, он может запросить выгрузку ещё раз
+			log.Error("failed to build data export", sl.Error(err))
+			export.Status = models.ExportFailed
+			export.Error = "failed to build archive"
+			export.Archive = nil
+		} else {
+			export.Status = models.ExportReady
+		}
+
+		if err := store.CompleteDataExport(ctx, export); err != nil {
+			log.Error("failed to save data export", sl.Error(err))
+			continue
+		}
+		saved++
+		log.Info("data export completed", slog.String("status", export.Status))
+	}
+	return saved, nil
+}
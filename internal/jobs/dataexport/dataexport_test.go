@@ -0,0 +1,55 @@
+package dataexport_test
+
+import (
+	"backend-app/internal/jobs/dataexport"
+	"backend-app/internal/privacy"
+	"backend-app/internal/storage/memory"
+	"backend-app/internal/storage/models"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var opts = dataexport.Options{
+	Interval:  time.Second,
+	BatchSize: 10,
+	Retention: time.Hour,
+}
+
+func TestFlush(t *testing.T) {
+	store := memory.New()
+	user := &models.User{Username: "alice", Email: "alice@example.com", Password: "hash", Role: "user", Country: "NL"}
+	require.NoError(t, store.CreateUser(t.Context(), user))
+
+	ok := &models.DataExport{UserID: user.ID, Format: privacy.FormatJSON, Status: models.ExportPending}
+	require.NoError(t, store.CreateDataExport(t.Context(), ok))
+	missing := &models.DataExport{UserID: 999, Format: privacy.FormatJSON, Status: models.ExportPending}
+	require.NoError(t, store.CreateDataExport(t.Context(), missing))
+
+	n, err := dataexport.Flush(t.Context(), slog.Default(), store, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	got, err := store.GetDataExport(t.Context(), ok.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExportReady, got.Status)
+	require.NotNil(t, got.ExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(opts.Retention), *got.ExpiresAt, time.Minute)
+	var archive privacy.Archive
+	require.NoError(t, json.Unmarshal(got.Archive, &archive))
+	assert.Equal(t, "alice", archive.Profile.Username)
+
+	got, err = store.GetDataExport(t.Context(), missing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExportFailed, got.Status)
+	assert.NotEmpty(t, got.Error)
+	assert.Empty(t, got.Archive)
+
+	n, err = dataexport.Flush(t.Context(), slog.Default(), store, opts)
+	require.NoError(t, err)
+	assert.Zero(t, n, "completed exports are not built again")
+}
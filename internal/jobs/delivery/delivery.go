@@ -0,0 +1,130 @@
+package delivery
+
+import (
+	"backend-app/internal/jobs/relay"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"backend-app/internal/webhooks"
+	"backend-app/pkg/sl"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type Store interface {
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	GetWebhook(ctx context.Context, id uint) (*models.Webhook, error)
+	UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+}
+
+// Options настраивает отправку webhooks.
+type Options struct {
+	// Interval - пауза между проверками очереди и первая задержка повтора
+	Interval  time.Duration
+	BatchSize int
+	Lease     time.Duration
+	// MaxAttempts - после стольких неудач доставка переходит в статус dead
+	MaxAttempts int
+	MaxBackoff  time.Duration
+	// Timeout ограничивает один запрос к webhook
+	Timeout time.Duration
+	Client  *http.Client
+}
+
+// Run раз в interval отправляет доставки, которым пришло время, пока не отменён ctx.
+func Run(ctx context.Context, log *slog.Logger, store Store, opts Options) {
+	const op = "jobs.delivery.Run"
+
+	log = log.With(slog.String("op", op))
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := Flush(ctx, log, store, opts)
+			if err != nil {
+				log.Error("failed to claim webhook deliveries", sl.Error(err))
+			}
+			if err != nil || n < opts.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush отправляет одну пачку доставок и возвращает её размер.
+func Flush(ctx context.Context, log *slog.Logger, store Store, opts Options) (int, error) {
+	claimed, err := store.ClaimDeliveries(ctx, opts.BatchSize, opts.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range claimed {
+		d := &claimed[i]
+		log := log.With(slog.Uint64("delivery_id", uint64(d.ID)), slog.Uint64("webhook_id", uint64(d.WebhookID)))
+
+		hook, err := store.GetWebhook(ctx, d.WebhookID)
+		if errors.Is(err, storage.ErrWebhookNotFound) {
+			// webhook удалили вместе с доставками, пока пачка была у нас
+			continue
+		}
+		if err != nil {
+			log.Error("failed to get webhook", sl.Error(err))
+			continue
+		}
+
+		Attempt(ctx, hook, d, opts)
+		if d.Status == models.DeliveryDead {
+			log.Warn("webhook delivery is dead", slog.Int("attempts", d.Attempts), slog.String("error", d.LastError))
+		}
+		if err := store.UpdateDelivery(ctx, d); err != nil {
+			// аренда истечёт, и доставку отправят ещё раз
+			log.Error("failed to save webhook delivery", sl.Error(err))
+		}
+	}
+	return len(claimed), nil
+}
+
+// Attempt делает одну попытку доставки и записывает её результат в d: delivered,
+// pending с экспоненциальной задержкой или dead, если попытки кончились
+// или webhook отключён.
+func Attempt(ctx context.Context, hook *models.Webhook, d *models.WebhookDelivery, opts Options) {
+	if !hook.Active {
+		d.Status = models.DeliveryDead
+		d.LastError = "webhook is disabled"
+		return
+	}
+
+	sendCtx := ctx
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		sendCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	status, err := webhooks.Send(sendCtx, opts.Client, hook, d)
+
+	d.Attempts++
+	d.ResponseStatus = status
+	switch {
+	case err == nil:
+		now := time.Now()
+		d.Status = models.DeliveryDelivered
+		d.DeliveredAt = &now
+		d.LastError = ""
+	case d.Attempts >= opts.MaxAttempts:
+		d.Status = models.DeliveryDead
+		d.LastError = err.Error()
+	default:
+		d.Status = models.DeliveryPending
+		d.LastError = err.Error()
+		d.NextAttemptAt = time.Now().Add(relay.Backoff(d.Attempts-1, opts.Interval, opts.MaxBackoff))
+	}
+}
@@ -0,0 +1,103 @@
+package delivery_test
+
+import (
+	"backend-app/internal/jobs/delivery"
+	"backend-app/internal/storage/memory"
+	"backend-app/internal/storage/models"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var opts = delivery.Options{
+	Interval:    time.Second,
+	BatchSize:   10,
+	Lease:       time.Minute,
+	MaxAttempts: 3,
+	MaxBackoff:  time.Minute,
+	Timeout:     time.Second,
+}
+
+func TestAttempt(t *testing.T) {
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ok.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	tests := []struct {
+		name       string
+		url        string
+		active     bool
+		attempts   int
+		wantStatus string
+		wantRetry  bool
+		wantCode   int
+	}{
+		{"delivered", ok.URL, true, 0, models.DeliveryDelivered, false, http.StatusOK},
+		{"retried", failing.URL, true, 0, models.DeliveryPending, true, http.StatusInternalServerError},
+		{"dead after max attempts", failing.URL, true, 2, models.DeliveryDead, false, http.StatusInternalServerError},
+		{"disabled webhook", ok.URL, false, 0, models.DeliveryDead, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := &models.Webhook{ID: 1, URL: tt.url, Secret: "secret", Active: tt.active}
+			d := &models.WebhookDelivery{ID: 1, WebhookID: 1, Payload: `{}`, Status: models.DeliveryPending, Attempts: tt.attempts}
+			start := time.Now()
+			delivery.Attempt(t.Context(), hook, d, opts)
+
+			assert.Equal(t, tt.wantStatus, d.Status)
+			assert.Equal(t, tt.wantCode, d.ResponseStatus)
+			if tt.wantRetry {
+				assert.False(t, d.NextAttemptAt.Before(start.Add(opts.Interval)))
+			}
+			if tt.wantStatus == models.DeliveryDelivered {
+				assert.NotNil(t, d.DeliveredAt)
+				assert.Empty(t, d.LastError)
+			} else {
+				assert.NotEmpty(t, d.LastError)
+			}
+		})
+	}
+}
+
+func TestFlush(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer srv.Close()
+
+	store := memory.New()
+	hook := &models.Webhook{URL: srv.URL, Secret: "secret", Events: "user.created", Active: true}
+	require.NoError(t, store.CreateWebhook(t.Context(), hook))
+	require.NoError(t, store.EnqueueDeliveries(t.Context(), []models.WebhookDelivery{{
+		WebhookID:     hook.ID,
+		EventID:       "user.created:1:1",
+		EventType:     "user.created",
+		Payload:       `{}`,
+		Status:        models.DeliveryPending,
+		NextAttemptAt: time.Now(),
+	}}))
+
+	n, err := delivery.Flush(t.Context(), slog.Default(), store, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 1, calls)
+
+	list, err := store.ListDeliveries(t.Context(), hook.ID, "", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, models.DeliveryDelivered, list[0].Status)
+	assert.Equal(t, 1, list[0].Attempts)
+
+	n, err = delivery.Flush(t.Context(), slog.Default(), store, opts)
+	require.NoError(t, err)
+	assert.Zero(t, n, "delivered webhooks are not sent again")
+}
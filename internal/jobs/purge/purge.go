@@ -0,0 +1,56 @@
+package purge
+
+import (
+	"backend-app/pkg/sl"
+	"context"
+	"log/slog"
+	"time"
+)
+
+type Purger interface {
+	PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, error)
+	DeleteLogins(ctx context.Context, before time.Time) (int64, error)
+}
+
+type Options struct {
+	// Retention - сколько удалённые пользователи хранятся до окончательного удаления
+	Retention time.Duration
+	// LoginRetention - сколько хранятся входы для статистики, 0 - всегда
+	LoginRetention time.Duration
+	Interval       time.Duration
+}
+
+// Run раз в opts.Interval окончательно удаляет пользователей, которые лежат
+// удалёнными дольше opts.Retention, и входы старше opts.LoginRetention. Работает,
+// пока не отменён ctx.
+func Run(ctx context.Context, log *slog.Logger, purger Purger, opts Options) {
+	const op = "jobs.purge.Run"
+
+	log = log.With(slog.String("op", op))
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := purger.PurgeDeletedUsers(ctx, time.Now().Add(-opts.Retention))
+		if err != nil {
+			log.Error("failed to purge deleted users", sl.Error(err))
+		} else if purged > 0 {
+			log.Info("deleted users purged", slog.Int64("count", purged))
+		}
+
+		if opts.LoginRetention > 0 {
+			deleted, err := purger.DeleteLogins(ctx, time.Now().Add(-opts.LoginRetention))
+			if err != nil {
+				log.Error("failed to delete old logins", sl.Error(err))
+			} else if deleted > 0 {
+				log.Info("old logins deleted", slog.Int64("count", deleted))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
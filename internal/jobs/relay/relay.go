@@ -0,0 +1,119 @@
+package relay
+
+import (
+	"backend-app/internal/events"
+	"backend-app/internal/storage/models"
+	"backend-app/pkg/sl"
+	"context"
+	"log/slog"
+	"time"
+)
+
+type Outbox interface {
+	ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error)
+	MarkPublished(ctx context.Context, id uint) error
+	MarkFailed(ctx context.Context, id uint, reason string, retryAt time.Time) error
+	DeletePublishedEvents(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Options настраивает relay.
+type Options struct {
+	// Interval - пауза между проверками outbox и первая задержка повтора после ошибки
+	Interval  time.Duration
+	BatchSize int
+	// Lease - сколько событие скрыто от других relay, должно быть больше времени публикации пачки
+	Lease time.Duration
+	// MaxBackoff ограничивает экспоненциальную задержку повторов
+	MaxBackoff time.Duration
+	// Retention - сколько хранятся опубликованные события
+	Retention time.Duration
+	// PublishTimeout ограничивает публикацию одного события, 0 - без ограничения
+	PublishTimeout time.Duration
+}
+
+// Run раз в interval публикует накопившиеся события outbox через publisher,
+// пока не отменён ctx. Несколько экземпляров могут работать одновременно.
+func Run(ctx context.Context, log *slog.Logger, outbox Outbox, publisher events.Publisher, opts Options) {
+	const op = "jobs.relay.Run"
+
+	log = log.With(slog.String("op", op))
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		// полная пачка - признак, что в outbox есть ещё события, забираем их без паузы
+		for {
+			n, err := Flush(ctx, log, outbox, publisher, opts)
+			if err != nil {
+				log.Error("failed to claim outbox events", sl.Error(err))
+			}
+			if err != nil || n < opts.BatchSize {
+				break
+			}
+		}
+
+		deleted, err := outbox.DeletePublishedEvents(ctx, time.Now().Add(-opts.Retention))
+		if err != nil {
+			log.Error("failed to delete published events", sl.Error(err))
+		} else if deleted > 0 {
+			log.Info("published events deleted", slog.Int64("count", deleted))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush забирает одну пачку событий и публикует их по порядку. Возвращает размер
+// пачки; ошибки публикации отдельных событий не прерывают пачку, а откладывают
+// эти события с экспоненциальной задержкой.
+func Flush(ctx context.Context, log *slog.Logger, outbox Outbox, publisher events.Publisher, opts Options) (int, error) {
+	claimed, err := outbox.ClaimEvents(ctx, opts.BatchSize, opts.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, e := range claimed {
+		if err := publish(ctx, publisher, e, opts.PublishTimeout); err != nil {
+			retryAt := time.Now().Add(Backoff(e.Attempts, opts.Interval, opts.MaxBackoff))
+			log.Warn("failed to publish event",
+				slog.String("key", e.Key),
+				slog.Int("attempts", e.Attempts+1),
+				slog.Time("retry_at", retryAt),
+				sl.Error(err),
+			)
+			if err := outbox.MarkFailed(ctx, e.ID, err.Error(), retryAt); err != nil {
+				// аренда истечёт, и событие заберут снова
+				log.Error("failed to mark event as failed", slog.String("key", e.Key), sl.Error(err))
+			}
+			continue
+		}
+		if err := outbox.MarkPublished(ctx, e.ID); err != nil {
+			// событие опубликуют ещё раз, подписчики отбросят дубль по ключу
+			log.Error("failed to mark event as published", slog.String("key", e.Key), sl.Error(err))
+		}
+	}
+	return len(claimed), nil
+}
+
+func publish(ctx context.Context, publisher events.Publisher, e models.OutboxEvent, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return publisher.Publish(ctx, events.FromOutbox(e))
+}
+
+// Backoff возвращает задержку перед повтором после attempts неудачных попыток:
+// base, 2*base, 4*base и так далее, но не больше limit.
+func Backoff(attempts int, base time.Duration, limit time.Duration) time.Duration {
+	d := base
+	for i := 0; i < attempts && d < limit; i++ {
+		d *= 2
+	}
+	return min(d, limit)
+}
@@ -0,0 +1,90 @@
+package relay_test
+
+import (
+	"backend-app/internal/events"
+	"backend-app/internal/jobs/relay"
+	"backend-app/internal/storage/memory"
+	"backend-app/internal/storage/models"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPublisher struct {
+	published []events.Event
+	fail      map[string]bool
+}
+
+func (m *mockPublisher) Publish(ctx context.Context, event events.Event) error {
+	if m.fail[event.Type] {
+		return errors.New("broker unavailable")
+	}
+	m.published = append(m.published, event)
+	return nil
+}
+
+var opts = relay.Options{
+	Interval:   time.Second,
+	BatchSize:  10,
+	Lease:      time.Minute,
+	MaxBackoff: time.Minute,
+	Retention:  time.Hour,
+}
+
+func TestFlush(t *testing.T) {
+	store := memory.New()
+	user := &models.User{Username: "alice", Email: "alice@example.com", Password: "x", Role: "user"}
+	require.NoError(t, store.CreateUser(t.Context(), user))
+	user.Role = "admin"
+	require.NoError(t, store.UpdateUser(t.Context(), user))
+
+	publisher := &mockPublisher{fail: map[string]bool{events.TypeUserRoleChanged: true}}
+	n, err := relay.Flush(t.Context(), slog.Default(), store, publisher, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	require.Len(t, publisher.published, 2, "a failed event does not stop the batch")
+	assert.Equal(t, events.TypeUserCreated, publisher.published[0].Type)
+	assert.Equal(t, events.TypeUserUpdated, publisher.published[1].Type)
+	assert.Equal(t, user.ID, publisher.published[0].UserID)
+	assert.JSONEq(t, `{
+		"id": 1,
+		"username": "alice",
+		"email": "alice@example.com",
+		"role": "user",
+		"country": "",
+		"status": "active",
+		"verified": false,
+		"version": 1
+	}`, string(publisher.published[0].Data))
+
+	n, err = relay.Flush(t.Context(), slog.Default(), store, publisher, opts)
+	require.NoError(t, err)
+	assert.Zero(t, n, "published events are not claimed again, failed ones wait for backoff")
+
+	claimed, err := store.ClaimEvents(t.Context(), 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{3, 8 * time.Second},
+		{6, time.Minute},
+		{100, time.Minute},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, relay.Backoff(tt.attempts, time.Second, time.Minute), "attempts %d", tt.attempts)
+	}
+}
@@ -0,0 +1,155 @@
+// Package permissions описывает права, которые дают роли и группы, и собирает
+// действующие права пользователя с объяснением, откуда каждое взялось.
+package permissions
+
+import (
+	"backend-app/internal/storage/models"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+const (
+	ProfileRead   = "profile:read"
+	ProfileWrite  = "profile:write"
+	ContentCreate = "content:create"
+
+	UsersRead      = "users:read"
+	UsersWrite     = "users:write"
+	UsersDelete    = "users:delete"
+	UsersImport    = "users:import"
+	UsersExport    = "users:export"
+	WebhooksManage = "webhooks:manage"
+	ErasureReview  = "erasure:review"
+	GroupsManage   = "groups:manage"
+	OrgsManage     = "orgs:manage"
+)
+
+var (
+	userPerms    = []string{ProfileRead, ProfileWrite}
+	creatorPerms = []string{ProfileRead, ProfileWrite, ContentCreate}
+	adminPerms   = []string{
+		ProfileRead, ProfileWrite, ContentCreate,
+		UsersRead, UsersWrite, UsersDelete, UsersImport, UsersExport,
+		WebhooksManage, ErasureReview, GroupsManage,
+	}
+)
+
+// Roles - права каждой роли.
+var Roles = map[string][]string{
+	"user":                userPerms,
+	"creator":             creatorPerms,
+	"combined":            creatorPerms,
+	models.RoleAdmin:      adminPerms,
+	models.RoleSuperAdmin: append(slices.Clone(adminPerms), OrgsManage),
+}
+
+var permissionFormat = regexp.MustCompile(`^[a-z][a-z0-9_-]*:[a-z][a-z0-9_-]*$`)
+
+// Check проверяет право, которое выдают группе. Кроме прав из каталога группа может
+// выдать права других сервисов вида "ресурс:действие", но не права администраторов
+// этого сервиса.
+func Check(perm string) error {
+	if !permissionFormat.MatchString(perm) {
+		return fmt.Errorf("permission %q must look like resource:action", perm)
+	}
+	// права администраторов - те, что есть у superadmin, но не у creator
+	if slices.Contains(Roles[models.RoleSuperAdmin], perm) && !slices.Contains(creatorPerms, perm) {
+		return fmt.Errorf("permission %q is granted only by the admin role", perm)
+	}
+	return nil
+}
+
+// Grant объясняет, откуда у пользователя право.
+type Grant struct {
+	Permission string
+	// Role - роль, которая дала право, пусто - право выдано группе напрямую
+	Role string
+	// Path - группы от той, в которой пользователь состоит, до той, что выдала право.
+	// Пусто - право дала роль самого пользователя.
+	Path []models.Group
+}
+
+// Resolve собирает права пользователя с ролью role, который напрямую состоит в
+// группах groups. Предки групп ищутся среди all.
+func Resolve(role string, groups []models.Group, all []models.Group) []Grant {
+	var grants []Grant
+	for _, perm := range Roles[role] {
+		grants = append(grants, Grant{Permission: perm, Role: role})
+	}
+
+	byID := make(map[uint]models.Group, len(all))
+	for _, g := range all {
+		byID[g.ID] = g
+	}
+	for _, g := range groups {
+		var path []models.Group
+		// visited защищает от цикла, если хранилище его всё же пропустило
+		visited := map[uint]bool{}
+		for group, ok := g, true; ok && !visited[group.ID]; group, ok = parent(byID, group) {
+			visited[group.ID] = true
+			path = append(path, group)
+			for _, r := range group.RoleList() {
+				for _, perm := range Roles[r] {
+					grants = append(grants, Grant{Permission: perm, Role: r, Path: slices.Clone(path)})
+				}
+			}
+			for _, perm := range group.PermissionList() {
+				grants = append(grants, Grant{Permission: perm, Path: slices.Clone(path)})
+			}
+		}
+	}
+	return grants
+}
+
+func parent(byID map[uint]models.Group, g models.Group) (models.Group, bool) {
+	if g.ParentID == nil {
+		return models.Group{}, false
+	}
+	p, ok := byID[*g.ParentID]
+	return p, ok
+}
+
+// Names возвращает права без повторов в порядке сортировки.
+func Names(grants []Grant) []string {
+	names := make([]string, 0, len(grants))
+	for _, g := range grants {
+		names = append(names, g.Permission)
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// Explain оставляет объяснения одного права. Пустой результат - права нет.
+func Explain(grants []Grant, perm string) []Grant {
+	res := []Grant{}
+	for _, g := range grants {
+		if g.Permission == perm {
+			res = append(res, g)
+		}
+	}
+	return res
+}
+
+type Groups interface {
+	ListGroups(ctx context.Context) ([]models.Group, error)
+	ListUserGroups(ctx context.Context, userID uint) ([]models.Group, error)
+}
+
+// Effective возвращает права пользователя userID, у которого роль role, вместе с
+// правами его групп.
+func Effective(ctx context.Context, groups Groups, userID uint, role string) ([]Grant, error) {
+	mine, err := groups.ListUserGroups(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(mine) == 0 {
+		return Resolve(role, nil, nil), nil
+	}
+	all, err := groups.ListGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Resolve(role, mine, all), nil
+}
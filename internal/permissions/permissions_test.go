@@ -0,0 +1,63 @@
+package permissions_test
+
+import (
+	"backend-app/internal/permissions"
+	"backend-app/internal/storage/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	assert.NoError(t, permissions.Check(permissions.ContentCreate))
+	assert.NoError(t, permissions.Check("dishes:publish"), "permissions of other services")
+	assert.Error(t, permissions.Check(permissions.UsersDelete), "admin permissions come only with the role")
+	assert.Error(t, permissions.Check(permissions.OrgsManage))
+	assert.Error(t, permissions.Check("publish"))
+	assert.Error(t, permissions.Check("Dishes:Publish"))
+}
+
+func groupIDs(path []models.Group) []uint {
+	ids := make([]uint, 0, len(path))
+	for _, g := range path {
+		ids = append(ids, g.ID)
+	}
+	return ids
+}
+
+func TestResolve(t *testing.T) {
+	root := models.Group{ID: 1, Name: "staff", Permissions: "dishes:read"}
+	parent := uint(1)
+	editors := models.Group{ID: 2, Name: "editors", ParentID: &parent, Roles: "creator", Permissions: "dishes:publish"}
+	other := models.Group{ID: 3, Name: "other", Permissions: "dishes:delete"}
+	all := []models.Group{root, editors, other}
+
+	grants := permissions.Resolve("user", []models.Group{editors}, all)
+	assert.Equal(t, []string{
+		"content:create", "dishes:publish", "dishes:read", "profile:read", "profile:write",
+	}, permissions.Names(grants))
+
+	profile := permissions.Explain(grants, permissions.ProfileRead)
+	require.Len(t, profile, 2, "from the user role and from the group role")
+	assert.Equal(t, "user", profile[0].Role)
+	assert.Empty(t, profile[0].Path)
+	assert.Equal(t, "creator", profile[1].Role)
+	assert.Equal(t, []uint{2}, groupIDs(profile[1].Path))
+
+	inherited := permissions.Explain(grants, "dishes:read")
+	require.Len(t, inherited, 1)
+	assert.Empty(t, inherited[0].Role)
+	assert.Equal(t, []uint{2, 1}, groupIDs(inherited[0].Path), "inherited from the parent group")
+
+	assert.Empty(t, permissions.Explain(grants, "dishes:delete"))
+}
+
+func TestResolveCycle(t *testing.T) {
+	one, two := uint(1), uint(2)
+	a := models.Group{ID: 1, ParentID: &two, Permissions: "a:read"}
+	b := models.Group{ID: 2, ParentID: &one, Permissions: "b:read"}
+
+	grants := permissions.Resolve("", []models.Group{a}, []models.Group{a, b})
+	assert.Equal(t, []string{"a:read", "b:read"}, permissions.Names(grants))
+}
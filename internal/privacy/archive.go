@@ -0,0 +1,177 @@
+// Package privacy собирает архив персональных данных пользователя, который он
+// может скачать по своему запросу.
+package privacy
+
+import (
+	"archive/zip"
+	"backend-app/internal/storage/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	FormatJSON = "json"
+	FormatZIP  = "zip"
+)
+
+var ErrUnknownFormat = errors.New("unknown format, expected json or zip")
+
+type Source interface {
+	GetUserByID(ctx context.Context, id uint) (*models.User, error)
+	ListUserEvents(ctx context.Context, userID uint) ([]models.OutboxEvent, error)
+	GetProfile(ctx context.Context, userID uint) (*models.Profile, error)
+}
+
+// Archive - всё, что сервис хранит о пользователе. Хеш пароля и сами токены в архив
+// не попадают: это секреты, а не данные о человеке.
+type Archive struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	Profile     Profile   `json:"profile"`
+	Sessions    []Session `json:"sessions"`
+	// Audit - события о пользователе, которые ещё хранятся в outbox
+	Audit []AuditEntry `json:"audit"`
+}
+
+type Profile struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Country   string    `json:"country"`
+	Status    string    `json:"status"`
+	Verified  bool      `json:"verified"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	DisplayName       string                 `json:"displayName"`
+	AvatarURL         string                 `json:"avatarUrl"`
+	Bio               string                 `json:"bio"`
+	Locale            string                 `json:"locale"`
+	TimeZone          string                 `json:"timeZone"`
+	NativeLanguages   []string               `json:"nativeLanguages"`
+	LearningLanguages []models.LanguageSkill `json:"learningLanguages"`
+	// Attributes - все сохранённые атрибуты, в том числе удалённые из схемы
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// Session - выданный refresh token. Сервис хранит один токен на пользователя,
+// поэтому сессий не больше одной.
+type Session struct {
+	ExpiresAt time.Time `json:"expiresAt"`
+	Active    bool      `json:"active"`
+}
+
+type AuditEntry struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Collect собирает архив пользователя userID.
+func Collect(ctx context.Context, src Source, userID uint) (*Archive, error) {
+	user, err := src.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := src.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	evs, err := src.ListUserEvents(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	archive := &Archive{
+		GeneratedAt: now,
+		Profile: Profile{
+			ID:        user.ID,
+			Username:  user.Username,
+			Email:     user.Email,
+			Role:      user.Role,
+			Country:   user.Country,
+			Status:    user.Status,
+			Verified:  user.Verified,
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
+
+			DisplayName:       profile.DisplayName,
+			AvatarURL:         profile.AvatarURL,
+			Bio:               profile.Bio,
+			Locale:            profile.Locale,
+			TimeZone:          profile.TimeZone,
+			NativeLanguages:   profile.NativeLanguageList(),
+			LearningLanguages: profile.LearningLanguageList(),
+			Attributes:        profile.Attributes,
+		},
+		Sessions: []Session{},
+		Audit:    make([]AuditEntry, 0, len(evs)),
+	}
+	if user.RefreshToken != "" {
+		archive.Sessions = append(archive.Sessions, Session{
+			ExpiresAt: user.TokenExpiry,
+			Active:    user.TokenExpiry.After(now),
+		})
+	}
+	for _, e := range evs {
+		archive.Audit = append(archive.Audit, AuditEntry{
+			ID:         e.Key,
+			Type:       e.Type,
+			OccurredAt: e.CreatedAt,
+			Data:       json.RawMessage(e.Payload),
+		})
+	}
+	return archive, nil
+}
+
+// Encode кодирует архив одним JSON-документом или ZIP с файлами profile.json,
+// sessions.json и audit.json.
+func (a *Archive) Encode(format string) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		return json.MarshalIndent(a, "", "  ")
+	case FormatZIP:
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		files := []struct {
+			name string
+			v    any
+		}{
+			{"profile.json", a.Profile},
+			{"sessions.json", a.Sessions},
+			{"audit.json", a.Audit},
+		}
+		for _, f := range files {
+			w, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: a.GeneratedAt})
+			if err != nil {
+				return nil, err
+			}
+			b, err := json.MarshalIndent(f.v, "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			if _, err := w.Write(b); err != nil {
+				return nil, err
+			}
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// ContentType возвращает MIME-тип архива в формате format.
+func ContentType(format string) string {
+	if format == FormatZIP {
+		return "application/zip"
+	}
+	return "application/json"
+}
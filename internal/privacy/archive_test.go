@@ -0,0 +1,111 @@
+package privacy_test
+
+import (
+	"archive/zip"
+	"backend-app/internal/privacy"
+	"backend-app/internal/storage/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type source struct {
+	user    *models.User
+	profile *models.Profile
+	events  []models.OutboxEvent
+}
+
+func (s *source) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	return s.user, nil
+}
+
+func (s *source) GetProfile(ctx context.Context, userID uint) (*models.Profile, error) {
+	return s.profile, nil
+}
+
+func (s *source) ListUserEvents(ctx context.Context, userID uint) ([]models.OutboxEvent, error) {
+	return s.events, nil
+}
+
+func newSource() *source {
+	return &source{
+		user: &models.User{
+			ID:           1,
+			Username:     "alice",
+			Email:        "alice@example.com",
+			Password:     "$2a$10$hash",
+			Role:         "user",
+			Country:      "NL",
+			Status:       models.StatusActive,
+			RefreshToken: "refresh",
+			TokenExpiry:  time.Now().Add(time.Hour),
+		},
+		profile: &models.Profile{
+			UserID:            1,
+			DisplayName:       "Alice",
+			LearningLanguages: "de:B1",
+			Attributes:        map[string]interface{}{"team": "core"},
+		},
+		events: []models.OutboxEvent{
+			{Key: "user.created:1:1", Type: "user.created", UserID: 1, Payload: `{"id":1,"username":"alice"}`},
+		},
+	}
+}
+
+func TestCollect(t *testing.T) {
+	archive, err := privacy.Collect(t.Context(), newSource(), 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, "alice", archive.Profile.Username)
+	assert.Equal(t, "NL", archive.Profile.Country)
+	assert.Equal(t, "Alice", archive.Profile.DisplayName)
+	assert.Equal(t, []models.LanguageSkill{{Language: "de", Level: "B1"}}, archive.Profile.LearningLanguages)
+	assert.Equal(t, "core", archive.Profile.Attributes["team"])
+	require.Len(t, archive.Sessions, 1)
+	assert.True(t, archive.Sessions[0].Active)
+	require.Len(t, archive.Audit, 1)
+	assert.Equal(t, "user.created", archive.Audit[0].Type)
+	assert.JSONEq(t, `{"id":1,"username":"alice"}`, string(archive.Audit[0].Data))
+
+	b, err := archive.Encode(privacy.FormatJSON)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "$2a$10$hash", "secrets are not exported")
+	assert.NotContains(t, string(b), "refresh\"")
+}
+
+func TestEncodeZIP(t *testing.T) {
+	archive, err := privacy.Collect(t.Context(), newSource(), 1)
+	require.NoError(t, err)
+
+	b, err := archive.Encode(privacy.FormatZIP)
+	require.NoError(t, err)
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		files[f.Name], err = io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+	}
+	require.Contains(t, files, "profile.json")
+	require.Contains(t, files, "sessions.json")
+	require.Contains(t, files, "audit.json")
+
+	var profile privacy.Profile
+	require.NoError(t, json.Unmarshal(files["profile.json"], &profile))
+	assert.Equal(t, "alice@example.com", profile.Email)
+}
+
+func TestEncodeUnknownFormat(t *testing.T) {
+	_, err := (&privacy.Archive{}).Encode("xml")
+	assert.ErrorIs(t, err, privacy.ErrUnknownFormat)
+}
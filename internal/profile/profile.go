@@ -0,0 +1,236 @@
+// Package profile применяет изменения к профилю пользователя и проверяет его
+// дополнительные атрибуты по схеме, которую задают администраторы.
+package profile
+
+import (
+	"backend-app/internal/storage/models"
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"unicode/utf8"
+)
+
+// Patch - изменение профиля. Отсутствующее поле остаётся прежним, пустая строка или
+// пустой список его очищают. В Attributes null удаляет значение атрибута.
+type Patch struct {
+	DisplayName *string `json:"displayName,omitempty" validate:"omitempty,max=100"`
+	// пустая строка разрешена отдельно: omitempty не пропускает указатель на ""
+	AvatarURL *string `json:"avatarUrl,omitempty" validate:"omitempty,len=0|http_url,max=2048"`
+	Bio       *string `json:"bio,omitempty" validate:"omitempty,max=1000"`
+	// Locale - тег BCP 47, например en-US
+	Locale *string `json:"locale,omitempty" validate:"omitempty,len=0|bcp47_language_tag"`
+	// TimeZone - зона из базы IANA, например Europe/Berlin
+	TimeZone          *string                `json:"timeZone,omitempty" validate:"omitempty,len=0|timezone"`
+	NativeLanguages   []string               `json:"nativeLanguages,omitempty" validate:"max=10,unique,dive,bcp47_language_tag"`
+	LearningLanguages []models.LanguageSkill `json:"learningLanguages,omitempty" validate:"max=10,unique=Language,dive"`
+	Attributes        map[string]interface{} `json:"attributes,omitempty" swaggertype:"object"`
+}
+
+// AttributeError - значение атрибута не подходит под схему.
+type AttributeError struct {
+	Key    string
+	Reason string
+}
+
+func (e *AttributeError) Error() string {
+	return fmt.Sprintf("attribute %q: %s", e.Key, e.Reason)
+}
+
+var keyFormat = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// CheckDefinition проверяет, что ограничения атрибута подходят к его типу и не
+// противоречат друг другу.
+func CheckDefinition(def *models.AttributeDefinition) error {
+	if !keyFormat.MatchString(def.Key) {
+		return fmt.Errorf("attribute key %q must be lowercase letters, digits and underscores", def.Key)
+	}
+	isString := def.Type == models.AttributeString
+	isNumber := def.Type == models.AttributeNumber || def.Type == models.AttributeInteger
+	if (def.Type == models.AttributeEnum) != (def.Enum != "") {
+		return fmt.Errorf("enum values are required for enum attributes and only for them")
+	}
+	if !isString && (def.MinLength != nil || def.MaxLength != nil || def.Pattern != "") {
+		return fmt.Errorf("minLength, maxLength and pattern apply only to string attributes")
+	}
+	if !isNumber && (def.Minimum != nil || def.Maximum != nil) {
+		return fmt.Errorf("minimum and maximum apply only to number and integer attributes")
+	}
+	if def.MinLength != nil && def.MaxLength != nil && *def.MinLength > *def.MaxLength {
+		return fmt.Errorf("minLength is greater than maxLength")
+	}
+	if def.Minimum != nil && def.Maximum != nil && *def.Minimum > *def.Maximum {
+		return fmt.Errorf("minimum is greater than maximum")
+	}
+	if def.Pattern != "" {
+		if _, err := regexp.Compile(def.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	return nil
+}
+
+// Apply переносит patch в p. Значения атрибутов проверяются по схеме defs, атрибуты,
+// которых в схеме нет, из профиля удаляются. Атрибуты без UserEditable меняет только
+// администратор (admin). Обязательные атрибуты проверяются, когда patch меняет
+// атрибуты, и только те, что вызывающий может заполнить сам: иначе пользователь не
+// смог бы сохранить профиль, пока администратор не заполнит свои атрибуты.
+func Apply(p *models.Profile, patch Patch, defs []models.AttributeDefinition, admin bool) error {
+	setString(&p.DisplayName, patch.DisplayName)
+	setString(&p.AvatarURL, patch.AvatarURL)
+	setString(&p.Bio, patch.Bio)
+	setString(&p.Locale, patch.Locale)
+	setString(&p.TimeZone, patch.TimeZone)
+	if patch.NativeLanguages != nil {
+		p.SetNativeLanguages(patch.NativeLanguages)
+	}
+	if patch.LearningLanguages != nil {
+		p.SetLearningLanguages(patch.LearningLanguages)
+	}
+
+	p.Attributes = Visible(defs, p.Attributes)
+	if patch.Attributes == nil {
+		return nil
+	}
+	for _, key := range slices.Sorted(maps.Keys(patch.Attributes)) {
+		value := patch.Attributes[key]
+		i := slices.IndexFunc(defs, func(d models.AttributeDefinition) bool { return d.Key == key })
+		if i < 0 {
+			return &AttributeError{Key: key, Reason: "unknown attribute"}
+		}
+		def := &defs[i]
+		if !def.UserEditable && !admin {
+			return &AttributeError{Key: key, Reason: "can be changed only by an administrator"}
+		}
+		if value == nil {
+			delete(p.Attributes, key)
+			continue
+		}
+		if err := checkValue(def, value); err != nil {
+			return err
+		}
+		p.Attributes[key] = value
+	}
+	for i := range defs {
+		def := &defs[i]
+		if _, ok := p.Attributes[def.Key]; !ok && def.Required && (admin || def.UserEditable) {
+			return &AttributeError{Key: def.Key, Reason: "is required"}
+		}
+	}
+	return nil
+}
+
+// Visible оставляет значения атрибутов, которые есть в схеме.
+func Visible(defs []models.AttributeDefinition, attrs map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(attrs))
+	for _, def := range defs {
+		if value, ok := attrs[def.Key]; ok {
+			res[def.Key] = value
+		}
+	}
+	return res
+}
+
+func setString(dst *string, src *string) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+// checkValue проверяет значение, как его разобрал encoding/json: числа приходят float64.
+func checkValue(def *models.AttributeDefinition, value interface{}) error {
+	invalid := func(format string, args ...interface{}) error {
+		return &AttributeError{Key: def.Key, Reason: fmt.Sprintf(format, args...)}
+	}
+	switch def.Type {
+	case models.AttributeString:
+		s, ok := value.(string)
+		if !ok {
+			return invalid("must be a string")
+		}
+		n := utf8.RuneCountInString(s)
+		if def.MinLength != nil && n < *def.MinLength {
+			return invalid("must be at least %d characters long", *def.MinLength)
+		}
+		if def.MaxLength != nil && n > *def.MaxLength {
+			return invalid("must be at most %d characters long", *def.MaxLength)
+		}
+		if def.Pattern != "" {
+			re, err := regexp.Compile(def.Pattern)
+			if err != nil {
+				return invalid("has invalid pattern in the schema")
+			}
+			if !re.MatchString(s) {
+				return invalid("must match %s", def.Pattern)
+			}
+		}
+	case models.AttributeNumber, models.AttributeInteger:
+		f, ok := value.(float64)
+		if !ok {
+			return invalid("must be a number")
+		}
+		if def.Type == models.AttributeInteger && f != math.Trunc(f) {
+			return invalid("must be an integer")
+		}
+		if def.Minimum != nil && f < *def.Minimum {
+			return invalid("must be at least %v", *def.Minimum)
+		}
+		if def.Maximum != nil && f > *def.Maximum {
+			return invalid("must be at most %v", *def.Maximum)
+		}
+	case models.AttributeBoolean:
+		if _, ok := value.(bool); !ok {
+			return invalid("must be a boolean")
+		}
+	case models.AttributeEnum:
+		s, ok := value.(string)
+		if !ok || !slices.Contains(def.EnumList(), s) {
+			return invalid("must be one of %v", def.EnumList())
+		}
+	default:
+		return invalid("has unknown type %q in the schema", def.Type)
+	}
+	return nil
+}
+
+type Store interface {
+	GetProfile(ctx context.Context, userID uint) (*models.Profile, error)
+	SaveProfile(ctx context.Context, profile *models.Profile) error
+	ListAttributeDefinitions(ctx context.Context) ([]models.AttributeDefinition, error)
+}
+
+// Get возвращает профиль пользователя userID с атрибутами, которые есть в схеме.
+func Get(ctx context.Context, store Store, userID uint) (*models.Profile, error) {
+	p, err := store.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	defs, err := store.ListAttributeDefinitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.Attributes = Visible(defs, p.Attributes)
+	return p, nil
+}
+
+// Update применяет patch к профилю пользователя userID (см. Apply) и сохраняет его.
+// Ошибки схемы - *AttributeError.
+func Update(ctx context.Context, store Store, userID uint, patch Patch, admin bool) (*models.Profile, error) {
+	p, err := store.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	defs, err := store.ListAttributeDefinitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := Apply(p, patch, defs, admin); err != nil {
+		return nil, err
+	}
+	if err := store.SaveProfile(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
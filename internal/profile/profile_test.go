@@ -0,0 +1,114 @@
+package profile_test
+
+import (
+	"backend-app/internal/profile"
+	"backend-app/internal/storage/models"
+	"errors"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func schema() []models.AttributeDefinition {
+	return []models.AttributeDefinition{
+		{Key: "employee_id", Type: models.AttributeString, Required: true, Pattern: `^E\d+$`},
+		{Key: "experience", Type: models.AttributeInteger, UserEditable: true, Minimum: ptr(0.0), Maximum: ptr(60.0)},
+		{Key: "newsletter", Type: models.AttributeBoolean, UserEditable: true},
+		{Key: "tshirt", Type: models.AttributeEnum, UserEditable: true, Enum: "S,M,L"},
+	}
+}
+
+func TestApply(t *testing.T) {
+	p := &models.Profile{
+		UserID:      1,
+		DisplayName: "Old",
+		Bio:         "keep",
+		Attributes:  map[string]interface{}{"employee_id": "E1", "removed": "gone"},
+	}
+	err := profile.Apply(p, profile.Patch{
+		DisplayName:       ptr("Ann"),
+		AvatarURL:         ptr(""),
+		NativeLanguages:   []string{"ru"},
+		LearningLanguages: []models.LanguageSkill{{Language: "de", Level: "B1"}},
+		Attributes:        map[string]interface{}{"experience": 3.0, "tshirt": "M"},
+	}, schema(), false)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Ann", p.DisplayName)
+	assert.Equal(t, "keep", p.Bio, "absent fields stay")
+	assert.Equal(t, []string{"ru"}, p.NativeLanguageList())
+	assert.Equal(t, []models.LanguageSkill{{Language: "de", Level: "B1"}}, p.LearningLanguageList())
+	assert.Equal(t, map[string]interface{}{"employee_id": "E1", "experience": 3.0, "tshirt": "M"}, p.Attributes,
+		"attributes missing from the schema are dropped")
+
+	err = profile.Apply(p, profile.Patch{Attributes: map[string]interface{}{"tshirt": nil}}, schema(), false)
+	require.NoError(t, err)
+	assert.NotContains(t, p.Attributes, "tshirt", "null removes the value")
+}
+
+func TestApplyRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs map[string]interface{}
+		admin bool
+		key   string
+	}{
+		{name: "unknown", attrs: map[string]interface{}{"shoe_size": 42.0}, key: "shoe_size"},
+		{name: "admin only", attrs: map[string]interface{}{"employee_id": "E2"}, key: "employee_id"},
+		{name: "pattern", attrs: map[string]interface{}{"employee_id": "X2"}, admin: true, key: "employee_id"},
+		{name: "not integer", attrs: map[string]interface{}{"experience": 1.5}, key: "experience"},
+		{name: "above maximum", attrs: map[string]interface{}{"experience": 61.0}, key: "experience"},
+		{name: "wrong type", attrs: map[string]interface{}{"newsletter": "yes"}, key: "newsletter"},
+		{name: "not in enum", attrs: map[string]interface{}{"tshirt": "XL"}, key: "tshirt"},
+		{name: "required by admin", attrs: map[string]interface{}{"employee_id": nil}, admin: true, key: "employee_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &models.Profile{Attributes: map[string]interface{}{"employee_id": "E1"}}
+			err := profile.Apply(p, profile.Patch{Attributes: tt.attrs}, schema(), tt.admin)
+			var attrErr *profile.AttributeError
+			require.True(t, errors.As(err, &attrErr), "got %v", err)
+			assert.Equal(t, tt.key, attrErr.Key)
+		})
+	}
+}
+
+func TestApplyRequiredForUsers(t *testing.T) {
+	// пользователь не может заполнить employee_id, поэтому его отсутствие не мешает
+	p := &models.Profile{Attributes: map[string]interface{}{}}
+	err := profile.Apply(p, profile.Patch{Attributes: map[string]interface{}{"newsletter": true}}, schema(), false)
+	assert.NoError(t, err)
+}
+
+func TestPatchValidation(t *testing.T) {
+	validate := validator.New()
+	assert.NoError(t, validate.Struct(profile.Patch{}))
+	assert.NoError(t, validate.Struct(profile.Patch{AvatarURL: ptr(""), Locale: ptr(""), TimeZone: ptr("")}),
+		"empty strings clear the fields")
+	assert.NoError(t, validate.Struct(profile.Patch{
+		AvatarURL: ptr("https://cdn.example.com/a.png"),
+		Locale:    ptr("en-US"),
+		TimeZone:  ptr("Europe/Berlin"),
+	}))
+	assert.Error(t, validate.Struct(profile.Patch{AvatarURL: ptr("not a url")}))
+	assert.Error(t, validate.Struct(profile.Patch{TimeZone: ptr("Mars/Olympus")}))
+	assert.Error(t, validate.Struct(profile.Patch{LearningLanguages: []models.LanguageSkill{{Language: "de", Level: "D1"}}}))
+	assert.Error(t, validate.Struct(profile.Patch{LearningLanguages: []models.LanguageSkill{
+		{Language: "de", Level: "A1"}, {Language: "de", Level: "B2"},
+	}}), "a language is listed once")
+}
+
+func TestCheckDefinition(t *testing.T) {
+	assert.NoError(t, profile.CheckDefinition(&models.AttributeDefinition{Key: "team", Type: models.AttributeString, MaxLength: ptr(20)}))
+	assert.Error(t, profile.CheckDefinition(&models.AttributeDefinition{Key: "Team", Type: models.AttributeString}))
+	assert.Error(t, profile.CheckDefinition(&models.AttributeDefinition{Key: "size", Type: models.AttributeEnum}))
+	assert.Error(t, profile.CheckDefinition(&models.AttributeDefinition{Key: "age", Type: models.AttributeInteger, MaxLength: ptr(3)}))
+	assert.Error(t, profile.CheckDefinition(&models.AttributeDefinition{Key: "age", Type: models.AttributeInteger, Minimum: ptr(10.0), Maximum: ptr(1.0)}))
+	assert.Error(t, profile.CheckDefinition(&models.AttributeDefinition{Key: "code", Type: models.AttributeString, Pattern: "("}))
+}
@@ -0,0 +1,162 @@
+// Package search реализует нечёткое сравнение строк для поиска пользователей
+// в хранилищах без полнотекстового поиска и подсветку совпадений для всех хранилищ.
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// MinSimilarity - порог триграммного сходства, как pg_trgm.similarity_threshold по умолчанию.
+const MinSimilarity = 0.3
+
+// FieldMatch описывает совпадение запроса с одним полем.
+type FieldMatch struct {
+	Field string
+	Value string
+	Score float64
+	// Start и End - границы подсвеченного фрагмента в Value в байтах, Start < 0 если подсветить нечего
+	Start int
+	End   int
+}
+
+// Highlight возвращает значение поля с совпавшим фрагментом в <em>, остальное экранируется.
+func (m FieldMatch) Highlight() string {
+	if m.Start < 0 {
+		return html.EscapeString(m.Value)
+	}
+	return html.EscapeString(m.Value[:m.Start]) +
+		"<em>" + html.EscapeString(m.Value[m.Start:m.End]) + "</em>" +
+		html.EscapeString(m.Value[m.End:])
+}
+
+// Normalize приводит запрос к виду, в котором его сравнивают с полями.
+func Normalize(query string) string {
+	return strings.ToLower(strings.TrimSpace(query))
+}
+
+// Match сравнивает нормализованный запрос со значением поля. Совпадение может быть
+// точным, по префиксу, по подстроке или нечётким: с опечатками в начале значения
+// или по триграммному сходству.
+func Match(query, field, value string) (FieldMatch, bool) {
+	m := FieldMatch{Field: field, Value: value, Start: -1}
+	if query == "" || value == "" {
+		return m, false
+	}
+	lower := strings.ToLower(value)
+	// ToLower может поменять длину строки в байтах, тогда границы подсветки не совпадут
+	aligned := len(lower) == len(value)
+
+	switch idx := strings.Index(lower, query); {
+	case lower == query:
+		m.Score = 1
+		m.Start, m.End = 0, len(value)
+	case idx == 0:
+		m.Score = 0.9
+		m.Start, m.End = 0, len(query)
+	case idx > 0:
+		m.Score = 0.7
+		m.Start, m.End = idx, idx+len(query)
+	default:
+		// опечатки: сравниваем запрос с началом значения той же длины
+		n := utf8.RuneCountInString(query)
+		prefix := firstRunes(lower, n)
+		if d := levenshtein(query, prefix); d > 0 && d <= TypoBudget(n) {
+			m.Score = 0.6 - 0.1*float64(d)
+			m.Start, m.End = 0, len(prefix)
+			break
+		}
+		if sim := Similarity(query, lower); sim >= MinSimilarity {
+			m.Score = 0.5 * sim
+			break
+		}
+		return m, false
+	}
+
+	if !aligned {
+		m.Start = -1
+	}
+	return m, true
+}
+
+// TypoBudget - сколько опечаток допускается в запросе из n символов.
+func TypoBudget(n int) int {
+	switch {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Similarity считает триграммное сходство строк так же, как pg_trgm.
+func Similarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	common := 0
+	for t := range ta {
+		if tb[t] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(ta)+len(tb)-common)
+}
+
+func trigrams(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(s), isSeparator) {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			set[string(runes[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// isSeparator повторяет pg_trgm: всё, что не буква и не цифра, разделяет слова.
+func isSeparator(r rune) bool {
+	return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r > utf8.RuneSelf)
+}
+
+func firstRunes(s string, n int) string {
+	i := 0
+	for pos := range s {
+		if i == n {
+			return s[:pos]
+		}
+		i++
+	}
+	return s
+}
+
+// levenshtein считает расстояние Дамерау-Левенштейна (optimal string alignment):
+// перестановка соседних букв - самая частая опечатка - стоит одну правку.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
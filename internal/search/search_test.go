@@ -0,0 +1,47 @@
+package search_test
+
+import (
+	"backend-app/internal/search"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		value     string
+		match     bool
+		score     float64
+		highlight string
+	}{
+		{name: "exact", query: "alice", value: "Alice", match: true, score: 1, highlight: "<em>Alice</em>"},
+		{name: "prefix", query: "ali", value: "Alice", match: true, score: 0.9, highlight: "<em>Ali</em>ce"},
+		{name: "substring", query: "example", value: "bob@example.com", match: true, score: 0.7, highlight: "bob@<em>example</em>.com"},
+		{name: "typo", query: "alcie", value: "alice", match: true, score: 0.5, highlight: "<em>alice</em>"},
+		{name: "typo in prefix", query: "jonh", value: "johnny", match: true, score: 0.5, highlight: "<em>john</em>ny"},
+		{name: "too many typos", query: "xyz", value: "alice", match: false},
+		{name: "short query has no typo budget", query: "aix", value: "alice", match: false},
+		{name: "escapes html", query: "bob", value: "<bob>", match: true, score: 0.7, highlight: "&lt;<em>bob</em>&gt;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, ok := search.Match(search.Normalize(tt.query), "field", tt.value)
+			assert.Equal(t, tt.match, ok)
+			if !tt.match {
+				return
+			}
+			assert.InDelta(t, tt.score, m.Score, 0.001)
+			assert.Equal(t, tt.highlight, m.Highlight())
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.InDelta(t, 1.0, search.Similarity("word", "word"), 0.001)
+	assert.InDelta(t, 0.0, search.Similarity("abc", "xyz"), 0.001)
+	// значение из документации pg_trgm: similarity('word', 'two words') = 0.363636
+	assert.InDelta(t, 0.3636, search.Similarity("word", "two words"), 0.001)
+}
@@ -0,0 +1,224 @@
+// Package stats собирает для администраторов статистику регистраций, входов и
+// подтверждений из агрегатов хранилища и кеширует готовые отчёты.
+package stats
+
+import (
+	"backend-app/internal/storage"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	// UnknownCountry - ключ регистраций без страны или со страной не из ISO 3166-1
+	UnknownCountry = "unknown"
+
+	day   = 24 * time.Hour
+	month = 30 * day
+)
+
+// Store - агрегаты, из которых строится отчёт.
+type Store interface {
+	RegistrationCounts(ctx context.Context, q storage.StatsQuery) ([]storage.RegistrationCount, error)
+	LoginCounts(ctx context.Context, q storage.StatsQuery) ([]storage.LoginCount, error)
+	ActiveUsers(ctx context.Context, orgID uint, from, to time.Time) (int64, error)
+}
+
+// Report - статистика за период [From, To). Ряды Registrations и Logins содержат все
+// интервалы периода, в том числе пустые.
+type Report struct {
+	From          time.Time            `json:"from"`
+	To            time.Time            `json:"to"`
+	Interval      string               `json:"interval"`
+	Registrations []RegistrationBucket `json:"registrations"`
+	// RegistrationsByCountry и RegistrationsByRole - итоги за весь период
+	RegistrationsByCountry map[string]int64 `json:"registrationsByCountry"`
+	RegistrationsByRole    map[string]int64 `json:"registrationsByRole"`
+	Logins                 []LoginBucket    `json:"logins"`
+	LoginTotals            LoginTotals      `json:"loginTotals"`
+	ActiveUsers            ActiveUsers      `json:"activeUsers"`
+	Verification           Verification     `json:"verification"`
+	GeneratedAt            time.Time        `json:"generatedAt"`
+}
+
+type RegistrationBucket struct {
+	// Start - первый день интервала, YYYY-MM-DD
+	Start     string           `json:"start"`
+	Total     int64            `json:"total"`
+	Verified  int64            `json:"verified"`
+	ByCountry map[string]int64 `json:"byCountry"`
+	ByRole    map[string]int64 `json:"byRole"`
+}
+
+type LoginBucket struct {
+	Start     string `json:"start"`
+	Succeeded int64  `json:"succeeded"`
+	Failed    int64  `json:"failed"`
+	// SuccessRate - доля успешных входов, 0, если входов не было
+	SuccessRate float64 `json:"successRate"`
+	// ActiveUsers - разные пользователи, успешно входившие за интервал
+	ActiveUsers int64 `json:"activeUsers"`
+}
+
+type LoginTotals struct {
+	Succeeded   int64   `json:"succeeded"`
+	Failed      int64   `json:"failed"`
+	SuccessRate float64 `json:"successRate"`
+}
+
+// ActiveUsers считается по успешным входам за последние сутки (DAU) и 30 дней (MAU)
+// перед концом периода.
+type ActiveUsers struct {
+	Daily   int64 `json:"dau"`
+	Monthly int64 `json:"mau"`
+}
+
+// Verification - сколько пользователей, зарегистрированных за период, уже подтверждены.
+type Verification struct {
+	Registered int64   `json:"registered"`
+	Verified   int64   `json:"verified"`
+	Rate       float64 `json:"rate"`
+}
+
+// Build считает отчёт по агрегатам store.
+func Build(ctx context.Context, store Store, q storage.StatsQuery) (*Report, error) {
+	registrations, err := store.RegistrationCounts(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	logins, err := store.LoginCounts(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	var active ActiveUsers
+	if active.Daily, err = store.ActiveUsers(ctx, q.OrgID, q.To.Add(-day), q.To); err != nil {
+		return nil, err
+	}
+	if active.Monthly, err = store.ActiveUsers(ctx, q.OrgID, q.To.Add(-month), q.To); err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		From:                   q.From,
+		To:                     q.To,
+		Interval:               q.Interval,
+		RegistrationsByCountry: make(map[string]int64),
+		RegistrationsByRole:    make(map[string]int64),
+		ActiveUsers:            active,
+		GeneratedAt:            time.Now().UTC(),
+	}
+	regBuckets := make(map[string]*RegistrationBucket)
+	loginBuckets := make(map[string]*LoginBucket)
+	for _, start := range Buckets(q.From, q.To, q.Interval) {
+		report.Registrations = append(report.Registrations, RegistrationBucket{
+			Start: start, ByCountry: make(map[string]int64), ByRole: make(map[string]int64),
+		})
+		report.Logins = append(report.Logins, LoginBucket{Start: start})
+	}
+	for i := range report.Registrations {
+		regBuckets[report.Registrations[i].Start] = &report.Registrations[i]
+		loginBuckets[report.Logins[i].Start] = &report.Logins[i]
+	}
+
+	for _, c := range registrations {
+		b, ok := regBuckets[c.Bucket]
+		if !ok {
+			continue
+		}
+		code := c.Country
+		if code == "" {
+			code = UnknownCountry
+		}
+		b.Total += c.Total
+		b.Verified += c.Verified
+		b.ByCountry[code] += c.Total
+		b.ByRole[c.Role] += c.Total
+		report.RegistrationsByCountry[code] += c.Total
+		report.RegistrationsByRole[c.Role] += c.Total
+		report.Verification.Registered += c.Total
+		report.Verification.Verified += c.Verified
+	}
+	report.Verification.Rate = rate(report.Verification.Verified, report.Verification.Registered)
+
+	for _, c := range logins {
+		b, ok := loginBuckets[c.Bucket]
+		if !ok {
+			continue
+		}
+		b.Succeeded, b.Failed, b.ActiveUsers = c.Succeeded, c.Failed, c.ActiveUsers
+		b.SuccessRate = rate(c.Succeeded, c.Succeeded+c.Failed)
+		report.LoginTotals.Succeeded += c.Succeeded
+		report.LoginTotals.Failed += c.Failed
+	}
+	report.LoginTotals.SuccessRate = rate(report.LoginTotals.Succeeded, report.LoginTotals.Succeeded+report.LoginTotals.Failed)
+	return report, nil
+}
+
+// Buckets возвращает начала интервалов, пересекающихся с [from, to), по возрастанию.
+func Buckets(from, to time.Time, interval string) []string {
+	step := day
+	if interval == storage.IntervalWeek {
+		step = 7 * day
+	}
+	start, err := time.Parse(time.DateOnly, storage.StatsBucket(from, interval))
+	if err != nil {
+		return nil
+	}
+	var buckets []string
+	for ; start.Before(to); start = start.Add(step) {
+		buckets = append(buckets, start.Format(time.DateOnly))
+	}
+	return buckets
+}
+
+func rate(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
+
+// Service строит отчёты и держит их в кеше TTL: одни и те же периоды администраторы
+// запрашивают часто, а агрегаты по всем пользователям пересчитывать дорого. Сбои
+// кеша не ломают запросы, отчёт тогда строится заново.
+type Service struct {
+	store Store
+	cache storage.Cache
+	ttl   time.Duration
+}
+
+// New возвращает Service. С ttl <= 0 или без кеша отчёты не кешируются.
+func New(store Store, cache storage.Cache, ttl time.Duration) *Service {
+	return &Service{store: store, cache: cache, ttl: ttl}
+}
+
+func (s *Service) Report(ctx context.Context, q storage.StatsQuery) (*Report, error) {
+	if s.cache == nil || s.ttl <= 0 {
+		return Build(ctx, s.store, q)
+	}
+
+	key := fmt.Sprintf("stats:%d:%s:%d:%d", q.OrgID, q.Interval, q.From.Unix(), q.To.Unix())
+	b, ok, err := s.cache.Get(ctx, key)
+	if err != nil {
+		slog.WarnContext(ctx, "stats cache get failed", slog.String("err", err.Error()))
+	}
+	if ok {
+		var report Report
+		if err := json.Unmarshal(b, &report); err == nil {
+			return &report, nil
+		}
+	}
+
+	report, err := Build(ctx, s.store, q)
+	if err != nil {
+		return nil, err
+	}
+	if b, err := json.Marshal(report); err == nil {
+		if err := s.cache.Set(ctx, key, b, s.ttl); err != nil {
+			slog.WarnContext(ctx, "stats cache set failed", slog.String("err", err.Error()))
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,105 @@
+package stats_test
+
+import (
+	"backend-app/internal/cache"
+	"backend-app/internal/stats"
+	"backend-app/internal/storage"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	registrations []storage.RegistrationCount
+	logins        []storage.LoginCount
+	calls         int
+	windows       []time.Duration
+}
+
+func (f *fakeStore) RegistrationCounts(ctx context.Context, q storage.StatsQuery) ([]storage.RegistrationCount, error) {
+	f.calls++
+	return f.registrations, nil
+}
+
+func (f *fakeStore) LoginCounts(ctx context.Context, q storage.StatsQuery) ([]storage.LoginCount, error) {
+	return f.logins, nil
+}
+
+func (f *fakeStore) ActiveUsers(ctx context.Context, orgID uint, from, to time.Time) (int64, error) {
+	f.windows = append(f.windows, to.Sub(from))
+	return int64(len(f.windows)), nil
+}
+
+func date(s string) time.Time {
+	t, _ := time.Parse(time.DateOnly, s)
+	return t
+}
+
+func TestBuckets(t *testing.T) {
+	assert.Equal(t, []string{"2026-03-02", "2026-03-03", "2026-03-04"},
+		stats.Buckets(date("2026-03-02"), date("2026-03-05"), storage.IntervalDay))
+	assert.Equal(t, []string{"2026-02-23", "2026-03-02"},
+		stats.Buckets(date("2026-03-01"), date("2026-03-09"), storage.IntervalWeek), "the first week starts on Monday")
+	assert.Empty(t, stats.Buckets(date("2026-03-02"), date("2026-03-02"), storage.IntervalDay))
+}
+
+func TestBuild(t *testing.T) {
+	store := &fakeStore{
+		registrations: []storage.RegistrationCount{
+			{Bucket: "2026-03-02", Country: "NL", Role: "user", Total: 3, Verified: 2},
+			{Bucket: "2026-03-02", Country: "", Role: "admin", Total: 1},
+			{Bucket: "2026-03-04", Country: "NL", Role: "admin", Total: 4, Verified: 1},
+		},
+		logins: []storage.LoginCount{
+			{Bucket: "2026-03-02", Succeeded: 3, Failed: 1, ActiveUsers: 2},
+		},
+	}
+	q := storage.StatsQuery{From: date("2026-03-02"), To: date("2026-03-05"), Interval: storage.IntervalDay}
+
+	report, err := stats.Build(t.Context(), store, q)
+	require.NoError(t, err)
+	require.Len(t, report.Registrations, 3)
+	assert.Equal(t, stats.RegistrationBucket{
+		Start:     "2026-03-02",
+		Total:     4,
+		Verified:  2,
+		ByCountry: map[string]int64{"NL": 3, stats.UnknownCountry: 1},
+		ByRole:    map[string]int64{"user": 3, "admin": 1},
+	}, report.Registrations[0])
+	assert.Zero(t, report.Registrations[1].Total, "empty days are kept")
+	assert.Equal(t, map[string]int64{"NL": 7, stats.UnknownCountry: 1}, report.RegistrationsByCountry)
+	assert.Equal(t, map[string]int64{"user": 3, "admin": 5}, report.RegistrationsByRole)
+	assert.Equal(t, stats.Verification{Registered: 8, Verified: 3, Rate: 0.375}, report.Verification)
+
+	require.Len(t, report.Logins, 3)
+	assert.Equal(t, stats.LoginBucket{Start: "2026-03-02", Succeeded: 3, Failed: 1, SuccessRate: 0.75, ActiveUsers: 2}, report.Logins[0])
+	assert.Equal(t, stats.LoginBucket{Start: "2026-03-03"}, report.Logins[1])
+	assert.Equal(t, stats.LoginTotals{Succeeded: 3, Failed: 1, SuccessRate: 0.75}, report.LoginTotals)
+
+	assert.Equal(t, []time.Duration{24 * time.Hour, 30 * 24 * time.Hour}, store.windows, "DAU and MAU end with the period")
+}
+
+func TestServiceCache(t *testing.T) {
+	store := &fakeStore{}
+	s := stats.New(store, cache.NewLRU(10), time.Minute)
+	q := storage.StatsQuery{From: date("2026-03-02"), To: date("2026-03-05"), Interval: storage.IntervalDay}
+
+	first, err := s.Report(t.Context(), q)
+	require.NoError(t, err)
+	second, err := s.Report(t.Context(), q)
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.calls, "the second report comes from the cache")
+	assert.True(t, first.GeneratedAt.Equal(second.GeneratedAt))
+
+	q.OrgID = 1
+	_, err = s.Report(t.Context(), q)
+	require.NoError(t, err)
+	assert.Equal(t, 2, store.calls, "organizations are cached separately")
+
+	_, err = stats.New(store, nil, time.Minute).Report(t.Context(), q)
+	require.NoError(t, err)
+	assert.Equal(t, 3, store.calls)
+}
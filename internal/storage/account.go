@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"backend-app/internal/storage/models"
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrEmailChangeNotFound = errors.New("email change not found")
+
+// AccountRepository хранит запросы пользователей на смену email до подтверждения.
+type AccountRepository interface {
+	// SaveEmailChange создаёт запрос или заменяет прежний запрос того же пользователя,
+	// токен прежнего запроса перестаёт действовать.
+	SaveEmailChange(ctx context.Context, change *models.EmailChange) error
+	// GetEmailChange ищет запрос по хешу токена. Истёкшие запросы тоже возвращаются,
+	// срок проверяет вызывающий.
+	GetEmailChange(ctx context.Context, tokenHash string) (*models.EmailChange, error)
+	DeleteEmailChange(ctx context.Context, userID uint) error
+	// ConfirmEmailChange в одной транзакции удаляет запрос с хешем токена tokenHash и
+	// меняет email пользователя на подтверждённый, отмечая его проверенным и записывая
+	// now в EmailChangedAt. Запрос, истёкший к now, даёт ErrEmailChangeNotFound. Если
+	// email не сменился, запрос остаётся.
+	ConfirmEmailChange(ctx context.Context, tokenHash string, now time.Time) (*models.User, error)
+}
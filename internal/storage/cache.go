@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"backend-app/internal/storage/models"
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// invalidateTimeout ограничивает удаление из кеша после записи. Удаление не зависит от
+// отмены запроса: запись в базу уже могла пройти.
+const invalidateTimeout = time.Second
+
+// Cache - хранилище байтов с TTL, например cache.LRU или cache.Redis.
+type Cache interface {
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+type CacheOptions struct {
+	TTL time.Duration
+	// Prefix отделяет ключи сервиса в общем Redis
+	Prefix  string
+	Metrics *CacheMetrics
+}
+
+// CacheMetrics считает обращения к кешу. Реализует expvar.Var.
+type CacheMetrics struct {
+	Hits   atomic.Int64
+	Misses atomic.Int64
+	// Errors - сбои кеша, при которых запрос обслужила база
+	Errors        atomic.Int64
+	Invalidations atomic.Int64
+}
+
+func (m *CacheMetrics) String() string {
+	b, _ := json.Marshal(map[string]int64{
+		"hits":          m.Hits.Load(),
+		"misses":        m.Misses.Load(),
+		"errors":        m.Errors.Load(),
+		"invalidations": m.Invalidations.Load(),
+	})
+	return string(b)
+}
+
+// WithCache кеширует GetUserByID и сбрасывает запись пользователя после каждого его
+// изменения через этот же Store. Одновременные промахи по одному пользователю идут в
+// базу одним запросом. Чтение, начатое до изменения, может успеть положить в кеш
+// старую версию, а другие экземпляры сервиса с кешем в памяти не узнают об изменении,
+// поэтому TTL - верхняя граница того, насколько устаревшим может быть пользователь.
+// Чтения с WithPrimary кеш не обслуживает: они идут в базу и обновляют запись в кеше.
+// Сбои кеша не ломают запросы: они уходят в базу.
+func WithCache(repo Store, c Cache, opts CacheOptions) Store {
+	if opts.Metrics == nil {
+		opts.Metrics = &CacheMetrics{}
+	}
+	return &cachedRepository{Store: repo, cache: c, opts: opts}
+}
+
+type cachedRepository struct {
+	Store
+	cache Cache
+	opts  CacheOptions
+	group singleflight.Group
+}
+
+func (r *cachedRepository) key(id uint) string {
+	return r.opts.Prefix + "user:" + strconv.FormatUint(uint64(id), 10)
+}
+
+func (r *cachedRepository) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	key := r.key(id)
+	// проверке отзыва токенов нужна версия из базы, а не из кеша другого экземпляра
+	if PrimaryOnly(ctx) {
+		return r.refresh(ctx, key, id)
+	}
+	b, ok, err := r.cache.Get(ctx, key)
+	if err != nil {
+		r.failed(ctx, "get", err)
+	}
+	if ok {
+		user, err := decodeUser(b)
+		if err == nil {
+			r.opts.Metrics.Hits.Add(1)
+			return user, nil
+		}
+		r.failed(ctx, "decode", err)
+	}
+	r.opts.Metrics.Misses.Add(1)
+
+	// вызывающие получают байты и декодируют каждый своего пользователя, чтобы не
+	// делить один *models.User между запросами
+	v, err, _ := r.group.Do(key, func() (interface{}, error) {
+		// с реплики в кеш попала бы старая версия, которая пережила бы сброс
+		user, err := r.Store.GetUserByID(WithPrimary(ctx), id)
+		if err != nil {
+			return nil, err
+		}
+		b, err := encodeUser(user)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.cache.Set(ctx, key, b, r.opts.TTL); err != nil {
+			r.failed(ctx, "set", err)
+		}
+		return b, nil
+	})
+	// запрос, к которому присоединились, могли отменить, а у этого время ещё есть
+	if isContextError(err) && ctx.Err() == nil {
+		return r.Store.GetUserByID(WithPrimary(ctx), id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeUser(v.([]byte))
+}
+
+// refresh читает пользователя с primary и кладёт его в кеш вместо прежней записи.
+func (r *cachedRepository) refresh(ctx context.Context, key string, id uint) (*models.User, error) {
+	user, err := r.Store.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	b, err := encodeUser(user)
+	if err != nil {
+		r.failed(ctx, "encode", err)
+		return user, nil
+	}
+	if err := r.cache.Set(ctx, key, b, r.opts.TTL); err != nil {
+		r.failed(ctx, "set", err)
+	}
+	return user, nil
+}
+
+func (r *cachedRepository) UpdateUser(ctx context.Context, user *models.User) error {
+	defer r.invalidate(ctx, user.ID)
+	return r.Store.UpdateUser(ctx, user)
+}
+
+func (r *cachedRepository) PatchUser(ctx context.Context, id uint, version uint, patch UserPatch) (*models.User, error) {
+	defer r.invalidate(ctx, id)
+	return r.Store.PatchUser(ctx, id, version, patch)
+}
+
+func (r *cachedRepository) ConfirmEmailChange(ctx context.Context, tokenHash string, now time.Time) (*models.User, error) {
+	user, err := r.Store.ConfirmEmailChange(ctx, tokenHash, now)
+	if err == nil {
+		r.invalidate(ctx, user.ID)
+	}
+	return user, err
+}
+
+func (r *cachedRepository) SetRefreshToken(ctx context.Context, id uint, token string, expiry time.Time) error {
+	defer r.invalidate(ctx, id)
+	return r.Store.SetRefreshToken(ctx, id, token, expiry)
+}
+
+func (r *cachedRepository) DeleteUser(ctx context.Context, id uint, deletedBy uint, reason string) error {
+	defer r.invalidate(ctx, id)
+	return r.Store.DeleteUser(ctx, id, deletedBy, reason)
+}
+
+func (r *cachedRepository) RestoreUser(ctx context.Context, id uint) error {
+	defer r.invalidate(ctx, id)
+	return r.Store.RestoreUser(ctx, id)
+}
+
+func (r *cachedRepository) EraseUser(ctx context.Context, requestID uint, reviewedBy uint, note string) (*models.ErasureRequest, error) {
+	req, err := r.Store.EraseUser(ctx, requestID, reviewedBy, note)
+	if err == nil {
+		r.invalidate(ctx, req.UserID)
+	}
+	return req, err
+}
+
+func (r *cachedRepository) SetMembership(ctx context.Context, m *models.Membership) error {
+	defer r.invalidate(ctx, m.UserID)
+	return r.Store.SetMembership(ctx, m)
+}
+
+func (r *cachedRepository) RemoveMembership(ctx context.Context, orgID uint, userID uint) error {
+	defer r.invalidate(ctx, userID)
+	return r.Store.RemoveMembership(ctx, orgID, userID)
+}
+
+// invalidate сбрасывает пользователя и после неудачной записи: при обрыве соединения
+// или таймауте транзакция могла успеть закоммититься.
+func (r *cachedRepository) invalidate(ctx context.Context, id uint) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), invalidateTimeout)
+	defer cancel()
+	if err := r.cache.Delete(ctx, r.key(id)); err != nil {
+		r.failed(ctx, "delete", err)
+		return
+	}
+	r.opts.Metrics.Invalidations.Add(1)
+}
+
+func (r *cachedRepository) failed(ctx context.Context, op string, err error) {
+	r.opts.Metrics.Errors.Add(1)
+	slog.WarnContext(ctx, "user cache "+op+" failed", slog.String("err", err.Error()))
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// Пользователь кодируется gob, а не JSON: в JSON нет пароля, токенов и их версии.
+// gob не передаёт нулевые значения, и указатель на ноль вернулся бы nil, но в
+// models.User указатели нулей не хранят.
+func encodeUser(user *models.User) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(user); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeUser(b []byte) (*models.User, error) {
+	var user models.User
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,221 @@
+package storage_test
+
+import (
+	"backend-app/internal/cache"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/memory"
+	"backend-app/internal/storage/models"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingRepo считает чтения пользователя по id и может их задержать.
+type countingRepo struct {
+	storage.Store
+	reads        atomic.Int64
+	primaryReads atomic.Int64
+	release      chan struct{}
+}
+
+func (r *countingRepo) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	r.reads.Add(1)
+	if storage.PrimaryOnly(ctx) {
+		r.primaryReads.Add(1)
+	}
+	if r.release != nil {
+		<-r.release
+	}
+	return r.Store.GetUserByID(ctx, id)
+}
+
+// brokenCache отвечает ошибкой на всё, как недоступный Redis.
+type brokenCache struct{}
+
+func (brokenCache) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, errors.New("connection refused")
+}
+
+func (brokenCache) Set(context.Context, string, []byte, time.Duration) error {
+	return errors.New("connection refused")
+}
+
+func (brokenCache) Delete(context.Context, ...string) error {
+	return errors.New("connection refused")
+}
+
+func newCachedRepo(t *testing.T, c storage.Cache) (*countingRepo, storage.Store, *storage.CacheMetrics, *models.User) {
+	t.Helper()
+	inner := &countingRepo{Store: memory.New()}
+	user := &models.User{Username: "alice", Password: "hash", Email: "alice@example.com", Role: "user", Country: "DE"}
+	require.NoError(t, inner.CreateUser(t.Context(), user))
+	metrics := &storage.CacheMetrics{}
+	repo := storage.WithCache(inner, c, storage.CacheOptions{TTL: time.Minute, Metrics: metrics})
+	return inner, repo, metrics, user
+}
+
+func TestWithCache(t *testing.T) {
+	ctx := t.Context()
+	inner, repo, metrics, user := newCachedRepo(t, cache.NewLRU(10))
+
+	got, err := repo.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got.Username)
+	assert.Equal(t, "hash", got.Password, "cached user keeps fields hidden from JSON")
+
+	got.Username = "changed by caller"
+	got, err = repo.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got.Username, "callers get their own copy")
+	assert.EqualValues(t, 1, inner.reads.Load())
+	assert.EqualValues(t, 1, metrics.Hits.Load())
+	assert.EqualValues(t, 1, metrics.Misses.Load())
+
+	_, err = repo.GetUserByID(ctx, 999)
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+	_, err = repo.GetUserByID(ctx, 999)
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+	assert.EqualValues(t, 3, inner.reads.Load(), "misses are not cached")
+	assert.EqualValues(t, 3, inner.primaryReads.Load(), "the cache is filled from the primary")
+}
+
+func TestWithCachePrimary(t *testing.T) {
+	ctx := t.Context()
+	inner, repo, _, user := newCachedRepo(t, cache.NewLRU(10))
+	_, err := repo.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+
+	// другой экземпляр сервиса меняет пользователя, и этот кеш о том не знает
+	suspended := models.StatusSuspended
+	updated, err := inner.PatchUser(ctx, user.ID, user.Version, storage.UserPatch{Status: &suspended})
+	require.NoError(t, err)
+
+	got, err := repo.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.Version, got.Version, "plain reads may be stale until the TTL")
+
+	got, err = repo.GetUserByID(storage.WithPrimary(ctx), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, updated.Version, got.Version, "primary reads skip the cache")
+	assert.Equal(t, models.StatusSuspended, got.Status)
+
+	got, err = repo.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, updated.Version, got.Version, "the primary read refreshes the cache")
+}
+
+func TestWithCacheInvalidation(t *testing.T) {
+	bob := "bob"
+	tests := []struct {
+		name   string
+		write  func(ctx context.Context, repo storage.Store, user *models.User) error
+		expect func(t *testing.T, user *models.User, err error)
+	}{
+		{
+			name: "patch",
+			write: func(ctx context.Context, repo storage.Store, user *models.User) error {
+				_, err := repo.PatchUser(ctx, user.ID, user.Version, storage.UserPatch{Username: &bob})
+				return err
+			},
+			expect: func(t *testing.T, user *models.User, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, "bob", user.Username)
+			},
+		},
+		{
+			name: "update",
+			write: func(ctx context.Context, repo storage.Store, user *models.User) error {
+				user.Country = "FR"
+				return repo.UpdateUser(ctx, user)
+			},
+			expect: func(t *testing.T, user *models.User, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, "FR", user.Country)
+			},
+		},
+		{
+			name: "refresh token",
+			write: func(ctx context.Context, repo storage.Store, user *models.User) error {
+				return repo.SetRefreshToken(ctx, user.ID, "token", time.Now().Add(time.Hour))
+			},
+			expect: func(t *testing.T, user *models.User, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, "token", user.RefreshToken)
+			},
+		},
+		{
+			name: "delete",
+			write: func(ctx context.Context, repo storage.Store, user *models.User) error {
+				return repo.DeleteUser(ctx, user.ID, 1, "")
+			},
+			expect: func(t *testing.T, user *models.User, err error) {
+				assert.ErrorIs(t, err, storage.ErrUserNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := t.Context()
+			_, repo, metrics, _ := newCachedRepo(t, cache.NewLRU(10))
+			user, err := repo.GetUserByID(ctx, 1)
+			require.NoError(t, err)
+
+			require.NoError(t, tt.write(ctx, repo, user))
+			assert.EqualValues(t, 1, metrics.Invalidations.Load())
+
+			user, err = repo.GetUserByID(ctx, 1)
+			tt.expect(t, user, err)
+		})
+	}
+}
+
+func TestWithCacheSingleflight(t *testing.T) {
+	inner, repo, _, user := newCachedRepo(t, cache.NewLRU(10))
+	inner.release = make(chan struct{})
+
+	const callers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.GetUserByID(t.Context(), user.ID)
+			errs <- err
+		}()
+	}
+	// даём всем вызовам дойти до singleflight, прежде чем отпустить базу
+	require.Eventually(t, func() bool { return inner.reads.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(inner.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.EqualValues(t, 1, inner.reads.Load())
+}
+
+func TestWithCacheFailures(t *testing.T) {
+	ctx := t.Context()
+	inner, repo, metrics, user := newCachedRepo(t, brokenCache{})
+
+	got, err := repo.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got.Username)
+	_, err = repo.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, inner.reads.Load())
+
+	require.NoError(t, repo.SetRefreshToken(ctx, user.ID, "token", time.Now()))
+	assert.EqualValues(t, 5, metrics.Errors.Load(), "two gets, two sets and a delete")
+	assert.Zero(t, metrics.Invalidations.Load())
+}
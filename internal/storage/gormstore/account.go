@@ -0,0 +1,88 @@
+package gormstore
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (s *Storage) SaveEmailChange(ctx context.Context, change *models.EmailChange) error {
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&models.User{}, change.UserID).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"new_email", "token_hash", "expires_at", "created_at"}),
+		}).Create(change).Error
+	})
+	return translate(ctx, err)
+}
+
+func (s *Storage) GetEmailChange(ctx context.Context, tokenHash string) (*models.EmailChange, error) {
+	var change models.EmailChange
+	// подтверждение идёт сразу за запросом, реплика могла его ещё не получить
+	err := s.DB.WithContext(ctx).Where("token_hash = ?", tokenHash).Take(&change).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, storage.ErrEmailChangeNotFound
+	}
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return &change, nil
+}
+
+func (s *Storage) DeleteEmailChange(ctx context.Context, userID uint) error {
+	res := s.DB.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.EmailChange{})
+	if res.Error != nil {
+		return translate(ctx, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return storage.ErrEmailChangeNotFound
+	}
+	return nil
+}
+
+func (s *Storage) ConfirmEmailChange(ctx context.Context, tokenHash string, now time.Time) (*models.User, error) {
+	var user models.User
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var change models.EmailChange
+		err := tx.Where("token_hash = ?", tokenHash).Take(&change).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return storage.ErrEmailChangeNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if now.After(change.ExpiresAt) {
+			return storage.ErrEmailChangeNotFound
+		}
+		// из двух одновременных подтверждений одним токеном запрос удалит только одно
+		res := tx.Where("user_id = ? AND token_hash = ?", change.UserID, tokenHash).Delete(&models.EmailChange{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return storage.ErrEmailChangeNotFound
+		}
+
+		if err := tx.First(&user, change.UserID).Error; err != nil {
+			return err
+		}
+		verified := true
+		return s.patchUser(tx, &user, user.Version, storage.UserPatch{
+			Email:          &change.NewEmail,
+			Verified:       &verified,
+			EmailChangedAt: &now,
+		})
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return &user, nil
+}
@@ -0,0 +1,141 @@
+package gormstore
+
+import (
+	"backend-app/internal/storage/models"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+var ErrEncryptionDisabled = errors.New("encryption keys are not configured")
+
+// encryptedUser - шифруемые колонки users в том виде, в каком они лежат в базе:
+// без сериализатора, чтобы было видно, каким ключом записано значение.
+type encryptedUser struct {
+	ID           uint
+	Email        string
+	Country      string
+	EmailIndex   *string
+	CountryIndex *string
+}
+
+// ReencryptUsers перешифровывает текущим ключом email и country, записанные старыми
+// ключами или открыто, и пересчитывает их слепые индексы, включая удалённых
+// пользователей. Строки обходятся пачками по batchSize, каждая пачка - своя
+// транзакция, после неё вызывается progress. Версия пользователей не растёт и
+// событий нет: данные не изменились. Старые пользователи, чей email совпадает с
+// более ранним без учёта регистра, сохраняют индекс по email как есть, о них
+// пишется предупреждение в лог.
+func (s *Storage) ReencryptUsers(ctx context.Context, batchSize int, progress func(scanned, updated int64)) (int64, error) {
+	if s.Keyring == nil {
+		return 0, ErrEncryptionDisabled
+	}
+
+	var scanned, updated int64
+	var lastID uint
+	for {
+		var rows []encryptedUser
+		var n int64
+		err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			err := tx.Table("users").
+				Select("id", "email", "country", "email_index", "country_index").
+				Where("id > ?", lastID).
+				Order("id").Limit(batchSize).
+				Find(&rows).Error
+			if err != nil {
+				return err
+			}
+			for _, row := range rows {
+				changes, err := s.reencrypt(ctx, tx, row)
+				if err != nil {
+					return fmt.Errorf("user %d: %w", row.ID, err)
+				}
+				if len(changes) == 0 {
+					continue
+				}
+				// если строку уже переписал запрос приложения, она записана текущим ключом
+				res := tx.Table("users").
+					Where("id = ? AND email = ? AND country = ?", row.ID, row.Email, row.Country).
+					UpdateColumns(changes)
+				if res.Error != nil {
+					return res.Error
+				}
+				n += res.RowsAffected
+			}
+			return nil
+		})
+		if err != nil {
+			return updated, translate(ctx, err)
+		}
+		if len(rows) == 0 {
+			return updated, nil
+		}
+
+		scanned += int64(len(rows))
+		updated += n
+		lastID = rows[len(rows)-1].ID
+		if progress != nil {
+			progress(scanned, updated)
+		}
+		if len(rows) < batchSize {
+			return updated, nil
+		}
+	}
+}
+
+// reencrypt возвращает колонки строки, которые нужно переписать.
+func (s *Storage) reencrypt(ctx context.Context, tx *gorm.DB, row encryptedUser) (map[string]interface{}, error) {
+	changes := make(map[string]interface{})
+	columns := []struct {
+		name  string
+		value string
+		index *string
+	}{
+		{"email", row.Email, row.EmailIndex},
+		{"country", row.Country, row.CountryIndex},
+	}
+	for _, c := range columns {
+		plaintext, err := s.Keyring.Decrypt(c.name, c.value)
+		if err != nil {
+			return nil, err
+		}
+		if c.value != "" && !s.Keyring.Current(c.value) {
+			if changes[c.name], err = s.Keyring.Encrypt(c.name, plaintext); err != nil {
+				return nil, err
+			}
+		}
+		index := s.Keyring.BlindIndex(c.name, plaintext)
+		if c.name == "email" {
+			// индекс email строится по нижнему регистру, как при записи пользователя,
+			// если он ещё не занят старым пользователем с тем же email в другом регистре
+			normalized := s.Keyring.BlindIndex(c.name, models.NormalizeEmail(plaintext))
+			taken, err := s.indexTaken(tx, row.ID, normalized)
+			if err != nil {
+				return nil, err
+			}
+			if !taken {
+				index = normalized
+			} else if c.index != nil {
+				index = *c.index
+			}
+			if taken {
+				slog.WarnContext(ctx, "email differs from another user only in case, index left as is",
+					slog.Uint64("user_id", uint64(row.ID)))
+			}
+		}
+		if c.index == nil || *c.index != index {
+			changes[c.name+"_index"] = index
+		}
+	}
+	return changes, nil
+}
+
+// indexTaken сообщает, записан ли email_index index у другого пользователя.
+func (s *Storage) indexTaken(tx *gorm.DB, id uint, index string) (bool, error) {
+	var n int64
+	err := tx.Table("users").Where("email_index = ? AND id <> ?", index, id).Count(&n).Error
+	return n > 0, err
+}
@@ -0,0 +1,485 @@
+package gormstore
+
+import (
+	"backend-app/internal/events"
+	"backend-app/internal/fieldcrypt"
+	"backend-app/internal/search"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Storage содержит общую для postgres и sqlite реализацию storage.UserRepository поверх gorm.
+type Storage struct {
+	DB *gorm.DB
+	// Replicas обслуживают чтения вне транзакций, пусто - всё идёт в DB.
+	// Реплика может немного отставать, поэтому записи и чтения перед записью идут в DB.
+	Replicas []*gorm.DB
+	// Keyring шифрует email и country, nil - они хранятся открыто. Задаётся через UseKeyring.
+	Keyring *fieldcrypt.Keyring
+	next    atomic.Uint64
+}
+
+// UseKeyring включает шифрование полей пользователей на primary и репликах.
+func (s *Storage) UseKeyring(k *fieldcrypt.Keyring) error {
+	if k == nil {
+		return nil
+	}
+	for _, db := range append([]*gorm.DB{s.DB}, s.Replicas...) {
+		if err := db.Use(fieldcrypt.Plugin{Keyring: k}); err != nil {
+			return err
+		}
+	}
+	s.Keyring = k
+	return nil
+}
+
+// setIndexes приводит к виду для сравнения email и username пользователя и
+// пересчитывает слепые индексы зашифрованных полей.
+func (s *Storage) setIndexes(user *models.User) {
+	user.Normalize()
+	if s.Keyring == nil {
+		return
+	}
+	email := s.Keyring.BlindIndex("email", user.Email)
+	country := s.Keyring.BlindIndex("country", user.Country)
+	user.EmailIndex, user.CountryIndex = &email, &country
+}
+
+func (s *Storage) CreateUser(ctx context.Context, user *models.User) error {
+	user.Version = 1
+	s.setIndexes(user)
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return createEvents(tx, events.UserCreated(user))
+	})
+	return translate(ctx, err)
+}
+
+func (s *Storage) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		return db.First(&user, id).Error
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return &user, nil
+}
+
+// GetUserByEmail ищет по слепому индексу, если email зашифрован. Кроме email в нижнем
+// регистре подходит и точное совпадение: так находятся старые записи, которые миграция
+// не привела к нижнему регистру из-за совпадения с чужим email.
+func (s *Storage) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	emails := []string{models.NormalizeEmail(email), email}
+	var user models.User
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		if s.Keyring != nil {
+			indexes := []string{s.Keyring.BlindIndex("email", emails[0]), s.Keyring.BlindIndex("email", emails[1])}
+			return db.Where("email_index IN ?", indexes).Order("id").First(&user).Error
+		}
+		return db.Where("email IN ?", emails).Order("id").First(&user).Error
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return &user, nil
+}
+
+// GetUserByUsername сначала ищет точное совпадение, чтобы старые пользователи без
+// UsernameKey находились по своему username, а потом - без учёта регистра.
+func (s *Storage) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	var user models.User
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		err := db.Where("username = ?", username).First(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = db.Where("username_key = ?", models.NormalizeUsername(username)).First(&user).Error
+		}
+		return err
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return &user, nil
+}
+
+// UpdateUser сохраняет пользователя, если его версия в базе всё ещё равна user.Version,
+// и увеличивает версию. TokenVersion растёт, если смена пароля, роли или блокировка
+// должны отозвать уже выданные токены. Refresh token меняет только SetRefreshToken.
+func (s *Storage) UpdateUser(ctx context.Context, user *models.User) error {
+	expected := user.Version
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current models.User
+		if err := tx.First(&current, user.ID).Error; err != nil {
+			return err
+		}
+		if current.Version != user.Version {
+			return storage.ErrVersionConflict
+		}
+
+		if user.Status == "" {
+			user.Status = current.Status
+		}
+		if user.Password == "" {
+			user.Password = current.Password
+		}
+		user.TokenVersion = current.TokenVersion
+		if user.RevokesTokens(&current) {
+			user.TokenVersion++
+		}
+		user.CreatedAt = current.CreatedAt
+		user.Version = current.Version + 1
+		usernameKept, emailKept := user.Username == current.Username, user.Email == current.Email
+		s.setIndexes(user)
+		// у старых пользователей, совпавших с более ранними без учёта регистра, нет
+		// username_key и email не в нижнем регистре: пока их не меняют, они остаются как были
+		if usernameKept {
+			user.UsernameKey = current.UsernameKey
+		}
+		if emailKept {
+			user.Email, user.EmailIndex = current.Email, current.EmailIndex
+		}
+
+		// compare-and-swap: между чтением и записью строку мог изменить другой запрос
+		res := tx.Model(&models.User{}).
+			Where("id = ? AND version = ?", user.ID, current.Version).
+			Select("*").
+			Omit("id", "created_at", "refresh_token", "token_expiry", "deleted_at", "deleted_by", "delete_reason").
+			Updates(user)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return storage.ErrVersionConflict
+		}
+		return createEvents(tx, events.UserChanged(&current, user))
+	})
+	if err != nil {
+		user.Version = expected
+	}
+	return translate(ctx, err)
+}
+
+func (s *Storage) PatchUser(ctx context.Context, id uint, version uint, patch storage.UserPatch) (*models.User, error) {
+	var user models.User
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&user, id).Error; err != nil {
+			return err
+		}
+		return s.patchUser(tx, &user, version, patch)
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return &user, nil
+}
+
+// patchUser применяет patch к прочитанному в транзакции tx пользователю и перечитывает его.
+func (s *Storage) patchUser(tx *gorm.DB, user *models.User, version uint, patch storage.UserPatch) error {
+	if user.Version != version {
+		return storage.ErrVersionConflict
+	}
+	id := user.ID
+
+	prev := *user
+	changes := patch.Apply(user)
+	if len(changes) == 0 {
+		return nil
+	}
+	if user.RevokesTokens(&prev) {
+		user.TokenVersion++
+		changes["token_version"] = user.TokenVersion
+	}
+	user.Version++
+	changes["version"] = user.Version
+
+	// обновляем из структуры, а не из changes: значения из map gorm пишет мимо
+	// сериализатора и не зашифровал бы их
+	columns := append(slices.Collect(maps.Keys(changes)), "updated_at")
+	if s.Keyring != nil {
+		s.setIndexes(user)
+		// индексы пишутся только для изменённых полей: у старых пользователей,
+		// совпавших с более ранними без учёта регистра, индекс email свой
+		if _, ok := changes["email"]; ok {
+			columns = append(columns, "email_index")
+		}
+		if _, ok := changes["country"]; ok {
+			columns = append(columns, "country_index")
+		}
+	}
+	res := tx.Model(&models.User{}).Where("id = ? AND version = ?", id, version).Select(columns).Updates(user)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return storage.ErrVersionConflict
+	}
+	if err := tx.First(user, id).Error; err != nil {
+		return err
+	}
+	return createEvents(tx, events.UserChanged(&prev, user))
+}
+
+func (s *Storage) SetRefreshToken(ctx context.Context, id uint, token string, expiry time.Time) error {
+	res := s.DB.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"refresh_token": token,
+		"token_expiry":  expiry,
+	})
+	if res.Error != nil {
+		return translate(ctx, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return storage.ErrUserNotFound
+	}
+	return nil
+}
+
+func (s *Storage) DeleteUser(ctx context.Context, id uint, deletedBy uint, reason string) error {
+	updates := map[string]interface{}{
+		"deleted_at":    time.Now(),
+		"delete_reason": reason,
+		"refresh_token": "",
+		// удаление тоже отзывает выданные токены
+		"token_version": gorm.Expr("token_version + 1"),
+		"version":       gorm.Expr("version + 1"),
+	}
+	if deletedBy != 0 {
+		updates["deleted_by"] = deletedBy
+	}
+
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.User{}).Where("id = ?", id).Updates(updates)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return storage.ErrUserNotFound
+		}
+		var user models.User
+		if err := tx.Unscoped().First(&user, id).Error; err != nil {
+			return err
+		}
+		return createEvents(tx, events.UserDeleted(&user))
+	})
+	return translate(ctx, err)
+}
+
+func (s *Storage) RestoreUser(ctx context.Context, id uint) error {
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Unscoped().Model(&models.User{}).
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			Updates(map[string]interface{}{
+				"deleted_at":    nil,
+				"deleted_by":    nil,
+				"delete_reason": "",
+				"version":       gorm.Expr("version + 1"),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return storage.ErrUserNotFound
+		}
+		var user models.User
+		if err := tx.First(&user, id).Error; err != nil {
+			return err
+		}
+		return createEvents(tx, events.UserRestored(&user))
+	})
+	return translate(ctx, err)
+}
+
+func (s *Storage) GetAllUsers(ctx context.Context, offset int, limit int) ([]models.User, error) {
+	var users []models.User
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		return db.Order("id").Offset(offset).Limit(limit).Find(&users).Error
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return users, nil
+}
+
+func (s *Storage) ListUsers(ctx context.Context, q storage.UserQuery) (storage.UserPage, error) {
+	if err := q.Normalize(); err != nil {
+		return storage.UserPage{}, err
+	}
+
+	// зашифрованные значения в базе упорядочены случайно
+	if s.Keyring != nil && (q.SortField == "email" || q.SortField == "country") {
+		return storage.UserPage{}, fmt.Errorf("%w: %s", storage.ErrUnsortableField, q.SortField)
+	}
+
+	f := q.Filter
+	filter := func(db *gorm.DB) *gorm.DB {
+		db = inOrg(db.Model(&models.User{}), f.OrgID)
+		if f.Role != "" {
+			db = db.Where("role = ?", f.Role)
+		}
+		if f.Country != "" && s.Keyring != nil {
+			db = db.Where("country_index = ?", s.Keyring.BlindIndex("country", f.Country))
+		} else if f.Country != "" {
+			db = db.Where("country = ?", f.Country)
+		}
+		if f.Status != "" {
+			db = db.Where("status = ?", f.Status)
+		}
+		if f.Verified != nil {
+			db = db.Where("verified = ?", *f.Verified)
+		}
+		if !f.CreatedFrom.IsZero() {
+			db = db.Where("created_at >= ?", f.CreatedFrom)
+		}
+		if !f.CreatedTo.IsZero() {
+			db = db.Where("created_at < ?", f.CreatedTo)
+		}
+		return db
+	}
+
+	// при листании назад идём в обратном порядке, а потом переворачиваем результат
+	backward := q.Cursor != nil && q.Cursor.Backward
+	op, dir := ">", "ASC"
+	if q.SortDesc != backward {
+		op, dir = "<", "DESC"
+	}
+
+	// имя колонки из белого списка storage.UserSortFields, подставлять его безопасно
+	col := q.SortField
+	var value interface{}
+	if q.Cursor != nil {
+		var err error
+		if value, err = storage.ParseSortValue(col, q.Cursor.Value); err != nil {
+			return storage.UserPage{}, err
+		}
+	}
+
+	var page storage.UserPage
+	var users []models.User
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		if q.WithTotal {
+			var total int64
+			if err := filter(db).Count(&total).Error; err != nil {
+				return err
+			}
+			page.Total = &total
+		}
+
+		query := filter(db)
+		if q.Cursor != nil {
+			if col == "id" {
+				query = query.Where("id "+op+" ?", value)
+			} else {
+				query = query.Where("("+col+" "+op+" ?) OR ("+col+" = ? AND id "+op+" ?)", value, value, q.Cursor.ID)
+			}
+		}
+		if col != "id" {
+			query = query.Order(col + " " + dir)
+		}
+		return query.Order("id " + dir).Limit(q.Limit + 1).Find(&users).Error
+	})
+	if err != nil {
+		return storage.UserPage{}, translate(ctx, err)
+	}
+
+	return storage.BuildUserPage(page, q, users), nil
+}
+
+// SearchUsers перебирает пользователей и оценивает их в Go. Postgres
+// переопределяет метод запросом с pg_trgm, здесь остаётся вариант для sqlite.
+func (s *Storage) SearchUsers(ctx context.Context, query string, orgID uint, limit int) ([]storage.UserMatch, error) {
+	query = search.Normalize(query)
+	var matches []storage.UserMatch
+
+	var batch []models.User
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		matches = matches[:0]
+		return inOrg(db, orgID).Order("id").FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+			for i := range batch {
+				if m, ok := storage.MatchUser(query, &batch[i]); ok {
+					matches = append(matches, m)
+				}
+			}
+			return nil
+		}).Error
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return storage.RankMatches(matches, limit), nil
+}
+
+func (s *Storage) GetDeletedUsers(ctx context.Context, orgID uint, offset int, limit int) ([]models.User, error) {
+	var users []models.User
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		return inOrg(db.Unscoped(), orgID).
+			Where("deleted_at IS NOT NULL").
+			Order("deleted_at DESC, id").
+			Offset(offset).Limit(limit).
+			Find(&users).Error
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return users, nil
+}
+
+func (s *Storage) PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		purgeable := tx.Unscoped().Model(&models.User{}).Select("id").Where("deleted_at IS NOT NULL AND deleted_at < ?", before)
+		if err := tx.Where("user_id IN (?)", purgeable).Delete(&models.Membership{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id IN (?)", purgeable).Delete(&models.GroupMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id IN (?)", purgeable).Delete(&models.Profile{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id IN (?)", purgeable).Delete(&models.Login{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id IN (?)", purgeable).Delete(&models.EmailChange{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id IN (?)", purgeable).Delete(&models.DataExport{}).Error; err != nil {
+			return err
+		}
+		res := tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Delete(&models.User{})
+		purged = res.RowsAffected
+		return res.Error
+	})
+	return purged, translate(ctx, err)
+}
+
+// translate приводит ошибки gorm к ошибкам пакета storage. Если запрос прервал
+// контекст, ошибка оборачивает ctx.Err(): драйверы не всегда делают это сами.
+func translate(ctx context.Context, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil && !errors.Is(err, ctx.Err()):
+		return fmt.Errorf("%w: %v", ctx.Err(), err)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return storage.ErrUserNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return storage.ErrUserExists
+	default:
+		return err
+	}
+}
+
+// Translate - translate для хранилищ, которые встраивают Storage и делают свои запросы.
+func Translate(ctx context.Context, err error) error {
+	return translate(ctx, err)
+}
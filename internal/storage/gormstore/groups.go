@@ -0,0 +1,181 @@
+package gormstore
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (s *Storage) CreateGroup(ctx context.Context, group *models.Group) error {
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkParent(tx, group); err != nil {
+			return err
+		}
+		return tx.Create(group).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return storage.ErrGroupExists
+	}
+	return translate(ctx, err)
+}
+
+func (s *Storage) GetGroup(ctx context.Context, id uint) (*models.Group, error) {
+	var group models.Group
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		return db.First(&group, id).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, storage.ErrGroupNotFound
+	}
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return &group, nil
+}
+
+func (s *Storage) ListGroups(ctx context.Context) ([]models.Group, error) {
+	var groups []models.Group
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		return db.Order("id").Find(&groups).Error
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return groups, nil
+}
+
+func (s *Storage) UpdateGroup(ctx context.Context, group *models.Group) error {
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkParent(tx, group); err != nil {
+			return err
+		}
+		group.UpdatedAt = time.Now()
+		res := tx.Model(&models.Group{}).Where("id = ?", group.ID).Updates(map[string]interface{}{
+			"name":        group.Name,
+			"description": group.Description,
+			"parent_id":   group.ParentID,
+			"roles":       group.Roles,
+			"permissions": group.Permissions,
+			"updated_at":  group.UpdatedAt,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return storage.ErrGroupNotFound
+		}
+		return tx.First(group, group.ID).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return storage.ErrGroupExists
+	}
+	return translate(ctx, err)
+}
+
+func (s *Storage) DeleteGroup(ctx context.Context, id uint) error {
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var children int64
+		if err := tx.Model(&models.Group{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+			return err
+		}
+		if children > 0 {
+			return storage.ErrGroupHasChildren
+		}
+		if err := tx.Where("group_id = ?", id).Delete(&models.GroupMember{}).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(&models.Group{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return storage.ErrGroupNotFound
+		}
+		return nil
+	})
+	return translate(ctx, err)
+}
+
+func (s *Storage) AddGroupMember(ctx context.Context, groupID uint, userID uint) error {
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Select("id").First(&models.Group{}, groupID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return storage.ErrGroupNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.Select("id").First(&models.User{}, userID).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.GroupMember{GroupID: groupID, UserID: userID}).Error
+	})
+	return translate(ctx, err)
+}
+
+func (s *Storage) RemoveGroupMember(ctx context.Context, groupID uint, userID uint) error {
+	res := s.DB.WithContext(ctx).Where("group_id = ? AND user_id = ?", groupID, userID).Delete(&models.GroupMember{})
+	if res.Error != nil {
+		return translate(ctx, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return storage.ErrGroupMemberNotFound
+	}
+	return nil
+}
+
+func (s *Storage) ListGroupMembers(ctx context.Context, groupID uint, offset int, limit int) ([]models.GroupMember, error) {
+	var members []models.GroupMember
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		err := db.Select("id").First(&models.Group{}, groupID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return storage.ErrGroupNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return db.Where("group_id = ?", groupID).Order("user_id").Offset(offset).Limit(limit).Find(&members).Error
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return members, nil
+}
+
+func (s *Storage) ListUserGroups(ctx context.Context, userID uint) ([]models.Group, error) {
+	var groups []models.Group
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		members := db.Model(&models.GroupMember{}).Select("group_id").Where("user_id = ?", userID)
+		return db.Where("id IN (?)", members).Order("id").Find(&groups).Error
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return groups, nil
+}
+
+// checkParent проходит по предкам группы: родитель должен существовать, а сама группа
+// не должна оказаться среди них.
+func checkParent(tx *gorm.DB, group *models.Group) error {
+	for parent := group.ParentID; parent != nil; {
+		if group.ID != 0 && *parent == group.ID {
+			return storage.ErrGroupCycle
+		}
+		var p models.Group
+		err := tx.Select("id", "parent_id").First(&p, *parent).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return storage.ErrGroupParentNotFound
+		}
+		if err != nil {
+			return err
+		}
+		parent = p.ParentID
+	}
+	return nil
+}
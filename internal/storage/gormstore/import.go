@@ -0,0 +1,55 @@
+package gormstore
+
+import (
+	"backend-app/internal/events"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// errDryRun откатывает транзакцию пробного импорта.
+var errDryRun = errors.New("dry run")
+
+// ImportUsers вставляет каждого пользователя под своей точкой сохранения: в postgres
+// ошибка прерывает всю транзакцию, а конфликт одной строки не должен откатывать остальные.
+func (s *Storage) ImportUsers(ctx context.Context, users []*models.User, dryRun bool) ([]error, error) {
+	errs := make([]error, len(users))
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, user := range users {
+			if err := tx.SavePoint("import_user").Error; err != nil {
+				return err
+			}
+			user.Version = 1
+			s.setIndexes(user)
+			err := tx.Create(user).Error
+			if err == nil {
+				err = createEvents(tx, events.UserCreated(user))
+			}
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				if err := tx.RollbackTo("import_user").Error; err != nil {
+					return err
+				}
+				user.ID = 0
+				errs[i] = storage.ErrUserExists
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		err = nil
+	}
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return errs, nil
+}
@@ -0,0 +1,60 @@
+package gormstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// SlowQueryThreshold - запросы дольше логируются как медленные.
+const SlowQueryThreshold = 200 * time.Millisecond
+
+// Config - общие настройки gorm для postgres и sqlite.
+func Config() *gorm.Config {
+	return &gorm.Config{TranslateError: true, Logger: Logger{}}
+}
+
+// Logger пишет ошибки и медленные запросы gorm в slog.Default() вместе с
+// request_id HTTP запроса, из которого пришёл контекст.
+type Logger struct{}
+
+func (l Logger) LogMode(logger.LogLevel) logger.Interface { return l }
+
+func (l Logger) Info(ctx context.Context, msg string, args ...interface{}) {
+	slog.InfoContext(ctx, fmt.Sprintf(msg, args...), requestID(ctx))
+}
+
+func (l Logger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	slog.WarnContext(ctx, fmt.Sprintf(msg, args...), requestID(ctx))
+}
+
+func (l Logger) Error(ctx context.Context, msg string, args ...interface{}) {
+	slog.ErrorContext(ctx, fmt.Sprintf(msg, args...), requestID(ctx))
+}
+
+func (l Logger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+	switch {
+	// "не найдено", дубликаты и прерванные запросы - обычные ответы хранилища, их разбирают вызывающие
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && !errors.Is(err, gorm.ErrDuplicatedKey) &&
+		!errors.Is(err, context.Canceled):
+		sql, rows := fc()
+		slog.ErrorContext(ctx, "query failed", requestID(ctx),
+			slog.String("sql", sql), slog.Int64("rows", rows), slog.Duration("elapsed", elapsed),
+			slog.String("err", err.Error()))
+	case elapsed > SlowQueryThreshold:
+		sql, rows := fc()
+		slog.WarnContext(ctx, "slow query", requestID(ctx),
+			slog.String("sql", sql), slog.Int64("rows", rows), slog.Duration("elapsed", elapsed))
+	}
+}
+
+func requestID(ctx context.Context) slog.Attr {
+	return slog.String("request_id", middleware.GetReqID(ctx))
+}
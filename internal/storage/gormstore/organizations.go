@@ -0,0 +1,175 @@
+package gormstore
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (s *Storage) CreateOrganization(ctx context.Context, org *models.Organization) error {
+	err := s.DB.WithContext(ctx).Create(org).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return storage.ErrOrgExists
+	}
+	return translate(ctx, err)
+}
+
+func (s *Storage) GetOrganization(ctx context.Context, id uint) (*models.Organization, error) {
+	var org models.Organization
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		return db.First(&org, id).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, storage.ErrOrgNotFound
+	}
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return &org, nil
+}
+
+func (s *Storage) ListOrganizations(ctx context.Context, offset int, limit int) ([]models.Organization, error) {
+	var orgs []models.Organization
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		return db.Order("id").Offset(offset).Limit(limit).Find(&orgs).Error
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return orgs, nil
+}
+
+func (s *Storage) DeleteOrganization(ctx context.Context, id uint) error {
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var members int64
+		if err := tx.Model(&models.Membership{}).Where("org_id = ?", id).Count(&members).Error; err != nil {
+			return err
+		}
+		if members > 0 {
+			return storage.ErrOrgNotEmpty
+		}
+		res := tx.Delete(&models.Organization{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return storage.ErrOrgNotFound
+		}
+		return nil
+	})
+	return translate(ctx, err)
+}
+
+func (s *Storage) SetMembership(ctx context.Context, m *models.Membership) error {
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Select("id").First(&models.Organization{}, m.OrgID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return storage.ErrOrgNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.Select("id").First(&models.User{}, m.UserID).Error; err != nil {
+			return err
+		}
+
+		var current models.Membership
+		err = tx.Where("org_id = ? AND user_id = ?", m.OrgID, m.UserID).First(&current).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			m.Organization = nil
+			return tx.Create(m).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		m.CreatedAt, m.UpdatedAt = current.CreatedAt, current.UpdatedAt
+		if current.Role == m.Role {
+			return nil
+		}
+		m.UpdatedAt = time.Now()
+		err = tx.Model(&models.Membership{}).
+			Where("org_id = ? AND user_id = ?", m.OrgID, m.UserID).
+			Updates(map[string]interface{}{"role": m.Role, "updated_at": m.UpdatedAt}).Error
+		if err != nil {
+			return err
+		}
+		return revokeTokens(tx, m.UserID)
+	})
+	return translate(ctx, err)
+}
+
+func (s *Storage) RemoveMembership(ctx context.Context, orgID uint, userID uint) error {
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("org_id = ? AND user_id = ?", orgID, userID).Delete(&models.Membership{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return storage.ErrMembershipNotFound
+		}
+		return revokeTokens(tx, userID)
+	})
+	return translate(ctx, err)
+}
+
+func (s *Storage) GetMembership(ctx context.Context, orgID uint, userID uint) (*models.Membership, error) {
+	var m models.Membership
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		return db.Where("org_id = ? AND user_id = ?", orgID, userID).First(&m).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, storage.ErrMembershipNotFound
+	}
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return &m, nil
+}
+
+func (s *Storage) ListMembers(ctx context.Context, orgID uint, offset int, limit int) ([]models.Membership, error) {
+	var members []models.Membership
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		err := db.Select("id").First(&models.Organization{}, orgID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return storage.ErrOrgNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return db.Where("org_id = ?", orgID).Order("user_id").Offset(offset).Limit(limit).Find(&members).Error
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return members, nil
+}
+
+func (s *Storage) ListUserMemberships(ctx context.Context, userID uint) ([]models.Membership, error) {
+	var members []models.Membership
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		return db.Preload("Organization").Where("user_id = ?", userID).Order("org_id").Find(&members).Error
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return members, nil
+}
+
+// revokeTokens отзывает токены пользователя: его роль в организации изменилась.
+func revokeTokens(tx *gorm.DB, userID uint) error {
+	return tx.Model(&models.User{}).Where("id = ?", userID).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error
+}
+
+// inOrg оставляет в выборке из users только участников организации orgID, если он не 0.
+func inOrg(db *gorm.DB, orgID uint) *gorm.DB {
+	if orgID == 0 {
+		return db
+	}
+	return db.Where("EXISTS (SELECT 1 FROM memberships WHERE memberships.user_id = users.id AND memberships.org_id = ?)", orgID)
+}
@@ -0,0 +1,73 @@
+package gormstore
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func createEvents(tx *gorm.DB, events []models.OutboxEvent) error {
+	return tx.Create(&events).Error
+}
+
+func (s *Storage) AddEvents(ctx context.Context, events []models.OutboxEvent) error {
+	return translate(ctx, createEvents(s.DB.WithContext(ctx), events))
+}
+
+// ClaimEvents помечает пачку событий меткой аренды одним UPDATE и читает помеченные.
+// Условие на next_attempt_at повторяется снаружи подзапроса: если два relay выбрали
+// одни и те же строки, postgres перепроверит его после блокировки и второй их пропустит.
+func (s *Storage) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	now := time.Now()
+	token := storage.NewLease()
+	db := s.DB.WithContext(ctx)
+
+	pending := db.Model(&models.OutboxEvent{}).
+		Select("id").
+		Where("published_at IS NULL AND next_attempt_at <= ?", now).
+		Order("id").
+		Limit(limit)
+	res := db.Model(&models.OutboxEvent{}).
+		Where("id IN (?) AND published_at IS NULL AND next_attempt_at <= ?", pending, now).
+		Updates(map[string]interface{}{
+			"lease":           token,
+			"next_attempt_at": now.Add(lease),
+		})
+	if res.Error != nil {
+		return nil, translate(ctx, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	var claimed []models.OutboxEvent
+	if err := db.Where("lease = ? AND published_at IS NULL", token).Order("id").Find(&claimed).Error; err != nil {
+		return nil, translate(ctx, err)
+	}
+	return claimed, nil
+}
+
+func (s *Storage) MarkPublished(ctx context.Context, id uint) error {
+	err := s.DB.WithContext(ctx).Model(&models.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"published_at": time.Now(),
+		"last_error":   "",
+	}).Error
+	return translate(ctx, err)
+}
+
+func (s *Storage) MarkFailed(ctx context.Context, id uint, reason string, retryAt time.Time) error {
+	err := s.DB.WithContext(ctx).Model(&models.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"last_error":      reason,
+		"next_attempt_at": retryAt,
+	}).Error
+	return translate(ctx, err)
+}
+
+func (s *Storage) DeletePublishedEvents(ctx context.Context, before time.Time) (int64, error) {
+	res := s.DB.WithContext(ctx).Where("published_at < ?", before).Delete(&models.OutboxEvent{})
+	return res.RowsAffected, translate(ctx, res.Error)
+}
@@ -0,0 +1,260 @@
+package gormstore
+
+import (
+	"backend-app/internal/events"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (s *Storage) CreateDataExport(ctx context.Context, export *models.DataExport) error {
+	return translate(ctx, s.DB.WithContext(ctx).Create(export).Error)
+}
+
+func (s *Storage) GetDataExport(ctx context.Context, id uint) (*models.DataExport, error) {
+	var export models.DataExport
+	// архив только что собран на primary, реплика могла его ещё не получить
+	err := s.DB.WithContext(ctx).First(&export, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, storage.ErrDataExportNotFound
+	}
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return &export, nil
+}
+
+func (s *Storage) ListDataExports(ctx context.Context, userID uint) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := s.DB.WithContext(ctx).
+		Omit("archive").
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Find(&exports).Error
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return exports, nil
+}
+
+func (s *Storage) PendingDataExports(ctx context.Context, limit int) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := s.DB.WithContext(ctx).
+		Where("status = ?", models.ExportPending).
+		Order("id").
+		Limit(limit).
+		Find(&exports).Error
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return exports, nil
+}
+
+func (s *Storage) CompleteDataExport(ctx context.Context, export *models.DataExport) error {
+	res := s.DB.WithContext(ctx).Model(export).
+		Select("status", "archive", "error", "completed_at", "expires_at").
+		Updates(export)
+	if res.Error != nil {
+		return translate(ctx, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return storage.ErrDataExportNotFound
+	}
+	return nil
+}
+
+func (s *Storage) DeleteExpiredDataExports(ctx context.Context, before time.Time) (int64, error) {
+	res := s.DB.WithContext(ctx).Where("expires_at < ?", before).Delete(&models.DataExport{})
+	return res.RowsAffected, translate(ctx, res.Error)
+}
+
+func (s *Storage) ListUserEvents(ctx context.Context, userID uint) ([]models.OutboxEvent, error) {
+	var evs []models.OutboxEvent
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		return db.Where("user_id = ?", userID).Order("id").Find(&evs).Error
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return evs, nil
+}
+
+func (s *Storage) CreateErasureRequest(ctx context.Context, req *models.ErasureRequest) error {
+	req.Status = models.ErasurePending
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// в postgres это же гарантирует частичный уникальный индекс, в sqlite его нет
+		var pending int64
+		err := tx.Model(&models.ErasureRequest{}).
+			Where("user_id = ? AND status = ?", req.UserID, models.ErasurePending).
+			Count(&pending).Error
+		if err != nil {
+			return err
+		}
+		if pending > 0 {
+			return storage.ErrErasurePending
+		}
+		return tx.Create(req).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return storage.ErrErasurePending
+	}
+	return translate(ctx, err)
+}
+
+func (s *Storage) GetErasureRequest(ctx context.Context, id uint) (*models.ErasureRequest, error) {
+	var req models.ErasureRequest
+	err := s.DB.WithContext(ctx).First(&req, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, storage.ErrErasureRequestNotFound
+	}
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return &req, nil
+}
+
+func (s *Storage) ListErasureRequests(ctx context.Context, status string, offset int, limit int) ([]models.ErasureRequest, error) {
+	var reqs []models.ErasureRequest
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		if status != "" {
+			db = db.Where("status = ?", status)
+		}
+		return db.Order("id DESC").Offset(offset).Limit(limit).Find(&reqs).Error
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return reqs, nil
+}
+
+func (s *Storage) RejectErasureRequest(ctx context.Context, id uint, reviewedBy uint, note string) (*models.ErasureRequest, error) {
+	var req models.ErasureRequest
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := pendingErasureRequest(tx, id, &req); err != nil {
+			return err
+		}
+		now := time.Now()
+		req.Status = models.ErasureRejected
+		req.ReviewedBy = &reviewedBy
+		req.ReviewNote = note
+		req.ReviewedAt = &now
+		return updateErasureRequest(tx, &req)
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return &req, nil
+}
+
+// EraseUser обезличивает и удалённых пользователей: мягкое удаление не стирает данные.
+func (s *Storage) EraseUser(ctx context.Context, requestID uint, reviewedBy uint, note string) (*models.ErasureRequest, error) {
+	var req models.ErasureRequest
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := pendingErasureRequest(tx, requestID, &req); err != nil {
+			return err
+		}
+
+		var user models.User
+		if err := tx.Unscoped().First(&user, req.UserID).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		user.Anonymize(now)
+		user.Version++
+		user.TokenVersion++
+		s.setIndexes(&user)
+		err := tx.Unscoped().Model(&user).
+			Select("username", "username_key", "email", "country", "email_index", "country_index", "password", "refresh_token",
+				"token_expiry", "delete_reason", "status", "erased_at", "version", "token_version", "updated_at").
+			Updates(&user).Error
+		if err != nil {
+			return err
+		}
+
+		var evs []models.OutboxEvent
+		if err := tx.Where("user_id = ?", user.ID).Find(&evs).Error; err != nil {
+			return err
+		}
+		for _, e := range evs {
+			err := tx.Model(&models.OutboxEvent{}).Where("id = ?", e.ID).
+				Update("payload", events.ScrubPayload(e.Payload, &user)).Error
+			if err != nil {
+				return err
+			}
+		}
+		var deliveries []models.WebhookDelivery
+		if err := tx.Where("user_id = ?", user.ID).Find(&deliveries).Error; err != nil {
+			return err
+		}
+		for _, d := range deliveries {
+			err := tx.Model(&models.WebhookDelivery{}).Where("id = ?", d.ID).
+				Update("payload", events.ScrubEvent(d.Payload, &user)).Error
+			if err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.DataExport{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.Profile{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.Login{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.EmailChange{}).Error; err != nil {
+			return err
+		}
+		if err := createEvents(tx, events.UserErased(&user)); err != nil {
+			return err
+		}
+
+		req.Status = models.ErasureCompleted
+		req.Reason = ""
+		req.ReviewedBy = &reviewedBy
+		req.ReviewNote = note
+		req.ReviewedAt = &now
+		req.CompletedAt = &now
+		req.ScrubbedEvents = int64(len(evs))
+		req.ScrubbedDeliveries = int64(len(deliveries))
+		return updateErasureRequest(tx, &req)
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return &req, nil
+}
+
+func pendingErasureRequest(tx *gorm.DB, id uint, req *models.ErasureRequest) error {
+	err := tx.First(req, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return storage.ErrErasureRequestNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if req.Status != models.ErasurePending {
+		return storage.ErrErasureNotPending
+	}
+	return nil
+}
+
+// updateErasureRequest сохраняет рассмотренный запрос, если его не рассмотрели
+// параллельно, иначе откатывает транзакцию с ErrErasureNotPending.
+func updateErasureRequest(tx *gorm.DB, req *models.ErasureRequest) error {
+	res := tx.Model(req).Where("status = ?", models.ErasurePending).
+		Select("status", "reason", "reviewed_by", "review_note", "reviewed_at", "completed_at",
+			"scrubbed_events", "scrubbed_deliveries").
+		Updates(req)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return storage.ErrErasureNotPending
+	}
+	return nil
+}
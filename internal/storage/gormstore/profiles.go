@@ -0,0 +1,115 @@
+package gormstore
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (s *Storage) GetProfile(ctx context.Context, userID uint) (*models.Profile, error) {
+	var profile models.Profile
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		if err := db.Select("id").First(&models.User{}, userID).Error; err != nil {
+			return err
+		}
+		err := db.Where("user_id = ?", userID).Take(&profile).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			profile = models.Profile{UserID: userID, Attributes: map[string]interface{}{}}
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	if profile.Attributes == nil {
+		profile.Attributes = map[string]interface{}{}
+	}
+	return &profile, nil
+}
+
+func (s *Storage) SaveProfile(ctx context.Context, profile *models.Profile) error {
+	if profile.Attributes == nil {
+		profile.Attributes = map[string]interface{}{}
+	}
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&models.User{}, profile.UserID).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		profile.CreatedAt = now
+		profile.UpdatedAt = now
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"display_name", "avatar_url", "bio", "locale", "time_zone",
+				"native_languages", "learning_languages", "attributes", "updated_at"}),
+		}).Create(profile).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", profile.UserID).Take(profile).Error
+	})
+	return translate(ctx, err)
+}
+
+func (s *Storage) ListAttributeDefinitions(ctx context.Context) ([]models.AttributeDefinition, error) {
+	var defs []models.AttributeDefinition
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		return db.Order("key").Find(&defs).Error
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return defs, nil
+}
+
+func (s *Storage) CreateAttributeDefinition(ctx context.Context, def *models.AttributeDefinition) error {
+	err := s.DB.WithContext(ctx).Create(def).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return storage.ErrAttributeExists
+	}
+	return translate(ctx, err)
+}
+
+func (s *Storage) UpdateAttributeDefinition(ctx context.Context, def *models.AttributeDefinition) error {
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		def.UpdatedAt = time.Now()
+		res := tx.Model(&models.AttributeDefinition{}).Where("key = ?", def.Key).Updates(map[string]interface{}{
+			"type":          def.Type,
+			"description":   def.Description,
+			"required":      def.Required,
+			"user_editable": def.UserEditable,
+			"enum":          def.Enum,
+			"min_length":    def.MinLength,
+			"max_length":    def.MaxLength,
+			"minimum":       def.Minimum,
+			"maximum":       def.Maximum,
+			"pattern":       def.Pattern,
+			"updated_at":    def.UpdatedAt,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return storage.ErrAttributeNotFound
+		}
+		return tx.Where("key = ?", def.Key).Take(def).Error
+	})
+	return translate(ctx, err)
+}
+
+func (s *Storage) DeleteAttributeDefinition(ctx context.Context, key string) error {
+	res := s.DB.WithContext(ctx).Where("key = ?", key).Delete(&models.AttributeDefinition{})
+	if res.Error != nil {
+		return translate(ctx, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return storage.ErrAttributeNotFound
+	}
+	return nil
+}
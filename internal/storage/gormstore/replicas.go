@@ -0,0 +1,32 @@
+package gormstore
+
+import (
+	"backend-app/internal/storage"
+	"context"
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+// Read выполняет запрос только на чтение на одной из реплик по кругу. Если реплик
+// нет или реплика не ответила, запрос повторяется на primary. С контекстом
+// storage.WithPrimary запрос сразу идёт на primary.
+func (s *Storage) Read(ctx context.Context, query func(db *gorm.DB) error) error {
+	if len(s.Replicas) > 0 && !storage.PrimaryOnly(ctx) {
+		i := s.next.Add(1) % uint64(len(s.Replicas))
+		err := query(s.Replicas[i].WithContext(ctx))
+		if !replicaFailed(ctx, err) {
+			return err
+		}
+		slog.WarnContext(ctx, "replica query failed, falling back to primary", requestID(ctx),
+			slog.Uint64("replica", i), slog.String("err", err.Error()))
+	}
+	return query(s.DB.WithContext(ctx))
+}
+
+// replicaFailed отличает отказ реплики от обычных ответов: "не найдено" с primary
+// было бы таким же, а отменённый запрос повторять незачем.
+func replicaFailed(ctx context.Context, err error) bool {
+	return err != nil && ctx.Err() == nil && !errors.Is(err, gorm.ErrRecordNotFound)
+}
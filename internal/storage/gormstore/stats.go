@@ -0,0 +1,154 @@
+package gormstore
+
+import (
+	"backend-app/internal/country"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (s *Storage) RecordLogin(ctx context.Context, login *models.Login) error {
+	return translate(ctx, s.DB.WithContext(ctx).Create(login).Error)
+}
+
+func (s *Storage) DeleteLogins(ctx context.Context, before time.Time) (int64, error) {
+	res := s.DB.WithContext(ctx).Where("created_at < ?", before).Delete(&models.Login{})
+	return res.RowsAffected, translate(ctx, res.Error)
+}
+
+// RegistrationCounts группирует пользователей в базе, а страны приводит к кодам уже
+// после: открыто хранятся и старые значения вроде названий, а зашифрованные страны
+// различаются только по слепому индексу.
+func (s *Storage) RegistrationCounts(ctx context.Context, q storage.StatsQuery) ([]storage.RegistrationCount, error) {
+	countryColumn, roleColumn := "COALESCE(users.country, '')", "COALESCE(users.role, '')"
+	if s.Keyring != nil {
+		countryColumn = "COALESCE(users.country_index, '')"
+	}
+	if q.OrgID != 0 {
+		roleColumn = "memberships.role"
+	}
+
+	var rows []storage.RegistrationCount
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		db = db.Unscoped().Table("users").
+			Select(s.bucketExpr("users.created_at", q.Interval)+" AS bucket, "+
+				countryColumn+" AS country, "+roleColumn+" AS role, "+
+				"COUNT(*) AS total, SUM(CASE WHEN users.verified THEN 1 ELSE 0 END) AS verified").
+			Where("users.created_at >= ? AND users.created_at < ?", q.From, q.To)
+		if q.OrgID != 0 {
+			db = db.Joins("JOIN memberships ON memberships.user_id = users.id AND memberships.org_id = ?", q.OrgID)
+		}
+		// номера колонок, а не псевдонимы: postgres предпочёл бы колонки users с теми же именами
+		return db.Group("1, 2, 3").Scan(&rows).Error
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+
+	code := func(value string) string {
+		// старые записи могли сохранить страну не кодом
+		c, err := country.Normalize(value)
+		if err != nil {
+			return ""
+		}
+		return c
+	}
+	if s.Keyring != nil {
+		codes := s.countryCodes()
+		code = func(index string) string { return codes[index] }
+	}
+
+	type key struct{ bucket, country, role string }
+	counts := make(map[key]*storage.RegistrationCount)
+	for _, row := range rows {
+		k := key{row.Bucket, code(row.Country), row.Role}
+		c, ok := counts[k]
+		if !ok {
+			c = &storage.RegistrationCount{Bucket: k.bucket, Country: k.country, Role: k.role}
+			counts[k] = c
+		}
+		c.Total += row.Total
+		c.Verified += row.Verified
+	}
+	result := make([]storage.RegistrationCount, 0, len(counts))
+	for _, c := range counts {
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Bucket != b.Bucket {
+			return a.Bucket < b.Bucket
+		}
+		if a.Country != b.Country {
+			return a.Country < b.Country
+		}
+		return a.Role < b.Role
+	})
+	return result, nil
+}
+
+func (s *Storage) LoginCounts(ctx context.Context, q storage.StatsQuery) ([]storage.LoginCount, error) {
+	var rows []storage.LoginCount
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		db = db.Table("logins").
+			Select(s.bucketExpr("logins.created_at", q.Interval)+" AS bucket, "+
+				"SUM(CASE WHEN logins.succeeded THEN 1 ELSE 0 END) AS succeeded, "+
+				"SUM(CASE WHEN logins.succeeded THEN 0 ELSE 1 END) AS failed, "+
+				"COUNT(DISTINCT CASE WHEN logins.succeeded THEN logins.user_id END) AS active_users").
+			Where("logins.created_at >= ? AND logins.created_at < ?", q.From, q.To)
+		if q.OrgID != 0 {
+			db = db.Joins("JOIN memberships ON memberships.user_id = logins.user_id AND memberships.org_id = ?", q.OrgID)
+		}
+		return db.Group("bucket").Order("bucket").Scan(&rows).Error
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return rows, nil
+}
+
+func (s *Storage) ActiveUsers(ctx context.Context, orgID uint, from, to time.Time) (int64, error) {
+	var active int64
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		db = db.Table("logins").
+			Select("COUNT(DISTINCT logins.user_id)").
+			Where("logins.succeeded = ? AND logins.created_at >= ? AND logins.created_at < ?", true, from, to)
+		if orgID != 0 {
+			db = db.Joins("JOIN memberships ON memberships.user_id = logins.user_id AND memberships.org_id = ?", orgID)
+		}
+		return db.Scan(&active).Error
+	})
+	return active, translate(ctx, err)
+}
+
+// bucketExpr возвращает выражение, дающее начало интервала для column в виде
+// YYYY-MM-DD по UTC, как storage.StatsBucket.
+func (s *Storage) bucketExpr(column, interval string) string {
+	if s.DB.Dialector.Name() == "postgres" {
+		if interval == storage.IntervalWeek {
+			return "to_char(date_trunc('week', " + column + " AT TIME ZONE 'UTC'), 'YYYY-MM-DD')"
+		}
+		return "to_char(" + column + " AT TIME ZONE 'UTC', 'YYYY-MM-DD')"
+	}
+	if interval == storage.IntervalWeek {
+		// ближайшее воскресенье, не раньше дня входа, минус шесть дней - понедельник
+		return "strftime('%Y-%m-%d', " + column + ", 'weekday 0', '-6 days')"
+	}
+	return "strftime('%Y-%m-%d', " + column + ")"
+}
+
+// countryCodes сопоставляет слепые индексы всех написаний стран, которые принимает
+// country.Normalize с точностью до регистра, кодам alpha-2.
+func (s *Storage) countryCodes() map[string]string {
+	codes := make(map[string]string)
+	for _, c := range country.All() {
+		for _, value := range []string{c.Alpha2, c.Alpha3, c.Numeric, c.Name} {
+			codes[s.Keyring.BlindIndex("country", value)] = c.Alpha2
+		}
+	}
+	return codes
+}
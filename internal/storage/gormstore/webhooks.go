@@ -0,0 +1,155 @@
+package gormstore
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (s *Storage) CreateWebhook(ctx context.Context, hook *models.Webhook) error {
+	return translate(ctx, s.DB.WithContext(ctx).Create(hook).Error)
+}
+
+func (s *Storage) GetWebhook(ctx context.Context, id uint) (*models.Webhook, error) {
+	var hook models.Webhook
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		return db.First(&hook, id).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, storage.ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return &hook, nil
+}
+
+func (s *Storage) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	var hooks []models.Webhook
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		return db.Order("id").Find(&hooks).Error
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return hooks, nil
+}
+
+func (s *Storage) UpdateWebhook(ctx context.Context, hook *models.Webhook) error {
+	res := s.DB.WithContext(ctx).Model(hook).
+		Select("url", "secret", "events", "active", "updated_at").
+		Updates(hook)
+	if res.Error != nil {
+		return translate(ctx, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return storage.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (s *Storage) DeleteWebhook(ctx context.Context, id uint) error {
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(&models.Webhook{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return storage.ErrWebhookNotFound
+		}
+		return nil
+	})
+	return translate(ctx, err)
+}
+
+func (s *Storage) EnqueueDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	err := s.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "webhook_id"}, {Name: "event_id"}}, DoNothing: true}).
+		Create(&deliveries).Error
+	return translate(ctx, err)
+}
+
+// ClaimDeliveries работает так же, как ClaimEvents.
+func (s *Storage) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	now := time.Now()
+	token := storage.NewLease()
+	db := s.DB.WithContext(ctx)
+
+	pending := db.Model(&models.WebhookDelivery{}).
+		Select("id").
+		Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, now).
+		Order("id").
+		Limit(limit)
+	res := db.Model(&models.WebhookDelivery{}).
+		Where("id IN (?) AND status = ? AND next_attempt_at <= ?", pending, models.DeliveryPending, now).
+		Updates(map[string]interface{}{
+			"lease":           token,
+			"next_attempt_at": now.Add(lease),
+		})
+	if res.Error != nil {
+		return nil, translate(ctx, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	var claimed []models.WebhookDelivery
+	err := db.Where("lease = ? AND status = ?", token, models.DeliveryPending).Order("id").Find(&claimed).Error
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return claimed, nil
+}
+
+func (s *Storage) GetDelivery(ctx context.Context, id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		return db.First(&delivery, id).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, storage.ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return &delivery, nil
+}
+
+func (s *Storage) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	res := s.DB.WithContext(ctx).Model(delivery).
+		Select("status", "attempts", "next_attempt_at", "last_error", "response_status", "delivered_at", "updated_at").
+		Updates(delivery)
+	if res.Error != nil {
+		return translate(ctx, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return storage.ErrDeliveryNotFound
+	}
+	return nil
+}
+
+func (s *Storage) ListDeliveries(ctx context.Context, webhookID uint, status string, offset int, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		db = db.Where("webhook_id = ?", webhookID)
+		if status != "" {
+			db = db.Where("status = ?", status)
+		}
+		return db.Order("id DESC").Offset(offset).Limit(limit).Find(&deliveries).Error
+	})
+	if err != nil {
+		return nil, translate(ctx, err)
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"backend-app/internal/storage/models"
+	"context"
+	"errors"
+)
+
+var (
+	ErrGroupNotFound = errors.New("group not found")
+	ErrGroupExists   = errors.New("group already exists")
+	// ErrGroupParentNotFound - родительской группы нет.
+	ErrGroupParentNotFound = errors.New("parent group not found")
+	// ErrGroupCycle - группа стала бы своим же предком.
+	ErrGroupCycle = errors.New("group cannot inherit from itself")
+	// ErrGroupHasChildren - от группы наследуют другие группы, её нельзя удалить.
+	ErrGroupHasChildren    = errors.New("group has child groups")
+	ErrGroupMemberNotFound = errors.New("user is not a member of the group")
+)
+
+// GroupRepository хранит группы и их участников. Права из групп попадают только в
+// токены, выпущенные после изменения, поэтому изменения групп токены не отзывают.
+type GroupRepository interface {
+	// CreateGroup возвращает ErrGroupExists, если имя занято, и ErrGroupParentNotFound,
+	// если нет родительской группы.
+	CreateGroup(ctx context.Context, group *models.Group) error
+	GetGroup(ctx context.Context, id uint) (*models.Group, error)
+	// ListGroups возвращает все группы по возрастанию id.
+	ListGroups(ctx context.Context) ([]models.Group, error)
+	// UpdateGroup заменяет группу целиком. Родитель, который сам наследует от группы,
+	// даёт ErrGroupCycle.
+	UpdateGroup(ctx context.Context, group *models.Group) error
+	// DeleteGroup удаляет группу вместе с участием в ней. Группу, от которой наследуют
+	// другие, удалить нельзя: ErrGroupHasChildren.
+	DeleteGroup(ctx context.Context, id uint) error
+	// AddGroupMember добавляет пользователя в группу, повторное добавление ничего не меняет.
+	AddGroupMember(ctx context.Context, groupID uint, userID uint) error
+	RemoveGroupMember(ctx context.Context, groupID uint, userID uint) error
+	// ListGroupMembers возвращает прямых участников группы по возрастанию id пользователя.
+	ListGroupMembers(ctx context.Context, groupID uint, offset int, limit int) ([]models.GroupMember, error)
+	// ListUserGroups возвращает группы, в которые пользователь добавлен напрямую.
+	ListUserGroups(ctx context.Context, userID uint) ([]models.Group, error)
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"time"
+)
+
+func (s *Storage) SaveEmailChange(ctx context.Context, change *models.EmailChange) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[change.UserID]; !ok {
+		return storage.ErrUserNotFound
+	}
+	change.CreatedAt = time.Now()
+	s.emailChanges[change.UserID] = *change
+	return nil
+}
+
+func (s *Storage) GetEmailChange(ctx context.Context, tokenHash string) (*models.EmailChange, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, change := range s.emailChanges {
+		if change.TokenHash == tokenHash {
+			return &change, nil
+		}
+	}
+	return nil, storage.ErrEmailChangeNotFound
+}
+
+func (s *Storage) DeleteEmailChange(ctx context.Context, userID uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.emailChanges[userID]; !ok {
+		return storage.ErrEmailChangeNotFound
+	}
+	delete(s.emailChanges, userID)
+	return nil
+}
+
+func (s *Storage) ConfirmEmailChange(ctx context.Context, tokenHash string, now time.Time) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, change := range s.emailChanges {
+		if change.TokenHash != tokenHash {
+			continue
+		}
+		if now.After(change.ExpiresAt) {
+			break
+		}
+		user, ok := s.users[change.UserID]
+		if !ok {
+			return nil, storage.ErrUserNotFound
+		}
+		verified := true
+		patched, err := s.patchUser(change.UserID, user.Version, storage.UserPatch{
+			Email:          &change.NewEmail,
+			Verified:       &verified,
+			EmailChangedAt: &now,
+		})
+		if err != nil {
+			return nil, err
+		}
+		delete(s.emailChanges, change.UserID)
+		return patched, nil
+	}
+	return nil, storage.ErrEmailChangeNotFound
+}
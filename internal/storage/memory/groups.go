@@ -0,0 +1,208 @@
+package memory
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"sort"
+	"time"
+)
+
+type groupMemberKey struct {
+	groupID uint
+	userID  uint
+}
+
+func (s *Storage) CreateGroup(ctx context.Context, group *models.Group) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkGroup(group); err != nil {
+		return err
+	}
+	now := time.Now()
+	group.ID = s.nextGroupID
+	group.CreatedAt = now
+	group.UpdatedAt = now
+	s.nextGroupID++
+	s.groups[group.ID] = *group
+	return nil
+}
+
+func (s *Storage) GetGroup(ctx context.Context, id uint) (*models.Group, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	group, ok := s.groups[id]
+	if !ok {
+		return nil, storage.ErrGroupNotFound
+	}
+	return &group, nil
+}
+
+func (s *Storage) ListGroups(ctx context.Context) ([]models.Group, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	groups := make([]models.Group, 0, len(s.groups))
+	for _, group := range s.groups {
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].ID < groups[j].ID })
+	return groups, nil
+}
+
+func (s *Storage) UpdateGroup(ctx context.Context, group *models.Group) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.groups[group.ID]
+	if !ok {
+		return storage.ErrGroupNotFound
+	}
+	if err := s.checkGroup(group); err != nil {
+		return err
+	}
+	group.CreatedAt = current.CreatedAt
+	group.UpdatedAt = time.Now()
+	s.groups[group.ID] = *group
+	return nil
+}
+
+func (s *Storage) DeleteGroup(ctx context.Context, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.groups[id]; !ok {
+		return storage.ErrGroupNotFound
+	}
+	for _, group := range s.groups {
+		if group.ParentID != nil && *group.ParentID == id {
+			return storage.ErrGroupHasChildren
+		}
+	}
+	for key := range s.groupMembers {
+		if key.groupID == id {
+			delete(s.groupMembers, key)
+		}
+	}
+	delete(s.groups, id)
+	return nil
+}
+
+func (s *Storage) AddGroupMember(ctx context.Context, groupID uint, userID uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.groups[groupID]; !ok {
+		return storage.ErrGroupNotFound
+	}
+	user, ok := s.users[userID]
+	if !ok || user.DeletedAt.Valid {
+		return storage.ErrUserNotFound
+	}
+	key := groupMemberKey{groupID, userID}
+	if _, ok := s.groupMembers[key]; !ok {
+		s.groupMembers[key] = models.GroupMember{GroupID: groupID, UserID: userID, CreatedAt: time.Now()}
+	}
+	return nil
+}
+
+func (s *Storage) RemoveGroupMember(ctx context.Context, groupID uint, userID uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := groupMemberKey{groupID, userID}
+	if _, ok := s.groupMembers[key]; !ok {
+		return storage.ErrGroupMemberNotFound
+	}
+	delete(s.groupMembers, key)
+	return nil
+}
+
+func (s *Storage) ListGroupMembers(ctx context.Context, groupID uint, offset int, limit int) ([]models.GroupMember, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.groups[groupID]; !ok {
+		return nil, storage.ErrGroupNotFound
+	}
+	var members []models.GroupMember
+	for key, m := range s.groupMembers {
+		if key.groupID == groupID {
+			members = append(members, m)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].UserID < members[j].UserID })
+	return paginate(members, offset, limit), nil
+}
+
+func (s *Storage) ListUserGroups(ctx context.Context, userID uint) ([]models.Group, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	groups := []models.Group{}
+	for key := range s.groupMembers {
+		if key.userID == userID {
+			groups = append(groups, s.groups[key.groupID])
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].ID < groups[j].ID })
+	return groups, nil
+}
+
+// checkGroup проверяет имя и родителя группы перед записью.
+func (s *Storage) checkGroup(group *models.Group) error {
+	for _, other := range s.groups {
+		if other.Name == group.Name && other.ID != group.ID {
+			return storage.ErrGroupExists
+		}
+	}
+	for parent := group.ParentID; parent != nil; {
+		if group.ID != 0 && *parent == group.ID {
+			return storage.ErrGroupCycle
+		}
+		p, ok := s.groups[*parent]
+		if !ok {
+			return storage.ErrGroupParentNotFound
+		}
+		parent = p.ParentID
+	}
+	return nil
+}
@@ -0,0 +1,547 @@
+package memory
+
+import (
+	"backend-app/internal/events"
+	"backend-app/internal/search"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Storage хранит пользователей в памяти процесса. Подходит для тестов и локального запуска.
+// Методы не блокируются на вводе-выводе, поэтому контекст проверяется только на входе.
+type Storage struct {
+	mu     sync.RWMutex
+	users  map[uint]models.User
+	nextID uint
+	// events - outbox, пишется под тем же mu, что и пользователи
+	events      []models.OutboxEvent
+	nextEventID uint
+
+	webhooks       map[uint]models.Webhook
+	nextWebhookID  uint
+	deliveries     []models.WebhookDelivery
+	nextDeliveryID uint
+
+	exports       []models.DataExport
+	nextExportID  uint
+	erasures      []models.ErasureRequest
+	nextErasureID uint
+
+	orgs        map[uint]models.Organization
+	nextOrgID   uint
+	memberships map[membershipKey]models.Membership
+
+	groups       map[uint]models.Group
+	nextGroupID  uint
+	groupMembers map[groupMemberKey]models.GroupMember
+
+	profiles   map[uint]models.Profile
+	attributes map[string]models.AttributeDefinition
+
+	logins      []models.Login
+	nextLoginID uint
+
+	emailChanges map[uint]models.EmailChange
+}
+
+func New() *Storage {
+	return &Storage{
+		users:          make(map[uint]models.User),
+		nextID:         1,
+		nextEventID:    1,
+		webhooks:       make(map[uint]models.Webhook),
+		nextWebhookID:  1,
+		nextDeliveryID: 1,
+		nextExportID:   1,
+		nextErasureID:  1,
+		orgs:           make(map[uint]models.Organization),
+		nextOrgID:      1,
+		memberships:    make(map[membershipKey]models.Membership),
+		groups:         make(map[uint]models.Group),
+		nextGroupID:    1,
+		groupMembers:   make(map[groupMemberKey]models.GroupMember),
+		profiles:       make(map[uint]models.Profile),
+		attributes:     make(map[string]models.AttributeDefinition),
+		nextLoginID:    1,
+		emailChanges:   make(map[uint]models.EmailChange),
+	}
+}
+
+func (s *Storage) CreateUser(ctx context.Context, user *models.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user.Normalize()
+	if s.conflicts(user) {
+		return storage.ErrUserExists
+	}
+	s.create(user)
+	return nil
+}
+
+func (s *Storage) ImportUsers(ctx context.Context, users []*models.User, dryRun bool) ([]error, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// taken нужен для dryRun, когда пользователи из пачки не попадают в s.users
+	errs := make([]error, len(users))
+	taken := make(map[string]bool)
+	for i, user := range users {
+		user.Normalize()
+		if s.conflicts(user) || taken["u:"+*user.UsernameKey] || taken["e:"+user.Email] {
+			errs[i] = storage.ErrUserExists
+			continue
+		}
+		taken["u:"+*user.UsernameKey], taken["e:"+user.Email] = true, true
+		if !dryRun {
+			s.create(user)
+		}
+	}
+	return errs, nil
+}
+
+// create сохраняет нового пользователя, s.mu должен быть захвачен.
+func (s *Storage) create(user *models.User) {
+	now := time.Now()
+	user.ID = s.nextID
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	user.Version = 1
+	if user.Role == "" {
+		user.Role = "user"
+	}
+	if user.Status == "" {
+		user.Status = models.StatusActive
+	}
+	s.nextID++
+	s.users[user.ID] = *user
+	s.addEvents(events.UserCreated(user))
+}
+
+func (s *Storage) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return nil, storage.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+func (s *Storage) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key := models.NormalizeUsername(username)
+	for _, user := range s.users {
+		if models.NormalizeUsername(user.Username) == key && !user.DeletedAt.Valid {
+			return &user, nil
+		}
+	}
+	return nil, storage.ErrUserNotFound
+}
+
+func (s *Storage) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	email = models.NormalizeEmail(email)
+	for _, user := range s.users {
+		if user.Email == email && !user.DeletedAt.Valid {
+			return &user, nil
+		}
+	}
+	return nil, storage.ErrUserNotFound
+}
+
+func (s *Storage) UpdateUser(ctx context.Context, user *models.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.users[user.ID]
+	if !ok || current.DeletedAt.Valid {
+		return storage.ErrUserNotFound
+	}
+	if current.Version != user.Version {
+		return storage.ErrVersionConflict
+	}
+	user.Normalize()
+	if s.conflicts(user) {
+		return storage.ErrUserExists
+	}
+
+	if user.Status == "" {
+		user.Status = current.Status
+	}
+	if user.Password == "" {
+		user.Password = current.Password
+	}
+	user.TokenVersion = current.TokenVersion
+	if user.RevokesTokens(&current) {
+		user.TokenVersion++
+	}
+	user.CreatedAt = current.CreatedAt
+	user.UpdatedAt = time.Now()
+	user.Version++
+	user.RefreshToken = current.RefreshToken
+	user.TokenExpiry = current.TokenExpiry
+	s.users[user.ID] = *user
+	s.addEvents(events.UserChanged(&current, user))
+	return nil
+}
+
+func (s *Storage) PatchUser(ctx context.Context, id uint, version uint, patch storage.UserPatch) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.patchUser(id, version, patch)
+}
+
+// patchUser - PatchUser под уже взятой s.mu.
+func (s *Storage) patchUser(id uint, version uint, patch storage.UserPatch) (*models.User, error) {
+	user, ok := s.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return nil, storage.ErrUserNotFound
+	}
+	if user.Version != version {
+		return nil, storage.ErrVersionConflict
+	}
+
+	prev := user
+	if len(patch.Apply(&user)) == 0 {
+		return &user, nil
+	}
+	if s.conflicts(&user) {
+		return nil, storage.ErrUserExists
+	}
+	if user.RevokesTokens(&prev) {
+		user.TokenVersion++
+	}
+	user.Version++
+	user.UpdatedAt = time.Now()
+	s.users[id] = user
+	s.addEvents(events.UserChanged(&prev, &user))
+	return &user, nil
+}
+
+func (s *Storage) SetRefreshToken(ctx context.Context, id uint, token string, expiry time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return storage.ErrUserNotFound
+	}
+	user.RefreshToken = token
+	user.TokenExpiry = expiry
+	s.users[id] = user
+	return nil
+}
+
+func (s *Storage) DeleteUser(ctx context.Context, id uint, deletedBy uint, reason string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return storage.ErrUserNotFound
+	}
+	user.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	user.DeleteReason = reason
+	user.DeletedBy = nil
+	if deletedBy != 0 {
+		user.DeletedBy = &deletedBy
+	}
+	user.RefreshToken = ""
+	user.TokenVersion++
+	user.Version++
+	s.users[id] = user
+	s.addEvents(events.UserDeleted(&user))
+	return nil
+}
+
+func (s *Storage) RestoreUser(ctx context.Context, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok || !user.DeletedAt.Valid {
+		return storage.ErrUserNotFound
+	}
+	user.DeletedAt = gorm.DeletedAt{}
+	user.DeletedBy = nil
+	user.DeleteReason = ""
+	user.Version++
+	s.users[id] = user
+	s.addEvents(events.UserRestored(&user))
+	return nil
+}
+
+func (s *Storage) GetAllUsers(ctx context.Context, offset int, limit int) ([]models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := s.filter(func(u *models.User) bool { return !u.DeletedAt.Valid })
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return paginate(users, offset, limit), nil
+}
+
+func (s *Storage) ListUsers(ctx context.Context, q storage.UserQuery) (storage.UserPage, error) {
+	if err := ctx.Err(); err != nil {
+		return storage.UserPage{}, err
+	}
+
+	if err := q.Normalize(); err != nil {
+		return storage.UserPage{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := s.filter(func(u *models.User) bool {
+		return !u.DeletedAt.Valid && q.Filter.Matches(u) && s.inOrg(q.Filter.OrgID, u.ID)
+	})
+
+	var page storage.UserPage
+	if q.WithTotal {
+		total := int64(len(users))
+		page.Total = &total
+	}
+
+	backward := q.Cursor != nil && q.Cursor.Backward
+	desc := q.SortDesc != backward
+	// less сравнивает пользователей в порядке обхода: по полю сортировки, затем по id
+	less := func(a, b *models.User) bool {
+		c := compareField(a, b, q.SortField)
+		if c == 0 {
+			c = compareUint(a.ID, b.ID)
+		}
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	}
+	sort.Slice(users, func(i, j int) bool { return less(&users[i], &users[j]) })
+
+	if q.Cursor != nil {
+		value, err := storage.ParseSortValue(q.SortField, q.Cursor.Value)
+		if err != nil {
+			return storage.UserPage{}, err
+		}
+		pivot := cursorUser(q.SortField, value, q.Cursor.ID)
+		start := sort.Search(len(users), func(i int) bool { return less(pivot, &users[i]) })
+		users = users[start:]
+	}
+	if len(users) > q.Limit+1 {
+		users = users[:q.Limit+1]
+	}
+
+	return storage.BuildUserPage(page, q, users), nil
+}
+
+func (s *Storage) SearchUsers(ctx context.Context, query string, orgID uint, limit int) ([]storage.UserMatch, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	query = search.Normalize(query)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matches []storage.UserMatch
+	for _, user := range s.users {
+		if user.DeletedAt.Valid || !s.inOrg(orgID, user.ID) {
+			continue
+		}
+		if m, ok := storage.MatchUser(query, &user); ok {
+			matches = append(matches, m)
+		}
+	}
+	return storage.RankMatches(matches, limit), nil
+}
+
+func (s *Storage) GetDeletedUsers(ctx context.Context, orgID uint, offset int, limit int) ([]models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := s.filter(func(u *models.User) bool { return u.DeletedAt.Valid && s.inOrg(orgID, u.ID) })
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].DeletedAt.Time.Equal(users[j].DeletedAt.Time) {
+			return users[i].DeletedAt.Time.After(users[j].DeletedAt.Time)
+		}
+		return users[i].ID < users[j].ID
+	})
+	return paginate(users, offset, limit), nil
+}
+
+func (s *Storage) PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for id, user := range s.users {
+		if user.DeletedAt.Valid && user.DeletedAt.Time.Before(before) {
+			delete(s.users, id)
+			s.exports = slices.DeleteFunc(s.exports, func(e models.DataExport) bool { return e.UserID == id })
+			delete(s.profiles, id)
+			s.deleteLogins(id)
+			delete(s.emailChanges, id)
+			for key := range s.memberships {
+				if key.userID == id {
+					delete(s.memberships, key)
+				}
+			}
+			for key := range s.groupMembers {
+				if key.userID == id {
+					delete(s.groupMembers, key)
+				}
+			}
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (s *Storage) filter(keep func(u *models.User) bool) []models.User {
+	users := make([]models.User, 0, len(s.users))
+	for _, user := range s.users {
+		if keep(&user) {
+			users = append(users, user)
+		}
+	}
+	return users
+}
+
+// cursorUser собирает пользователя-ориентир из значения курсора.
+func cursorUser(field string, value interface{}, id uint) *models.User {
+	u := &models.User{ID: id}
+	switch v := value.(type) {
+	case time.Time:
+		u.CreatedAt = v
+	case string:
+		switch field {
+		case "username":
+			u.Username = v
+		case "email":
+			u.Email = v
+		case "country":
+			u.Country = v
+		}
+	}
+	return u
+}
+
+func compareField(a, b *models.User, field string) int {
+	switch field {
+	case "username":
+		return strings.Compare(a.Username, b.Username)
+	case "email":
+		return strings.Compare(a.Email, b.Email)
+	case "country":
+		return strings.Compare(a.Country, b.Country)
+	case "created_at":
+		return a.CreatedAt.Compare(b.CreatedAt)
+	default:
+		return 0
+	}
+}
+
+func compareUint(a, b uint) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func paginate[T any](items []T, offset int, limit int) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// conflicts проверяет уникальность username и email без учёта регистра, как unique
+// индексы в базе. user должен быть нормализован.
+func (s *Storage) conflicts(user *models.User) bool {
+	for id, other := range s.users {
+		if id == user.ID {
+			continue
+		}
+		if models.NormalizeUsername(other.Username) == models.NormalizeUsername(user.Username) || other.Email == user.Email {
+			return true
+		}
+	}
+	return false
+}
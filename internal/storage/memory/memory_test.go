@@ -0,0 +1,14 @@
+package memory_test
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/memory"
+	"backend-app/internal/storage/storagetest"
+	"testing"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Store {
+		return memory.New()
+	})
+}
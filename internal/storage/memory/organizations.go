@@ -0,0 +1,203 @@
+package memory
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"sort"
+	"time"
+)
+
+type membershipKey struct {
+	orgID  uint
+	userID uint
+}
+
+func (s *Storage) CreateOrganization(ctx context.Context, org *models.Organization) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, other := range s.orgs {
+		if other.Name == org.Name {
+			return storage.ErrOrgExists
+		}
+	}
+	now := time.Now()
+	org.ID = s.nextOrgID
+	org.CreatedAt = now
+	org.UpdatedAt = now
+	s.nextOrgID++
+	s.orgs[org.ID] = *org
+	return nil
+}
+
+func (s *Storage) GetOrganization(ctx context.Context, id uint) (*models.Organization, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	org, ok := s.orgs[id]
+	if !ok {
+		return nil, storage.ErrOrgNotFound
+	}
+	return &org, nil
+}
+
+func (s *Storage) ListOrganizations(ctx context.Context, offset int, limit int) ([]models.Organization, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	orgs := make([]models.Organization, 0, len(s.orgs))
+	for _, org := range s.orgs {
+		orgs = append(orgs, org)
+	}
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].ID < orgs[j].ID })
+	return paginate(orgs, offset, limit), nil
+}
+
+func (s *Storage) DeleteOrganization(ctx context.Context, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.orgs[id]; !ok {
+		return storage.ErrOrgNotFound
+	}
+	for key := range s.memberships {
+		if key.orgID == id {
+			return storage.ErrOrgNotEmpty
+		}
+	}
+	delete(s.orgs, id)
+	return nil
+}
+
+func (s *Storage) SetMembership(ctx context.Context, m *models.Membership) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.orgs[m.OrgID]; !ok {
+		return storage.ErrOrgNotFound
+	}
+	user, ok := s.users[m.UserID]
+	if !ok || user.DeletedAt.Valid {
+		return storage.ErrUserNotFound
+	}
+
+	key := membershipKey{m.OrgID, m.UserID}
+	now := time.Now()
+	m.CreatedAt, m.UpdatedAt = now, now
+	if current, ok := s.memberships[key]; ok {
+		m.CreatedAt = current.CreatedAt
+		if current.Role != m.Role {
+			user.TokenVersion++
+			s.users[user.ID] = user
+		}
+	}
+	m.Organization = nil
+	s.memberships[key] = *m
+	return nil
+}
+
+func (s *Storage) RemoveMembership(ctx context.Context, orgID uint, userID uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := membershipKey{orgID, userID}
+	if _, ok := s.memberships[key]; !ok {
+		return storage.ErrMembershipNotFound
+	}
+	delete(s.memberships, key)
+	if user, ok := s.users[userID]; ok {
+		user.TokenVersion++
+		s.users[userID] = user
+	}
+	return nil
+}
+
+func (s *Storage) GetMembership(ctx context.Context, orgID uint, userID uint) (*models.Membership, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	m, ok := s.memberships[membershipKey{orgID, userID}]
+	if !ok {
+		return nil, storage.ErrMembershipNotFound
+	}
+	return &m, nil
+}
+
+func (s *Storage) ListMembers(ctx context.Context, orgID uint, offset int, limit int) ([]models.Membership, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.orgs[orgID]; !ok {
+		return nil, storage.ErrOrgNotFound
+	}
+	var members []models.Membership
+	for key, m := range s.memberships {
+		if key.orgID == orgID {
+			members = append(members, m)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].UserID < members[j].UserID })
+	return paginate(members, offset, limit), nil
+}
+
+func (s *Storage) ListUserMemberships(ctx context.Context, userID uint) ([]models.Membership, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	members := []models.Membership{}
+	for key, m := range s.memberships {
+		if key.userID == userID {
+			org := s.orgs[key.orgID]
+			m.Organization = &org
+			members = append(members, m)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].OrgID < members[j].OrgID })
+	return members, nil
+}
+
+// inOrg сообщает, состоит ли пользователь в организации. Нулевой orgID - любой пользователь.
+func (s *Storage) inOrg(orgID uint, userID uint) bool {
+	if orgID == 0 {
+		return true
+	}
+	_, ok := s.memberships[membershipKey{orgID, userID}]
+	return ok
+}
@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"time"
+)
+
+// addEvents добавляет события в outbox, вызывается под s.mu.
+func (s *Storage) addEvents(events []models.OutboxEvent) {
+	for _, e := range events {
+		e.ID = s.nextEventID
+		s.nextEventID++
+		s.events = append(s.events, e)
+	}
+}
+
+func (s *Storage) AddEvents(ctx context.Context, events []models.OutboxEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.addEvents(events)
+	return nil
+}
+
+func (s *Storage) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	token := storage.NewLease()
+	var claimed []models.OutboxEvent
+	for i := range s.events {
+		if len(claimed) == limit {
+			break
+		}
+		e := &s.events[i]
+		if e.PublishedAt != nil || e.NextAttemptAt.After(now) {
+			continue
+		}
+		e.Lease = token
+		e.NextAttemptAt = now.Add(lease)
+		claimed = append(claimed, *e)
+	}
+	return claimed, nil
+}
+
+func (s *Storage) MarkPublished(ctx context.Context, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e := s.event(id); e != nil {
+		now := time.Now()
+		e.PublishedAt = &now
+		e.LastError = ""
+	}
+	return nil
+}
+
+func (s *Storage) MarkFailed(ctx context.Context, id uint, reason string, retryAt time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e := s.event(id); e != nil {
+		e.Attempts++
+		e.LastError = reason
+		e.NextAttemptAt = retryAt
+	}
+	return nil
+}
+
+func (s *Storage) DeletePublishedEvents(ctx context.Context, before time.Time) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.events[:0]
+	for _, e := range s.events {
+		if e.PublishedAt == nil || !e.PublishedAt.Before(before) {
+			kept = append(kept, e)
+		}
+	}
+	deleted := int64(len(s.events) - len(kept))
+	s.events = kept
+	return deleted, nil
+}
+
+// event ищет событие по id, вызывается под s.mu.
+func (s *Storage) event(id uint) *models.OutboxEvent {
+	for i := range s.events {
+		if s.events[i].ID == id {
+			return &s.events[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+package memory
+
+import (
+	"backend-app/internal/events"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"time"
+)
+
+func (s *Storage) CreateDataExport(ctx context.Context, export *models.DataExport) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	export.ID = s.nextExportID
+	export.CreatedAt = time.Now()
+	s.nextExportID++
+	s.exports = append(s.exports, *export)
+	return nil
+}
+
+func (s *Storage) GetDataExport(ctx context.Context, id uint) (*models.DataExport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.export(id)
+	if i < 0 {
+		return nil, storage.ErrDataExportNotFound
+	}
+	export := s.exports[i]
+	return &export, nil
+}
+
+func (s *Storage) ListDataExports(ctx context.Context, userID uint) ([]models.DataExport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	exports := []models.DataExport{}
+	for i := len(s.exports) - 1; i >= 0; i-- {
+		if e := s.exports[i]; e.UserID == userID {
+			e.Archive = nil
+			exports = append(exports, e)
+		}
+	}
+	return exports, nil
+}
+
+func (s *Storage) PendingDataExports(ctx context.Context, limit int) ([]models.DataExport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var exports []models.DataExport
+	for _, e := range s.exports {
+		if len(exports) == limit {
+			break
+		}
+		if e.Status == models.ExportPending {
+			exports = append(exports, e)
+		}
+	}
+	return exports, nil
+}
+
+func (s *Storage) CompleteDataExport(ctx context.Context, export *models.DataExport) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.export(export.ID)
+	if i < 0 {
+		return storage.ErrDataExportNotFound
+	}
+	e := &s.exports[i]
+	e.Status = export.Status
+	e.Archive = export.Archive
+	e.Error = export.Error
+	e.CompletedAt = export.CompletedAt
+	e.ExpiresAt = export.ExpiresAt
+	return nil
+}
+
+func (s *Storage) DeleteExpiredDataExports(ctx context.Context, before time.Time) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.exports[:0]
+	for _, e := range s.exports {
+		if e.ExpiresAt == nil || !e.ExpiresAt.Before(before) {
+			kept = append(kept, e)
+		}
+	}
+	deleted := int64(len(s.exports) - len(kept))
+	s.exports = kept
+	return deleted, nil
+}
+
+func (s *Storage) ListUserEvents(ctx context.Context, userID uint) ([]models.OutboxEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	evs := []models.OutboxEvent{}
+	for _, e := range s.events {
+		if e.UserID == userID {
+			evs = append(evs, e)
+		}
+	}
+	return evs, nil
+}
+
+func (s *Storage) CreateErasureRequest(ctx context.Context, req *models.ErasureRequest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.erasures {
+		if r.UserID == req.UserID && r.Status == models.ErasurePending {
+			return storage.ErrErasurePending
+		}
+	}
+	req.ID = s.nextErasureID
+	req.Status = models.ErasurePending
+	req.CreatedAt = time.Now()
+	s.nextErasureID++
+	s.erasures = append(s.erasures, *req)
+	return nil
+}
+
+func (s *Storage) GetErasureRequest(ctx context.Context, id uint) (*models.ErasureRequest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.erasure(id)
+	if i < 0 {
+		return nil, storage.ErrErasureRequestNotFound
+	}
+	req := s.erasures[i]
+	return &req, nil
+}
+
+func (s *Storage) ListErasureRequests(ctx context.Context, status string, offset int, limit int) ([]models.ErasureRequest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reqs := []models.ErasureRequest{}
+	for i := len(s.erasures) - 1; i >= 0; i-- {
+		if r := s.erasures[i]; status == "" || r.Status == status {
+			reqs = append(reqs, r)
+		}
+	}
+	if offset >= len(reqs) {
+		return []models.ErasureRequest{}, nil
+	}
+	reqs = reqs[offset:]
+	if limit >= 0 && limit < len(reqs) {
+		reqs = reqs[:limit]
+	}
+	return reqs, nil
+}
+
+func (s *Storage) RejectErasureRequest(ctx context.Context, id uint, reviewedBy uint, note string) (*models.ErasureRequest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req, err := s.pendingErasure(id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	req.Status = models.ErasureRejected
+	req.ReviewedBy = &reviewedBy
+	req.ReviewNote = note
+	req.ReviewedAt = &now
+	result := *req
+	return &result, nil
+}
+
+func (s *Storage) EraseUser(ctx context.Context, requestID uint, reviewedBy uint, note string) (*models.ErasureRequest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req, err := s.pendingErasure(requestID)
+	if err != nil {
+		return nil, err
+	}
+	user, ok := s.users[req.UserID]
+	if !ok {
+		return nil, storage.ErrUserNotFound
+	}
+
+	now := time.Now()
+	user.Anonymize(now)
+	user.Normalize()
+	user.Version++
+	user.TokenVersion++
+	user.UpdatedAt = now
+	s.users[user.ID] = user
+
+	var scrubbedEvents, scrubbedDeliveries int64
+	for i := range s.events {
+		if e := &s.events[i]; e.UserID == user.ID {
+			e.Payload = events.ScrubPayload(e.Payload, &user)
+			scrubbedEvents++
+		}
+	}
+	for i := range s.deliveries {
+		if d := &s.deliveries[i]; d.UserID == user.ID {
+			d.Payload = events.ScrubEvent(d.Payload, &user)
+			scrubbedDeliveries++
+		}
+	}
+	kept := s.exports[:0]
+	for _, e := range s.exports {
+		if e.UserID != user.ID {
+			kept = append(kept, e)
+		}
+	}
+	s.exports = kept
+	delete(s.profiles, user.ID)
+	s.deleteLogins(user.ID)
+	delete(s.emailChanges, user.ID)
+	s.addEvents(events.UserErased(&user))
+
+	req.Status = models.ErasureCompleted
+	req.Reason = ""
+	req.ReviewedBy = &reviewedBy
+	req.ReviewNote = note
+	req.ReviewedAt = &now
+	req.CompletedAt = &now
+	req.ScrubbedEvents = scrubbedEvents
+	req.ScrubbedDeliveries = scrubbedDeliveries
+	result := *req
+	return &result, nil
+}
+
+// export возвращает индекс выгрузки в s.exports или -1, вызывается под s.mu.
+func (s *Storage) export(id uint) int {
+	for i := range s.exports {
+		if s.exports[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// erasure возвращает индекс запроса в s.erasures или -1, вызывается под s.mu.
+func (s *Storage) erasure(id uint) int {
+	for i := range s.erasures {
+		if s.erasures[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// pendingErasure возвращает запрос на рассмотрении для изменения на месте, вызывается под s.mu.
+func (s *Storage) pendingErasure(id uint) (*models.ErasureRequest, error) {
+	i := s.erasure(id)
+	if i < 0 {
+		return nil, storage.ErrErasureRequestNotFound
+	}
+	if s.erasures[i].Status != models.ErasurePending {
+		return nil, storage.ErrErasureNotPending
+	}
+	return &s.erasures[i], nil
+}
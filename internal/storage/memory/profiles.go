@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"maps"
+	"sort"
+	"time"
+)
+
+func (s *Storage) GetProfile(ctx context.Context, userID uint) (*models.Profile, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if user, ok := s.users[userID]; !ok || user.DeletedAt.Valid {
+		return nil, storage.ErrUserNotFound
+	}
+	profile, ok := s.profiles[userID]
+	if !ok {
+		return &models.Profile{UserID: userID, Attributes: map[string]interface{}{}}, nil
+	}
+	// значения атрибутов - скаляры, поверхностной копии карты достаточно
+	profile.Attributes = maps.Clone(profile.Attributes)
+	return &profile, nil
+}
+
+func (s *Storage) SaveProfile(ctx context.Context, profile *models.Profile) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user, ok := s.users[profile.UserID]; !ok || user.DeletedAt.Valid {
+		return storage.ErrUserNotFound
+	}
+	now := time.Now()
+	profile.CreatedAt = now
+	if current, ok := s.profiles[profile.UserID]; ok {
+		profile.CreatedAt = current.CreatedAt
+	}
+	profile.UpdatedAt = now
+	if profile.Attributes == nil {
+		profile.Attributes = map[string]interface{}{}
+	}
+	stored := *profile
+	stored.Attributes = maps.Clone(profile.Attributes)
+	s.profiles[profile.UserID] = stored
+	return nil
+}
+
+func (s *Storage) ListAttributeDefinitions(ctx context.Context) ([]models.AttributeDefinition, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	defs := make([]models.AttributeDefinition, 0, len(s.attributes))
+	for _, def := range s.attributes {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Key < defs[j].Key })
+	return defs, nil
+}
+
+func (s *Storage) CreateAttributeDefinition(ctx context.Context, def *models.AttributeDefinition) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.attributes[def.Key]; ok {
+		return storage.ErrAttributeExists
+	}
+	now := time.Now()
+	def.CreatedAt = now
+	def.UpdatedAt = now
+	s.attributes[def.Key] = *def
+	return nil
+}
+
+func (s *Storage) UpdateAttributeDefinition(ctx context.Context, def *models.AttributeDefinition) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.attributes[def.Key]
+	if !ok {
+		return storage.ErrAttributeNotFound
+	}
+	def.CreatedAt = current.CreatedAt
+	def.UpdatedAt = time.Now()
+	s.attributes[def.Key] = *def
+	return nil
+}
+
+func (s *Storage) DeleteAttributeDefinition(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.attributes[key]; !ok {
+		return storage.ErrAttributeNotFound
+	}
+	delete(s.attributes, key)
+	return nil
+}
@@ -0,0 +1,180 @@
+package memory
+
+import (
+	"backend-app/internal/country"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"sort"
+	"time"
+)
+
+func (s *Storage) RecordLogin(ctx context.Context, login *models.Login) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	login.ID = s.nextLoginID
+	s.nextLoginID++
+	if login.CreatedAt.IsZero() {
+		login.CreatedAt = time.Now()
+	}
+	s.logins = append(s.logins, *login)
+	return nil
+}
+
+func (s *Storage) DeleteLogins(ctx context.Context, before time.Time) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.logins[:0]
+	for _, l := range s.logins {
+		if !l.CreatedAt.Before(before) {
+			kept = append(kept, l)
+		}
+	}
+	deleted := int64(len(s.logins) - len(kept))
+	s.logins = kept
+	return deleted, nil
+}
+
+func (s *Storage) RegistrationCounts(ctx context.Context, q storage.StatsQuery) ([]storage.RegistrationCount, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type key struct{ bucket, country, role string }
+	counts := make(map[key]*storage.RegistrationCount)
+	for _, u := range s.users {
+		if u.CreatedAt.Before(q.From) || !u.CreatedAt.Before(q.To) {
+			continue
+		}
+		role := u.Role
+		if q.OrgID != 0 {
+			m, ok := s.memberships[membershipKey{q.OrgID, u.ID}]
+			if !ok {
+				continue
+			}
+			role = m.Role
+		}
+		// старые записи могли сохранить страну не кодом
+		code, err := country.Normalize(u.Country)
+		if err != nil {
+			code = ""
+		}
+		k := key{storage.StatsBucket(u.CreatedAt, q.Interval), code, role}
+		c, ok := counts[k]
+		if !ok {
+			c = &storage.RegistrationCount{Bucket: k.bucket, Country: k.country, Role: k.role}
+			counts[k] = c
+		}
+		c.Total++
+		if u.Verified {
+			c.Verified++
+		}
+	}
+
+	result := make([]storage.RegistrationCount, 0, len(counts))
+	for _, c := range counts {
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Bucket != b.Bucket {
+			return a.Bucket < b.Bucket
+		}
+		if a.Country != b.Country {
+			return a.Country < b.Country
+		}
+		return a.Role < b.Role
+	})
+	return result, nil
+}
+
+func (s *Storage) LoginCounts(ctx context.Context, q storage.StatsQuery) ([]storage.LoginCount, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[string]*storage.LoginCount)
+	active := make(map[string]map[uint]bool)
+	for _, l := range s.logins {
+		if !s.countsLogin(l, q.OrgID, q.From, q.To) {
+			continue
+		}
+		bucket := storage.StatsBucket(l.CreatedAt, q.Interval)
+		c, ok := counts[bucket]
+		if !ok {
+			c = &storage.LoginCount{Bucket: bucket}
+			counts[bucket] = c
+			active[bucket] = make(map[uint]bool)
+		}
+		if !l.Succeeded {
+			c.Failed++
+			continue
+		}
+		c.Succeeded++
+		active[bucket][l.UserID] = true
+	}
+
+	result := make([]storage.LoginCount, 0, len(counts))
+	for bucket, c := range counts {
+		c.ActiveUsers = int64(len(active[bucket]))
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Bucket < result[j].Bucket })
+	return result, nil
+}
+
+func (s *Storage) ActiveUsers(ctx context.Context, orgID uint, from, to time.Time) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	active := make(map[uint]bool)
+	for _, l := range s.logins {
+		if l.Succeeded && s.countsLogin(l, orgID, from, to) {
+			active[l.UserID] = true
+		}
+	}
+	return int64(len(active)), nil
+}
+
+// countsLogin сообщает, попадает ли вход в [from, to) и в организацию orgID.
+func (s *Storage) countsLogin(l models.Login, orgID uint, from, to time.Time) bool {
+	if l.CreatedAt.Before(from) || !l.CreatedAt.Before(to) {
+		return false
+	}
+	if orgID == 0 {
+		return true
+	}
+	_, ok := s.memberships[membershipKey{orgID, l.UserID}]
+	return ok
+}
+
+// deleteLogins удаляет входы пользователя, вызывается под mu.
+func (s *Storage) deleteLogins(userID uint) {
+	kept := s.logins[:0]
+	for _, l := range s.logins {
+		if l.UserID != userID {
+			kept = append(kept, l)
+		}
+	}
+	s.logins = kept
+}
@@ -0,0 +1,228 @@
+package memory
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"sort"
+	"time"
+)
+
+func (s *Storage) CreateWebhook(ctx context.Context, hook *models.Webhook) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	hook.ID = s.nextWebhookID
+	hook.CreatedAt = now
+	hook.UpdatedAt = now
+	s.nextWebhookID++
+	s.webhooks[hook.ID] = *hook
+	return nil
+}
+
+func (s *Storage) GetWebhook(ctx context.Context, id uint) (*models.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hook, ok := s.webhooks[id]
+	if !ok {
+		return nil, storage.ErrWebhookNotFound
+	}
+	return &hook, nil
+}
+
+func (s *Storage) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hooks := make([]models.Webhook, 0, len(s.webhooks))
+	for _, hook := range s.webhooks {
+		hooks = append(hooks, hook)
+	}
+	sort.Slice(hooks, func(i, j int) bool { return hooks[i].ID < hooks[j].ID })
+	return hooks, nil
+}
+
+func (s *Storage) UpdateWebhook(ctx context.Context, hook *models.Webhook) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.webhooks[hook.ID]
+	if !ok {
+		return storage.ErrWebhookNotFound
+	}
+	hook.CreatedAt = current.CreatedAt
+	hook.UpdatedAt = time.Now()
+	s.webhooks[hook.ID] = *hook
+	return nil
+}
+
+func (s *Storage) DeleteWebhook(ctx context.Context, id uint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.webhooks[id]; !ok {
+		return storage.ErrWebhookNotFound
+	}
+	delete(s.webhooks, id)
+	kept := s.deliveries[:0]
+	for _, d := range s.deliveries {
+		if d.WebhookID != id {
+			kept = append(kept, d)
+		}
+	}
+	s.deliveries = kept
+	return nil
+}
+
+func (s *Storage) EnqueueDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, d := range deliveries {
+		if s.enqueued(d.WebhookID, d.EventID) {
+			continue
+		}
+		d.ID = s.nextDeliveryID
+		d.CreatedAt = now
+		d.UpdatedAt = now
+		s.nextDeliveryID++
+		s.deliveries = append(s.deliveries, d)
+	}
+	return nil
+}
+
+func (s *Storage) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	token := storage.NewLease()
+	var claimed []models.WebhookDelivery
+	for i := range s.deliveries {
+		if len(claimed) == limit {
+			break
+		}
+		d := &s.deliveries[i]
+		if d.Status != models.DeliveryPending || d.NextAttemptAt.After(now) {
+			continue
+		}
+		d.Lease = token
+		d.NextAttemptAt = now.Add(lease)
+		claimed = append(claimed, *d)
+	}
+	return claimed, nil
+}
+
+func (s *Storage) GetDelivery(ctx context.Context, id uint) (*models.WebhookDelivery, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	d := s.delivery(id)
+	if d == nil {
+		return nil, storage.ErrDeliveryNotFound
+	}
+	delivery := *d
+	return &delivery, nil
+}
+
+func (s *Storage) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d := s.delivery(delivery.ID)
+	if d == nil {
+		return storage.ErrDeliveryNotFound
+	}
+	d.Status = delivery.Status
+	d.Attempts = delivery.Attempts
+	d.NextAttemptAt = delivery.NextAttemptAt
+	d.LastError = delivery.LastError
+	d.ResponseStatus = delivery.ResponseStatus
+	d.DeliveredAt = delivery.DeliveredAt
+	d.UpdatedAt = time.Now()
+	return nil
+}
+
+func (s *Storage) ListDeliveries(ctx context.Context, webhookID uint, status string, offset int, limit int) ([]models.WebhookDelivery, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deliveries := []models.WebhookDelivery{}
+	for i := len(s.deliveries) - 1; i >= 0; i-- {
+		d := s.deliveries[i]
+		if d.WebhookID == webhookID && (status == "" || d.Status == status) {
+			deliveries = append(deliveries, d)
+		}
+	}
+	if offset >= len(deliveries) {
+		return []models.WebhookDelivery{}, nil
+	}
+	deliveries = deliveries[offset:]
+	if limit >= 0 && limit < len(deliveries) {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// enqueued повторяет уникальный индекс (webhook_id, event_id), вызывается под s.mu.
+func (s *Storage) enqueued(webhookID uint, eventID string) bool {
+	for _, d := range s.deliveries {
+		if d.WebhookID == webhookID && d.EventID == eventID {
+			return true
+		}
+	}
+	return false
+}
+
+// delivery ищет доставку по id, вызывается под s.mu.
+func (s *Storage) delivery(id uint) *models.WebhookDelivery {
+	for i := range s.deliveries {
+		if s.deliveries[i].ID == id {
+			return &s.deliveries[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// EmailChange - запрос пользователя на смену email. Email меняется, когда пользователь
+// подтвердит новый адрес токеном из письма. У пользователя не больше одного запроса,
+// новый заменяет прежний.
+type EmailChange struct {
+	UserID   uint   `gorm:"primaryKey;autoIncrement:false"`
+	NewEmail string `gorm:"not null;serializer:encrypted"`
+	// TokenHash - SHA-256 токена подтверждения, сам токен есть только в письме
+	TokenHash string    `gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime:true"`
+}
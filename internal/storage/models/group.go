@@ -0,0 +1,51 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Group выдаёт роли и права всем своим участникам. Участники группы получают и всё,
+// что выдано родительской группе и её предкам.
+type Group struct {
+	ID          uint   `gorm:"primaryKey"`
+	Name        string `gorm:"unique;not null"`
+	Description string `gorm:"not null;default:''"`
+	// ParentID - группа, права которой наследует эта, nil у групп верхнего уровня
+	ParentID *uint `gorm:"index"`
+	// Roles и Permissions - через запятую
+	Roles       string    `gorm:"not null;default:''"`
+	Permissions string    `gorm:"not null;default:''"`
+	CreatedAt   time.Time `gorm:"autoCreateTime:true"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime:true"`
+}
+
+func (g *Group) RoleList() []string {
+	return splitList(g.Roles)
+}
+
+func (g *Group) SetRoles(roles []string) {
+	g.Roles = strings.Join(roles, ",")
+}
+
+func (g *Group) PermissionList() []string {
+	return splitList(g.Permissions)
+}
+
+func (g *Group) SetPermissions(perms []string) {
+	g.Permissions = strings.Join(perms, ",")
+}
+
+// GroupMember - пользователь, которого добавили в группу напрямую.
+type GroupMember struct {
+	GroupID   uint      `gorm:"primaryKey;autoIncrement:false"`
+	UserID    uint      `gorm:"primaryKey;autoIncrement:false;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime:true"`
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ",")
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Login - попытка входа, по ним считается статистика активных пользователей и
+// доля неудачных входов. UserID равен 0, если пользователя с таким username нет.
+type Login struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	Succeeded bool      `gorm:"not null"`
+	CreatedAt time.Time `gorm:"not null;index;autoCreateTime:true"`
+}
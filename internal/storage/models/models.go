@@ -2,20 +2,88 @@ package models
 
 import (
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+	"log/slog"
+	"strings"
 	"time"
 )
 
+const (
+	StatusActive    = "active"
+	StatusSuspended = "suspended"
+)
+
+const (
+	RoleAdmin = "admin"
+	// RoleSuperAdmin управляет организациями и видит пользователей всех организаций.
+	// Эту роль нельзя получить при регистрации или импорте, её выдаёт только другой superadmin.
+	RoleSuperAdmin = "superadmin"
+)
+
 type User struct {
 	ID           uint      `json:"id" gorm:"primaryKey"`
 	Username     string    `json:"username" validate:"required" gorm:"unique;not null"`
-	Password     string    `json:"password" validate:"required" gorm:"not null"`
-	Email        string    `json:"email" validate:"required,email" gorm:"unique;not null"`
-	Role         string    `json:"role" validate:"required,oneof=user creator combined admin" gorm:"default:'user'"`
-	Country      string    `json:"country" gorm:"not null"`
+	Password     string    `json:"-" validate:"required" gorm:"not null"`
+	Email        string    `json:"email" validate:"required,email" gorm:"unique;not null;serializer:encrypted"`
+	Role         string    `json:"role" validate:"required,oneof=user creator combined admin superadmin" gorm:"default:'user'"`
+	Country      string    `json:"country" gorm:"not null;serializer:encrypted"`
+	Status       string    `json:"status,omitempty" validate:"omitempty,oneof=active suspended" gorm:"not null;default:'active'"`
+	Verified     bool      `json:"verified" gorm:"not null;default:false"`
 	RefreshToken string    `json:"-"`
 	TokenExpiry  time.Time `json:"-"`
-	CreatedAt    time.Time `json:"createdAt,omitempty" gorm:"autoCreateTime:true"`
+	TokenVersion uint      `json:"-" gorm:"not null;default:0"`
+	Version      uint      `json:"version" gorm:"not null;default:1"` // растёт при каждом изменении, по ней строится ETag
+	CreatedAt    time.Time `json:"createdAt,omitempty" gorm:"autoCreateTime:true;index"`
 	UpdatedAt    time.Time `json:"updatedAt,omitempty" gorm:"autoUpdateTime:true"`
+	// мягкое удаление: такие пользователи не видны в выборках и не могут войти
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+	DeletedBy    *uint          `json:"-"`
+	DeleteReason string         `json:"-"`
+	// EmailIndex и CountryIndex - слепые индексы зашифрованных email и country,
+	// nil, пока шифрование не включено (см. fieldcrypt)
+	EmailIndex   *string `json:"-" gorm:"uniqueIndex"`
+	CountryIndex *string `json:"-" gorm:"index"`
+	// ErasedAt - когда персональные данные пользователя обезличены по его запросу
+	ErasedAt *time.Time `json:"-"`
+	// UsernameKey - username в виде для сравнения (NormalizeUsername), по нему username
+	// уникален без учёта регистра. nil у старых пользователей, чей username совпал
+	// с чужим без учёта регистра, такие входят только по точному username
+	UsernameKey *string `json:"-" gorm:"uniqueIndex"`
+	// UsernameChangedAt и EmailChangedAt - когда пользователь сам последний раз сменил
+	// username и email, от них отсчитывается пауза до следующей смены
+	UsernameChangedAt *time.Time `json:"-"`
+	EmailChangedAt    *time.Time `json:"-"`
+}
+
+// NormalizeUsername возвращает username в виде для сравнения: без пробелов по краям,
+// в NFKC и со сложенным регистром, так что "Alice" и "ＡＬＩＣＥ" совпадают.
+func NormalizeUsername(username string) string {
+	return cases.Fold().String(norm.NFKC.String(strings.TrimSpace(username)))
+}
+
+// NormalizeEmail возвращает email в виде, в котором он хранится: без пробелов по
+// краям и в нижнем регистре.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Normalize приводит email к нижнему регистру и пересчитывает UsernameKey, хранилища
+// вызывают его перед каждой записью пользователя.
+func (u *User) Normalize() {
+	u.Email = NormalizeEmail(u.Email)
+	key := NormalizeUsername(u.Username)
+	u.UsernameKey = &key
+}
+
+// LogValue не даёт попасть в логи хешу пароля и refresh token.
+func (u User) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Uint64("id", uint64(u.ID)),
+		slog.String("username", u.Username),
+		slog.String("role", u.Role),
+	)
 }
 
 func (u *User) HashPassword() error {
@@ -27,6 +95,13 @@ func (u *User) HashPassword() error {
 	return nil
 }
 
+// RevokesTokens сообщает, делает ли переход из prev в u недействительными уже выданные токены.
+func (u *User) RevokesTokens(prev *User) bool {
+	return u.Password != prev.Password ||
+		u.Role != prev.Role ||
+		(u.Status == StatusSuspended && prev.Status != StatusSuspended)
+}
+
 func (u *User) CheckPassword(password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
 }
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Organization - продукт, который пользуется сервисом: у него свои пользователи и
+// свои администраторы.
+type Organization struct {
+	ID        uint      `gorm:"primaryKey"`
+	Name      string    `gorm:"unique;not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime:true"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime:true"`
+}
+
+// Membership - участие пользователя в организации. Role действует только внутри
+// организации: она попадает в токены, выпущенные для этой организации, вместо
+// глобальной роли пользователя.
+type Membership struct {
+	OrgID     uint      `gorm:"primaryKey;autoIncrement:false"`
+	UserID    uint      `gorm:"primaryKey;autoIncrement:false;index"`
+	Role      string    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime:true"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime:true"`
+	// Organization заполняется только в ListUserMemberships
+	Organization *Organization `gorm:"foreignKey:OrgID"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// OutboxEvent - доменное событие, записанное в одной транзакции с изменением пользователя.
+// Relay публикует его позже, поэтому событие не теряется, даже если брокер недоступен.
+type OutboxEvent struct {
+	ID uint `gorm:"primaryKey"`
+	// Key - ключ дедупликации: событие может быть опубликовано больше одного раза
+	Key     string `gorm:"uniqueIndex;not null"`
+	Type    string `gorm:"not null"`
+	UserID  uint   `gorm:"not null;index"`
+	Payload string `gorm:"not null"`
+	// NextAttemptAt - когда событие можно забрать: сразу после записи, после
+	// аренды relay или после отката при ошибке публикации
+	NextAttemptAt time.Time `gorm:"not null;index"`
+	// Lease - метка relay, который забрал событие последним
+	Lease       string     `gorm:"not null;default:''"`
+	Attempts    int        `gorm:"not null;default:0"`
+	LastError   string     `gorm:"not null;default:''"`
+	PublishedAt *time.Time `gorm:"index"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime:true"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	ExportPending = "pending"
+	ExportReady   = "ready"
+	ExportFailed  = "failed"
+)
+
+const (
+	ErasurePending   = "pending"
+	ErasureRejected  = "rejected"
+	ErasureCompleted = "completed"
+)
+
+// ErasedFields - поля пользователя, которые обезличивает Anonymize.
+var ErasedFields = []string{"username", "email", "country", "password", "refreshToken", "deleteReason"}
+
+// DataExport - выгрузка данных пользователя по его запросу. Архив собирает
+// jobs/dataexport и хранит до ExpiresAt.
+type DataExport struct {
+	ID     uint `gorm:"primaryKey"`
+	UserID uint `gorm:"not null;index"`
+	// Format: json или zip
+	Format      string `gorm:"not null"`
+	Status      string `gorm:"not null;index"`
+	Archive     []byte
+	Error       string    `gorm:"not null;default:''"`
+	CreatedAt   time.Time `gorm:"autoCreateTime:true"`
+	CompletedAt *time.Time
+	ExpiresAt   *time.Time `gorm:"index"`
+}
+
+// ErasureRequest - запрос пользователя на удаление его персональных данных.
+// Данные обезличиваются только после одобрения администратором.
+type ErasureRequest struct {
+	ID     uint   `gorm:"primaryKey"`
+	UserID uint   `gorm:"not null;index"`
+	Reason string `gorm:"not null;default:''"`
+	Status string `gorm:"not null;index"`
+	// ReviewedBy - администратор, который одобрил или отклонил запрос
+	ReviewedBy *uint
+	ReviewNote string `gorm:"not null;default:''"`
+	ReviewedAt *time.Time
+	// ScrubbedEvents и ScrubbedDeliveries - сколько событий outbox и доставок
+	// webhooks очищено от персональных данных, для квитанции
+	ScrubbedEvents     int64 `gorm:"not null;default:0"`
+	ScrubbedDeliveries int64 `gorm:"not null;default:0"`
+	CompletedAt        *time.Time
+	CreatedAt          time.Time `gorm:"autoCreateTime:true"`
+}
+
+// Anonymize заменяет персональные данные пользователя обезличенными значениями. ID
+// остаётся прежним, поэтому ссылки на пользователя из событий и журналов не рвутся.
+// Уникальные username и email строятся из ID, пароль становится пустым, и войти под
+// пользователем больше нельзя.
+func (u *User) Anonymize(now time.Time) {
+	u.Username = fmt.Sprintf("erased-%d", u.ID)
+	u.Email = fmt.Sprintf("erased-%d@erased.invalid", u.ID)
+	u.Country = ""
+	u.Password = ""
+	u.RefreshToken = ""
+	u.TokenExpiry = time.Time{}
+	u.DeleteReason = ""
+	u.Status = StatusSuspended
+	u.ErasedAt = &now
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Типы дополнительных атрибутов профиля.
+const (
+	AttributeString  = "string"
+	AttributeNumber  = "number"
+	AttributeInteger = "integer"
+	AttributeBoolean = "boolean"
+	AttributeEnum    = "enum"
+)
+
+// Profile - то, что пользователь рассказывает о себе. Строки нет, пока профиль
+// ни разу не сохраняли.
+type Profile struct {
+	UserID      uint   `gorm:"primaryKey;autoIncrement:false"`
+	DisplayName string `gorm:"not null;default:''"`
+	AvatarURL   string `gorm:"not null;default:''"`
+	Bio         string `gorm:"not null;default:''"`
+	// Locale - тег BCP 47, TimeZone - зона из базы IANA
+	Locale   string `gorm:"not null;default:''"`
+	TimeZone string `gorm:"not null;default:''"`
+	// NativeLanguages - теги BCP 47 через запятую, LearningLanguages - пары "тег:уровень"
+	NativeLanguages   string `gorm:"not null;default:''"`
+	LearningLanguages string `gorm:"not null;default:''"`
+	// Attributes - значения атрибутов из AttributeDefinition, в postgres это jsonb
+	Attributes map[string]interface{} `gorm:"serializer:json;not null;default:'{}'"`
+	CreatedAt  time.Time              `gorm:"autoCreateTime:true"`
+	UpdatedAt  time.Time              `gorm:"autoUpdateTime:true"`
+}
+
+// LanguageSkill - изучаемый язык и уровень владения им по шкале CEFR.
+type LanguageSkill struct {
+	Language string `json:"language" validate:"required,bcp47_language_tag"`
+	Level    string `json:"level" validate:"required,oneof=A1 A2 B1 B2 C1 C2"`
+}
+
+func (p *Profile) NativeLanguageList() []string {
+	return splitList(p.NativeLanguages)
+}
+
+func (p *Profile) SetNativeLanguages(langs []string) {
+	p.NativeLanguages = strings.Join(langs, ",")
+}
+
+func (p *Profile) LearningLanguageList() []LanguageSkill {
+	items := splitList(p.LearningLanguages)
+	skills := make([]LanguageSkill, 0, len(items))
+	for _, item := range items {
+		lang, level, _ := strings.Cut(item, ":")
+		skills = append(skills, LanguageSkill{Language: lang, Level: level})
+	}
+	return skills
+}
+
+func (p *Profile) SetLearningLanguages(skills []LanguageSkill) {
+	items := make([]string, 0, len(skills))
+	for _, s := range skills {
+		items = append(items, s.Language+":"+s.Level)
+	}
+	p.LearningLanguages = strings.Join(items, ",")
+}
+
+// AttributeDefinition - атрибут профиля, который завёл администратор: его тип и
+// ограничения на значение.
+type AttributeDefinition struct {
+	Key         string `gorm:"primaryKey"`
+	Type        string `gorm:"not null"`
+	Description string `gorm:"not null;default:''"`
+	// Required - атрибут нельзя оставить без значения
+	Required bool `gorm:"not null;default:false"`
+	// UserEditable - значение меняет сам пользователь, иначе только администратор
+	UserEditable bool `gorm:"not null;default:false"`
+	// Enum - допустимые значения атрибута типа enum через запятую
+	Enum string `gorm:"not null;default:''"`
+	// MinLength, MaxLength и Pattern ограничивают строки, Minimum и Maximum - числа
+	MinLength *int
+	MaxLength *int
+	Minimum   *float64
+	Maximum   *float64
+	Pattern   string    `gorm:"not null;default:''"`
+	CreatedAt time.Time `gorm:"autoCreateTime:true"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime:true"`
+}
+
+func (d *AttributeDefinition) EnumList() []string {
+	return splitList(d.Enum)
+}
+
+func (d *AttributeDefinition) SetEnum(values []string) {
+	d.Enum = strings.Join(values, ",")
+}
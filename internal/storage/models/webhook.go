@@ -0,0 +1,70 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	// DeliveryDead - попытки кончились, доставку можно только повторить вручную
+	DeliveryDead = "dead"
+)
+
+// Webhook - адрес, на который отправляются события выбранных типов.
+type Webhook struct {
+	ID  uint   `gorm:"primaryKey"`
+	URL string `gorm:"not null"`
+	// Secret - ключ HMAC-подписи запросов, показывается только при создании
+	Secret string `gorm:"not null"`
+	// Events - типы событий через запятую
+	Events    string    `gorm:"not null"`
+	Active    bool      `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime:true"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime:true"`
+}
+
+// EventTypes возвращает типы событий, на которые подписан webhook.
+func (w *Webhook) EventTypes() []string {
+	if w.Events == "" {
+		return []string{}
+	}
+	return strings.Split(w.Events, ",")
+}
+
+func (w *Webhook) SetEventTypes(types []string) {
+	w.Events = strings.Join(types, ",")
+}
+
+// Subscribed сообщает, нужно ли отправлять на webhook события типа eventType.
+func (w *Webhook) Subscribed(eventType string) bool {
+	for _, t := range w.EventTypes() {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery - отправка одного события на один webhook и журнал её попыток.
+type WebhookDelivery struct {
+	ID        uint   `gorm:"primaryKey"`
+	WebhookID uint   `gorm:"not null;uniqueIndex:idx_webhook_deliveries_event"`
+	EventID   string `gorm:"not null;uniqueIndex:idx_webhook_deliveries_event"`
+	EventType string `gorm:"not null"`
+	// UserID - пользователь, о котором событие, по нему доставки очищаются при обезличивании
+	UserID uint `gorm:"not null;default:0;index"`
+	// Payload - тело запроса, одинаковое для всех попыток
+	Payload       string    `gorm:"not null"`
+	Status        string    `gorm:"not null;index"`
+	Attempts      int       `gorm:"not null"`
+	NextAttemptAt time.Time `gorm:"not null"`
+	Lease         string    `gorm:"not null"`
+	LastError     string    `gorm:"not null"`
+	// ResponseStatus - HTTP-статус последней попытки, 0 если ответа не было
+	ResponseStatus int `gorm:"not null"`
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime:true"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime:true"`
+}
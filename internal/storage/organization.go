@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"backend-app/internal/storage/models"
+	"context"
+	"errors"
+)
+
+var (
+	ErrOrgNotFound = errors.New("organization not found")
+	ErrOrgExists   = errors.New("organization already exists")
+	// ErrOrgNotEmpty - в организации остались участники, её нельзя удалить.
+	ErrOrgNotEmpty        = errors.New("organization has members")
+	ErrMembershipNotFound = errors.New("membership not found")
+)
+
+// OrganizationRepository хранит организации и участие в них пользователей.
+type OrganizationRepository interface {
+	// CreateOrganization возвращает ErrOrgExists, если имя уже занято.
+	CreateOrganization(ctx context.Context, org *models.Organization) error
+	GetOrganization(ctx context.Context, id uint) (*models.Organization, error)
+	ListOrganizations(ctx context.Context, offset int, limit int) ([]models.Organization, error)
+	// DeleteOrganization удаляет организацию без участников, иначе возвращает ErrOrgNotEmpty.
+	DeleteOrganization(ctx context.Context, id uint) error
+	// SetMembership добавляет пользователя в организацию или меняет его роль в ней.
+	// Смена роли увеличивает TokenVersion пользователя: токены со старой ролью отзываются.
+	SetMembership(ctx context.Context, m *models.Membership) error
+	// RemoveMembership исключает пользователя из организации и отзывает его токены.
+	RemoveMembership(ctx context.Context, orgID uint, userID uint) error
+	GetMembership(ctx context.Context, orgID uint, userID uint) (*models.Membership, error)
+	// ListMembers возвращает участников организации по возрастанию id пользователя.
+	ListMembers(ctx context.Context, orgID uint, offset int, limit int) ([]models.Membership, error)
+	// ListUserMemberships возвращает организации пользователя вместе с Organization.
+	ListUserMemberships(ctx context.Context, userID uint) ([]models.Membership, error)
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"backend-app/internal/storage/models"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Outbox хранит доменные события, которые хранилище записывает в одной транзакции
+// с изменением пользователя. Их публикует relay из internal/jobs/relay.
+type Outbox interface {
+	// AddEvents записывает события, которые не сопровождают изменение пользователя,
+	// например неудачный вход.
+	AddEvents(ctx context.Context, events []models.OutboxEvent) error
+	// ClaimEvents забирает до limit неопубликованных событий в порядке записи и на время
+	// lease скрывает их от других relay. Событие, не отмеченное за это время, будет
+	// забрано снова, поэтому доставка at-least-once.
+	ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error)
+	MarkPublished(ctx context.Context, id uint) error
+	// MarkFailed запоминает ошибку публикации и откладывает событие до retryAt.
+	MarkFailed(ctx context.Context, id uint, reason string, retryAt time.Time) error
+	// DeletePublishedEvents удаляет события, опубликованные раньше before.
+	DeletePublishedEvents(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Store - хранилище пользователей вместе с их outbox, webhooks, выгрузками, запросами
+// на удаление, организациями, группами, входами и запросами на смену email.
+type Store interface {
+	UserRepository
+	Outbox
+	WebhookRepository
+	PrivacyRepository
+	OrganizationRepository
+	GroupRepository
+	ProfileRepository
+	StatsRepository
+	AccountRepository
+}
+
+// NewLease возвращает случайную метку для ClaimEvents.
+func NewLease() string {
+	b := make([]byte, 16)
+	// crypto/rand.Read не возвращает ошибок
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"backend-app/internal/storage/models"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrUnsortableField - поле хранится зашифрованным, и база не может по нему сортировать.
+	ErrUnsortableField = errors.New("cannot sort by an encrypted field")
+)
+
+// UserSortFields - поля, по которым разрешено сортировать список пользователей.
+var UserSortFields = map[string]bool{
+	"id":         true,
+	"username":   true,
+	"email":      true,
+	"country":    true,
+	"created_at": true,
+}
+
+// cursorTimeLayout фиксированной длины, чтобы значения сравнивались как строки.
+const cursorTimeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+type UserFilter struct {
+	// OrgID оставляет только участников организации, 0 - всех пользователей
+	OrgID       uint
+	Role        string
+	Country     string
+	Status      string
+	Verified    *bool
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+}
+
+type UserQuery struct {
+	Filter    UserFilter
+	SortField string
+	SortDesc  bool
+	Cursor    *Cursor
+	Limit     int
+	WithTotal bool
+}
+
+type UserPage struct {
+	Users      []models.User
+	NextCursor string
+	PrevCursor string
+	Total      *int64
+}
+
+// Cursor указывает на пользователя, после (или до, если Backward) которого начинается страница.
+type Cursor struct {
+	SortField string `json:"f"`
+	SortDesc  bool   `json:"d,omitempty"`
+	Value     string `json:"v"`
+	ID        uint   `json:"id"`
+	Backward  bool   `json:"b,omitempty"`
+}
+
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || !UserSortFields[c.SortField] {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// Normalize проверяет поле сортировки и курсор и выставляет размер страницы по умолчанию.
+func (q *UserQuery) Normalize() error {
+	if q.SortField == "" {
+		q.SortField = "id"
+	}
+	if !UserSortFields[q.SortField] {
+		return errors.New("unsupported sort field: " + q.SortField)
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultPageSize
+	}
+	if q.Limit > MaxPageSize {
+		q.Limit = MaxPageSize
+	}
+	if q.Cursor != nil && (q.Cursor.SortField != q.SortField || q.Cursor.SortDesc != q.SortDesc) {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+// CursorFor строит курсор на пользователя u для текущей сортировки.
+func (q *UserQuery) CursorFor(u *models.User, backward bool) string {
+	return Cursor{
+		SortField: q.SortField,
+		SortDesc:  q.SortDesc,
+		Value:     SortValue(u, q.SortField),
+		ID:        u.ID,
+		Backward:  backward,
+	}.Encode()
+}
+
+// BuildUserPage принимает до q.Limit+1 пользователей в порядке обхода, обрезает лишнего,
+// разворачивает выборку при листании назад и проставляет курсоры.
+func BuildUserPage(page UserPage, q UserQuery, users []models.User) UserPage {
+	backward := q.Cursor != nil && q.Cursor.Backward
+	hasMore := len(users) > q.Limit
+	if hasMore {
+		users = users[:q.Limit]
+	}
+	if backward {
+		for i, j := 0, len(users)-1; i < j; i, j = i+1, j-1 {
+			users[i], users[j] = users[j], users[i]
+		}
+	}
+	page.Users = users
+	if len(users) == 0 {
+		return page
+	}
+
+	// назад мы пришли со следующей страницы, значит она точно есть
+	if hasMore || backward {
+		page.NextCursor = q.CursorFor(&users[len(users)-1], false)
+	}
+	if (backward && hasMore) || (!backward && q.Cursor != nil) {
+		page.PrevCursor = q.CursorFor(&users[0], true)
+	}
+	return page
+}
+
+// SortValue возвращает значение поля сортировки в виде строки для курсора.
+func SortValue(u *models.User, field string) string {
+	switch field {
+	case "username":
+		return u.Username
+	case "email":
+		return u.Email
+	case "country":
+		return u.Country
+	case "created_at":
+		return u.CreatedAt.UTC().Format(cursorTimeLayout)
+	default:
+		return strconv.FormatUint(uint64(u.ID), 10)
+	}
+}
+
+// ParseSortValue превращает значение из курсора обратно в тип колонки.
+func ParseSortValue(field string, value string) (interface{}, error) {
+	switch field {
+	case "created_at":
+		t, err := time.Parse(cursorTimeLayout, value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return t, nil
+	case "id":
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return uint(id), nil
+	default:
+		return value, nil
+	}
+}
+
+// Matches сообщает, подходит ли пользователь под фильтр. Нужен хранилищам без SQL.
+// OrgID не проверяется: участие в организациях хранится отдельно от пользователя.
+func (f UserFilter) Matches(u *models.User) bool {
+	switch {
+	case f.Role != "" && u.Role != f.Role:
+		return false
+	case f.Country != "" && u.Country != f.Country:
+		return false
+	case f.Status != "" && u.Status != f.Status:
+		return false
+	case f.Verified != nil && u.Verified != *f.Verified:
+		return false
+	case !f.CreatedFrom.IsZero() && u.CreatedAt.Before(f.CreatedFrom):
+		return false
+	case !f.CreatedTo.IsZero() && !u.CreatedAt.Before(f.CreatedTo):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"backend-app/internal/storage/models"
+	"time"
+)
+
+// UserPatch - частичное изменение пользователя, nil поля не меняются.
+// Password должен быть уже захеширован.
+type UserPatch struct {
+	Username *string
+	Email    *string
+	Password *string
+	Role     *string
+	Country  *string
+	Status   *string
+	Verified *bool
+	// UsernameChangedAt и EmailChangedAt задают смены, которые пользователь делает сам
+	UsernameChangedAt *time.Time
+	EmailChangedAt    *time.Time
+}
+
+// Apply применяет изменения к u и возвращает колонки, значения которых действительно поменялись.
+// Email приводится к нижнему регистру, вместе с username меняется UsernameKey.
+func (p UserPatch) Apply(u *models.User) map[string]interface{} {
+	changes := make(map[string]interface{})
+	setString := func(column string, dst *string, v *string) {
+		if v != nil && *v != *dst {
+			*dst = *v
+			changes[column] = *v
+		}
+	}
+	setString("username", &u.Username, p.Username)
+	if _, ok := changes["username"]; ok {
+		key := models.NormalizeUsername(u.Username)
+		u.UsernameKey = &key
+		changes["username_key"] = key
+	}
+	if p.Email != nil {
+		email := models.NormalizeEmail(*p.Email)
+		setString("email", &u.Email, &email)
+	}
+	setString("password", &u.Password, p.Password)
+	setString("role", &u.Role, p.Role)
+	setString("country", &u.Country, p.Country)
+	setString("status", &u.Status, p.Status)
+	if p.Verified != nil && *p.Verified != u.Verified {
+		u.Verified = *p.Verified
+		changes["verified"] = *p.Verified
+	}
+	if p.UsernameChangedAt != nil {
+		u.UsernameChangedAt = p.UsernameChangedAt
+		changes["username_changed_at"] = *p.UsernameChangedAt
+	}
+	if p.EmailChangedAt != nil {
+		u.EmailChangedAt = p.EmailChangedAt
+		changes["email_changed_at"] = *p.EmailChangedAt
+	}
+	return changes
+}
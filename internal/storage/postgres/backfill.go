@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"backend-app/internal/storage/models"
+	"context"
+	"database/sql"
+)
+
+// backfills - шаги миграций на Go по версии миграции.
+var backfills = map[int64]func(ctx context.Context, tx *sql.Tx) error{
+	14: backfillUsernameKeys,
+}
+
+// backfillUsernameKeys заполняет username_key через models.NormalizeUsername, как это
+// делает хранилище. Пользователь, чей ключ совпал с ключом более раннего, остаётся без
+// ключа, как и описано в миграции.
+func backfillUsernameKeys(ctx context.Context, tx *sql.Tx) error {
+	type user struct {
+		id       int64
+		username string
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT id, username FROM users ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	var users []user
+	for rows.Next() {
+		var u user
+		if err := rows.Scan(&u.id, &u.username); err != nil {
+			rows.Close()
+			return err
+		}
+		users = append(users, u)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `UPDATE users SET username_key = $1 WHERE id = $2`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	seen := make(map[string]bool, len(users))
+	for _, u := range users {
+		key := models.NormalizeUsername(u.username)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if _, err := stmt.ExecContext(ctx, key, u.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
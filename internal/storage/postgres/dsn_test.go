@@ -0,0 +1,69 @@
+package postgres_test
+
+import (
+	"backend-app/internal/config"
+	"backend-app/internal/storage/postgres"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDSN(t *testing.T) {
+	base := config.Database{
+		User:     "postgres",
+		Password: "postgres",
+		DBName:   "auth",
+		SSLMode:  "disable",
+		TimeZone: "UTC",
+	}
+
+	tests := []struct {
+		name string
+		cfg  func(cfg *config.Database)
+		host string
+		port int
+		want string
+	}{
+		{
+			name: "defaults",
+			cfg:  func(cfg *config.Database) {},
+			host: "localhost",
+			port: 5432,
+			want: "host=localhost port=5432 user=postgres password=postgres dbname=auth sslmode=disable TimeZone=UTC",
+		},
+		{
+			name: "password with quotes and spaces",
+			cfg:  func(cfg *config.Database) { cfg.Password = `it's a \secret` },
+			host: "localhost",
+			port: 5432,
+			want: `host=localhost port=5432 user=postgres password='it\'s a \\secret' dbname=auth sslmode=disable TimeZone=UTC`,
+		},
+		{
+			name: "empty password",
+			cfg:  func(cfg *config.Database) { cfg.Password = "" },
+			host: "localhost",
+			port: 5432,
+			want: "host=localhost port=5432 user=postgres password='' dbname=auth sslmode=disable TimeZone=UTC",
+		},
+		{
+			name: "replica with certificates",
+			cfg: func(cfg *config.Database) {
+				cfg.SSLMode = "verify-full"
+				cfg.SSLRootCert = "/etc/ssl/ca.pem"
+				cfg.SSLKey = "/etc/ssl/client.key"
+				cfg.TimeZone = "Europe/Moscow"
+			},
+			host: "replica-1",
+			port: 6432,
+			want: "host=replica-1 port=6432 user=postgres password=postgres dbname=auth sslmode=verify-full TimeZone=Europe/Moscow sslrootcert=/etc/ssl/ca.pem sslkey=/etc/ssl/client.key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := base
+			tt.cfg(&cfg)
+			assert.Equal(t, tt.want, postgres.DSN(cfg, tt.host, tt.port))
+		})
+	}
+}
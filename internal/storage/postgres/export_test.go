@@ -0,0 +1,7 @@
+package postgres
+
+// SearchQuery открывает запрос поиска для проверки плана в тестах.
+const SearchQuery = searchQuery
+
+// EncryptedSearchQuery открывает запрос поиска по зашифрованной базе.
+const EncryptedSearchQuery = encryptedSearchQuery
@@ -0,0 +1,86 @@
+package postgres_test
+
+import (
+	"backend-app/internal/storage/postgres"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := postgres.Migrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	for i, mig := range migrations {
+		assert.NotEmpty(t, mig.Up)
+		assert.NotEmpty(t, mig.Down)
+		if i > 0 {
+			assert.Greater(t, mig.Version, migrations[i-1].Version)
+		}
+		if mig.Name == "users_case_insensitive" {
+			assert.NotNil(t, mig.Backfill, "username keys are computed in Go")
+		}
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       fstest.MapFS
+		expectError bool
+		expected    []int64
+	}{
+		{
+			name: "sorted by version",
+			files: fstest.MapFS{
+				"0002_second.up.sql":   {Data: []byte("SELECT 2")},
+				"0002_second.down.sql": {Data: []byte("SELECT -2")},
+				"0001_first.up.sql":    {Data: []byte("SELECT 1")},
+				"0001_first.down.sql":  {Data: []byte("SELECT -1")},
+			},
+			expected: []int64{1, 2},
+		},
+		{
+			name: "missing down",
+			files: fstest.MapFS{
+				"0001_first.up.sql": {Data: []byte("SELECT 1")},
+			},
+			expectError: true,
+		},
+		{
+			name: "bad file name",
+			files: fstest.MapFS{
+				"first.sql": {Data: []byte("SELECT 1")},
+			},
+			expectError: true,
+		},
+		{
+			name: "name mismatch",
+			files: fstest.MapFS{
+				"0001_first.up.sql":   {Data: []byte("SELECT 1")},
+				"0001_other.down.sql": {Data: []byte("SELECT -1")},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := postgres.LoadMigrations(tt.files)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			versions := make([]int64, 0, len(migrations))
+			for _, mig := range migrations {
+				versions = append(versions, mig.Version)
+			}
+			assert.Equal(t, tt.expected, versions)
+		})
+	}
+}
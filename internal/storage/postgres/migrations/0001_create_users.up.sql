@@ -0,0 +1,21 @@
+-- Базы, созданные раньше через AutoMigrate, уже содержат таблицу users,
+-- поэтому миграция только дополняет недостающее.
+CREATE TABLE IF NOT EXISTS users (
+    id            BIGSERIAL PRIMARY KEY,
+    username      TEXT        NOT NULL,
+    password      TEXT        NOT NULL,
+    email         TEXT        NOT NULL,
+    role          TEXT                 DEFAULT 'user',
+    country       TEXT        NOT NULL,
+    status        TEXT        NOT NULL DEFAULT 'active',
+    refresh_token TEXT,
+    token_expiry  TIMESTAMPTZ,
+    token_version BIGINT      NOT NULL DEFAULT 0,
+    created_at    TIMESTAMPTZ,
+    updated_at    TIMESTAMPTZ,
+    CONSTRAINT uni_users_username UNIQUE (username),
+    CONSTRAINT uni_users_email UNIQUE (email)
+);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version BIGINT NOT NULL DEFAULT 0;
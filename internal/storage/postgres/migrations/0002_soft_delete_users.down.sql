@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_users_deleted_at;
+
+ALTER TABLE users DROP COLUMN delete_reason;
+ALTER TABLE users DROP COLUMN deleted_by;
+ALTER TABLE users DROP COLUMN deleted_at;
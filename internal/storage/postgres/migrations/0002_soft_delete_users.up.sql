@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN deleted_by BIGINT;
+ALTER TABLE users ADD COLUMN delete_reason TEXT;
+
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);
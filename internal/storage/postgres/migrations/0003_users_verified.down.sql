@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_users_role;
+DROP INDEX IF EXISTS idx_users_country_id;
+DROP INDEX IF EXISTS idx_users_created_at_id;
+
+ALTER TABLE users DROP COLUMN verified;
@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- индексы под фильтры и сортировки списка пользователей, id замыкает ключ для keyset пагинации
+CREATE INDEX idx_users_created_at_id ON users (created_at, id);
+CREATE INDEX idx_users_country_id ON users (country, id);
+CREATE INDEX idx_users_role ON users (role);
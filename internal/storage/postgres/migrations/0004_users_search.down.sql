@@ -0,0 +1,4 @@
+-- расширения не удаляем: ими могут пользоваться другие объекты базы
+DROP INDEX IF EXISTS idx_users_country_trgm;
+DROP INDEX IF EXISTS idx_users_email_trgm;
+DROP INDEX IF EXISTS idx_users_username_trgm;
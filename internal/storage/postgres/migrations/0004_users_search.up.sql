@@ -0,0 +1,6 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE EXTENSION IF NOT EXISTS fuzzystrmatch;
+
+CREATE INDEX idx_users_username_trgm ON users USING GIN (lower(username) gin_trgm_ops);
+CREATE INDEX idx_users_email_trgm ON users USING GIN (lower(email) gin_trgm_ops);
+CREATE INDEX idx_users_country_trgm ON users USING GIN (lower(country) gin_trgm_ops);
@@ -0,0 +1,2 @@
+-- версия строки для оптимистичных блокировок: UPDATE ... WHERE id = ? AND version = ?
+ALTER TABLE users ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
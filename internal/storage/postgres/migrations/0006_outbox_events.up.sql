@@ -0,0 +1,21 @@
+-- доменные события пишутся в одной транзакции с изменением пользователя,
+-- relay публикует их отдельно
+CREATE TABLE outbox_events (
+    id              BIGSERIAL PRIMARY KEY,
+    key             TEXT        NOT NULL,
+    type            TEXT        NOT NULL,
+    user_id         BIGINT      NOT NULL,
+    payload         JSONB       NOT NULL,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    lease           TEXT        NOT NULL DEFAULT '',
+    attempts        INTEGER     NOT NULL DEFAULT 0,
+    last_error      TEXT        NOT NULL DEFAULT '',
+    published_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT uni_outbox_events_key UNIQUE (key)
+);
+
+-- relay выбирает только неопубликованные события, их немного
+CREATE INDEX idx_outbox_events_pending ON outbox_events (next_attempt_at, id) WHERE published_at IS NULL;
+CREATE INDEX idx_outbox_events_published_at ON outbox_events (published_at) WHERE published_at IS NOT NULL;
+CREATE INDEX idx_outbox_events_user_id ON outbox_events (user_id);
@@ -0,0 +1,31 @@
+CREATE TABLE webhooks (
+    id         BIGSERIAL PRIMARY KEY,
+    url        TEXT        NOT NULL,
+    secret     TEXT        NOT NULL,
+    events     TEXT        NOT NULL,
+    active     BOOLEAN     NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE webhook_deliveries (
+    id              BIGSERIAL PRIMARY KEY,
+    webhook_id      BIGINT      NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id        TEXT        NOT NULL,
+    event_type      TEXT        NOT NULL,
+    payload         JSONB       NOT NULL,
+    status          TEXT        NOT NULL DEFAULT 'pending',
+    attempts        INTEGER     NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    lease           TEXT        NOT NULL DEFAULT '',
+    last_error      TEXT        NOT NULL DEFAULT '',
+    response_status INTEGER     NOT NULL DEFAULT 0,
+    delivered_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- relay может опубликовать событие повторно, в очередь webhook оно попадает один раз
+CREATE UNIQUE INDEX idx_webhook_deliveries_event ON webhook_deliveries (webhook_id, event_id);
+CREATE INDEX idx_webhook_deliveries_pending ON webhook_deliveries (next_attempt_at, id) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_status ON webhook_deliveries (status);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS erasure_requests;
+DROP TABLE IF EXISTS data_exports;
+DROP INDEX IF EXISTS idx_webhook_deliveries_user_id;
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS user_id;
+ALTER TABLE users DROP COLUMN IF EXISTS erased_at;
@@ -0,0 +1,42 @@
+ALTER TABLE users ADD COLUMN erased_at TIMESTAMPTZ;
+
+-- по user_id доставки находятся при обезличивании пользователя
+ALTER TABLE webhook_deliveries ADD COLUMN user_id BIGINT NOT NULL DEFAULT 0;
+UPDATE webhook_deliveries SET user_id = COALESCE((payload ->> 'userId')::BIGINT, 0);
+CREATE INDEX idx_webhook_deliveries_user_id ON webhook_deliveries (user_id);
+
+CREATE TABLE data_exports (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT      NOT NULL,
+    format       TEXT        NOT NULL,
+    status       TEXT        NOT NULL,
+    archive      BYTEA,
+    error        TEXT        NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at TIMESTAMPTZ,
+    expires_at   TIMESTAMPTZ
+);
+
+CREATE INDEX idx_data_exports_user_id ON data_exports (user_id);
+CREATE INDEX idx_data_exports_status ON data_exports (status);
+CREATE INDEX idx_data_exports_expires_at ON data_exports (expires_at);
+
+-- запросы не удаляются: после обезличивания они остаются квитанцией
+CREATE TABLE erasure_requests (
+    id                  BIGSERIAL PRIMARY KEY,
+    user_id             BIGINT      NOT NULL,
+    reason              TEXT        NOT NULL DEFAULT '',
+    status              TEXT        NOT NULL,
+    reviewed_by         BIGINT,
+    review_note         TEXT        NOT NULL DEFAULT '',
+    reviewed_at         TIMESTAMPTZ,
+    scrubbed_events     BIGINT      NOT NULL DEFAULT 0,
+    scrubbed_deliveries BIGINT      NOT NULL DEFAULT 0,
+    completed_at        TIMESTAMPTZ,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_erasure_requests_user_id ON erasure_requests (user_id);
+CREATE INDEX idx_erasure_requests_status ON erasure_requests (status);
+-- у пользователя может быть только один запрос на рассмотрении
+CREATE UNIQUE INDEX idx_erasure_requests_pending ON erasure_requests (user_id) WHERE status = 'pending';
@@ -0,0 +1,6 @@
+-- Откат не расшифровывает email и country, зашифрованные строки останутся зашифрованными.
+DROP INDEX IF EXISTS idx_users_country_index;
+DROP INDEX IF EXISTS idx_users_email_index;
+
+ALTER TABLE users DROP COLUMN IF EXISTS country_index;
+ALTER TABLE users DROP COLUMN IF EXISTS email_index;
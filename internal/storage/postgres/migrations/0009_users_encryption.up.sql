@@ -0,0 +1,7 @@
+-- Слепые индексы email и country, заполняются, когда включено шифрование этих полей.
+-- Пока они NULL, уникальность email обеспечивает uni_users_email.
+ALTER TABLE users ADD COLUMN email_index TEXT;
+ALTER TABLE users ADD COLUMN country_index TEXT;
+
+CREATE UNIQUE INDEX idx_users_email_index ON users (email_index);
+CREATE INDEX idx_users_country_index ON users (country_index);
@@ -0,0 +1,19 @@
+CREATE TABLE organizations (
+    id         BIGSERIAL PRIMARY KEY,
+    name       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT uni_organizations_name UNIQUE (name)
+);
+
+-- организацию с участниками удалить нельзя, участие удаляется вместе с пользователем
+CREATE TABLE memberships (
+    org_id     BIGINT      NOT NULL REFERENCES organizations (id),
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (org_id, user_id)
+);
+
+CREATE INDEX idx_memberships_user_id ON memberships (user_id);
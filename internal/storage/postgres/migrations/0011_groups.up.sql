@@ -0,0 +1,23 @@
+-- группу, от которой наследуют другие, удалить нельзя
+CREATE TABLE groups (
+    id          BIGSERIAL PRIMARY KEY,
+    name        TEXT        NOT NULL,
+    description TEXT        NOT NULL DEFAULT '',
+    parent_id   BIGINT      REFERENCES groups (id),
+    roles       TEXT        NOT NULL DEFAULT '',
+    permissions TEXT        NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT uni_groups_name UNIQUE (name)
+);
+
+CREATE INDEX idx_groups_parent_id ON groups (parent_id);
+
+CREATE TABLE group_members (
+    group_id   BIGINT      NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX idx_group_members_user_id ON group_members (user_id);
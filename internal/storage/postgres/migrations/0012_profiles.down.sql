@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS attribute_definitions;
+DROP TABLE IF EXISTS profiles;
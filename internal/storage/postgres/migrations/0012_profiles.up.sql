@@ -0,0 +1,29 @@
+CREATE TABLE profiles (
+    user_id            BIGINT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    display_name       TEXT        NOT NULL DEFAULT '',
+    avatar_url         TEXT        NOT NULL DEFAULT '',
+    bio                TEXT        NOT NULL DEFAULT '',
+    locale             TEXT        NOT NULL DEFAULT '',
+    time_zone          TEXT        NOT NULL DEFAULT '',
+    native_languages   TEXT        NOT NULL DEFAULT '',
+    learning_languages TEXT        NOT NULL DEFAULT '',
+    attributes         JSONB       NOT NULL DEFAULT '{}',
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE attribute_definitions (
+    key           TEXT PRIMARY KEY,
+    type          TEXT             NOT NULL,
+    description   TEXT             NOT NULL DEFAULT '',
+    required      BOOLEAN          NOT NULL DEFAULT false,
+    user_editable BOOLEAN          NOT NULL DEFAULT false,
+    enum          TEXT             NOT NULL DEFAULT '',
+    min_length    INTEGER,
+    max_length    INTEGER,
+    minimum       DOUBLE PRECISION,
+    maximum       DOUBLE PRECISION,
+    pattern       TEXT             NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ      NOT NULL DEFAULT now(),
+    updated_at    TIMESTAMPTZ      NOT NULL DEFAULT now()
+);
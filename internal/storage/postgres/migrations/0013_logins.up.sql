@@ -0,0 +1,13 @@
+-- входы для статистики администратора, user_id = 0 - вход с неизвестным username
+CREATE TABLE logins (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT      NOT NULL,
+    succeeded  BOOLEAN     NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_logins_user_id ON logins (user_id);
+CREATE INDEX idx_logins_created_at ON logins (created_at);
+
+-- регистрации считаются по дате создания пользователя
+CREATE INDEX idx_users_created_at ON users (created_at);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS email_changes;
+DROP INDEX IF EXISTS idx_users_username_key;
+ALTER TABLE users DROP COLUMN IF EXISTS email_changed_at;
+ALTER TABLE users DROP COLUMN IF EXISTS username_changed_at;
+ALTER TABLE users DROP COLUMN IF EXISTS username_key;
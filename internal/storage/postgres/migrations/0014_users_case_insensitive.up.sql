@@ -0,0 +1,26 @@
+-- username уникален без учёта регистра по username_key, email хранится в нижнем
+-- регистре. Старые пользователи, чьи username или email совпадают с более ранними без
+-- учёта регистра, остаются как были: username_key у них пуст, и входят они по точному
+-- username. username_key заполняет backfillUsernameKeys после этого скрипта: lower()
+-- не совпадает с models.NormalizeUsername. Индексы зашифрованных email пересчитывает
+-- команда rotate-keys.
+ALTER TABLE users ADD COLUMN username_key TEXT;
+ALTER TABLE users ADD COLUMN username_changed_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN email_changed_at TIMESTAMPTZ;
+
+UPDATE users u SET email = lower(u.email)
+WHERE u.email <> lower(u.email) AND u.email_index IS NULL
+AND NOT EXISTS (SELECT 1 FROM users o WHERE lower(o.email) = lower(u.email) AND o.id <> u.id);
+
+CREATE UNIQUE INDEX idx_users_username_key ON users (username_key);
+
+-- запрос на смену email ждёт подтверждения нового адреса
+CREATE TABLE email_changes (
+    user_id    BIGINT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    new_email  TEXT        NOT NULL,
+    token_hash TEXT        NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX idx_email_changes_token_hash ON email_changes (token_hash);
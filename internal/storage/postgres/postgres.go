@@ -2,73 +2,118 @@ package postgres
 
 import (
 	"backend-app/internal/config"
-	"backend-app/internal/storage/models"
-
+	"backend-app/internal/storage/gormstore"
+	"context"
+	"database/sql"
 	"fmt"
+	"strings"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 type Storage struct {
-	DB *gorm.DB
+	gormstore.Storage
 }
 
-func New(cfg *config.Config) (Storage, error) {
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%d sslmode=disable TimeZone=Asia/Shanghai",
-		cfg.Database.Host, cfg.Database.User, cfg.Database.Password, cfg.Database.DBName, cfg.Database.Port,
-	)
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
-
+// New подключается к базе и отказывается работать, если схема отстаёт от миграций.
+func New(cfg *config.Config) (*Storage, error) {
+	s, err := Open(cfg)
 	if err != nil {
-		return Storage{}, err
+		return nil, err
 	}
-	db.AutoMigrate(models.User{})
-	return Storage{DB: db}, nil
-}
 
-func (s *Storage) CreateUser(user *models.User) error {
-	if err := s.DB.Create(user).Error; err != nil {
-		return err
+	migrator, err := s.Migrator()
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	pending, err := migrator.Pending(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) > 0 {
+		return nil, fmt.Errorf("%w: %d pending migrations, run `migrate up`", ErrSchemaOutdated, len(pending))
+	}
+	return s, nil
 }
 
-func (s *Storage) GetUserByID(id uint) (*models.User, error) {
-	var user models.User
-	if err := s.DB.First(&user, id).Error; err != nil {
+// Open подключается к primary и репликам без проверки схемы, нужен для команды migrate.
+func Open(cfg *config.Config) (*Storage, error) {
+	db, err := open(cfg.Database, cfg.Database.Host, cfg.Database.Port)
+	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+
+	s := &Storage{gormstore.Storage{DB: db}}
+	for _, replica := range cfg.Database.Replicas {
+		port := replica.Port
+		if port == 0 {
+			port = cfg.Database.Port
+		}
+		rdb, err := open(cfg.Database, replica.Host, port)
+		if err != nil {
+			return nil, fmt.Errorf("replica %s: %w", replica.Host, err)
+		}
+		s.Replicas = append(s.Replicas, rdb)
+	}
+	return s, nil
 }
 
-func (s *Storage) GetUserByUsername(username string) (*models.User, error) {
-	var user models.User
-	if err := s.DB.Where("username = ?", username).First(&user).Error; err != nil {
+func open(cfg config.Database, host string, port int) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(DSN(cfg, host, port)), gormstore.Config())
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+	configurePool(sqlDB, cfg.Pool)
+	return db, nil
 }
 
-func (s *Storage) UpdateUser(user *models.User) error {
-	if err := s.DB.Save(user).Error; err != nil {
-		return err
+// DSN собирает строку подключения libpq к host:port с параметрами из cfg.
+func DSN(cfg config.Database, host string, port int) string {
+	params := []string{
+		"host=" + quote(host),
+		fmt.Sprintf("port=%d", port),
+		"user=" + quote(cfg.User),
+		"password=" + quote(cfg.Password),
+		"dbname=" + quote(cfg.DBName),
+		"sslmode=" + quote(cfg.SSLMode),
+		"TimeZone=" + quote(cfg.TimeZone),
+	}
+	for _, p := range [][2]string{
+		{"sslrootcert", cfg.SSLRootCert},
+		{"sslcert", cfg.SSLCert},
+		{"sslkey", cfg.SSLKey},
+	} {
+		if p[1] != "" {
+			params = append(params, p[0]+"="+quote(p[1]))
+		}
 	}
-	return nil
+	return strings.Join(params, " ")
 }
 
-func (s *Storage) DeleteUser(id uint) error {
-	if err := s.DB.Delete(&models.User{}, id).Error; err != nil {
-		return err
+// quote экранирует значение по правилам libpq, чтобы пароль с пробелами или кавычками не ломал DSN.
+func quote(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
 	}
-	return nil
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
 }
 
-func (s *Storage) GetAllUsers(offset int, limit int) ([]models.User, error) {
-	var users []models.User
-	if err := s.DB.Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+func configurePool(db *sql.DB, pool config.Pool) {
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+}
+
+func (s *Storage) Migrator() (*Migrator, error) {
+	sqlDB, err := s.DB.DB()
+	if err != nil {
 		return nil, err
 	}
-	return users, nil
+	return NewMigrator(sqlDB)
 }
@@ -1,149 +1,97 @@
 package postgres_test
 
 import (
-	"backend-app/internal/storage/models"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/gormstore"
 	"backend-app/internal/storage/postgres"
-	"fmt"
+	"backend-app/internal/storage/storagetest"
+	"context"
+	"database/sql"
+	"os"
+	"strings"
 	"testing"
 
 	driver "gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-func setupTestDB() (*postgres.Storage, error) {
-	dsn := "host=localhost user=postgres password=postgres dbname=authdb port=5432 sslmode=disable TimeZone=Europe/Moscow"
-	db, err := gorm.Open(driver.Open(dsn), &gorm.Config{})
-	if err != nil {
-		return nil, err
-	}
-
-	db.AutoMigrate(&models.User{})
-
-	return &postgres.Storage{DB: db}, nil
-}
-
-func TestCreateUser(t *testing.T) {
-	storage, err := setupTestDB()
-	if err != nil {
-		t.Fatalf("Fa`iled to set up test DB: %v", err)
-	}
-
-	user := &models.User{
-		Username: "tes3tusedr33333346576",
-		Password: "password1233",
-		Email:    "testu3ser3333@example.com",
-		Country:  "Testland",
-	}
-
-	err = storage.CreateUser(user)
-	if err != nil {
-		t.Errorf("Failed to create user: %v", err)
-	}
-
-	if user.ID == 0 {
-		t.Errorf("Expected user ID to be set, got 0")
+// Тесты очищают таблицу users, поэтому нужна отдельная база, например
+// TEST_POSTGRES_DSN="host=localhost user=postgres password=postgres dbname=authdb_test port=5432 sslmode=disable"
+func setupTestDB(t *testing.T) *postgres.Storage {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
 	}
-}
 
-func TestGetUserByID(t *testing.T) {
-	storage, err := setupTestDB()
+	db, err := gorm.Open(driver.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		t.Fatalf("Failed to set up test DB: %v", err)
 	}
+	s := &postgres.Storage{Storage: gormstore.Storage{DB: db}}
 
-	user := &models.User{
-		Username: "testuser56565365",
-		Password: "password123",
-		Email:    "testus33er@example.com",
-		Country:  "Testland",
-	}
-
-	storage.CreateUser(user)
-
-	retrievedUser, err := storage.GetUserByID(user.ID)
+	migrator, err := s.Migrator()
 	if err != nil {
-		t.Errorf("Failed to get user by ID: %v", err)
-	}
-
-	if retrievedUser.Username != user.Username {
-		t.Errorf("Expected username %s, got %s", user.Username, retrievedUser.Username)
+		t.Fatalf("Failed to load migrations: %v", err)
 	}
-}
-
-func TestUpdateUser(t *testing.T) {
-	storage, err := setupTestDB()
-	if err != nil {
-		t.Fatalf("Failed to set up test DB: %v", err)
-	}
-
-	user := &models.User{
-		Username: "testuse3r66767676",
-		Password: "password123",
-		Email:    "tes3tuse4r@example.com",
-		Country:  "Testland",
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("Failed to migrate test DB: %v", err)
 	}
-
-	storage.CreateUser(user)
-
-	user.Username = "updateduser"
-	err = storage.UpdateUser(user)
-	if err != nil {
-		t.Errorf("Failed to update user: %v", err)
+	if err := db.Exec("TRUNCATE users, outbox_events, webhooks, webhook_deliveries, data_exports, erasure_requests RESTART IDENTITY").Error; err != nil {
+		t.Fatalf("Failed to clean test DB: %v", err)
 	}
 
-	updatedUser, _ := storage.GetUserByID(user.ID)
-	if updatedUser.Username != "updateduser" {
-		t.Errorf("Expected username to be updated to 'updateduser', got %s", updatedUser.Username)
-	}
+	return s
 }
 
-func TestDeleteUser(t *testing.T) {
-	storage, err := setupTestDB()
-	if err != nil {
-		t.Fatalf("Failed to set up test DB: %v", err)
-	}
-
-	user := &models.User{
-		Username: "test43user54353453",
-		Password: "password123",
-		Email:    "testu34ser@example.com",
-		Country:  "Testland",
-	}
-
-	storage.CreateUser(user)
-
-	err = storage.DeleteUser(user.ID)
-	if err != nil {
-		t.Errorf("Failed to delete user: %v", err)
-	}
-
-	_, err = storage.GetUserByID(user.ID)
-	if err == nil {
-		t.Errorf("Expected error when retrieving deleted user, got nil")
-	}
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Store {
+		return setupTestDB(t)
+	})
 }
 
-func TestGetAllUsers(t *testing.T) {
-	storage, err := setupTestDB()
-	if err != nil {
-		t.Fatalf("Failed to set up test DB: %v", err)
-	}
-
-	users := []models.User{
-		{Username: "user1", Password: "pass1", Email: "user1@example.com", Country: "Country1"},
-		{Username: "user2", Password: "pass2", Email: "user2@example.com", Country: "Country2"},
-	}
-
-	for _, user := range users {
-		storage.CreateUser(&user)
-	}
-
-	retrievedUsers, err := storage.GetAllUsers()
-	if err != nil {
-		t.Errorf("Failed to get all users: %v", err)
-	}
-
-	if len(retrievedUsers) != len(users) {
-		fmt.Printf("Expected %d users, got %d", len(users), len(retrievedUsers))
+func TestSearchQueryUsesIndexes(t *testing.T) {
+	s := setupTestDB(t)
+
+	tests := []struct {
+		name    string
+		query   string
+		indexes []string
+	}{
+		{"plain", postgres.SearchQuery, []string{"idx_users_username_trgm", "idx_users_email_trgm", "idx_users_country_trgm"}},
+		{"encrypted", postgres.EncryptedSearchQuery, []string{"idx_users_username_trgm", "idx_users_email_index", "idx_users_country_index"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var plan []string
+			err := s.DB.Transaction(func(tx *gorm.DB) error {
+				// на пустой таблице seq scan дешевле любого индекса
+				if err := tx.Exec("SET LOCAL enable_seqscan = off").Error; err != nil {
+					return err
+				}
+				return tx.Raw("EXPLAIN "+tt.query,
+					sql.Named("pattern", "%alice%"),
+					sql.Named("q", "alice"),
+					sql.Named("org", 0),
+					sql.Named("len", 5),
+					sql.Named("typos", 2),
+					sql.Named("limit", 20),
+					sql.Named("emails", []string{"email-index"}),
+					sql.Named("countries", []string{"country-index"}),
+				).Scan(&plan).Error
+			})
+			if err != nil {
+				t.Fatalf("Failed to explain search query: %v", err)
+			}
+
+			text := strings.Join(plan, "\n")
+			if strings.Contains(text, "Seq Scan on users") {
+				t.Errorf("search query scans the whole users table:\n%s", text)
+			}
+			for _, index := range tt.indexes {
+				if !strings.Contains(text, index) {
+					t.Errorf("plan does not use %s:\n%s", index, text)
+				}
+			}
+		})
 	}
 }
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"backend-app/internal/country"
+	"backend-app/internal/search"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/gormstore"
+	"backend-app/internal/storage/models"
+	"context"
+	"database/sql"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// candidatesPerResult - во сколько раз больше кандидатов берём из базы, чем нужно
+// результатов: окончательный порядок считается в search так же, как в других хранилищах.
+const candidatesPerResult = 5
+
+// candidateThreshold - порог pg_trgm.similarity_threshold для отбора кандидатов. Он ниже
+// порога по умолчанию (0.3), чтобы в кандидаты попадали короткие значения с опечаткой:
+// у "alcie" и "alice" сходство 0.2.
+const candidateThreshold = "0.15"
+
+// searchQuery отбирает кандидатов только условиями, для которых подходят триграммные
+// индексы (LIKE и %): с levenshtein в том же OR postgres читал бы всю таблицу. Расстояние
+// Левенштейна до начала значения поднимает опечатки в коротких запросах при сортировке.
+const searchQuery = `
+WITH candidates AS (
+	SELECT id FROM users
+	WHERE lower(username) LIKE @pattern OR lower(username) % @q
+	OR lower(email) LIKE @pattern OR lower(email) % @q
+	OR lower(country) LIKE @pattern OR lower(country) % @q
+)
+SELECT users.* FROM users JOIN candidates ON candidates.id = users.id
+WHERE users.deleted_at IS NULL
+AND (@org = 0 OR EXISTS (SELECT 1 FROM memberships m WHERE m.user_id = users.id AND m.org_id = @org))
+ORDER BY GREATEST(
+	similarity(lower(username), @q),
+	similarity(lower(email), @q),
+	similarity(lower(country), @q),
+	CASE WHEN levenshtein(left(lower(username), @len), @q) <= @typos
+		OR levenshtein(left(lower(email), @len), @q) <= @typos THEN 1 ELSE 0 END
+) DESC, users.id
+LIMIT @limit`
+
+// encryptedSearchQuery - searchQuery для базы с зашифрованными email и country. По шифртексту
+// нечёткий поиск невозможен, а расшифровка всей таблицы слишком дорога, поэтому нечётко
+// ищется только username, а email и country - по слепому индексу точного значения.
+const encryptedSearchQuery = `
+WITH candidates AS (
+	SELECT id FROM users
+	WHERE lower(username) LIKE @pattern OR lower(username) % @q
+	OR email_index IN @emails OR country_index IN @countries
+)
+SELECT users.* FROM users JOIN candidates ON candidates.id = users.id
+WHERE users.deleted_at IS NULL
+AND (@org = 0 OR EXISTS (SELECT 1 FROM memberships m WHERE m.user_id = users.id AND m.org_id = @org))
+ORDER BY GREATEST(
+	similarity(lower(username), @q),
+	CASE WHEN email_index IN @emails OR country_index IN @countries
+		OR levenshtein(left(lower(username), @len), @q) <= @typos THEN 1 ELSE 0 END
+) DESC, users.id
+LIMIT @limit`
+
+func (s *Storage) SearchUsers(ctx context.Context, query string, orgID uint, limit int) ([]storage.UserMatch, error) {
+	query = search.Normalize(query)
+	if query == "" {
+		return nil, nil
+	}
+
+	n := utf8.RuneCountInString(query)
+	sqlQuery, args := searchQuery, []any{
+		sql.Named("pattern", "%"+escapeLike(query)+"%"),
+		sql.Named("q", query),
+		sql.Named("org", orgID),
+		sql.Named("len", n),
+		// levenshtein из fuzzystrmatch считает перестановку за две правки, search - за одну
+		sql.Named("typos", search.TypoBudget(n)+1),
+		sql.Named("limit", limit*candidatesPerResult),
+	}
+	if s.Keyring != nil {
+		emails := []string{s.Keyring.BlindIndex("email", models.NormalizeEmail(query))}
+		// пустой IN не совпадает ни с чем, а индекс пустой страны - у всех пользователей без неё
+		countries := []string{}
+		if c, ok := country.Lookup(query); ok {
+			countries = append(countries, s.Keyring.BlindIndex("country", c.Alpha2))
+		}
+		sqlQuery = encryptedSearchQuery
+		args = append(args, sql.Named("emails", emails), sql.Named("countries", countries))
+	}
+
+	var users []models.User
+	err := s.Read(ctx, func(db *gorm.DB) error {
+		// set_config с is_local действует до конца транзакции
+		return db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("SELECT set_config('pg_trgm.similarity_threshold', ?, true)", candidateThreshold).Error; err != nil {
+				return err
+			}
+			return tx.Raw(sqlQuery, args...).Scan(&users).Error
+		})
+	})
+	if err != nil {
+		return nil, gormstore.Translate(ctx, err)
+	}
+
+	matches := make([]storage.UserMatch, 0, len(users))
+	for i := range users {
+		if m, ok := storage.MatchUser(query, &users[i]); ok {
+			matches = append(matches, m)
+		}
+	}
+	return storage.RankMatches(matches, limit), nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"backend-app/internal/storage/models"
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrDataExportNotFound     = errors.New("data export not found")
+	ErrErasureRequestNotFound = errors.New("erasure request not found")
+	// ErrErasurePending - у пользователя уже есть запрос на удаление, ждущий рассмотрения.
+	ErrErasurePending = errors.New("erasure request is already pending")
+	// ErrErasureNotPending - запрос уже одобрен или отклонён.
+	ErrErasureNotPending = errors.New("erasure request is not pending")
+)
+
+// PrivacyRepository хранит выгрузки данных пользователей и запросы на их удаление.
+type PrivacyRepository interface {
+	CreateDataExport(ctx context.Context, export *models.DataExport) error
+	GetDataExport(ctx context.Context, id uint) (*models.DataExport, error)
+	// ListDataExports возвращает выгрузки пользователя без архивов, новые первыми.
+	ListDataExports(ctx context.Context, userID uint) ([]models.DataExport, error)
+	// PendingDataExports возвращает до limit ожидающих выгрузок, старые первыми.
+	PendingDataExports(ctx context.Context, limit int) ([]models.DataExport, error)
+	// CompleteDataExport сохраняет статус, архив или ошибку и срок хранения выгрузки.
+	CompleteDataExport(ctx context.Context, export *models.DataExport) error
+	// DeleteExpiredDataExports удаляет выгрузки, срок хранения которых истёк до before.
+	DeleteExpiredDataExports(ctx context.Context, before time.Time) (int64, error)
+	// ListUserEvents возвращает ещё хранящиеся события outbox о пользователе в порядке записи.
+	ListUserEvents(ctx context.Context, userID uint) ([]models.OutboxEvent, error)
+
+	// CreateErasureRequest возвращает ErrErasurePending, если у пользователя уже
+	// есть запрос на рассмотрении.
+	CreateErasureRequest(ctx context.Context, req *models.ErasureRequest) error
+	GetErasureRequest(ctx context.Context, id uint) (*models.ErasureRequest, error)
+	// ListErasureRequests возвращает запросы, новые первыми. Пустой status - все статусы.
+	ListErasureRequests(ctx context.Context, status string, offset int, limit int) ([]models.ErasureRequest, error)
+	// RejectErasureRequest отклоняет запрос на рассмотрении.
+	RejectErasureRequest(ctx context.Context, id uint, reviewedBy uint, note string) (*models.ErasureRequest, error)
+	// EraseUser одобряет запрос и в одной транзакции обезличивает пользователя
+	// (models.User.Anonymize), очищает от его данных события outbox и доставки webhooks,
+	// удаляет его профиль, входы, запрос на смену email и выгрузки и пишет событие
+	// user.erased. Возвращает завершённый запрос.
+	EraseUser(ctx context.Context, requestID uint, reviewedBy uint, note string) (*models.ErasureRequest, error)
+}
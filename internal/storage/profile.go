@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"backend-app/internal/storage/models"
+	"context"
+	"errors"
+)
+
+var (
+	ErrAttributeNotFound = errors.New("profile attribute not found")
+	ErrAttributeExists   = errors.New("profile attribute already exists")
+)
+
+// ProfileRepository хранит профили пользователей и схему их дополнительных атрибутов.
+// Значения атрибутов проверяет пакет profile, хранилище сохраняет их как есть.
+type ProfileRepository interface {
+	// GetProfile возвращает пустой профиль, если пользователь его ещё не заполнял, и
+	// ErrUserNotFound, если пользователя нет.
+	GetProfile(ctx context.Context, userID uint) (*models.Profile, error)
+	// SaveProfile создаёт или целиком заменяет профиль пользователя profile.UserID.
+	SaveProfile(ctx context.Context, profile *models.Profile) error
+	// ListAttributeDefinitions возвращает схему атрибутов по возрастанию ключа.
+	ListAttributeDefinitions(ctx context.Context) ([]models.AttributeDefinition, error)
+	CreateAttributeDefinition(ctx context.Context, def *models.AttributeDefinition) error
+	UpdateAttributeDefinition(ctx context.Context, def *models.AttributeDefinition) error
+	// DeleteAttributeDefinition удаляет атрибут из схемы. Сохранённые значения остаются
+	// в профилях, но не возвращаются и пропадают при следующем сохранении профиля.
+	DeleteAttributeDefinition(ctx context.Context, key string) error
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"backend-app/internal/search"
+	"backend-app/internal/storage/models"
+	"sort"
+)
+
+// UserMatch - найденный пользователь с релевантностью и совпавшими полями.
+type UserMatch struct {
+	User    models.User
+	Score   float64
+	Matches []search.FieldMatch
+}
+
+// MatchUser сравнивает нормализованный запрос с username, email и country.
+// Релевантность пользователя - лучшая из релевантностей полей.
+func MatchUser(query string, u *models.User) (UserMatch, bool) {
+	match := UserMatch{User: *u}
+	for _, f := range []struct{ name, value string }{
+		{"username", u.Username},
+		{"email", u.Email},
+		{"country", u.Country},
+	} {
+		m, ok := search.Match(query, f.name, f.value)
+		if !ok {
+			continue
+		}
+		match.Matches = append(match.Matches, m)
+		if m.Score > match.Score {
+			match.Score = m.Score
+		}
+	}
+	return match, len(match.Matches) > 0
+}
+
+// RankMatches сортирует по убыванию релевантности, при равенстве по id, и оставляет limit лучших.
+func RankMatches(matches []UserMatch, limit int) []UserMatch {
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].User.ID < matches[j].User.ID
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
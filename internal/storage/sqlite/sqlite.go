@@ -0,0 +1,37 @@
+package sqlite
+
+import (
+	"backend-app/internal/storage/gormstore"
+	"backend-app/internal/storage/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// Storage - хранилище на SQLite для локальной разработки и тестов.
+type Storage struct {
+	gormstore.Storage
+}
+
+// New открывает базу по пути path, ":memory:" создаёт временную базу в памяти.
+func New(path string) (*Storage, error) {
+	db, err := gorm.Open(sqlite.Open(path), gormstore.Config())
+	if err != nil {
+		return nil, err
+	}
+	if path == ":memory:" {
+		// у каждого соединения была бы своя пустая база
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		sqlDB.SetMaxOpenConns(1)
+	}
+	if err := db.AutoMigrate(models.User{}, models.OutboxEvent{}, models.Webhook{}, models.WebhookDelivery{},
+		models.DataExport{}, models.ErasureRequest{}, models.Organization{}, models.Membership{},
+		models.Group{}, models.GroupMember{}, models.Profile{}, models.AttributeDefinition{}, models.Login{},
+		models.EmailChange{}); err != nil {
+		return nil, err
+	}
+	return &Storage{gormstore.Storage{DB: db}}, nil
+}
@@ -0,0 +1,287 @@
+package sqlite_test
+
+import (
+	"backend-app/internal/fieldcrypt"
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/gormstore"
+	"backend-app/internal/storage/models"
+	"backend-app/internal/storage/sqlite"
+	"backend-app/internal/storage/storagetest"
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Store {
+		s, err := sqlite.New(":memory:")
+		if err != nil {
+			t.Fatalf("Failed to open sqlite: %v", err)
+		}
+		return s
+	})
+}
+
+func TestReplicas(t *testing.T) {
+	open := func() *sqlite.Storage {
+		s, err := sqlite.New(":memory:")
+		if err != nil {
+			t.Fatalf("Failed to open sqlite: %v", err)
+		}
+		return s
+	}
+	primary, replica := open(), open()
+	require.NoError(t, primary.CreateUser(t.Context(), &models.User{Username: "alice", Email: "alice@example.com", Password: "x"}))
+	require.NoError(t, replica.CreateUser(t.Context(), &models.User{Username: "lagging", Email: "lagging@example.com", Password: "x"}))
+	primary.Replicas = []*gorm.DB{replica.DB}
+
+	_, err := primary.GetUserByUsername(t.Context(), "lagging")
+	assert.NoError(t, err, "reads go to the replica")
+	_, err = primary.GetUserByUsername(t.Context(), "alice")
+	assert.ErrorIs(t, err, storage.ErrUserNotFound, "not found on the replica is an answer, not a failure")
+	_, err = primary.GetUserByUsername(storage.WithPrimary(t.Context()), "alice")
+	assert.NoError(t, err, "WithPrimary skips the replicas")
+
+	user := &models.User{Username: "bob", Email: "bob@example.com", Password: "x"}
+	require.NoError(t, primary.CreateUser(t.Context(), user))
+	got, err := primary.PatchUser(t.Context(), user.ID, user.Version, storage.UserPatch{})
+	require.NoError(t, err, "writes and reads inside them go to the primary")
+	assert.Equal(t, "bob", got.Username)
+
+	sqlDB, err := replica.DB.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+
+	_, err = primary.GetUserByUsername(t.Context(), "alice")
+	assert.NoError(t, err, "falls back to the primary when the replica is down")
+	users, err := primary.GetAllUsers(t.Context(), 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, users, 2)
+}
+
+// TestLegacyUsers - пользователи, созданные до уникальности без учёта регистра.
+func TestLegacyUsers(t *testing.T) {
+	s, err := sqlite.New(":memory:")
+	require.NoError(t, err)
+	ctx := t.Context()
+
+	alice := &models.User{Username: "alice", Email: "alice@example.com", Password: "x", Role: "user"}
+	legacy := &models.User{Username: "legacy", Email: "legacy@example.com", Password: "x", Role: "user"}
+	require.NoError(t, s.CreateUser(ctx, alice))
+	require.NoError(t, s.CreateUser(ctx, legacy))
+	require.NoError(t, s.DB.Exec("UPDATE users SET username = 'Alice', username_key = NULL, email = 'Alice@Example.com' WHERE id = ?", legacy.ID).Error)
+
+	update := &models.User{ID: legacy.ID, Username: "Alice", Email: "Alice@Example.com", Role: "admin", Version: legacy.Version}
+	require.NoError(t, s.UpdateUser(ctx, update), "unchanged username and email keep their legacy form")
+	got, err := s.GetUserByID(ctx, legacy.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", got.Role)
+	assert.Nil(t, got.UsernameKey)
+	assert.Equal(t, "Alice@Example.com", got.Email)
+
+	rename := &models.User{ID: legacy.ID, Username: "ALICE", Email: "Alice@Example.com", Role: "admin", Version: update.Version}
+	assert.ErrorIs(t, s.UpdateUser(ctx, rename), storage.ErrUserExists, "a new username gets a key")
+}
+
+func keyring(t *testing.T, current string) *fieldcrypt.Keyring {
+	k, err := fieldcrypt.New(map[string][]byte{
+		"k1": bytes.Repeat([]byte{1}, 32),
+		"k2": bytes.Repeat([]byte{2}, 32),
+	}, current, bytes.Repeat([]byte{9}, 32))
+	require.NoError(t, err)
+	return k
+}
+
+// rawColumns читает email и country так, как они лежат в базе.
+func rawColumns(t *testing.T, s *gormstore.Storage, id uint) (email, country string) {
+	require.NoError(t, s.DB.Table("users").Select("email", "country").Where("id = ?", id).Row().Scan(&email, &country))
+	return email, country
+}
+
+func TestEncryption(t *testing.T) {
+	s, err := sqlite.New(":memory:")
+	require.NoError(t, err)
+	require.NoError(t, s.UseKeyring(keyring(t, "k1")))
+	ctx := t.Context()
+
+	alice := &models.User{Username: "alice", Email: "alice@example.com", Password: "x", Country: "NL"}
+	bob := &models.User{Username: "bob", Email: "bob@example.com", Password: "x", Country: "DE"}
+	require.NoError(t, s.CreateUser(ctx, alice))
+	require.NoError(t, s.CreateUser(ctx, bob))
+
+	email, country := rawColumns(t, &s.Storage, alice.ID)
+	assert.True(t, fieldcrypt.IsEncrypted(email))
+	assert.NotContains(t, email, "alice")
+	assert.True(t, fieldcrypt.IsEncrypted(country))
+
+	got, err := s.GetUserByID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", got.Email)
+	assert.Equal(t, "NL", got.Country)
+
+	got, err = s.GetUserByEmail(ctx, "bob@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, bob.ID, got.ID)
+
+	dup := &models.User{Username: "alice2", Email: "alice@example.com", Password: "x"}
+	assert.ErrorIs(t, s.CreateUser(ctx, dup), storage.ErrUserExists, "the blind index keeps emails unique")
+
+	page, err := s.ListUsers(ctx, storage.UserQuery{Filter: storage.UserFilter{Country: "NL"}})
+	require.NoError(t, err)
+	require.Len(t, page.Users, 1)
+	assert.Equal(t, alice.ID, page.Users[0].ID)
+
+	_, err = s.ListUsers(ctx, storage.UserQuery{SortField: "email"})
+	assert.ErrorIs(t, err, storage.ErrUnsortableField)
+
+	newEmail := "alice@example.org"
+	patched, err := s.PatchUser(ctx, alice.ID, alice.Version, storage.UserPatch{Email: &newEmail})
+	require.NoError(t, err)
+	assert.Equal(t, newEmail, patched.Email)
+	assert.False(t, patched.UpdatedAt.Before(alice.UpdatedAt))
+	email, _ = rawColumns(t, &s.Storage, alice.ID)
+	assert.True(t, fieldcrypt.IsEncrypted(email))
+	_, err = s.GetUserByEmail(ctx, "alice@example.com")
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+	_, err = s.GetUserByEmail(ctx, newEmail)
+	assert.NoError(t, err)
+
+	bob.Country = "FR"
+	require.NoError(t, s.UpdateUser(ctx, bob))
+	page, err = s.ListUsers(ctx, storage.UserQuery{Filter: storage.UserFilter{Country: "FR"}})
+	require.NoError(t, err)
+	require.Len(t, page.Users, 1)
+	assert.Equal(t, bob.ID, page.Users[0].ID)
+
+	matches, err := s.SearchUsers(ctx, "example.org", 0, 10)
+	require.NoError(t, err)
+	require.NotEmpty(t, matches)
+	assert.Equal(t, alice.ID, matches[0].User.ID, "search sees decrypted emails")
+
+	carol := &models.User{Username: "carol", Email: "carol@example.com", Password: "x", Country: "Netherlands"}
+	require.NoError(t, s.CreateUser(ctx, carol))
+	counts, err := s.RegistrationCounts(ctx, storage.StatsQuery{
+		From: time.Now().Add(-time.Hour), To: time.Now().Add(time.Hour), Interval: storage.IntervalDay,
+	})
+	require.NoError(t, err)
+	countries := make(map[string]int64)
+	for _, c := range counts {
+		countries[c.Country] += c.Total
+	}
+	assert.Equal(t, map[string]int64{"NL": 2, "FR": 1}, countries, "countries are recovered from blind indexes")
+}
+
+func TestReencryptUsers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.db")
+	ctx := t.Context()
+
+	// пользователи, записанные до включения шифрования
+	plain, err := sqlite.New(path)
+	require.NoError(t, err)
+	var ids []uint
+	for _, name := range []string{"alice", "bob", "carol"} {
+		u := &models.User{Username: name, Email: name + "@example.com", Password: "x", Country: "NL"}
+		require.NoError(t, plain.CreateUser(ctx, u))
+		ids = append(ids, u.ID)
+	}
+	require.NoError(t, plain.DeleteUser(ctx, ids[2], 0, ""))
+	_, err = plain.ReencryptUsers(ctx, 2, nil)
+	assert.ErrorIs(t, err, gormstore.ErrEncryptionDisabled)
+
+	open := func(current string) *sqlite.Storage {
+		s, err := sqlite.New(path)
+		require.NoError(t, err)
+		require.NoError(t, s.UseKeyring(keyring(t, current)))
+		return s
+	}
+
+	s := open("k1")
+	got, err := s.GetUserByID(ctx, ids[0])
+	require.NoError(t, err, "plaintext rows stay readable")
+	assert.Equal(t, "alice@example.com", got.Email)
+	_, err = s.GetUserByEmail(ctx, "alice@example.com")
+	assert.ErrorIs(t, err, storage.ErrUserNotFound, "no blind index until rotation")
+
+	var batches int
+	updated, err := s.ReencryptUsers(ctx, 2, func(scanned, updated int64) { batches++ })
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), updated, "deleted users are encrypted too")
+	assert.Equal(t, 2, batches)
+	for _, id := range ids {
+		email, _ := rawColumns(t, &s.Storage, id)
+		assert.True(t, strings.HasPrefix(email, "enc:v1:k1:"))
+	}
+	_, err = s.GetUserByEmail(ctx, "alice@example.com")
+	assert.NoError(t, err)
+
+	updated, err = s.ReencryptUsers(ctx, 2, nil)
+	require.NoError(t, err)
+	assert.Zero(t, updated, "nothing to do the second time")
+
+	s = open("k2")
+	before, err := s.GetUserByID(ctx, ids[0])
+	require.NoError(t, err)
+	updated, err = s.ReencryptUsers(ctx, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), updated)
+	email, _ := rawColumns(t, &s.Storage, ids[0])
+	assert.True(t, strings.HasPrefix(email, "enc:v1:k2:"))
+
+	after, err := s.GetUserByEmail(ctx, "alice@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, before.Version, after.Version, "rotation is not a user change")
+}
+
+func TestReencryptCaseDuplicates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.db")
+	ctx := t.Context()
+
+	// старые пользователи, чьи email различаются только регистром
+	plain, err := sqlite.New(path)
+	require.NoError(t, err)
+	first := &models.User{Username: "alice", Email: "alice@example.com", Password: "x"}
+	legacy := &models.User{Username: "alice2", Email: "legacy@example.com", Password: "x"}
+	require.NoError(t, plain.CreateUser(ctx, first))
+	require.NoError(t, plain.CreateUser(ctx, legacy))
+	require.NoError(t, plain.DB.Exec("UPDATE users SET email = 'Alice@Example.com' WHERE id = ?", legacy.ID).Error)
+
+	s, err := sqlite.New(path)
+	require.NoError(t, err)
+	k := keyring(t, "k1")
+	require.NoError(t, s.UseKeyring(k))
+	updated, err := s.ReencryptUsers(ctx, 10, nil)
+	require.NoError(t, err, "case duplicates do not stop the rotation")
+	assert.Equal(t, int64(2), updated)
+
+	var index string
+	require.NoError(t, s.DB.Table("users").Select("email_index").Where("id = ?", legacy.ID).Row().Scan(&index))
+	assert.Equal(t, k.BlindIndex("email", "Alice@Example.com"), index, "the duplicate keeps an index by its own email")
+	got, err := s.GetUserByEmail(ctx, "ALICE@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, got.ID)
+	got, err = s.GetUserByID(ctx, legacy.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice@Example.com", got.Email)
+
+	updated, err = s.ReencryptUsers(ctx, 10, nil)
+	require.NoError(t, err)
+	assert.Zero(t, updated, "the kept index is stable")
+
+	suspended := models.StatusSuspended
+	patched, err := s.PatchUser(ctx, legacy.ID, got.Version, storage.UserPatch{Status: &suspended})
+	require.NoError(t, err, "patching another field keeps the legacy index")
+	assert.Equal(t, "Alice@Example.com", patched.Email)
+	country := "DE"
+	patched, err = s.PatchUser(ctx, legacy.ID, patched.Version, storage.UserPatch{Country: &country})
+	require.NoError(t, err)
+	page, err := s.ListUsers(ctx, storage.UserQuery{Filter: storage.UserFilter{Country: "DE"}})
+	require.NoError(t, err)
+	require.Len(t, page.Users, 1, "a changed country gets a new index")
+	assert.Equal(t, legacy.ID, page.Users[0].ID)
+}
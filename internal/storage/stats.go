@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"backend-app/internal/storage/models"
+	"context"
+	"time"
+)
+
+const (
+	IntervalDay  = "day"
+	IntervalWeek = "week"
+)
+
+// StatsQuery - период [From, To), разбитый на интервалы по UTC. Недели начинаются с
+// понедельника. Ненулевой OrgID оставляет только участников организации.
+type StatsQuery struct {
+	From     time.Time
+	To       time.Time
+	Interval string
+	OrgID    uint
+}
+
+// RegistrationCount - регистрации за интервал, начинающийся в Bucket (YYYY-MM-DD),
+// с одной страной и ролью. Verified - сколько из них уже подтверждены.
+type RegistrationCount struct {
+	Bucket   string
+	Country  string
+	Role     string
+	Total    int64
+	Verified int64
+}
+
+// LoginCount - входы за интервал, начинающийся в Bucket (YYYY-MM-DD). ActiveUsers -
+// число разных пользователей, которые хотя бы раз вошли успешно.
+type LoginCount struct {
+	Bucket      string
+	Succeeded   int64
+	Failed      int64
+	ActiveUsers int64
+}
+
+// StatsRepository записывает входы и считает агрегаты для статистики администратора.
+// Регистрации считаются вместе с мягко удалёнными пользователями. Окончательно
+// удалённые пользователи пропадают из статистики, у обезличенных удаляются входы.
+type StatsRepository interface {
+	RecordLogin(ctx context.Context, login *models.Login) error
+	// DeleteLogins удаляет входы, записанные раньше before, и возвращает их количество.
+	DeleteLogins(ctx context.Context, before time.Time) (int64, error)
+	// RegistrationCounts возвращает регистрации по интервалам, странам и ролям. В
+	// организации роль - роль участника. Страна, которую не удалось привести к коду
+	// ISO 3166-1 alpha-2, возвращается пустой.
+	RegistrationCounts(ctx context.Context, q StatsQuery) ([]RegistrationCount, error)
+	// LoginCounts возвращает входы по интервалам, интервалы без входов пропускаются.
+	// Неудачные входы с неизвестным username в статистику организации не попадают.
+	LoginCounts(ctx context.Context, q StatsQuery) ([]LoginCount, error)
+	// ActiveUsers возвращает число разных пользователей, успешно входивших в [from, to).
+	ActiveUsers(ctx context.Context, orgID uint, from, to time.Time) (int64, error)
+}
+
+// StatsBucket возвращает начало интервала, в который попадает t, в виде YYYY-MM-DD.
+func StatsBucket(t time.Time, interval string) string {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if interval == IntervalWeek {
+		day = day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	}
+	return day.Format(time.DateOnly)
+}
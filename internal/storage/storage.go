@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"backend-app/internal/storage/models"
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrUserExists   = errors.New("user already exists")
+	// ErrVersionConflict - пользователя изменили после того, как клиент прочитал его версию.
+	ErrVersionConflict = errors.New("user version conflict")
+)
+
+// UserRepository реализуют все хранилища: postgres, sqlite и memory. Все методы
+// прерываются, когда отменён ctx, и тогда возвращают ошибку, оборачивающую ctx.Err().
+// Методы, меняющие пользователя, в той же транзакции пишут события в Outbox.
+// Username и email уникальны без учёта регистра, email хранится в нижнем регистре.
+type UserRepository interface {
+	CreateUser(ctx context.Context, user *models.User) error
+	// ImportUsers создаёт пользователей одной транзакцией. Пользователь, чей username или
+	// email уже занят, пропускается, и на его месте в errs стоит ErrUserExists, остальные
+	// сохраняются. С dryRun транзакция откатывается, но errs заполняются так же.
+	ImportUsers(ctx context.Context, users []*models.User, dryRun bool) (errs []error, err error)
+	GetUserByID(ctx context.Context, id uint) (*models.User, error)
+	// GetUserByUsername ищет без учёта регистра (models.NormalizeUsername).
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	// GetUserByEmail ищет без учёта регистра и работает, даже если email хранится зашифрованным.
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	// UpdateUser сохраняет пользователя только если user.Version совпадает с сохранённой
+	// версией, иначе возвращает ErrVersionConflict. При успехе user.Version увеличивается.
+	// Пустые Password и Status оставляют прежние значения.
+	UpdateUser(ctx context.Context, user *models.User) error
+	// PatchUser записывает только изменившиеся поля, если версия пользователя равна version,
+	// и возвращает пользователя после изменения.
+	PatchUser(ctx context.Context, id uint, version uint, patch UserPatch) (*models.User, error)
+	// SetRefreshToken сохраняет выданный refresh token, версия пользователя не меняется.
+	SetRefreshToken(ctx context.Context, id uint, token string, expiry time.Time) error
+	// DeleteUser помечает пользователя удалённым, строка остаётся до PurgeDeletedUsers.
+	DeleteUser(ctx context.Context, id uint, deletedBy uint, reason string) error
+	RestoreUser(ctx context.Context, id uint) error
+	GetAllUsers(ctx context.Context, offset int, limit int) ([]models.User, error)
+	// ListUsers возвращает страницу пользователей с фильтрами, сортировкой и keyset пагинацией.
+	ListUsers(ctx context.Context, q UserQuery) (UserPage, error)
+	// SearchUsers ищет по username, email и country без учёта регистра и с опечатками.
+	// Ненулевой orgID оставляет только участников организации.
+	SearchUsers(ctx context.Context, query string, orgID uint, limit int) ([]UserMatch, error)
+	// GetDeletedUsers возвращает удалённых пользователей, с ненулевым orgID - только
+	// участников организации.
+	GetDeletedUsers(ctx context.Context, orgID uint, offset int, limit int) ([]models.User, error)
+	// PurgeDeletedUsers окончательно удаляет пользователей, удалённых раньше before,
+	// вместе с зависимыми записями, включая профиль, входы, запрос на смену email, выгрузки
+	// данных и участие в организациях и группах, и возвращает их количество.
+	PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, error)
+}
+
+type primaryKey struct{}
+
+// WithPrimary помечает контекст: чтения с ним идут на primary, а не на реплики. Нужен
+// там, где отставание реплики недопустимо: проверка отзыва токенов, refresh и ETag,
+// который клиент вернёт в If-Match.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// PrimaryOnly сообщает, помечен ли контекст WithPrimary.
+func PrimaryOnly(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryKey{}).(bool)
+	return primary
+}
@@ -0,0 +1,1550 @@
+// Package storagetest содержит общий набор тестов, который должна проходить
+// каждая реализация storage.UserRepository.
+package storagetest
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory возвращает пустое хранилище для одного теста.
+type Factory func(t *testing.T) storage.Store
+
+func Run(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo storage.UserRepository)
+	}{
+		{"CreateUser", testCreateUser},
+		{"CreateUserDuplicate", testCreateUserDuplicate},
+		{"GetUserByID", testGetUserByID},
+		{"GetUserByUsername", testGetUserByUsername},
+		{"GetUserByEmail", testGetUserByEmail},
+		{"UpdateUser", testUpdateUser},
+		{"UpdateUserNotFound", testUpdateUserNotFound},
+		{"UpdateUserTokenVersion", testUpdateUserTokenVersion},
+		{"UpdateUserVersionConflict", testUpdateUserVersionConflict},
+		{"SetRefreshToken", testSetRefreshToken},
+		{"PatchUser", testPatchUser},
+		{"DeleteUser", testDeleteUser},
+		{"DeletedUsersHidden", testDeletedUsersHidden},
+		{"RestoreUser", testRestoreUser},
+		{"PurgeDeletedUsers", testPurgeDeletedUsers},
+		{"GetAllUsers", testGetAllUsers},
+		{"ListUsersFilter", testListUsersFilter},
+		{"ListUsersKeyset", testListUsersKeyset},
+		{"ListUsersInvalid", testListUsersInvalid},
+		{"SearchUsers", testSearchUsers},
+		{"CaseInsensitiveUsers", testCaseInsensitiveUsers},
+		{"CanceledContext", testCanceledContext},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+
+	outboxTests := []struct {
+		name string
+		fn   func(t *testing.T, store storage.Store)
+	}{
+		{"OutboxEvents", testOutboxEvents},
+		{"OutboxNoEventOnFailure", testOutboxNoEventOnFailure},
+		{"OutboxClaim", testOutboxClaim},
+		{"OutboxRetry", testOutboxRetry},
+		{"OutboxAddEvents", testOutboxAddEvents},
+		{"ImportUsers", testImportUsers},
+		{"Webhooks", testWebhooks},
+		{"WebhookDeliveries", testWebhookDeliveries},
+		{"DataExports", testDataExports},
+		{"ErasureRequests", testErasureRequests},
+		{"EraseUser", testEraseUser},
+		{"Organizations", testOrganizations},
+		{"Memberships", testMemberships},
+		{"TenantScopedQueries", testTenantScopedQueries},
+		{"Groups", testGroups},
+		{"GroupMembers", testGroupMembers},
+		{"Profiles", testProfiles},
+		{"AttributeDefinitions", testAttributeDefinitions},
+		{"RegistrationCounts", testRegistrationCounts},
+		{"LoginCounts", testLoginCounts},
+		{"EmailChanges", testEmailChanges},
+	}
+
+	for _, tt := range outboxTests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+func newUser(name string) *models.User {
+	return &models.User{
+		Username: name,
+		Password: "password123",
+		Email:    name + "@example.com",
+		Role:     "user",
+		Country:  "Testland",
+	}
+}
+
+func testCreateUser(t *testing.T, repo storage.UserRepository) {
+	user := newUser("alice")
+	require.NoError(t, repo.CreateUser(t.Context(), user))
+
+	assert.NotZero(t, user.ID)
+	assert.False(t, user.CreatedAt.IsZero())
+}
+
+func testCreateUserDuplicate(t *testing.T, repo storage.UserRepository) {
+	require.NoError(t, repo.CreateUser(t.Context(), newUser("alice")))
+
+	sameName := newUser("alice")
+	sameName.Email = "other@example.com"
+	assert.ErrorIs(t, repo.CreateUser(t.Context(), sameName), storage.ErrUserExists)
+
+	sameEmail := newUser("bob")
+	sameEmail.Email = "alice@example.com"
+	assert.ErrorIs(t, repo.CreateUser(t.Context(), sameEmail), storage.ErrUserExists)
+}
+
+func testGetUserByID(t *testing.T, repo storage.UserRepository) {
+	user := newUser("alice")
+	require.NoError(t, repo.CreateUser(t.Context(), user))
+
+	got, err := repo.GetUserByID(t.Context(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.Username, got.Username)
+	assert.Equal(t, user.Email, got.Email)
+	assert.Equal(t, models.StatusActive, got.Status)
+
+	_, err = repo.GetUserByID(t.Context(), user.ID+100)
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+}
+
+func testGetUserByUsername(t *testing.T, repo storage.UserRepository) {
+	user := newUser("alice")
+	require.NoError(t, repo.CreateUser(t.Context(), user))
+
+	got, err := repo.GetUserByUsername(t.Context(), "alice")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, got.ID)
+
+	_, err = repo.GetUserByUsername(t.Context(), "nobody")
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+}
+
+func testGetUserByEmail(t *testing.T, repo storage.UserRepository) {
+	user := newUser("alice")
+	require.NoError(t, repo.CreateUser(t.Context(), user))
+
+	got, err := repo.GetUserByEmail(t.Context(), "alice@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, got.ID)
+	assert.Equal(t, "alice@example.com", got.Email)
+
+	_, err = repo.GetUserByEmail(t.Context(), "nobody@example.com")
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	require.NoError(t, repo.DeleteUser(t.Context(), user.ID, 0, ""))
+	_, err = repo.GetUserByEmail(t.Context(), "alice@example.com")
+	assert.ErrorIs(t, err, storage.ErrUserNotFound, "deleted users are not found")
+}
+
+func testUpdateUser(t *testing.T, repo storage.UserRepository) {
+	user := newUser("alice")
+	require.NoError(t, repo.CreateUser(t.Context(), user))
+
+	user.Username = "updateduser"
+	require.NoError(t, repo.UpdateUser(t.Context(), user))
+
+	got, err := repo.GetUserByID(t.Context(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "updateduser", got.Username)
+}
+
+func testUpdateUserNotFound(t *testing.T, repo storage.UserRepository) {
+	user := newUser("alice")
+	user.ID = 42
+	assert.ErrorIs(t, repo.UpdateUser(t.Context(), user), storage.ErrUserNotFound)
+}
+
+func testUpdateUserTokenVersion(t *testing.T, repo storage.UserRepository) {
+	user := newUser("alice")
+	require.NoError(t, repo.CreateUser(t.Context(), user))
+
+	user.Country = "Elsewhere"
+	require.NoError(t, repo.UpdateUser(t.Context(), user))
+	got, err := repo.GetUserByID(t.Context(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uint(0), got.TokenVersion, "unrelated change must keep tokens")
+
+	got.Role = "admin"
+	require.NoError(t, repo.UpdateUser(t.Context(), got))
+	got, err = repo.GetUserByID(t.Context(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), got.TokenVersion, "role change must revoke tokens")
+
+	got.Status = models.StatusSuspended
+	require.NoError(t, repo.UpdateUser(t.Context(), got))
+	got, err = repo.GetUserByID(t.Context(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), got.TokenVersion, "suspension must revoke tokens")
+}
+
+func testUpdateUserVersionConflict(t *testing.T, repo storage.UserRepository) {
+	user := newUser("alice")
+	require.NoError(t, repo.CreateUser(t.Context(), user))
+	assert.Equal(t, uint(1), user.Version)
+
+	first, err := repo.GetUserByID(t.Context(), user.ID)
+	require.NoError(t, err)
+	second, err := repo.GetUserByID(t.Context(), user.ID)
+	require.NoError(t, err)
+
+	first.Country = "First"
+	require.NoError(t, repo.UpdateUser(t.Context(), first))
+	assert.Equal(t, uint(2), first.Version)
+
+	second.Country = "Second"
+	assert.ErrorIs(t, repo.UpdateUser(t.Context(), second), storage.ErrVersionConflict)
+	assert.Equal(t, uint(1), second.Version, "failed update must keep the expected version")
+
+	got, err := repo.GetUserByID(t.Context(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "First", got.Country, "stale update must not overwrite")
+	assert.Equal(t, uint(2), got.Version)
+
+	require.NoError(t, repo.DeleteUser(t.Context(), user.ID, 0, ""))
+	require.NoError(t, repo.RestoreUser(t.Context(), user.ID))
+	got, err = repo.GetUserByID(t.Context(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uint(4), got.Version, "delete and restore change the user")
+}
+
+func testPatchUser(t *testing.T, repo storage.UserRepository) {
+	user := newUser("alice")
+	require.NoError(t, repo.CreateUser(t.Context(), user))
+	require.NoError(t, repo.SetRefreshToken(t.Context(), user.ID, "token", time.Now().Add(time.Hour)))
+	require.NoError(t, repo.CreateUser(t.Context(), newUser("bob")))
+
+	country := "DE"
+	got, err := repo.PatchUser(t.Context(), user.ID, user.Version, storage.UserPatch{Country: &country})
+	require.NoError(t, err)
+	assert.Equal(t, "DE", got.Country)
+	assert.Equal(t, "alice", got.Username, "untouched fields stay")
+	assert.Equal(t, "password123", got.Password, "untouched fields stay")
+	assert.Equal(t, "token", got.RefreshToken, "refresh token is not touched")
+	assert.Equal(t, uint(2), got.Version)
+	assert.Equal(t, uint(0), got.TokenVersion)
+
+	same, err := repo.PatchUser(t.Context(), user.ID, got.Version, storage.UserPatch{Country: &country})
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), same.Version, "no-op patch is not a change")
+
+	_, err = repo.PatchUser(t.Context(), user.ID, 1, storage.UserPatch{Country: &country})
+	assert.ErrorIs(t, err, storage.ErrVersionConflict)
+
+	role := "admin"
+	got, err = repo.PatchUser(t.Context(), user.ID, got.Version, storage.UserPatch{Role: &role})
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), got.TokenVersion, "role change must revoke tokens")
+
+	taken := "bob"
+	_, err = repo.PatchUser(t.Context(), user.ID, got.Version, storage.UserPatch{Username: &taken})
+	assert.ErrorIs(t, err, storage.ErrUserExists)
+
+	_, err = repo.PatchUser(t.Context(), user.ID+100, 1, storage.UserPatch{Country: &country})
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+}
+
+func testSetRefreshToken(t *testing.T, repo storage.UserRepository) {
+	user := newUser("alice")
+	require.NoError(t, repo.CreateUser(t.Context(), user))
+
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	require.NoError(t, repo.SetRefreshToken(t.Context(), user.ID, "token", expiry))
+
+	got, err := repo.GetUserByID(t.Context(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "token", got.RefreshToken)
+	assert.True(t, got.TokenExpiry.Equal(expiry))
+	assert.Equal(t, uint(1), got.Version, "issuing tokens is not a user change")
+
+	got.Country = "Elsewhere"
+	got.RefreshToken = ""
+	require.NoError(t, repo.UpdateUser(t.Context(), got))
+	got, err = repo.GetUserByID(t.Context(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "token", got.RefreshToken, "UpdateUser must not touch the refresh token")
+
+	assert.ErrorIs(t, repo.SetRefreshToken(t.Context(), user.ID+100, "token", expiry), storage.ErrUserNotFound)
+}
+
+func testDeleteUser(t *testing.T, repo storage.UserRepository) {
+	user := newUser("alice")
+	require.NoError(t, repo.CreateUser(t.Context(), user))
+
+	require.NoError(t, repo.DeleteUser(t.Context(), user.ID, 0, ""))
+
+	_, err := repo.GetUserByID(t.Context(), user.ID)
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+	assert.ErrorIs(t, repo.DeleteUser(t.Context(), user.ID, 0, ""), storage.ErrUserNotFound)
+	assert.ErrorIs(t, repo.DeleteUser(t.Context(), user.ID+100, 0, ""), storage.ErrUserNotFound)
+}
+
+func testDeletedUsersHidden(t *testing.T, repo storage.UserRepository) {
+	admin := newUser("admin")
+	require.NoError(t, repo.CreateUser(t.Context(), admin))
+	user := newUser("alice")
+	require.NoError(t, repo.CreateUser(t.Context(), user))
+
+	require.NoError(t, repo.DeleteUser(t.Context(), user.ID, admin.ID, "spam"))
+
+	_, err := repo.GetUserByUsername(t.Context(), "alice")
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	users, err := repo.GetAllUsers(t.Context(), 0, 10)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, admin.ID, users[0].ID)
+
+	deleted, err := repo.GetDeletedUsers(t.Context(), 0, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	assert.Equal(t, user.ID, deleted[0].ID)
+	assert.True(t, deleted[0].DeletedAt.Valid)
+	require.NotNil(t, deleted[0].DeletedBy)
+	assert.Equal(t, admin.ID, *deleted[0].DeletedBy)
+	assert.Equal(t, "spam", deleted[0].DeleteReason)
+	assert.Equal(t, uint(1), deleted[0].TokenVersion, "deletion must revoke tokens")
+
+	user.Country = "Elsewhere"
+	assert.ErrorIs(t, repo.UpdateUser(t.Context(), user), storage.ErrUserNotFound)
+}
+
+func testRestoreUser(t *testing.T, repo storage.UserRepository) {
+	user := newUser("alice")
+	require.NoError(t, repo.CreateUser(t.Context(), user))
+
+	assert.ErrorIs(t, repo.RestoreUser(t.Context(), user.ID), storage.ErrUserNotFound, "active user can't be restored")
+
+	require.NoError(t, repo.DeleteUser(t.Context(), user.ID, 0, "mistake"))
+	require.NoError(t, repo.RestoreUser(t.Context(), user.ID))
+
+	got, err := repo.GetUserByUsername(t.Context(), "alice")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, got.ID)
+	assert.Nil(t, got.DeletedBy)
+	assert.Empty(t, got.DeleteReason)
+
+	deleted, err := repo.GetDeletedUsers(t.Context(), 0, 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, deleted)
+}
+
+func testPurgeDeletedUsers(t *testing.T, repo storage.UserRepository) {
+	kept := newUser("kept")
+	require.NoError(t, repo.CreateUser(t.Context(), kept))
+	gone := newUser("gone")
+	require.NoError(t, repo.CreateUser(t.Context(), gone))
+	require.NoError(t, repo.DeleteUser(t.Context(), gone.ID, 0, ""))
+
+	purged, err := repo.PurgeDeletedUsers(t.Context(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, purged, "retention period has not passed yet")
+
+	purged, err = repo.PurgeDeletedUsers(t.Context(), time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	deleted, err := repo.GetDeletedUsers(t.Context(), 0, 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, deleted)
+	assert.ErrorIs(t, repo.RestoreUser(t.Context(), gone.ID), storage.ErrUserNotFound)
+
+	_, err = repo.GetUserByID(t.Context(), kept.ID)
+	assert.NoError(t, err)
+}
+
+func testGetAllUsers(t *testing.T, repo storage.UserRepository) {
+	for i := 0; i < 5; i++ {
+		require.NoError(t, repo.CreateUser(t.Context(), newUser(fmt.Sprintf("user%d", i))))
+	}
+
+	users, err := repo.GetAllUsers(t.Context(), 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, users, 5)
+
+	page, err := repo.GetAllUsers(t.Context(), 1, 2)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, "user1", page[0].Username)
+	assert.Equal(t, "user2", page[1].Username)
+}
+
+func seedUsers(t *testing.T, repo storage.UserRepository) {
+	seed := []struct {
+		name     string
+		role     string
+		country  string
+		verified bool
+	}{
+		{"dave", "user", "RU", true},
+		{"alice", "admin", "DE", false},
+		{"erin", "user", "DE", true},
+		{"bob", "creator", "RU", false},
+		{"carol", "user", "US", true},
+	}
+	for _, u := range seed {
+		user := newUser(u.name)
+		user.Role = u.role
+		user.Country = u.country
+		user.Verified = u.verified
+		require.NoError(t, repo.CreateUser(t.Context(), user))
+	}
+}
+
+func usernames(users []models.User) []string {
+	names := make([]string, 0, len(users))
+	for _, u := range users {
+		names = append(names, u.Username)
+	}
+	return names
+}
+
+func testListUsersFilter(t *testing.T, repo storage.UserRepository) {
+	seedUsers(t, repo)
+	verified := true
+
+	page, err := repo.ListUsers(t.Context(), storage.UserQuery{
+		Filter:    storage.UserFilter{Country: "DE"},
+		SortField: "username",
+		WithTotal: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "erin"}, usernames(page.Users))
+	require.NotNil(t, page.Total)
+	assert.Equal(t, int64(2), *page.Total)
+	assert.Empty(t, page.NextCursor)
+	assert.Empty(t, page.PrevCursor)
+
+	page, err = repo.ListUsers(t.Context(), storage.UserQuery{
+		Filter:    storage.UserFilter{Role: "user", Verified: &verified},
+		SortField: "username",
+		SortDesc:  true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"erin", "dave", "carol"}, usernames(page.Users))
+	assert.Nil(t, page.Total)
+
+	page, err = repo.ListUsers(t.Context(), storage.UserQuery{
+		Filter: storage.UserFilter{CreatedFrom: time.Now().Add(time.Hour)},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, page.Users)
+
+	page, err = repo.ListUsers(t.Context(), storage.UserQuery{
+		Filter: storage.UserFilter{CreatedTo: time.Now().Add(time.Hour)},
+	})
+	require.NoError(t, err)
+	assert.Len(t, page.Users, 5)
+}
+
+func testListUsersKeyset(t *testing.T, repo storage.UserRepository) {
+	seedUsers(t, repo)
+	query := func(cursor string) storage.UserQuery {
+		q := storage.UserQuery{SortField: "country", SortDesc: true, Limit: 2, WithTotal: true}
+		if cursor != "" {
+			c, err := storage.DecodeCursor(cursor)
+			require.NoError(t, err)
+			q.Cursor = c
+		}
+		return q
+	}
+
+	// country DESC, затем id DESC: carol(US), bob(RU), dave(RU), erin(DE), alice(DE)
+	first, err := repo.ListUsers(t.Context(), query(""))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"carol", "bob"}, usernames(first.Users))
+	assert.Empty(t, first.PrevCursor)
+	require.NotEmpty(t, first.NextCursor)
+	require.NotNil(t, first.Total)
+	assert.Equal(t, int64(5), *first.Total)
+
+	second, err := repo.ListUsers(t.Context(), query(first.NextCursor))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dave", "erin"}, usernames(second.Users))
+	require.NotEmpty(t, second.NextCursor)
+	require.NotEmpty(t, second.PrevCursor)
+
+	third, err := repo.ListUsers(t.Context(), query(second.NextCursor))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, usernames(third.Users))
+	assert.Empty(t, third.NextCursor)
+	require.NotEmpty(t, third.PrevCursor)
+
+	back, err := repo.ListUsers(t.Context(), query(third.PrevCursor))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dave", "erin"}, usernames(back.Users))
+	assert.NotEmpty(t, back.NextCursor)
+	require.NotEmpty(t, back.PrevCursor)
+
+	start, err := repo.ListUsers(t.Context(), query(back.PrevCursor))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"carol", "bob"}, usernames(start.Users))
+	assert.Empty(t, start.PrevCursor)
+	assert.NotEmpty(t, start.NextCursor)
+
+	byDate, err := repo.ListUsers(t.Context(), storage.UserQuery{SortField: "created_at", Limit: 3})
+	require.NoError(t, err)
+	require.Len(t, byDate.Users, 3)
+	c, err := storage.DecodeCursor(byDate.NextCursor)
+	require.NoError(t, err)
+	rest, err := repo.ListUsers(t.Context(), storage.UserQuery{SortField: "created_at", Limit: 3, Cursor: c})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob", "carol"}, usernames(rest.Users))
+}
+
+func testListUsersInvalid(t *testing.T, repo storage.UserRepository) {
+	seedUsers(t, repo)
+
+	_, err := repo.ListUsers(t.Context(), storage.UserQuery{SortField: "password"})
+	assert.Error(t, err)
+
+	page, err := repo.ListUsers(t.Context(), storage.UserQuery{SortField: "username", Limit: 1})
+	require.NoError(t, err)
+	c, err := storage.DecodeCursor(page.NextCursor)
+	require.NoError(t, err)
+
+	_, err = repo.ListUsers(t.Context(), storage.UserQuery{SortField: "email", Cursor: c})
+	assert.ErrorIs(t, err, storage.ErrInvalidCursor, "cursor must match the sort order")
+
+	_, err = storage.DecodeCursor("not a cursor")
+	assert.ErrorIs(t, err, storage.ErrInvalidCursor)
+}
+
+func testSearchUsers(t *testing.T, repo storage.UserRepository) {
+	for _, name := range []string{"alice", "Alicia", "alexander", "bob"} {
+		require.NoError(t, repo.CreateUser(t.Context(), newUser(name)))
+	}
+	searchNames := func(query string) []string {
+		matches, err := repo.SearchUsers(t.Context(), query, 0, 10)
+		require.NoError(t, err)
+		names := make([]string, 0, len(matches))
+		for i, m := range matches {
+			if i > 0 {
+				assert.GreaterOrEqual(t, matches[i-1].Score, m.Score, "results must be ranked")
+			}
+			assert.NotEmpty(t, m.Matches)
+			names = append(names, m.User.Username)
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"alice", "Alicia"}, searchNames("ALI"), "prefix, case-insensitive")
+	assert.Equal(t, "alice", searchNames("alice")[0], "exact match goes first")
+	assert.Contains(t, searchNames("alcie"), "alice", "typo")
+	assert.Contains(t, searchNames("alexnader"), "alexander", "typo")
+	assert.Empty(t, searchNames("zzz"))
+
+	matches, err := repo.SearchUsers(t.Context(), "ali", 0, 1)
+	require.NoError(t, err)
+	assert.Len(t, matches, 1)
+
+	alicia, err := repo.GetUserByUsername(t.Context(), "Alicia")
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteUser(t.Context(), alicia.ID, 0, ""))
+	assert.Equal(t, []string{"alice"}, searchNames("ali"), "deleted users are not searchable")
+}
+
+func testCanceledContext(t *testing.T, repo storage.UserRepository) {
+	user := newUser("alice")
+	require.NoError(t, repo.CreateUser(t.Context(), user))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	assert.ErrorIs(t, repo.CreateUser(ctx, newUser("bob")), context.Canceled)
+	_, err := repo.GetUserByID(ctx, user.ID)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = repo.ListUsers(ctx, storage.UserQuery{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, repo.UpdateUser(ctx, user), context.Canceled)
+
+	_, err = repo.GetUserByUsername(t.Context(), "bob")
+	assert.ErrorIs(t, err, storage.ErrUserNotFound, "canceled create must not store the user")
+}
+
+// claimAll забирает все доступные события outbox.
+func claimAll(t *testing.T, store storage.Store) []models.OutboxEvent {
+	claimed, err := store.ClaimEvents(t.Context(), 100, time.Minute)
+	require.NoError(t, err)
+	return claimed
+}
+
+func testOutboxEvents(t *testing.T, store storage.Store) {
+	user := newUser("alice")
+	require.NoError(t, store.CreateUser(t.Context(), user))
+	user.Role = "admin"
+	require.NoError(t, store.UpdateUser(t.Context(), user))
+	country, verified := "Elsewhere", true
+	_, err := store.PatchUser(t.Context(), user.ID, user.Version, storage.UserPatch{Country: &country, Verified: &verified})
+	require.NoError(t, err)
+	require.NoError(t, store.SetRefreshToken(t.Context(), user.ID, "token", time.Now().Add(time.Hour)))
+	require.NoError(t, store.DeleteUser(t.Context(), user.ID, 0, "spam"))
+	require.NoError(t, store.RestoreUser(t.Context(), user.ID))
+
+	claimed := claimAll(t, store)
+	types := make([]string, 0, len(claimed))
+	keys := make(map[string]bool)
+	for _, e := range claimed {
+		types = append(types, e.Type)
+		keys[e.Key] = true
+		assert.Equal(t, user.ID, e.UserID)
+		assert.NotContains(t, e.Payload, "password123")
+		assert.NotContains(t, e.Payload, "token\"")
+	}
+	assert.Equal(t, []string{
+		"user.created", "user.updated", "user.role_changed", "user.updated", "user.verified", "user.deleted", "user.restored",
+	}, types, "refresh token is not a domain change")
+	assert.Len(t, keys, len(claimed), "every event has its own dedupe key")
+	assert.Contains(t, claimed[2].Payload, `"previousRole":"user"`)
+	assert.Contains(t, claimed[5].Payload, `"deleteReason":"spam"`)
+}
+
+func testOutboxNoEventOnFailure(t *testing.T, store storage.Store) {
+	user := newUser("alice")
+	require.NoError(t, store.CreateUser(t.Context(), user))
+
+	stale := *user
+	user.Country = "Elsewhere"
+	require.NoError(t, store.UpdateUser(t.Context(), user))
+	stale.Country = "Nowhere"
+	assert.ErrorIs(t, store.UpdateUser(t.Context(), &stale), storage.ErrVersionConflict)
+	assert.ErrorIs(t, store.CreateUser(t.Context(), newUser("alice")), storage.ErrUserExists)
+	assert.ErrorIs(t, store.DeleteUser(t.Context(), user.ID+100, 0, ""), storage.ErrUserNotFound)
+
+	claimed := claimAll(t, store)
+	require.Len(t, claimed, 2, "failed writes must not leave events")
+	assert.Equal(t, "user.created", claimed[0].Type)
+	assert.Equal(t, "user.updated", claimed[1].Type)
+}
+
+func testOutboxClaim(t *testing.T, store storage.Store) {
+	for _, name := range []string{"alice", "bob", "carol"} {
+		require.NoError(t, store.CreateUser(t.Context(), newUser(name)))
+	}
+
+	first, err := store.ClaimEvents(t.Context(), 2, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	rest := claimAll(t, store)
+	require.Len(t, rest, 1, "claimed events are leased")
+	assert.Greater(t, rest[0].ID, first[1].ID, "events are claimed in order")
+	assert.Empty(t, claimAll(t, store))
+
+	for _, e := range append(first, rest...) {
+		require.NoError(t, store.MarkPublished(t.Context(), e.ID))
+	}
+	deleted, err := store.DeletePublishedEvents(t.Context(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, deleted, "recently published events are kept")
+	deleted, err = store.DeletePublishedEvents(t.Context(), time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+}
+
+func testOutboxRetry(t *testing.T, store storage.Store) {
+	require.NoError(t, store.CreateUser(t.Context(), newUser("alice")))
+
+	// аренда в прошлом - как relay, который упал и не отметил событие
+	claimed, err := store.ClaimEvents(t.Context(), 10, -time.Second)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	claimed = claimAll(t, store)
+	require.Len(t, claimed, 1, "expired lease makes the event available again")
+
+	id := claimed[0].ID
+	require.NoError(t, store.MarkFailed(t.Context(), id, "broker unavailable", time.Now().Add(-time.Second)))
+	claimed = claimAll(t, store)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, 1, claimed[0].Attempts)
+	assert.Equal(t, "broker unavailable", claimed[0].LastError)
+
+	require.NoError(t, store.MarkFailed(t.Context(), id, "broker unavailable", time.Now().Add(time.Hour)))
+	assert.Empty(t, claimAll(t, store), "failed event waits for retryAt")
+}
+
+func testOutboxAddEvents(t *testing.T, store storage.Store) {
+	user := newUser("alice")
+	require.NoError(t, store.CreateUser(t.Context(), user))
+	require.NoError(t, store.AddEvents(t.Context(), []models.OutboxEvent{{
+		Key:           "user.login_failed:1:abc",
+		Type:          "user.login_failed",
+		UserID:        user.ID,
+		Payload:       `{"id":1}`,
+		NextAttemptAt: time.Now(),
+	}}))
+
+	claimed := claimAll(t, store)
+	require.Len(t, claimed, 2)
+	assert.Equal(t, "user.login_failed", claimed[1].Type)
+	assert.Equal(t, "user.login_failed:1:abc", claimed[1].Key)
+}
+
+func testImportUsers(t *testing.T, store storage.Store) {
+	require.NoError(t, store.CreateUser(t.Context(), newUser("alice")))
+	batch := func() []*models.User {
+		sameEmail := newUser("bob2")
+		sameEmail.Email = "bob@example.com"
+		return []*models.User{newUser("bob"), newUser("alice"), newUser("carol"), sameEmail}
+	}
+	want := []error{nil, storage.ErrUserExists, nil, storage.ErrUserExists}
+
+	errs, err := store.ImportUsers(t.Context(), batch(), true)
+	require.NoError(t, err)
+	assert.Equal(t, want, errs)
+	_, err = store.GetUserByUsername(t.Context(), "bob")
+	assert.ErrorIs(t, err, storage.ErrUserNotFound, "dry run does not save users")
+	assert.Len(t, claimAll(t, store), 1, "dry run does not write events")
+
+	users := batch()
+	errs, err = store.ImportUsers(t.Context(), users, false)
+	require.NoError(t, err)
+	assert.Equal(t, want, errs)
+	for _, name := range []string{"bob", "carol"} {
+		got, err := store.GetUserByUsername(t.Context(), name)
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), got.Version)
+		assert.Equal(t, models.StatusActive, got.Status)
+	}
+	assert.NotZero(t, users[0].ID)
+	assert.Zero(t, users[1].ID)
+	_, err = store.GetUserByUsername(t.Context(), "bob2")
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	claimed := claimAll(t, store)
+	require.Len(t, claimed, 2)
+	assert.Equal(t, "user.created", claimed[0].Type)
+	assert.Equal(t, users[0].ID, claimed[0].UserID)
+	assert.Equal(t, users[2].ID, claimed[1].UserID)
+}
+
+func testWebhooks(t *testing.T, store storage.Store) {
+	hook := &models.Webhook{URL: "https://example.com/a", Secret: "secret", Events: "user.created,user.deleted"}
+	require.NoError(t, store.CreateWebhook(t.Context(), hook))
+	assert.NotZero(t, hook.ID)
+	require.NoError(t, store.CreateWebhook(t.Context(), &models.Webhook{URL: "https://example.com/b", Secret: "secret", Events: "user.created", Active: true}))
+
+	got, err := store.GetWebhook(t.Context(), hook.ID)
+	require.NoError(t, err)
+	assert.False(t, got.Active, "inactive webhook must stay inactive")
+	assert.Equal(t, []string{"user.created", "user.deleted"}, got.EventTypes())
+
+	got.Active = true
+	got.URL = "https://example.com/c"
+	require.NoError(t, store.UpdateWebhook(t.Context(), got))
+	got, err = store.GetWebhook(t.Context(), hook.ID)
+	require.NoError(t, err)
+	assert.True(t, got.Active)
+	assert.Equal(t, "https://example.com/c", got.URL)
+
+	hooks, err := store.ListWebhooks(t.Context())
+	require.NoError(t, err)
+	require.Len(t, hooks, 2)
+	assert.Equal(t, hook.ID, hooks[0].ID)
+
+	require.NoError(t, store.DeleteWebhook(t.Context(), hook.ID))
+	_, err = store.GetWebhook(t.Context(), hook.ID)
+	assert.ErrorIs(t, err, storage.ErrWebhookNotFound)
+	assert.ErrorIs(t, store.DeleteWebhook(t.Context(), hook.ID), storage.ErrWebhookNotFound)
+	assert.ErrorIs(t, store.UpdateWebhook(t.Context(), hook), storage.ErrWebhookNotFound)
+}
+
+func testWebhookDeliveries(t *testing.T, store storage.Store) {
+	hook := &models.Webhook{URL: "https://example.com", Secret: "secret", Events: "user.created", Active: true}
+	require.NoError(t, store.CreateWebhook(t.Context(), hook))
+
+	delivery := func(eventID string) models.WebhookDelivery {
+		return models.WebhookDelivery{
+			WebhookID:     hook.ID,
+			EventID:       eventID,
+			EventType:     "user.created",
+			Payload:       `{"id":"` + eventID + `"}`,
+			Status:        models.DeliveryPending,
+			NextAttemptAt: time.Now(),
+		}
+	}
+	require.NoError(t, store.EnqueueDeliveries(t.Context(), []models.WebhookDelivery{delivery("e1"), delivery("e2")}))
+	require.NoError(t, store.EnqueueDeliveries(t.Context(), []models.WebhookDelivery{delivery("e1")}), "duplicates are skipped")
+	require.NoError(t, store.EnqueueDeliveries(t.Context(), nil))
+
+	claimed, err := store.ClaimDeliveries(t.Context(), 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2, "event is queued once per webhook")
+	assert.Equal(t, "e1", claimed[0].EventID)
+	again, err := store.ClaimDeliveries(t.Context(), 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, again, "claimed deliveries are leased")
+
+	now := time.Now()
+	d := claimed[0]
+	d.Status = models.DeliveryDelivered
+	d.Attempts = 1
+	d.ResponseStatus = 200
+	d.DeliveredAt = &now
+	require.NoError(t, store.UpdateDelivery(t.Context(), &d))
+	d = claimed[1]
+	d.Status = models.DeliveryDead
+	d.Attempts = 3
+	d.ResponseStatus = 500
+	d.LastError = "webhook responded with 500"
+	require.NoError(t, store.UpdateDelivery(t.Context(), &d))
+
+	got, err := store.GetDelivery(t.Context(), d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliveryDead, got.Status)
+	assert.Equal(t, 3, got.Attempts)
+	assert.Equal(t, "webhook responded with 500", got.LastError)
+	_, err = store.GetDelivery(t.Context(), d.ID+100)
+	assert.ErrorIs(t, err, storage.ErrDeliveryNotFound)
+
+	all, err := store.ListDeliveries(t.Context(), hook.ID, "", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "e2", all[0].EventID, "newest first")
+	dead, err := store.ListDeliveries(t.Context(), hook.ID, models.DeliveryDead, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, "e2", dead[0].EventID)
+	page, err := store.ListDeliveries(t.Context(), hook.ID, "", 1, 10)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, "e1", page[0].EventID)
+
+	require.NoError(t, store.DeleteWebhook(t.Context(), hook.ID))
+	_, err = store.GetDelivery(t.Context(), d.ID)
+	assert.ErrorIs(t, err, storage.ErrDeliveryNotFound, "deliveries are deleted with the webhook")
+}
+
+func testDataExports(t *testing.T, store storage.Store) {
+	export := &models.DataExport{UserID: 1, Format: "json", Status: models.ExportPending}
+	require.NoError(t, store.CreateDataExport(t.Context(), export))
+	require.NoError(t, store.CreateDataExport(t.Context(), &models.DataExport{UserID: 2, Format: "zip", Status: models.ExportPending}))
+
+	pending, err := store.PendingDataExports(t.Context(), 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, export.ID, pending[0].ID)
+
+	now := time.Now()
+	expires := now.Add(time.Hour)
+	export.Status = models.ExportReady
+	export.Archive = []byte(`{"profile":{}}`)
+	export.CompletedAt = &now
+	export.ExpiresAt = &expires
+	require.NoError(t, store.CompleteDataExport(t.Context(), export))
+
+	got, err := store.GetDataExport(t.Context(), export.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExportReady, got.Status)
+	assert.Equal(t, []byte(`{"profile":{}}`), got.Archive)
+	list, err := store.ListDataExports(t.Context(), 1)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Empty(t, list[0].Archive, "list does not load archives")
+	pending, err = store.PendingDataExports(t.Context(), 10)
+	require.NoError(t, err)
+	assert.Len(t, pending, 1)
+
+	deleted, err := store.DeleteExpiredDataExports(t.Context(), now)
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+	deleted, err = store.DeleteExpiredDataExports(t.Context(), expires.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	_, err = store.GetDataExport(t.Context(), export.ID)
+	assert.ErrorIs(t, err, storage.ErrDataExportNotFound)
+
+	user := newUser("alice")
+	require.NoError(t, store.CreateUser(t.Context(), user))
+	require.NoError(t, store.CreateDataExport(t.Context(), &models.DataExport{UserID: user.ID, Format: "json", Status: models.ExportPending}))
+	require.NoError(t, store.DeleteUser(t.Context(), user.ID, 0, ""))
+	_, err = store.PurgeDeletedUsers(t.Context(), time.Now().Add(time.Second))
+	require.NoError(t, err)
+	list, err = store.ListDataExports(t.Context(), user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, list, "purge removes the archives of the user")
+	list, err = store.ListDataExports(t.Context(), 2)
+	require.NoError(t, err)
+	assert.Len(t, list, 1, "other users keep theirs")
+}
+
+func testErasureRequests(t *testing.T, store storage.Store) {
+	req := &models.ErasureRequest{UserID: 1, Reason: "leaving"}
+	require.NoError(t, store.CreateErasureRequest(t.Context(), req))
+	assert.Equal(t, models.ErasurePending, req.Status)
+	assert.ErrorIs(t, store.CreateErasureRequest(t.Context(), &models.ErasureRequest{UserID: 1}), storage.ErrErasurePending)
+	require.NoError(t, store.CreateErasureRequest(t.Context(), &models.ErasureRequest{UserID: 2}))
+
+	rejected, err := store.RejectErasureRequest(t.Context(), req.ID, 9, "account has open orders")
+	require.NoError(t, err)
+	assert.Equal(t, models.ErasureRejected, rejected.Status)
+	assert.Equal(t, uint(9), *rejected.ReviewedBy)
+	_, err = store.RejectErasureRequest(t.Context(), req.ID, 9, "")
+	assert.ErrorIs(t, err, storage.ErrErasureNotPending)
+	_, err = store.EraseUser(t.Context(), req.ID, 9, "")
+	assert.ErrorIs(t, err, storage.ErrErasureNotPending)
+	_, err = store.GetErasureRequest(t.Context(), 100)
+	assert.ErrorIs(t, err, storage.ErrErasureRequestNotFound)
+
+	require.NoError(t, store.CreateErasureRequest(t.Context(), &models.ErasureRequest{UserID: 1}), "rejected request does not block a new one")
+	all, err := store.ListErasureRequests(t.Context(), "", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, uint(1), all[0].UserID, "newest first")
+	pending, err := store.ListErasureRequests(t.Context(), models.ErasurePending, 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, pending, 2)
+	page, err := store.ListErasureRequests(t.Context(), "", 2, 10)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, req.ID, page[0].ID)
+}
+
+func testEraseUser(t *testing.T, store storage.Store) {
+	user := newUser("alice")
+	require.NoError(t, store.CreateUser(t.Context(), user))
+	require.NoError(t, store.SaveProfile(t.Context(), &models.Profile{UserID: user.ID, DisplayName: "Alice"}))
+	require.NoError(t, store.DeleteUser(t.Context(), user.ID, 7, "asked by alice@example.com"))
+	bob := newUser("bob")
+	require.NoError(t, store.CreateUser(t.Context(), bob))
+
+	hook := &models.Webhook{URL: "https://example.com", Secret: "secret", Events: "user.created", Active: true}
+	require.NoError(t, store.CreateWebhook(t.Context(), hook))
+	require.NoError(t, store.EnqueueDeliveries(t.Context(), []models.WebhookDelivery{{
+		WebhookID:     hook.ID,
+		EventID:       "user.created:1:1",
+		EventType:     "user.created",
+		UserID:        user.ID,
+		Payload:       `{"id":"user.created:1:1","type":"user.created","userId":1,"data":{"id":1,"username":"alice","email":"alice@example.com","version":1}}`,
+		Status:        models.DeliveryDelivered,
+		NextAttemptAt: time.Now(),
+	}}))
+	require.NoError(t, store.CreateDataExport(t.Context(), &models.DataExport{UserID: user.ID, Format: "json", Status: models.ExportPending}))
+
+	req := &models.ErasureRequest{UserID: user.ID, Reason: "I am alice"}
+	require.NoError(t, store.CreateErasureRequest(t.Context(), req))
+	done, err := store.EraseUser(t.Context(), req.ID, 9, "approved")
+	require.NoError(t, err)
+	assert.Equal(t, models.ErasureCompleted, done.Status)
+	assert.NotNil(t, done.CompletedAt)
+	assert.Empty(t, done.Reason)
+	assert.Equal(t, int64(2), done.ScrubbedEvents, "created and deleted events")
+	assert.Equal(t, int64(1), done.ScrubbedDeliveries)
+
+	got, err := store.GetErasureRequest(t.Context(), req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ErasureCompleted, got.Status)
+	assert.Equal(t, int64(2), got.ScrubbedEvents)
+
+	_, err = store.GetUserByUsername(t.Context(), "alice")
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+	// пользователь удалён мягко, поэтому ищем среди удалённых
+	deleted, err := store.GetDeletedUsers(t.Context(), 0, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	erased := deleted[0]
+	assert.Equal(t, user.ID, erased.ID, "id is kept for references")
+	assert.Equal(t, fmt.Sprintf("erased-%d", user.ID), erased.Username)
+	assert.Equal(t, fmt.Sprintf("erased-%d@erased.invalid", user.ID), erased.Email)
+	assert.Empty(t, erased.Country)
+	assert.Empty(t, erased.DeleteReason)
+	assert.NotNil(t, erased.ErasedAt)
+	assert.Greater(t, erased.TokenVersion, user.TokenVersion, "outstanding tokens are revoked")
+
+	evs, err := store.ListUserEvents(t.Context(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, evs, 3)
+	for _, e := range evs {
+		assert.NotContains(t, e.Payload, "alice")
+		assert.NotContains(t, e.Payload, "Testland")
+	}
+	assert.Equal(t, "user.erased", evs[2].Type)
+	deliveries, err := store.ListDeliveries(t.Context(), hook.ID, "", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.NotContains(t, deliveries[0].Payload, "alice")
+	assert.Contains(t, deliveries[0].Payload, `"userId":1`)
+	exports, err := store.ListDataExports(t.Context(), user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, exports)
+
+	other, err := store.GetUserByUsername(t.Context(), "bob")
+	require.NoError(t, err)
+	assert.Equal(t, bob.Email, other.Email, "other users are untouched")
+	bobEvents, err := store.ListUserEvents(t.Context(), bob.ID)
+	require.NoError(t, err)
+	require.Len(t, bobEvents, 1)
+	assert.Contains(t, bobEvents[0].Payload, "bob@example.com")
+}
+
+func testOrganizations(t *testing.T, store storage.Store) {
+	acme := &models.Organization{Name: "acme"}
+	require.NoError(t, store.CreateOrganization(t.Context(), acme))
+	assert.NotZero(t, acme.ID)
+	assert.ErrorIs(t, store.CreateOrganization(t.Context(), &models.Organization{Name: "acme"}), storage.ErrOrgExists)
+	globex := &models.Organization{Name: "globex"}
+	require.NoError(t, store.CreateOrganization(t.Context(), globex))
+
+	got, err := store.GetOrganization(t.Context(), acme.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", got.Name)
+	_, err = store.GetOrganization(t.Context(), 100)
+	assert.ErrorIs(t, err, storage.ErrOrgNotFound)
+
+	orgs, err := store.ListOrganizations(t.Context(), 0, 10)
+	require.NoError(t, err)
+	require.Len(t, orgs, 2)
+	assert.Equal(t, acme.ID, orgs[0].ID)
+	orgs, err = store.ListOrganizations(t.Context(), 1, 10)
+	require.NoError(t, err)
+	require.Len(t, orgs, 1)
+	assert.Equal(t, globex.ID, orgs[0].ID)
+
+	user := newUser("alice")
+	require.NoError(t, store.CreateUser(t.Context(), user))
+	require.NoError(t, store.SetMembership(t.Context(), &models.Membership{OrgID: acme.ID, UserID: user.ID, Role: "user"}))
+	assert.ErrorIs(t, store.DeleteOrganization(t.Context(), acme.ID), storage.ErrOrgNotEmpty)
+	require.NoError(t, store.DeleteOrganization(t.Context(), globex.ID))
+	assert.ErrorIs(t, store.DeleteOrganization(t.Context(), globex.ID), storage.ErrOrgNotFound)
+	_, err = store.GetOrganization(t.Context(), globex.ID)
+	assert.ErrorIs(t, err, storage.ErrOrgNotFound)
+}
+
+func testMemberships(t *testing.T, store storage.Store) {
+	acme := &models.Organization{Name: "acme"}
+	require.NoError(t, store.CreateOrganization(t.Context(), acme))
+	globex := &models.Organization{Name: "globex"}
+	require.NoError(t, store.CreateOrganization(t.Context(), globex))
+	alice := newUser("alice")
+	require.NoError(t, store.CreateUser(t.Context(), alice))
+	bob := newUser("bob")
+	require.NoError(t, store.CreateUser(t.Context(), bob))
+
+	assert.ErrorIs(t, store.SetMembership(t.Context(), &models.Membership{OrgID: 100, UserID: alice.ID, Role: "user"}), storage.ErrOrgNotFound)
+	assert.ErrorIs(t, store.SetMembership(t.Context(), &models.Membership{OrgID: acme.ID, UserID: 100, Role: "user"}), storage.ErrUserNotFound)
+
+	require.NoError(t, store.SetMembership(t.Context(), &models.Membership{OrgID: acme.ID, UserID: bob.ID, Role: "user"}))
+	require.NoError(t, store.SetMembership(t.Context(), &models.Membership{OrgID: acme.ID, UserID: alice.ID, Role: "user"}))
+	require.NoError(t, store.SetMembership(t.Context(), &models.Membership{OrgID: globex.ID, UserID: alice.ID, Role: "admin"}))
+	before, err := store.GetUserByID(t.Context(), alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, alice.TokenVersion, before.TokenVersion, "joining does not revoke tokens")
+
+	m, err := store.GetMembership(t.Context(), globex.ID, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", m.Role)
+	_, err = store.GetMembership(t.Context(), globex.ID, bob.ID)
+	assert.ErrorIs(t, err, storage.ErrMembershipNotFound)
+
+	members, err := store.ListMembers(t.Context(), acme.ID, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, alice.ID, members[0].UserID, "ordered by user id")
+	assert.Equal(t, bob.ID, members[1].UserID)
+	members, err = store.ListMembers(t.Context(), acme.ID, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	assert.Equal(t, bob.ID, members[0].UserID)
+	_, err = store.ListMembers(t.Context(), 100, 0, 10)
+	assert.ErrorIs(t, err, storage.ErrOrgNotFound)
+
+	mine, err := store.ListUserMemberships(t.Context(), alice.ID)
+	require.NoError(t, err)
+	require.Len(t, mine, 2)
+	require.NotNil(t, mine[0].Organization)
+	assert.Equal(t, "acme", mine[0].Organization.Name)
+	assert.Equal(t, "globex", mine[1].Organization.Name)
+	assert.Equal(t, "admin", mine[1].Role)
+
+	require.NoError(t, store.SetMembership(t.Context(), &models.Membership{OrgID: acme.ID, UserID: alice.ID, Role: "admin"}))
+	promoted, err := store.GetUserByID(t.Context(), alice.ID)
+	require.NoError(t, err)
+	assert.Greater(t, promoted.TokenVersion, before.TokenVersion, "role change revokes tokens")
+	m, err = store.GetMembership(t.Context(), acme.ID, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", m.Role)
+
+	require.NoError(t, store.RemoveMembership(t.Context(), acme.ID, alice.ID))
+	removed, err := store.GetUserByID(t.Context(), alice.ID)
+	require.NoError(t, err)
+	assert.Greater(t, removed.TokenVersion, promoted.TokenVersion, "removal revokes tokens")
+	assert.ErrorIs(t, store.RemoveMembership(t.Context(), acme.ID, alice.ID), storage.ErrMembershipNotFound)
+	mine, err = store.ListUserMemberships(t.Context(), alice.ID)
+	require.NoError(t, err)
+	require.Len(t, mine, 1)
+	assert.Equal(t, globex.ID, mine[0].OrgID)
+}
+
+func testTenantScopedQueries(t *testing.T, store storage.Store) {
+	seedUsers(t, store)
+	acme := &models.Organization{Name: "acme"}
+	require.NoError(t, store.CreateOrganization(t.Context(), acme))
+	for _, name := range []string{"alice", "bob", "erin"} {
+		user, err := store.GetUserByUsername(t.Context(), name)
+		require.NoError(t, err)
+		require.NoError(t, store.SetMembership(t.Context(), &models.Membership{OrgID: acme.ID, UserID: user.ID, Role: "user"}))
+	}
+
+	page, err := store.ListUsers(t.Context(), storage.UserQuery{
+		Filter:    storage.UserFilter{OrgID: acme.ID},
+		SortField: "username",
+		WithTotal: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob", "erin"}, usernames(page.Users))
+	require.NotNil(t, page.Total)
+	assert.Equal(t, int64(3), *page.Total)
+
+	page, err = store.ListUsers(t.Context(), storage.UserQuery{
+		Filter:    storage.UserFilter{OrgID: acme.ID, Country: "DE"},
+		SortField: "username",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "erin"}, usernames(page.Users))
+
+	matches, err := store.SearchUsers(t.Context(), "dave", acme.ID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, matches, "dave is not a member")
+	matches, err = store.SearchUsers(t.Context(), "erin", acme.ID, 10)
+	require.NoError(t, err)
+	require.NotEmpty(t, matches)
+	assert.Equal(t, "erin", matches[0].User.Username)
+
+	erin, err := store.GetUserByUsername(t.Context(), "erin")
+	require.NoError(t, err)
+	dave, err := store.GetUserByUsername(t.Context(), "dave")
+	require.NoError(t, err)
+	require.NoError(t, store.DeleteUser(t.Context(), erin.ID, 0, ""))
+	require.NoError(t, store.DeleteUser(t.Context(), dave.ID, 0, ""))
+	deleted, err := store.GetDeletedUsers(t.Context(), acme.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"erin"}, usernames(deleted))
+
+	purged, err := store.PurgeDeletedUsers(t.Context(), time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+	_, err = store.GetMembership(t.Context(), acme.ID, erin.ID)
+	assert.ErrorIs(t, err, storage.ErrMembershipNotFound, "purge removes memberships")
+	members, err := store.ListMembers(t.Context(), acme.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+}
+
+func testGroups(t *testing.T, store storage.Store) {
+	staff := &models.Group{Name: "staff", Permissions: "dishes:read"}
+	require.NoError(t, store.CreateGroup(t.Context(), staff))
+	assert.NotZero(t, staff.ID)
+	assert.ErrorIs(t, store.CreateGroup(t.Context(), &models.Group{Name: "staff"}), storage.ErrGroupExists)
+	missing := uint(100)
+	assert.ErrorIs(t, store.CreateGroup(t.Context(), &models.Group{Name: "orphans", ParentID: &missing}), storage.ErrGroupParentNotFound)
+
+	editors := &models.Group{Name: "editors", ParentID: &staff.ID, Roles: "creator"}
+	require.NoError(t, store.CreateGroup(t.Context(), editors))
+	seniors := &models.Group{Name: "seniors", ParentID: &editors.ID}
+	require.NoError(t, store.CreateGroup(t.Context(), seniors))
+
+	got, err := store.GetGroup(t.Context(), editors.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "editors", got.Name)
+	require.NotNil(t, got.ParentID)
+	assert.Equal(t, staff.ID, *got.ParentID)
+	assert.Equal(t, []string{"creator"}, got.RoleList())
+	_, err = store.GetGroup(t.Context(), 100)
+	assert.ErrorIs(t, err, storage.ErrGroupNotFound)
+
+	staff.ParentID = &seniors.ID
+	assert.ErrorIs(t, store.UpdateGroup(t.Context(), staff), storage.ErrGroupCycle, "staff would inherit from itself")
+	staff.ParentID = &staff.ID
+	assert.ErrorIs(t, store.UpdateGroup(t.Context(), staff), storage.ErrGroupCycle)
+	staff.ParentID = nil
+	staff.Name = "editors"
+	assert.ErrorIs(t, store.UpdateGroup(t.Context(), staff), storage.ErrGroupExists)
+	staff.Name = "everyone"
+	staff.Description = "all employees"
+	staff.SetPermissions([]string{"dishes:read", "dishes:comment"})
+	require.NoError(t, store.UpdateGroup(t.Context(), staff))
+	assert.ErrorIs(t, store.UpdateGroup(t.Context(), &models.Group{ID: 100, Name: "ghosts"}), storage.ErrGroupNotFound)
+
+	got, err = store.GetGroup(t.Context(), staff.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "everyone", got.Name)
+	assert.Equal(t, "all employees", got.Description)
+	assert.Equal(t, []string{"dishes:read", "dishes:comment"}, got.PermissionList())
+	assert.Nil(t, got.ParentID)
+
+	groups, err := store.ListGroups(t.Context())
+	require.NoError(t, err)
+	require.Len(t, groups, 3)
+	assert.Equal(t, staff.ID, groups[0].ID)
+
+	assert.ErrorIs(t, store.DeleteGroup(t.Context(), editors.ID), storage.ErrGroupHasChildren)
+	require.NoError(t, store.DeleteGroup(t.Context(), seniors.ID))
+	require.NoError(t, store.DeleteGroup(t.Context(), editors.ID))
+	assert.ErrorIs(t, store.DeleteGroup(t.Context(), editors.ID), storage.ErrGroupNotFound)
+}
+
+func testGroupMembers(t *testing.T, store storage.Store) {
+	staff := &models.Group{Name: "staff"}
+	require.NoError(t, store.CreateGroup(t.Context(), staff))
+	editors := &models.Group{Name: "editors", ParentID: &staff.ID}
+	require.NoError(t, store.CreateGroup(t.Context(), editors))
+	alice := newUser("alice")
+	require.NoError(t, store.CreateUser(t.Context(), alice))
+	bob := newUser("bob")
+	require.NoError(t, store.CreateUser(t.Context(), bob))
+
+	assert.ErrorIs(t, store.AddGroupMember(t.Context(), 100, alice.ID), storage.ErrGroupNotFound)
+	assert.ErrorIs(t, store.AddGroupMember(t.Context(), staff.ID, 100), storage.ErrUserNotFound)
+	require.NoError(t, store.AddGroupMember(t.Context(), staff.ID, bob.ID))
+	require.NoError(t, store.AddGroupMember(t.Context(), staff.ID, alice.ID))
+	require.NoError(t, store.AddGroupMember(t.Context(), staff.ID, alice.ID), "adding again changes nothing")
+	require.NoError(t, store.AddGroupMember(t.Context(), editors.ID, alice.ID))
+
+	members, err := store.ListGroupMembers(t.Context(), staff.ID, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, alice.ID, members[0].UserID, "ordered by user id")
+	assert.Equal(t, bob.ID, members[1].UserID)
+	members, err = store.ListGroupMembers(t.Context(), staff.ID, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	_, err = store.ListGroupMembers(t.Context(), 100, 0, 10)
+	assert.ErrorIs(t, err, storage.ErrGroupNotFound)
+
+	groups, err := store.ListUserGroups(t.Context(), alice.ID)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	assert.Equal(t, "staff", groups[0].Name)
+	assert.Equal(t, "editors", groups[1].Name)
+
+	require.NoError(t, store.RemoveGroupMember(t.Context(), staff.ID, alice.ID))
+	assert.ErrorIs(t, store.RemoveGroupMember(t.Context(), staff.ID, alice.ID), storage.ErrGroupMemberNotFound)
+	groups, err = store.ListUserGroups(t.Context(), alice.ID)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, editors.ID, groups[0].ID)
+
+	require.NoError(t, store.DeleteGroup(t.Context(), editors.ID))
+	groups, err = store.ListUserGroups(t.Context(), alice.ID)
+	require.NoError(t, err)
+	assert.Empty(t, groups, "deleting a group removes its members")
+
+	require.NoError(t, store.DeleteUser(t.Context(), bob.ID, 0, ""))
+	_, err = store.PurgeDeletedUsers(t.Context(), time.Now().Add(time.Second))
+	require.NoError(t, err)
+	members, err = store.ListGroupMembers(t.Context(), staff.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, members, "purge removes group members")
+}
+
+func testProfiles(t *testing.T, store storage.Store) {
+	user := newUser("alice")
+	require.NoError(t, store.CreateUser(t.Context(), user))
+
+	_, err := store.GetProfile(t.Context(), 100)
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+	assert.ErrorIs(t, store.SaveProfile(t.Context(), &models.Profile{UserID: 100}), storage.ErrUserNotFound)
+
+	empty, err := store.GetProfile(t.Context(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, empty.UserID)
+	assert.Empty(t, empty.DisplayName)
+	assert.NotNil(t, empty.Attributes, "a profile that was never saved has no attributes")
+
+	p := &models.Profile{
+		UserID:      user.ID,
+		DisplayName: "Alice",
+		TimeZone:    "Europe/Berlin",
+		Attributes:  map[string]interface{}{"team": "core", "experience": 3.0, "newsletter": true},
+	}
+	p.SetLearningLanguages([]models.LanguageSkill{{Language: "de", Level: "B1"}})
+	require.NoError(t, store.SaveProfile(t.Context(), p))
+	created := p.CreatedAt
+
+	got, err := store.GetProfile(t.Context(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", got.DisplayName)
+	assert.Equal(t, "Europe/Berlin", got.TimeZone)
+	assert.Equal(t, []models.LanguageSkill{{Language: "de", Level: "B1"}}, got.LearningLanguageList())
+	assert.Equal(t, map[string]interface{}{"team": "core", "experience": 3.0, "newsletter": true}, got.Attributes)
+
+	got.Attributes["team"] = "changed"
+	again, err := store.GetProfile(t.Context(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "core", again.Attributes["team"], "returned profiles are copies")
+
+	p.DisplayName = "Al"
+	p.Attributes = map[string]interface{}{}
+	require.NoError(t, store.SaveProfile(t.Context(), p))
+	got, err = store.GetProfile(t.Context(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Al", got.DisplayName)
+	assert.Empty(t, got.Attributes, "saving replaces the whole profile")
+	assert.WithinDuration(t, created, got.CreatedAt, time.Second)
+
+	require.NoError(t, store.DeleteUser(t.Context(), user.ID, 0, ""))
+	_, err = store.GetProfile(t.Context(), user.ID)
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+	_, err = store.PurgeDeletedUsers(t.Context(), time.Now().Add(time.Second))
+	require.NoError(t, err)
+}
+
+func testAttributeDefinitions(t *testing.T, store storage.Store) {
+	maxLen := 20
+	team := &models.AttributeDefinition{Key: "team", Type: models.AttributeString, MaxLength: &maxLen, UserEditable: true}
+	require.NoError(t, store.CreateAttributeDefinition(t.Context(), team))
+	size := &models.AttributeDefinition{Key: "size", Type: models.AttributeEnum, Enum: "S,M,L", Required: true}
+	require.NoError(t, store.CreateAttributeDefinition(t.Context(), size))
+	assert.ErrorIs(t, store.CreateAttributeDefinition(t.Context(), &models.AttributeDefinition{Key: "team", Type: models.AttributeBoolean}),
+		storage.ErrAttributeExists)
+
+	defs, err := store.ListAttributeDefinitions(t.Context())
+	require.NoError(t, err)
+	require.Len(t, defs, 2)
+	assert.Equal(t, "size", defs[0].Key, "ordered by key")
+	assert.Equal(t, []string{"S", "M", "L"}, defs[0].EnumList())
+	assert.True(t, defs[0].Required)
+	require.NotNil(t, defs[1].MaxLength)
+	assert.Equal(t, 20, *defs[1].MaxLength)
+	assert.True(t, defs[1].UserEditable)
+
+	team.Description = "Team name"
+	team.MaxLength = nil
+	require.NoError(t, store.UpdateAttributeDefinition(t.Context(), team))
+	assert.Nil(t, team.MaxLength)
+	assert.Equal(t, "Team name", team.Description)
+	assert.ErrorIs(t, store.UpdateAttributeDefinition(t.Context(), &models.AttributeDefinition{Key: "missing", Type: models.AttributeString}),
+		storage.ErrAttributeNotFound)
+
+	require.NoError(t, store.DeleteAttributeDefinition(t.Context(), "size"))
+	assert.ErrorIs(t, store.DeleteAttributeDefinition(t.Context(), "size"), storage.ErrAttributeNotFound)
+	defs, err = store.ListAttributeDefinitions(t.Context())
+	require.NoError(t, err)
+	require.Len(t, defs, 1)
+	assert.Nil(t, defs[0].MaxLength)
+}
+
+func testRegistrationCounts(t *testing.T, store storage.Store) {
+	alice := newUser("alice")
+	alice.Country = "NO"
+	require.NoError(t, store.CreateUser(t.Context(), alice))
+	bob := newUser("bob")
+	bob.Country = "Norway"
+	bob.Role = "admin"
+	bob.Verified = true
+	require.NoError(t, store.CreateUser(t.Context(), bob))
+	require.NoError(t, store.CreateUser(t.Context(), newUser("carol")))
+	require.NoError(t, store.DeleteUser(t.Context(), bob.ID, 0, ""))
+	acme := &models.Organization{Name: "acme"}
+	require.NoError(t, store.CreateOrganization(t.Context(), acme))
+	require.NoError(t, store.SetMembership(t.Context(), &models.Membership{OrgID: acme.ID, UserID: alice.ID, Role: "admin"}))
+
+	now := time.Now()
+	q := storage.StatsQuery{From: now.Add(-time.Hour), To: now.Add(time.Hour), Interval: storage.IntervalDay}
+	counts, err := store.RegistrationCounts(t.Context(), q)
+	require.NoError(t, err)
+	today := storage.StatsBucket(alice.CreatedAt, storage.IntervalDay)
+	assert.Equal(t, []storage.RegistrationCount{
+		{Bucket: today, Country: "", Role: "user", Total: 1},
+		{Bucket: today, Country: "NO", Role: "admin", Total: 1, Verified: 1},
+		{Bucket: today, Country: "NO", Role: "user", Total: 1},
+	}, counts, "countries are normalized, deleted users still count")
+
+	q.Interval = storage.IntervalWeek
+	q.OrgID = acme.ID
+	counts, err = store.RegistrationCounts(t.Context(), q)
+	require.NoError(t, err)
+	assert.Equal(t, []storage.RegistrationCount{
+		{Bucket: storage.StatsBucket(alice.CreatedAt, storage.IntervalWeek), Country: "NO", Role: "admin", Total: 1},
+	}, counts, "role inside the organization")
+
+	q.From, q.OrgID = now.Add(time.Minute), 0
+	counts, err = store.RegistrationCounts(t.Context(), q)
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+}
+
+func testLoginCounts(t *testing.T, store storage.Store) {
+	alice := newUser("alice")
+	require.NoError(t, store.CreateUser(t.Context(), alice))
+	bob := newUser("bob")
+	require.NoError(t, store.CreateUser(t.Context(), bob))
+	acme := &models.Organization{Name: "acme"}
+	require.NoError(t, store.CreateOrganization(t.Context(), acme))
+	require.NoError(t, store.SetMembership(t.Context(), &models.Membership{OrgID: acme.ID, UserID: alice.ID, Role: "user"}))
+
+	// 2 марта 2026 - понедельник
+	at := func(day, hour int) time.Time { return time.Date(2026, time.March, day, hour, 0, 0, 0, time.UTC) }
+	for _, l := range []models.Login{
+		{UserID: alice.ID, Succeeded: true, CreatedAt: at(2, 10)},
+		{UserID: bob.ID, Succeeded: false, CreatedAt: at(2, 11)},
+		{UserID: alice.ID, Succeeded: true, CreatedAt: at(2, 12)},
+		{UserID: 0, Succeeded: false, CreatedAt: at(3, 9)},
+		{UserID: bob.ID, Succeeded: true, CreatedAt: at(3, 23)},
+		{UserID: alice.ID, Succeeded: true, CreatedAt: at(9, 8)},
+		{UserID: alice.ID, Succeeded: true, CreatedAt: at(10, 8)},
+	} {
+		require.NoError(t, store.RecordLogin(t.Context(), &l))
+	}
+
+	q := storage.StatsQuery{From: at(2, 0), To: at(10, 0), Interval: storage.IntervalDay}
+	counts, err := store.LoginCounts(t.Context(), q)
+	require.NoError(t, err)
+	assert.Equal(t, []storage.LoginCount{
+		{Bucket: "2026-03-02", Succeeded: 2, Failed: 1, ActiveUsers: 1},
+		{Bucket: "2026-03-03", Succeeded: 1, Failed: 1, ActiveUsers: 1},
+		{Bucket: "2026-03-09", Succeeded: 1, ActiveUsers: 1},
+	}, counts)
+
+	q.Interval = storage.IntervalWeek
+	counts, err = store.LoginCounts(t.Context(), q)
+	require.NoError(t, err)
+	assert.Equal(t, []storage.LoginCount{
+		{Bucket: "2026-03-02", Succeeded: 3, Failed: 2, ActiveUsers: 2},
+		{Bucket: "2026-03-09", Succeeded: 1, ActiveUsers: 1},
+	}, counts)
+
+	q.Interval, q.OrgID = storage.IntervalDay, acme.ID
+	counts, err = store.LoginCounts(t.Context(), q)
+	require.NoError(t, err)
+	assert.Equal(t, []storage.LoginCount{
+		{Bucket: "2026-03-02", Succeeded: 2, ActiveUsers: 1},
+		{Bucket: "2026-03-09", Succeeded: 1, ActiveUsers: 1},
+	}, counts)
+
+	active, err := store.ActiveUsers(t.Context(), 0, at(2, 0), at(9, 0))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), active)
+	active, err = store.ActiveUsers(t.Context(), acme.ID, at(2, 0), at(9, 0))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), active)
+
+	deleted, err := store.DeleteLogins(t.Context(), at(3, 0))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+
+	require.NoError(t, store.DeleteUser(t.Context(), bob.ID, 0, ""))
+	_, err = store.PurgeDeletedUsers(t.Context(), time.Now().Add(time.Second))
+	require.NoError(t, err)
+	counts, err = store.LoginCounts(t.Context(), storage.StatsQuery{From: at(1, 0), To: at(11, 0), Interval: storage.IntervalWeek})
+	require.NoError(t, err)
+	assert.Equal(t, []storage.LoginCount{
+		{Bucket: "2026-03-02", Failed: 1},
+		{Bucket: "2026-03-09", Succeeded: 2, ActiveUsers: 1},
+	}, counts, "purged users lose their logins")
+}
+
+func testCaseInsensitiveUsers(t *testing.T, repo storage.UserRepository) {
+	alice := newUser("Alice")
+	alice.Email = " Alice@Example.COM"
+	require.NoError(t, repo.CreateUser(t.Context(), alice))
+	assert.Equal(t, "Alice", alice.Username, "the username keeps its case")
+	assert.Equal(t, "alice@example.com", alice.Email)
+
+	dup := newUser("ALICE")
+	assert.ErrorIs(t, repo.CreateUser(t.Context(), dup), storage.ErrUserExists)
+	dup = newUser("alice2")
+	dup.Email = "ALICE@example.com"
+	assert.ErrorIs(t, repo.CreateUser(t.Context(), dup), storage.ErrUserExists)
+
+	got, err := repo.GetUserByUsername(t.Context(), "aLiCe")
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, got.ID)
+	got, err = repo.GetUserByEmail(t.Context(), "ALICE@EXAMPLE.com")
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, got.ID)
+	assert.Equal(t, "alice@example.com", got.Email)
+
+	renamed := "ALICE"
+	got, err = repo.PatchUser(t.Context(), alice.ID, got.Version, storage.UserPatch{Username: &renamed})
+	require.NoError(t, err, "a user can change the case of their own username")
+	assert.Equal(t, "ALICE", got.Username)
+
+	bob := newUser("bob")
+	require.NoError(t, repo.CreateUser(t.Context(), bob))
+	taken := "alice"
+	_, err = repo.PatchUser(t.Context(), bob.ID, bob.Version, storage.UserPatch{Username: &taken})
+	assert.ErrorIs(t, err, storage.ErrUserExists)
+	email := "Bob@Example.org"
+	got, err = repo.PatchUser(t.Context(), bob.ID, bob.Version, storage.UserPatch{Email: &email})
+	require.NoError(t, err)
+	assert.Equal(t, "bob@example.org", got.Email)
+
+	errs, err := repo.ImportUsers(t.Context(), []*models.User{newUser("BOB"), newUser("carol"), newUser("CAROL")}, false)
+	require.NoError(t, err)
+	assert.ErrorIs(t, errs[0], storage.ErrUserExists)
+	assert.NoError(t, errs[1])
+	assert.ErrorIs(t, errs[2], storage.ErrUserExists, "duplicates inside the batch")
+}
+
+func testEmailChanges(t *testing.T, store storage.Store) {
+	alice := newUser("alice")
+	require.NoError(t, store.CreateUser(t.Context(), alice))
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	assert.ErrorIs(t, store.SaveEmailChange(t.Context(), &models.EmailChange{UserID: 100, NewEmail: "x@example.com", TokenHash: "h0", ExpiresAt: expires}), storage.ErrUserNotFound)
+	require.NoError(t, store.SaveEmailChange(t.Context(), &models.EmailChange{UserID: alice.ID, NewEmail: "new@example.com", TokenHash: "h1", ExpiresAt: expires}))
+	change, err := store.GetEmailChange(t.Context(), "h1")
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, change.UserID)
+	assert.Equal(t, "new@example.com", change.NewEmail)
+	assert.True(t, change.ExpiresAt.Equal(expires))
+
+	require.NoError(t, store.SaveEmailChange(t.Context(), &models.EmailChange{UserID: alice.ID, NewEmail: "other@example.com", TokenHash: "h2", ExpiresAt: expires}))
+	_, err = store.GetEmailChange(t.Context(), "h1")
+	assert.ErrorIs(t, err, storage.ErrEmailChangeNotFound, "a new request replaces the previous token")
+	change, err = store.GetEmailChange(t.Context(), "h2")
+	require.NoError(t, err)
+	assert.Equal(t, "other@example.com", change.NewEmail)
+
+	require.NoError(t, store.DeleteEmailChange(t.Context(), alice.ID))
+	assert.ErrorIs(t, store.DeleteEmailChange(t.Context(), alice.ID), storage.ErrEmailChangeNotFound)
+	_, err = store.GetEmailChange(t.Context(), "h2")
+	assert.ErrorIs(t, err, storage.ErrEmailChangeNotFound)
+
+	bob := newUser("bob")
+	require.NoError(t, store.CreateUser(t.Context(), bob))
+	require.NoError(t, store.SaveEmailChange(t.Context(), &models.EmailChange{UserID: alice.ID, NewEmail: "bob@example.com", TokenHash: "h3", ExpiresAt: expires}))
+	_, err = store.ConfirmEmailChange(t.Context(), "h3", time.Now())
+	assert.ErrorIs(t, err, storage.ErrUserExists)
+	_, err = store.GetEmailChange(t.Context(), "h3")
+	assert.NoError(t, err, "a failed confirmation keeps the request")
+
+	require.NoError(t, store.SaveEmailChange(t.Context(), &models.EmailChange{UserID: alice.ID, NewEmail: "confirmed@example.com", TokenHash: "h4", ExpiresAt: expires}))
+	_, err = store.ConfirmEmailChange(t.Context(), "h4", expires.Add(time.Minute))
+	assert.ErrorIs(t, err, storage.ErrEmailChangeNotFound, "an expired request cannot be confirmed")
+
+	now := time.Now()
+	user, err := store.ConfirmEmailChange(t.Context(), "h4", now)
+	require.NoError(t, err)
+	assert.Equal(t, "confirmed@example.com", user.Email)
+	assert.True(t, user.Verified)
+	require.NotNil(t, user.EmailChangedAt)
+	assert.WithinDuration(t, now, *user.EmailChangedAt, time.Second)
+	assert.Equal(t, alice.Version+1, user.Version)
+	_, err = store.ConfirmEmailChange(t.Context(), "h4", now)
+	assert.ErrorIs(t, err, storage.ErrEmailChangeNotFound, "the token is used once")
+	_, err = store.GetEmailChange(t.Context(), "h4")
+	assert.ErrorIs(t, err, storage.ErrEmailChangeNotFound)
+}
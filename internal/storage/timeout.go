@@ -0,0 +1,491 @@
+package storage
+
+import (
+	"backend-app/internal/storage/models"
+	"context"
+	"time"
+)
+
+// Timeouts ограничивает время операций с хранилищем.
+type Timeouts struct {
+	Default time.Duration
+	// PerOperation переопределяет Default, ключ - имя метода UserRepository
+	PerOperation map[string]time.Duration
+}
+
+// For возвращает таймаут операции op, 0 - без ограничения.
+func (t Timeouts) For(op string) time.Duration {
+	if d, ok := t.PerOperation[op]; ok {
+		return d
+	}
+	return t.Default
+}
+
+// WithTimeouts оборачивает repo так, что каждый вызов получает дедлайн из t.
+// Дедлайн запроса, если он раньше, сохраняется.
+func WithTimeouts(repo Store, t Timeouts) Store {
+	return &timeoutRepository{repo: repo, timeouts: t}
+}
+
+type timeoutRepository struct {
+	repo     Store
+	timeouts Timeouts
+}
+
+func (r *timeoutRepository) context(ctx context.Context, op string) (context.Context, context.CancelFunc) {
+	d := r.timeouts.For(op)
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+func (r *timeoutRepository) CreateUser(ctx context.Context, user *models.User) error {
+	ctx, cancel := r.context(ctx, "CreateUser")
+	defer cancel()
+	return r.repo.CreateUser(ctx, user)
+}
+
+func (r *timeoutRepository) ImportUsers(ctx context.Context, users []*models.User, dryRun bool) ([]error, error) {
+	ctx, cancel := r.context(ctx, "ImportUsers")
+	defer cancel()
+	return r.repo.ImportUsers(ctx, users, dryRun)
+}
+
+func (r *timeoutRepository) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	ctx, cancel := r.context(ctx, "GetUserByID")
+	defer cancel()
+	return r.repo.GetUserByID(ctx, id)
+}
+
+func (r *timeoutRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	ctx, cancel := r.context(ctx, "GetUserByUsername")
+	defer cancel()
+	return r.repo.GetUserByUsername(ctx, username)
+}
+
+func (r *timeoutRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	ctx, cancel := r.context(ctx, "GetUserByEmail")
+	defer cancel()
+	return r.repo.GetUserByEmail(ctx, email)
+}
+
+func (r *timeoutRepository) UpdateUser(ctx context.Context, user *models.User) error {
+	ctx, cancel := r.context(ctx, "UpdateUser")
+	defer cancel()
+	return r.repo.UpdateUser(ctx, user)
+}
+
+func (r *timeoutRepository) PatchUser(ctx context.Context, id uint, version uint, patch UserPatch) (*models.User, error) {
+	ctx, cancel := r.context(ctx, "PatchUser")
+	defer cancel()
+	return r.repo.PatchUser(ctx, id, version, patch)
+}
+
+func (r *timeoutRepository) SetRefreshToken(ctx context.Context, id uint, token string, expiry time.Time) error {
+	ctx, cancel := r.context(ctx, "SetRefreshToken")
+	defer cancel()
+	return r.repo.SetRefreshToken(ctx, id, token, expiry)
+}
+
+func (r *timeoutRepository) DeleteUser(ctx context.Context, id uint, deletedBy uint, reason string) error {
+	ctx, cancel := r.context(ctx, "DeleteUser")
+	defer cancel()
+	return r.repo.DeleteUser(ctx, id, deletedBy, reason)
+}
+
+func (r *timeoutRepository) RestoreUser(ctx context.Context, id uint) error {
+	ctx, cancel := r.context(ctx, "RestoreUser")
+	defer cancel()
+	return r.repo.RestoreUser(ctx, id)
+}
+
+func (r *timeoutRepository) GetAllUsers(ctx context.Context, offset int, limit int) ([]models.User, error) {
+	ctx, cancel := r.context(ctx, "GetAllUsers")
+	defer cancel()
+	return r.repo.GetAllUsers(ctx, offset, limit)
+}
+
+func (r *timeoutRepository) ListUsers(ctx context.Context, q UserQuery) (UserPage, error) {
+	ctx, cancel := r.context(ctx, "ListUsers")
+	defer cancel()
+	return r.repo.ListUsers(ctx, q)
+}
+
+func (r *timeoutRepository) SearchUsers(ctx context.Context, query string, orgID uint, limit int) ([]UserMatch, error) {
+	ctx, cancel := r.context(ctx, "SearchUsers")
+	defer cancel()
+	return r.repo.SearchUsers(ctx, query, orgID, limit)
+}
+
+func (r *timeoutRepository) GetDeletedUsers(ctx context.Context, orgID uint, offset int, limit int) ([]models.User, error) {
+	ctx, cancel := r.context(ctx, "GetDeletedUsers")
+	defer cancel()
+	return r.repo.GetDeletedUsers(ctx, orgID, offset, limit)
+}
+
+func (r *timeoutRepository) PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := r.context(ctx, "PurgeDeletedUsers")
+	defer cancel()
+	return r.repo.PurgeDeletedUsers(ctx, before)
+}
+
+func (r *timeoutRepository) AddEvents(ctx context.Context, events []models.OutboxEvent) error {
+	ctx, cancel := r.context(ctx, "AddEvents")
+	defer cancel()
+	return r.repo.AddEvents(ctx, events)
+}
+
+func (r *timeoutRepository) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	ctx, cancel := r.context(ctx, "ClaimEvents")
+	defer cancel()
+	return r.repo.ClaimEvents(ctx, limit, lease)
+}
+
+func (r *timeoutRepository) MarkPublished(ctx context.Context, id uint) error {
+	ctx, cancel := r.context(ctx, "MarkPublished")
+	defer cancel()
+	return r.repo.MarkPublished(ctx, id)
+}
+
+func (r *timeoutRepository) MarkFailed(ctx context.Context, id uint, reason string, retryAt time.Time) error {
+	ctx, cancel := r.context(ctx, "MarkFailed")
+	defer cancel()
+	return r.repo.MarkFailed(ctx, id, reason, retryAt)
+}
+
+func (r *timeoutRepository) DeletePublishedEvents(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := r.context(ctx, "DeletePublishedEvents")
+	defer cancel()
+	return r.repo.DeletePublishedEvents(ctx, before)
+}
+
+func (r *timeoutRepository) CreateWebhook(ctx context.Context, hook *models.Webhook) error {
+	ctx, cancel := r.context(ctx, "CreateWebhook")
+	defer cancel()
+	return r.repo.CreateWebhook(ctx, hook)
+}
+
+func (r *timeoutRepository) GetWebhook(ctx context.Context, id uint) (*models.Webhook, error) {
+	ctx, cancel := r.context(ctx, "GetWebhook")
+	defer cancel()
+	return r.repo.GetWebhook(ctx, id)
+}
+
+func (r *timeoutRepository) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	ctx, cancel := r.context(ctx, "ListWebhooks")
+	defer cancel()
+	return r.repo.ListWebhooks(ctx)
+}
+
+func (r *timeoutRepository) UpdateWebhook(ctx context.Context, hook *models.Webhook) error {
+	ctx, cancel := r.context(ctx, "UpdateWebhook")
+	defer cancel()
+	return r.repo.UpdateWebhook(ctx, hook)
+}
+
+func (r *timeoutRepository) DeleteWebhook(ctx context.Context, id uint) error {
+	ctx, cancel := r.context(ctx, "DeleteWebhook")
+	defer cancel()
+	return r.repo.DeleteWebhook(ctx, id)
+}
+
+func (r *timeoutRepository) EnqueueDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	ctx, cancel := r.context(ctx, "EnqueueDeliveries")
+	defer cancel()
+	return r.repo.EnqueueDeliveries(ctx, deliveries)
+}
+
+func (r *timeoutRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	ctx, cancel := r.context(ctx, "ClaimDeliveries")
+	defer cancel()
+	return r.repo.ClaimDeliveries(ctx, limit, lease)
+}
+
+func (r *timeoutRepository) GetDelivery(ctx context.Context, id uint) (*models.WebhookDelivery, error) {
+	ctx, cancel := r.context(ctx, "GetDelivery")
+	defer cancel()
+	return r.repo.GetDelivery(ctx, id)
+}
+
+func (r *timeoutRepository) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	ctx, cancel := r.context(ctx, "UpdateDelivery")
+	defer cancel()
+	return r.repo.UpdateDelivery(ctx, delivery)
+}
+
+func (r *timeoutRepository) ListDeliveries(ctx context.Context, webhookID uint, status string, offset int, limit int) ([]models.WebhookDelivery, error) {
+	ctx, cancel := r.context(ctx, "ListDeliveries")
+	defer cancel()
+	return r.repo.ListDeliveries(ctx, webhookID, status, offset, limit)
+}
+
+func (r *timeoutRepository) CreateDataExport(ctx context.Context, export *models.DataExport) error {
+	ctx, cancel := r.context(ctx, "CreateDataExport")
+	defer cancel()
+	return r.repo.CreateDataExport(ctx, export)
+}
+
+func (r *timeoutRepository) GetDataExport(ctx context.Context, id uint) (*models.DataExport, error) {
+	ctx, cancel := r.context(ctx, "GetDataExport")
+	defer cancel()
+	return r.repo.GetDataExport(ctx, id)
+}
+
+func (r *timeoutRepository) ListDataExports(ctx context.Context, userID uint) ([]models.DataExport, error) {
+	ctx, cancel := r.context(ctx, "ListDataExports")
+	defer cancel()
+	return r.repo.ListDataExports(ctx, userID)
+}
+
+func (r *timeoutRepository) PendingDataExports(ctx context.Context, limit int) ([]models.DataExport, error) {
+	ctx, cancel := r.context(ctx, "PendingDataExports")
+	defer cancel()
+	return r.repo.PendingDataExports(ctx, limit)
+}
+
+func (r *timeoutRepository) CompleteDataExport(ctx context.Context, export *models.DataExport) error {
+	ctx, cancel := r.context(ctx, "CompleteDataExport")
+	defer cancel()
+	return r.repo.CompleteDataExport(ctx, export)
+}
+
+func (r *timeoutRepository) DeleteExpiredDataExports(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := r.context(ctx, "DeleteExpiredDataExports")
+	defer cancel()
+	return r.repo.DeleteExpiredDataExports(ctx, before)
+}
+
+func (r *timeoutRepository) ListUserEvents(ctx context.Context, userID uint) ([]models.OutboxEvent, error) {
+	ctx, cancel := r.context(ctx, "ListUserEvents")
+	defer cancel()
+	return r.repo.ListUserEvents(ctx, userID)
+}
+
+func (r *timeoutRepository) CreateErasureRequest(ctx context.Context, req *models.ErasureRequest) error {
+	ctx, cancel := r.context(ctx, "CreateErasureRequest")
+	defer cancel()
+	return r.repo.CreateErasureRequest(ctx, req)
+}
+
+func (r *timeoutRepository) GetErasureRequest(ctx context.Context, id uint) (*models.ErasureRequest, error) {
+	ctx, cancel := r.context(ctx, "GetErasureRequest")
+	defer cancel()
+	return r.repo.GetErasureRequest(ctx, id)
+}
+
+func (r *timeoutRepository) ListErasureRequests(ctx context.Context, status string, offset int, limit int) ([]models.ErasureRequest, error) {
+	ctx, cancel := r.context(ctx, "ListErasureRequests")
+	defer cancel()
+	return r.repo.ListErasureRequests(ctx, status, offset, limit)
+}
+
+func (r *timeoutRepository) RejectErasureRequest(ctx context.Context, id uint, reviewedBy uint, note string) (*models.ErasureRequest, error) {
+	ctx, cancel := r.context(ctx, "RejectErasureRequest")
+	defer cancel()
+	return r.repo.RejectErasureRequest(ctx, id, reviewedBy, note)
+}
+
+func (r *timeoutRepository) EraseUser(ctx context.Context, requestID uint, reviewedBy uint, note string) (*models.ErasureRequest, error) {
+	ctx, cancel := r.context(ctx, "EraseUser")
+	defer cancel()
+	return r.repo.EraseUser(ctx, requestID, reviewedBy, note)
+}
+
+func (r *timeoutRepository) CreateOrganization(ctx context.Context, org *models.Organization) error {
+	ctx, cancel := r.context(ctx, "CreateOrganization")
+	defer cancel()
+	return r.repo.CreateOrganization(ctx, org)
+}
+
+func (r *timeoutRepository) GetOrganization(ctx context.Context, id uint) (*models.Organization, error) {
+	ctx, cancel := r.context(ctx, "GetOrganization")
+	defer cancel()
+	return r.repo.GetOrganization(ctx, id)
+}
+
+func (r *timeoutRepository) ListOrganizations(ctx context.Context, offset int, limit int) ([]models.Organization, error) {
+	ctx, cancel := r.context(ctx, "ListOrganizations")
+	defer cancel()
+	return r.repo.ListOrganizations(ctx, offset, limit)
+}
+
+func (r *timeoutRepository) DeleteOrganization(ctx context.Context, id uint) error {
+	ctx, cancel := r.context(ctx, "DeleteOrganization")
+	defer cancel()
+	return r.repo.DeleteOrganization(ctx, id)
+}
+
+func (r *timeoutRepository) SetMembership(ctx context.Context, m *models.Membership) error {
+	ctx, cancel := r.context(ctx, "SetMembership")
+	defer cancel()
+	return r.repo.SetMembership(ctx, m)
+}
+
+func (r *timeoutRepository) RemoveMembership(ctx context.Context, orgID uint, userID uint) error {
+	ctx, cancel := r.context(ctx, "RemoveMembership")
+	defer cancel()
+	return r.repo.RemoveMembership(ctx, orgID, userID)
+}
+
+func (r *timeoutRepository) GetMembership(ctx context.Context, orgID uint, userID uint) (*models.Membership, error) {
+	ctx, cancel := r.context(ctx, "GetMembership")
+	defer cancel()
+	return r.repo.GetMembership(ctx, orgID, userID)
+}
+
+func (r *timeoutRepository) ListMembers(ctx context.Context, orgID uint, offset int, limit int) ([]models.Membership, error) {
+	ctx, cancel := r.context(ctx, "ListMembers")
+	defer cancel()
+	return r.repo.ListMembers(ctx, orgID, offset, limit)
+}
+
+func (r *timeoutRepository) ListUserMemberships(ctx context.Context, userID uint) ([]models.Membership, error) {
+	ctx, cancel := r.context(ctx, "ListUserMemberships")
+	defer cancel()
+	return r.repo.ListUserMemberships(ctx, userID)
+}
+
+func (r *timeoutRepository) CreateGroup(ctx context.Context, group *models.Group) error {
+	ctx, cancel := r.context(ctx, "CreateGroup")
+	defer cancel()
+	return r.repo.CreateGroup(ctx, group)
+}
+
+func (r *timeoutRepository) GetGroup(ctx context.Context, id uint) (*models.Group, error) {
+	ctx, cancel := r.context(ctx, "GetGroup")
+	defer cancel()
+	return r.repo.GetGroup(ctx, id)
+}
+
+func (r *timeoutRepository) ListGroups(ctx context.Context) ([]models.Group, error) {
+	ctx, cancel := r.context(ctx, "ListGroups")
+	defer cancel()
+	return r.repo.ListGroups(ctx)
+}
+
+func (r *timeoutRepository) UpdateGroup(ctx context.Context, group *models.Group) error {
+	ctx, cancel := r.context(ctx, "UpdateGroup")
+	defer cancel()
+	return r.repo.UpdateGroup(ctx, group)
+}
+
+func (r *timeoutRepository) DeleteGroup(ctx context.Context, id uint) error {
+	ctx, cancel := r.context(ctx, "DeleteGroup")
+	defer cancel()
+	return r.repo.DeleteGroup(ctx, id)
+}
+
+func (r *timeoutRepository) AddGroupMember(ctx context.Context, groupID uint, userID uint) error {
+	ctx, cancel := r.context(ctx, "AddGroupMember")
+	defer cancel()
+	return r.repo.AddGroupMember(ctx, groupID, userID)
+}
+
+func (r *timeoutRepository) RemoveGroupMember(ctx context.Context, groupID uint, userID uint) error {
+	ctx, cancel := r.context(ctx, "RemoveGroupMember")
+	defer cancel()
+	return r.repo.RemoveGroupMember(ctx, groupID, userID)
+}
+
+func (r *timeoutRepository) ListGroupMembers(ctx context.Context, groupID uint, offset int, limit int) ([]models.GroupMember, error) {
+	ctx, cancel := r.context(ctx, "ListGroupMembers")
+	defer cancel()
+	return r.repo.ListGroupMembers(ctx, groupID, offset, limit)
+}
+
+func (r *timeoutRepository) ListUserGroups(ctx context.Context, userID uint) ([]models.Group, error) {
+	ctx, cancel := r.context(ctx, "ListUserGroups")
+	defer cancel()
+	return r.repo.ListUserGroups(ctx, userID)
+}
+
+func (r *timeoutRepository) GetProfile(ctx context.Context, userID uint) (*models.Profile, error) {
+	ctx, cancel := r.context(ctx, "GetProfile")
+	defer cancel()
+	return r.repo.GetProfile(ctx, userID)
+}
+
+func (r *timeoutRepository) SaveProfile(ctx context.Context, profile *models.Profile) error {
+	ctx, cancel := r.context(ctx, "SaveProfile")
+	defer cancel()
+	return r.repo.SaveProfile(ctx, profile)
+}
+
+func (r *timeoutRepository) ListAttributeDefinitions(ctx context.Context) ([]models.AttributeDefinition, error) {
+	ctx, cancel := r.context(ctx, "ListAttributeDefinitions")
+	defer cancel()
+	return r.repo.ListAttributeDefinitions(ctx)
+}
+
+func (r *timeoutRepository) CreateAttributeDefinition(ctx context.Context, def *models.AttributeDefinition) error {
+	ctx, cancel := r.context(ctx, "CreateAttributeDefinition")
+	defer cancel()
+	return r.repo.CreateAttributeDefinition(ctx, def)
+}
+
+func (r *timeoutRepository) UpdateAttributeDefinition(ctx context.Context, def *models.AttributeDefinition) error {
+	ctx, cancel := r.context(ctx, "UpdateAttributeDefinition")
+	defer cancel()
+	return r.repo.UpdateAttributeDefinition(ctx, def)
+}
+
+func (r *timeoutRepository) DeleteAttributeDefinition(ctx context.Context, key string) error {
+	ctx, cancel := r.context(ctx, "DeleteAttributeDefinition")
+	defer cancel()
+	return r.repo.DeleteAttributeDefinition(ctx, key)
+}
+
+func (r *timeoutRepository) RecordLogin(ctx context.Context, login *models.Login) error {
+	ctx, cancel := r.context(ctx, "RecordLogin")
+	defer cancel()
+	return r.repo.RecordLogin(ctx, login)
+}
+
+func (r *timeoutRepository) DeleteLogins(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := r.context(ctx, "DeleteLogins")
+	defer cancel()
+	return r.repo.DeleteLogins(ctx, before)
+}
+
+func (r *timeoutRepository) RegistrationCounts(ctx context.Context, q StatsQuery) ([]RegistrationCount, error) {
+	ctx, cancel := r.context(ctx, "RegistrationCounts")
+	defer cancel()
+	return r.repo.RegistrationCounts(ctx, q)
+}
+
+func (r *timeoutRepository) LoginCounts(ctx context.Context, q StatsQuery) ([]LoginCount, error) {
+	ctx, cancel := r.context(ctx, "LoginCounts")
+	defer cancel()
+	return r.repo.LoginCounts(ctx, q)
+}
+
+func (r *timeoutRepository) ActiveUsers(ctx context.Context, orgID uint, from, to time.Time) (int64, error) {
+	ctx, cancel := r.context(ctx, "ActiveUsers")
+	defer cancel()
+	return r.repo.ActiveUsers(ctx, orgID, from, to)
+}
+
+func (r *timeoutRepository) SaveEmailChange(ctx context.Context, change *models.EmailChange) error {
+	ctx, cancel := r.context(ctx, "SaveEmailChange")
+	defer cancel()
+	return r.repo.SaveEmailChange(ctx, change)
+}
+
+func (r *timeoutRepository) GetEmailChange(ctx context.Context, tokenHash string) (*models.EmailChange, error) {
+	ctx, cancel := r.context(ctx, "GetEmailChange")
+	defer cancel()
+	return r.repo.GetEmailChange(ctx, tokenHash)
+}
+
+func (r *timeoutRepository) DeleteEmailChange(ctx context.Context, userID uint) error {
+	ctx, cancel := r.context(ctx, "DeleteEmailChange")
+	defer cancel()
+	return r.repo.DeleteEmailChange(ctx, userID)
+}
+
+func (r *timeoutRepository) ConfirmEmailChange(ctx context.Context, tokenHash string, now time.Time) (*models.User, error) {
+	ctx, cancel := r.context(ctx, "ConfirmEmailChange")
+	defer cancel()
+	return r.repo.ConfirmEmailChange(ctx, tokenHash, now)
+}
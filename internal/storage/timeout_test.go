@@ -0,0 +1,51 @@
+package storage_test
+
+import (
+	"backend-app/internal/storage"
+	"backend-app/internal/storage/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// deadlineRepo запоминает дедлайн контекста, с которым его вызвали.
+type deadlineRepo struct {
+	storage.Store
+	deadline time.Time
+	ok       bool
+}
+
+func (r *deadlineRepo) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	r.deadline, r.ok = ctx.Deadline()
+	return nil, ctx.Err()
+}
+
+func (r *deadlineRepo) ListUsers(ctx context.Context, q storage.UserQuery) (storage.UserPage, error) {
+	r.deadline, r.ok = ctx.Deadline()
+	return storage.UserPage{}, ctx.Err()
+}
+
+func TestWithTimeouts(t *testing.T) {
+	inner := &deadlineRepo{}
+	repo := storage.WithTimeouts(inner, storage.Timeouts{
+		Default:      time.Second,
+		PerOperation: map[string]time.Duration{"ListUsers": time.Minute, "GetUserByID": 0},
+	})
+
+	_, err := repo.ListUsers(context.Background(), storage.UserQuery{})
+	assert.NoError(t, err)
+	assert.True(t, inner.ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), inner.deadline, time.Second)
+
+	_, err = repo.GetUserByID(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.False(t, inner.ok, "zero timeout disables the deadline")
+
+	// более ранний дедлайн запроса не продлевается
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _ = repo.ListUsers(ctx, storage.UserQuery{})
+	assert.WithinDuration(t, time.Now().Add(10*time.Millisecond), inner.deadline, 10*time.Millisecond)
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"backend-app/internal/storage/models"
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// WebhookRepository хранит webhooks и очередь их доставок.
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, hook *models.Webhook) error
+	GetWebhook(ctx context.Context, id uint) (*models.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]models.Webhook, error)
+	UpdateWebhook(ctx context.Context, hook *models.Webhook) error
+	// DeleteWebhook удаляет webhook вместе с его доставками.
+	DeleteWebhook(ctx context.Context, id uint) error
+	// EnqueueDeliveries ставит доставки в очередь. Доставка события, уже поставленного
+	// в очередь того же webhook, пропускается: relay может опубликовать событие дважды.
+	EnqueueDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error
+	// ClaimDeliveries забирает до limit ожидающих доставок и на время lease скрывает
+	// их от других обработчиков, так же как Outbox.ClaimEvents.
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, id uint) (*models.WebhookDelivery, error)
+	// UpdateDelivery сохраняет статус и результат последней попытки доставки.
+	UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	// ListDeliveries возвращает доставки webhook, новые первыми. Пустой status - все статусы.
+	ListDeliveries(ctx context.Context, webhookID uint, status string, offset int, limit int) ([]models.WebhookDelivery, error)
+}
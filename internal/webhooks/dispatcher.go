@@ -0,0 +1,60 @@
+package webhooks
+
+import (
+	"backend-app/internal/events"
+	"backend-app/internal/storage/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type Store interface {
+	ListWebhooks(ctx context.Context) ([]models.Webhook, error)
+	EnqueueDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error
+}
+
+// Dispatcher - events.Publisher, который ставит событие в очередь доставки каждого
+// активного webhook, подписанного на его тип. Отправляет их jobs/delivery.
+type Dispatcher struct {
+	Store Store
+}
+
+func (d Dispatcher) Publish(ctx context.Context, event events.Event) error {
+	hooks, err := d.Store.ListWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var deliveries []models.WebhookDelivery
+	for _, hook := range hooks {
+		if !hook.Active || !hook.Subscribed(event.Type) {
+			continue
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			WebhookID:     hook.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			UserID:        event.UserID,
+			Payload:       string(payload),
+			Status:        models.DeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+	return d.Store.EnqueueDeliveries(ctx, deliveries)
+}
+
+// CheckEventTypes проверяет, что webhook подписывают только на известные события.
+func CheckEventTypes(types []string) error {
+	for _, t := range types {
+		if !events.IsType(t) {
+			return fmt.Errorf("unknown event type %q", t)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package webhooks
+
+import (
+	"backend-app/internal/events"
+	"backend-app/internal/storage/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Send отправляет доставку на webhook и возвращает HTTP-статус ответа, 0 если
+// ответа не было. Ответ вне 2xx считается ошибкой.
+func Send(ctx context.Context, client *http.Client, hook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "backend-app-webhooks")
+	// у Ping нет записи в журнале доставок
+	if delivery.ID != 0 {
+		req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	}
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(hook.Secret, now, body))
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// дочитываем тело, чтобы соединение вернулось в пул
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// TypePing - тип тестового события, которое отправляет Ping.
+const TypePing = "webhook.ping"
+
+// Ping синхронно отправляет на webhook тестовое событие, не ставя его в очередь.
+func Ping(ctx context.Context, client *http.Client, hook *models.Webhook) (int, error) {
+	data, err := json.Marshal(map[string]uint{"webhookId": hook.ID})
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	payload, err := json.Marshal(events.Event{
+		ID:         fmt.Sprintf("%s:%d:%d", TypePing, hook.ID, now.UnixNano()),
+		Type:       TypePing,
+		OccurredAt: now,
+		Data:       data,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return Send(ctx, client, hook, &models.WebhookDelivery{
+		WebhookID: hook.ID,
+		EventType: TypePing,
+		Payload:   string(payload),
+	})
+}
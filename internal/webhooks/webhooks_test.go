@@ -0,0 +1,118 @@
+package webhooks_test
+
+import (
+	"backend-app/internal/events"
+	"backend-app/internal/storage/memory"
+	"backend-app/internal/storage/models"
+	"backend-app/internal/webhooks"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":"user.created:1:1"}`)
+	now := time.Now()
+	ts := strconv.FormatInt(now.Unix(), 10)
+	signature := webhooks.Sign("secret", now, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		body      []byte
+		wantErr   bool
+	}{
+		{"valid", "secret", ts, signature, body, false},
+		{"wrong secret", "other", ts, signature, body, true},
+		{"tampered body", "secret", ts, signature, []byte(`{"id":"user.created:1:2"}`), true},
+		{"old timestamp", "secret", strconv.FormatInt(now.Add(-time.Hour).Unix(), 10), webhooks.Sign("secret", now.Add(-time.Hour), body), body, true},
+		{"bad timestamp", "secret", "yesterday", signature, body, true},
+		{"no prefix", "secret", ts, signature[len("sha256="):], body, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := webhooks.Verify(tt.secret, tt.timestamp, tt.signature, tt.body, 5*time.Minute)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, webhooks.ErrInvalidSignature)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSend(t *testing.T) {
+	var got *http.Request
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	hook := &models.Webhook{ID: 1, URL: srv.URL, Secret: "secret"}
+	delivery := &models.WebhookDelivery{ID: 7, WebhookID: 1, EventType: events.TypeUserCreated, Payload: `{"type":"user.created"}`}
+	status, err := webhooks.Send(t.Context(), srv.Client(), hook, delivery)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, status)
+
+	assert.Equal(t, "7", got.Header.Get(webhooks.HeaderDelivery))
+	assert.Equal(t, events.TypeUserCreated, got.Header.Get(webhooks.HeaderEvent))
+	assert.Equal(t, delivery.Payload, string(gotBody))
+	assert.NoError(t, webhooks.Verify("secret", got.Header.Get(webhooks.HeaderTimestamp), got.Header.Get(webhooks.HeaderSignature), gotBody, time.Minute))
+}
+
+func TestSendError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	hook := &models.Webhook{ID: 1, URL: srv.URL, Secret: "secret"}
+	status, err := webhooks.Ping(t.Context(), srv.Client(), hook)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadGateway, status)
+}
+
+func TestDispatcher(t *testing.T) {
+	store := memory.New()
+	hooks := []*models.Webhook{
+		{URL: "https://example.com/a", Secret: "s", Events: "user.created", Active: true},
+		{URL: "https://example.com/b", Secret: "s", Events: "user.deleted", Active: true},
+		{URL: "https://example.com/c", Secret: "s", Events: "user.created", Active: false},
+	}
+	for _, hook := range hooks {
+		require.NoError(t, store.CreateWebhook(t.Context(), hook))
+	}
+
+	event := events.Event{ID: "user.created:1:1", Type: events.TypeUserCreated, UserID: 1, Data: json.RawMessage(`{}`)}
+	dispatcher := webhooks.Dispatcher{Store: store}
+	require.NoError(t, dispatcher.Publish(t.Context(), event))
+	require.NoError(t, dispatcher.Publish(t.Context(), event), "republished event is not queued twice")
+
+	claimed, err := store.ClaimDeliveries(t.Context(), 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1, "only active subscribed webhooks get the event")
+	assert.Equal(t, hooks[0].ID, claimed[0].WebhookID)
+	assert.Equal(t, event.ID, claimed[0].EventID)
+	assert.Equal(t, models.DeliveryPending, claimed[0].Status)
+
+	var sent events.Event
+	require.NoError(t, json.Unmarshal([]byte(claimed[0].Payload), &sent))
+	assert.Equal(t, event.ID, sent.ID)
+}
+
+func TestCheckEventTypes(t *testing.T) {
+	assert.NoError(t, webhooks.CheckEventTypes([]string{events.TypeUserCreated, events.TypeUserVerified}))
+	assert.Error(t, webhooks.CheckEventTypes([]string{events.TypeUserCreated, "user.exploded"}))
+}
@@ -0,0 +1,78 @@
+package response
+
+// Коды ошибок в Problem.Code. Коды - часть API: их не переименовывают, новые
+// добавляют, когда клиенту нужно отличать ошибку от остальных с тем же статусом.
+const (
+	// запрос
+	CodeInvalidBody          = "invalid_body"
+	CodeInvalidParameter     = "invalid_parameter"
+	CodeValidationFailed     = "validation_failed"
+	CodeNotFound             = "not_found"
+	CodeMethodNotAllowed     = "method_not_allowed"
+	CodePayloadTooLarge      = "payload_too_large"
+	CodePreconditionRequired = "precondition_required"
+	CodePreconditionFailed   = "precondition_failed"
+	CodeVersionConflict      = "version_conflict"
+	CodeRateLimited          = "rate_limited"
+	CodeInternal             = "internal_error"
+	CodeTimeout              = "timeout"
+	CodeCanceled             = "request_canceled"
+
+	// аутентификация и доступ
+	CodeUnauthorized        = "unauthorized"
+	CodeInvalidCredentials  = "invalid_credentials"
+	CodeInvalidPassword     = "invalid_password"
+	CodeInvalidRefreshToken = "invalid_refresh_token"
+	CodeTokenRevoked        = "token_revoked"
+	CodeInvalidCSRFToken    = "invalid_csrf_token"
+	CodeForbidden           = "forbidden"
+	CodeUserSuspended       = "user_suspended"
+	CodeNotMember           = "not_member"
+
+	// пользователи
+	CodeUserNotFound               = "user_not_found"
+	CodeUserExists                 = "user_exists"
+	CodeUsernameTaken              = "username_taken"
+	CodeEmailTaken                 = "email_taken"
+	CodeInvalidUsername            = "invalid_username"
+	CodeReservedUsername           = "reserved_username"
+	CodeEmailUnchanged             = "email_unchanged"
+	CodeChangeCooldown             = "change_cooldown"
+	CodeInvalidConfirmationToken   = "invalid_confirmation_token"
+	CodeMailerUnavailable          = "mailer_unavailable"
+	CodeUnknownCountry             = "unknown_country"
+	CodeCountryNotAllowed          = "country_not_allowed"
+	CodeInvalidImportFile          = "invalid_import_file"
+	CodeInvalidAttribute           = "invalid_attribute"
+	CodeAttributeNotFound          = "attribute_not_found"
+	CodeAttributeExists            = "attribute_exists"
+	CodeInvalidAttributeDefinition = "invalid_attribute_definition"
+	CodeUnknownPermission          = "unknown_permission"
+
+	// организации и группы
+	CodeOrganizationNotFound = "organization_not_found"
+	CodeOrganizationExists   = "organization_exists"
+	CodeOrganizationNotEmpty = "organization_not_empty"
+	CodeMembershipNotFound   = "membership_not_found"
+	CodeGroupNotFound        = "group_not_found"
+	CodeGroupExists          = "group_exists"
+	CodeGroupHasChildren     = "group_has_children"
+	CodeGroupParentNotFound  = "group_parent_not_found"
+	CodeGroupCycle           = "group_cycle"
+	CodeGroupMemberNotFound  = "group_member_not_found"
+
+	// webhooks
+	CodeWebhookNotFound  = "webhook_not_found"
+	CodeDeliveryNotFound = "delivery_not_found"
+	CodeDeliveryPending  = "delivery_pending"
+	CodeUnknownEventType = "unknown_event_type"
+
+	// персональные данные
+	CodeUnknownExportFormat = "unknown_export_format"
+	CodeDataExportNotFound  = "data_export_not_found"
+	CodeDataExportNotReady  = "data_export_not_ready"
+	CodeErasureNotFound     = "erasure_request_not_found"
+	CodeErasurePending      = "erasure_request_pending"
+	CodeErasureNotPending   = "erasure_request_not_pending"
+	CodeErasureNotCompleted = "erasure_request_not_completed"
+)
@@ -0,0 +1,152 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-playground/validator/v10"
+)
+
+// ProblemContentType - тип ответов с ошибками по RFC 7807.
+const ProblemContentType = "application/problem+json"
+
+// Problem - ответ с ошибкой по RFC 7807. Клиентам стоит разбирать ошибки по Code:
+// он не меняется, а Detail пишется для людей и может меняться.
+type Problem struct {
+	// Type всегда about:blank, вид ошибки передаёт Code
+	Type   string `json:"type" example:"about:blank"`
+	Title  string `json:"title" example:"Not Found"`
+	Status int    `json:"status" example:"404"`
+	Code   string `json:"code" example:"user_not_found"`
+	Detail string `json:"detail,omitempty" example:"user not found"`
+	// Instance - путь запроса, в котором случилась ошибка
+	Instance  string `json:"instance,omitempty" example:"/v1/user/42"`
+	RequestID string `json:"requestId,omitempty"`
+	// Errors - ошибки отдельных полей тела запроса
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError - ошибка в поле тела запроса. Field - имя поля из JSON, для вложенных
+// полей через точку, Code - нарушенное правило, например required или email.
+type FieldError struct {
+	Field  string `json:"field" example:"email"`
+	Code   string `json:"code" example:"email"`
+	Detail string `json:"detail" example:"must be a valid email"`
+}
+
+// NewProblem - ошибка со статусом status и кодом code, detail поясняет её человеку.
+func NewProblem(status int, code, detail string) Problem {
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+		Detail: detail,
+	}
+}
+
+// WriteProblem отправляет p клиенту, дополнив его путём и id запроса.
+func WriteProblem(w http.ResponseWriter, r *http.Request, p Problem) {
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+	if p.RequestID == "" {
+		p.RequestID = middleware.GetReqID(r.Context())
+	}
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	// структура без map и интерфейсов всегда сериализуется
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// Fail отправляет ошибку без ошибок полей, короткая форма WriteProblem.
+func Fail(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	WriteProblem(w, r, NewProblem(status, code, detail))
+}
+
+// ContextError переводит ошибку прерванного контекста в ответ: 504, если хранилище
+// не уложилось в таймаут, 503, если запрос отменили раньше.
+func ContextError(err error) (Problem, bool) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return NewProblem(http.StatusGatewayTimeout, CodeTimeout, "request timed out"), true
+	case errors.Is(err, context.Canceled):
+		return NewProblem(http.StatusServiceUnavailable, CodeCanceled, "request canceled"), true
+	default:
+		return Problem{}, false
+	}
+}
+
+// ValidationFailed - ответ 422 на ошибку validator с ошибкой каждого поля. Имена
+// полей берутся из тегов json, если validator создан через validate.New.
+func ValidationFailed(err error) Problem {
+	p := NewProblem(http.StatusUnprocessableEntity, CodeValidationFailed, "validation failed")
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return p
+	}
+	for _, fe := range errs {
+		p.Errors = append(p.Errors, FieldError{
+			Field:  fieldName(fe),
+			Code:   fe.Tag(),
+			Detail: fieldDetail(fe),
+		})
+	}
+	return p
+}
+
+// FieldErrors переводит ошибку validator.Var для значения поля field в FieldError.
+func FieldErrors(field string, err error) []FieldError {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return []FieldError{{Field: field, Code: "invalid", Detail: "is invalid"}}
+	}
+	fields := make([]FieldError, 0, len(errs))
+	for _, fe := range errs {
+		fields = append(fields, FieldError{Field: field, Code: fe.Tag(), Detail: fieldDetail(fe)})
+	}
+	return fields
+}
+
+// fieldName отрезает от пути поля имя корневой структуры: Request.profile.bio -> profile.bio.
+func fieldName(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	for i := 0; i < len(ns); i++ {
+		if ns[i] == '.' {
+			return ns[i+1:]
+		}
+	}
+	return fe.Field()
+}
+
+func fieldDetail(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email"
+	case "url", "http_url":
+		return "must be a valid URL"
+	case "oneof":
+		return "must be one of: " + fe.Param()
+	case "min", "max", "len":
+		bound := map[string]string{"min": "at least", "max": "at most", "len": "exactly"}[fe.Tag()]
+		switch fe.Kind() {
+		case reflect.String:
+			return "must be " + bound + " " + fe.Param() + " characters long"
+		case reflect.Slice, reflect.Map, reflect.Array:
+			return "must have " + bound + " " + fe.Param() + " items"
+		default:
+			return "must be " + bound + " " + fe.Param()
+		}
+	case "unique":
+		return "must not contain duplicates"
+	default:
+		return "failed on the " + fe.Tag() + " rule"
+	}
+}
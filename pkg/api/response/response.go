@@ -2,12 +2,10 @@ package response
 
 type Response struct {
 	Status string `json:"status"`
-	Error  string `json:"error,omitempty"`
 }
 
 const (
-	StatusOK    = "OK"
-	StatusError = "Error"
+	StatusOK = "OK"
 )
 
 func OK() Response {
@@ -15,9 +13,11 @@ func OK() Response {
 		Status: StatusOK,
 	}
 }
-func Error(err string) Response {
-	return Response{
-		Status: StatusError,
-		Error:  err,
-	}
+
+// Page - общий конверт для постраничных ответов.
+type Page struct {
+	Items      interface{} `json:"items"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+	PrevCursor string      `json:"prevCursor,omitempty"`
+	Total      *int64      `json:"total,omitempty"`
 }
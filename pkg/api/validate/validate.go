@@ -0,0 +1,23 @@
+// Package validate создаёт validator, который называет поля по тегам json: под
+// этими именами их видят клиенты в ошибках валидации.
+package validate
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// New возвращает validator с именами полей из тегов json.
+func New() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			return f.Name
+		}
+		return name
+	})
+	return v
+}
@@ -7,10 +7,15 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func GenerateTokenPair(userID uint, role string) (config.TokenPair, error) {
+// GenerateTokenPair выпускает токены для организации orgID, 0 - без организации.
+// Права perms получает только access token: refresh пересчитывает их заново.
+func GenerateTokenPair(userID uint, role string, orgID uint, version uint, perms []string) (config.TokenPair, error) {
 	accessClaims := &config.Claims{
-		UserID: userID,
-		Role:   role,
+		UserID:       userID,
+		Role:         role,
+		TokenVersion: version,
+		OrgID:        orgID,
+		Perms:        perms,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(config.AccessTokenExpiry)),
 		},
@@ -23,8 +28,10 @@ func GenerateTokenPair(userID uint, role string) (config.TokenPair, error) {
 	}
 
 	refreshClaims := &config.Claims{
-		UserID: userID,
-		Role:   role,
+		UserID:       userID,
+		Role:         role,
+		TokenVersion: version,
+		OrgID:        orgID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(config.RefreshTokenExpiry)),
 		},